@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tuple ADD COLUMN expires_at TIMESTAMP NULL;
+CREATE INDEX idx_tuple_expires_at ON tuple (expires_at);
+
+-- +goose Down
+DROP INDEX idx_tuple_expires_at ON tuple;
+ALTER TABLE tuple DROP COLUMN expires_at;
@@ -0,0 +1,352 @@
+// Package benchcheck contains the command to benchmark the latency of Check requests against a running OpenFGA server.
+package benchcheck
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+const (
+	grpcAddrFlag    = "grpc-addr"
+	apiTokenFlag    = "api-token"
+	tlsEnabledFlag  = "tls-enabled"
+	storeIDFlag     = "store-id"
+	modelIDFlag     = "model-id"
+	tupleFlag       = "tuple"
+	tuplesFileFlag  = "tuples-file"
+	qpsFlag         = "qps"
+	durationFlag    = "duration"
+	concurrencyFlag = "concurrency"
+
+	// sequencePlaceholder is replaced, in every check tuple, by the sequence number of the Check request.
+	sequencePlaceholder = "{i}"
+)
+
+// histogramBuckets are the upper bounds of the latency histogram printed at the end of a benchmark.
+var histogramBuckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+func NewBenchCheckCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bench-check",
+		Short: "Benchmark the latency of Check requests against a store of a running OpenFGA server",
+		Long: "Connect to a running OpenFGA server and issue Check requests against a store and authorization model at a target rate " +
+			"for a duration, then print the latency percentiles, a latency histogram and the error rate.\n" +
+			"Check tuples are written as 'object#relation@user'. The placeholder '" + sequencePlaceholder + "' in a tuple is replaced " +
+			"by the sequence number of the request, e.g. 'document:{i}#viewer@user:anne', to generate distinct checks.",
+		RunE: runBenchCheck,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the grpc server to benchmark")
+	flags.String(apiTokenFlag, "", "the token sent as a bearer token, if the server requires authentication")
+	flags.Bool(tlsEnabledFlag, false, "connect to the grpc server using TLS")
+	flags.String(storeIDFlag, "", "the id of the store to run the Check requests against")
+	flags.String(modelIDFlag, "", "the id of the authorization model to run the Check requests against. If empty, the latest model of the store is used")
+	flags.StringArray(tupleFlag, []string{}, "a check tuple, as 'object#relation@user'. Can be repeated; the tuples are checked in a round-robin fashion")
+	flags.String(tuplesFileFlag, "", "a file with one check tuple per line, checked along with the tuples given by --"+tupleFlag)
+	flags.Int(qpsFlag, 100, "the target number of Check requests per second")
+	flags.Duration(durationFlag, 30*time.Second, "how long to run the benchmark for")
+	flags.Int(concurrencyFlag, 10, "the maximum number of Check requests in flight")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindRunFlagsFunc(flags)
+
+	return cmd
+}
+
+func runBenchCheck(cmd *cobra.Command, _ []string) error {
+	storeID := viper.GetString(storeIDFlag)
+	if storeID == "" {
+		return fmt.Errorf("the --%s flag is required", storeIDFlag)
+	}
+
+	tupleKeys, err := parseTupleKeys(viper.GetStringSlice(tupleFlag), viper.GetString(tuplesFileFlag))
+	if err != nil {
+		return err
+	}
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	conn, err := util.DialGRPCServer(dialCtx, viper.GetString(grpcAddrFlag), viper.GetBool(tlsEnabledFlag))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx := util.ContextWithAPIToken(context.Background(), viper.GetString(apiTokenFlag))
+
+	result, err := RunBenchmark(ctx, openfgapb.NewOpenFGAServiceClient(conn), BenchmarkConfig{
+		StoreID:     storeID,
+		ModelID:     viper.GetString(modelIDFlag),
+		TupleKeys:   tupleKeys,
+		QPS:         viper.GetInt(qpsFlag),
+		Duration:    viper.GetDuration(durationFlag),
+		Concurrency: viper.GetInt(concurrencyFlag),
+	})
+	if err != nil {
+		return err
+	}
+
+	result.Print(cmd.OutOrStdout())
+	return nil
+}
+
+// parseTupleKeys parses the check tuples given as flags and the ones in the file at path, if any.
+func parseTupleKeys(tuples []string, path string) ([]*openfgapb.TupleKey, error) {
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open the tuples file: %w", err)
+		}
+		defer f.Close()
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				tuples = append(tuples, line)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read the tuples file: %w", err)
+		}
+	}
+
+	if len(tuples) == 0 {
+		return nil, fmt.Errorf("at least one check tuple is required, use --%s or --%s", tupleFlag, tuplesFileFlag)
+	}
+
+	tupleKeys := make([]*openfgapb.TupleKey, 0, len(tuples))
+	for _, t := range tuples {
+		tk, err := ParseTupleKey(t)
+		if err != nil {
+			return nil, err
+		}
+		tupleKeys = append(tupleKeys, tk)
+	}
+
+	return tupleKeys, nil
+}
+
+// ParseTupleKey parses a tuple written as 'object#relation@user'. The user may be a userset, as in
+// 'document:1#viewer@group:eng#member'.
+func ParseTupleKey(s string) (*openfgapb.TupleKey, error) {
+	object, rest, ok := strings.Cut(s, "#")
+	if !ok {
+		return nil, fmt.Errorf("invalid check tuple '%s', expected 'object#relation@user'", s)
+	}
+
+	relation, user, ok := strings.Cut(rest, "@")
+	if !ok || object == "" || relation == "" || user == "" {
+		return nil, fmt.Errorf("invalid check tuple '%s', expected 'object#relation@user'", s)
+	}
+
+	return tuple.NewTupleKey(object, relation, user), nil
+}
+
+// BenchmarkConfig defines a Check benchmark.
+type BenchmarkConfig struct {
+	StoreID string
+
+	// ModelID is the authorization model the Check requests are evaluated against. If empty, the latest model is used.
+	ModelID string
+
+	// TupleKeys are checked in a round-robin fashion. The sequence placeholder '{i}' in any of their fields is
+	// replaced by the sequence number of the request.
+	TupleKeys []*openfgapb.TupleKey
+
+	// QPS is the target number of Check requests issued per second. Requests that can't be issued because
+	// Concurrency requests are already in flight are skipped and counted as such.
+	QPS int
+
+	Duration time.Duration
+
+	Concurrency int
+}
+
+// BenchmarkResult holds the outcome of every Check request issued during a benchmark.
+type BenchmarkResult struct {
+	Duration  time.Duration
+	Latencies []time.Duration
+	Allowed   int
+	Errors    int
+	Skipped   int
+}
+
+// RunBenchmark issues Check requests against a store at the configured rate until the configured duration has
+// elapsed or ctx is done, and returns the latency of every request.
+func RunBenchmark(ctx context.Context, client openfgapb.OpenFGAServiceClient, config BenchmarkConfig) (*BenchmarkResult, error) {
+	if len(config.TupleKeys) == 0 {
+		return nil, fmt.Errorf("at least one check tuple is required")
+	}
+	if config.QPS <= 0 {
+		return nil, fmt.Errorf("the target qps must be greater than 0")
+	}
+	if config.Concurrency <= 0 {
+		return nil, fmt.Errorf("the concurrency must be greater than 0")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.Duration)
+	defer cancel()
+
+	var (
+		mu     sync.Mutex
+		result BenchmarkResult
+		wg     sync.WaitGroup
+	)
+
+	requests := make(chan int)
+	for w := 0; w < config.Concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for i := range requests {
+				tk := config.TupleKeys[i%len(config.TupleKeys)]
+
+				start := time.Now()
+				resp, err := client.Check(ctx, &openfgapb.CheckRequest{
+					StoreId:              config.StoreID,
+					AuthorizationModelId: config.ModelID,
+					TupleKey:             withSequenceNumber(tk, i),
+				})
+				latency := time.Since(start)
+
+				if err != nil && ctx.Err() != nil {
+					// the request was cut short by the end of the benchmark
+					continue
+				}
+
+				mu.Lock()
+				result.Latencies = append(result.Latencies, latency)
+				if err != nil {
+					result.Errors++
+				} else if resp.GetAllowed() {
+					result.Allowed++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(time.Second / time.Duration(config.QPS))
+	defer ticker.Stop()
+
+	start := time.Now()
+loop:
+	for i := 0; ; i++ {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case requests <- i:
+		default:
+			result.Skipped++
+		}
+	}
+	close(requests)
+	wg.Wait()
+
+	result.Duration = time.Since(start)
+
+	return &result, nil
+}
+
+func withSequenceNumber(tk *openfgapb.TupleKey, i int) *openfgapb.TupleKey {
+	seq := strconv.Itoa(i)
+
+	return tuple.NewTupleKey(
+		strings.ReplaceAll(tk.GetObject(), sequencePlaceholder, seq),
+		strings.ReplaceAll(tk.GetRelation(), sequencePlaceholder, seq),
+		strings.ReplaceAll(tk.GetUser(), sequencePlaceholder, seq),
+	)
+}
+
+// Percentile returns the latency under which p percent of the requests completed.
+func (r *BenchmarkResult) Percentile(p float64) time.Duration {
+	if len(r.Latencies) == 0 {
+		return 0
+	}
+
+	sorted := make([]time.Duration, len(r.Latencies))
+	copy(sorted, r.Latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	idx := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+
+	return sorted[idx]
+}
+
+// Print writes a summary of the benchmark, the latency percentiles and a latency histogram to w.
+func (r *BenchmarkResult) Print(w io.Writer) {
+	total := len(r.Latencies)
+
+	fmt.Fprintf(w, "requests: %d in %s (%.1f/s)\n", total, r.Duration.Round(time.Millisecond), float64(total)/r.Duration.Seconds())
+	fmt.Fprintf(w, "allowed: %d, errors: %d (%.2f%%), skipped: %d\n", r.Allowed, r.Errors, percentOf(r.Errors, total), r.Skipped)
+
+	if total == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "latency:")
+	for _, p := range []float64{50, 90, 95, 99, 100} {
+		fmt.Fprintf(w, "  p%-3v %s\n", p, r.Percentile(p))
+	}
+
+	counts := make([]int, len(histogramBuckets)+1)
+	for _, l := range r.Latencies {
+		counts[sort.Search(len(histogramBuckets), func(i int) bool { return l <= histogramBuckets[i] })]++
+	}
+
+	fmt.Fprintln(w, "histogram:")
+	for i, count := range counts {
+		label := "+Inf"
+		if i < len(histogramBuckets) {
+			label = histogramBuckets[i].String()
+		}
+
+		fmt.Fprintf(w, "  <= %-6s %8d %6.2f%% %s\n", label, count, percentOf(count, total), strings.Repeat("#", int(percentOf(count, total)/2)))
+	}
+}
+
+func percentOf(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return float64(n) / float64(total) * 100
+}
@@ -0,0 +1,112 @@
+package benchcheck
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/openfga/openfga/cmd/run"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/openfga/openfga/tests"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestParseTupleKey(t *testing.T) {
+	tk, err := ParseTupleKey("document:1#viewer@user:anne")
+	require.NoError(t, err)
+	require.Equal(t, tuple.NewTupleKey("document:1", "viewer", "user:anne"), tk)
+
+	tk, err = ParseTupleKey("document:1#viewer@group:eng#member")
+	require.NoError(t, err)
+	require.Equal(t, tuple.NewTupleKey("document:1", "viewer", "group:eng#member"), tk)
+
+	for _, s := range []string{"document:1", "document:1#viewer", "#viewer@user:anne", "document:1#@user:anne"} {
+		_, err := ParseTupleKey(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestRunBenchmark(t *testing.T) {
+	cfg := run.MustDefaultConfigWithRandomPorts()
+	cfg.Log.Level = "none"
+	cfg.Datastore.Engine = "memory"
+
+	cancel := tests.StartServer(t, cfg)
+	defer cancel()
+
+	conn, err := grpc.Dial(cfg.GRPC.Addr,
+		grpc.WithBlock(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := openfgapb.NewOpenFGAServiceClient(conn)
+	ctx := context.Background()
+
+	createStoreResp, err := client.CreateStore(ctx, &openfgapb.CreateStoreRequest{Name: "bench-check"})
+	require.NoError(t, err)
+	storeID := createStoreResp.GetId()
+
+	writeModelResp, err := client.WriteAuthorizationModel(ctx, &openfgapb.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgapb.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgapb.Userset{
+					"viewer": typesystem.This(),
+				},
+				Metadata: &openfgapb.Metadata{
+					Relations: map[string]*openfgapb.RelationMetadata{
+						"viewer": {
+							DirectlyRelatedUserTypes: []*openfgapb.RelationReference{
+								typesystem.DirectRelationReference("user", ""),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = client.Write(ctx, &openfgapb.WriteRequest{
+		StoreId: storeID,
+		Writes: &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{
+			tuple.NewTupleKey("document:0", "viewer", "user:anne"),
+		}},
+	})
+	require.NoError(t, err)
+
+	result, err := RunBenchmark(ctx, client, BenchmarkConfig{
+		StoreID: storeID,
+		ModelID: writeModelResp.GetAuthorizationModelId(),
+		TupleKeys: []*openfgapb.TupleKey{
+			tuple.NewTupleKey("document:{i}", "viewer", "user:anne"),
+		},
+		QPS:         50,
+		Duration:    500 * time.Millisecond,
+		Concurrency: 5,
+	})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, result.Latencies)
+	require.Zero(t, result.Errors)
+	// only the check of document:0 is allowed
+	require.Equal(t, 1, result.Allowed)
+	require.LessOrEqual(t, result.Percentile(50), result.Percentile(100))
+
+	var out bytes.Buffer
+	result.Print(&out)
+	require.Contains(t, out.String(), "latency:")
+	require.Contains(t, out.String(), "histogram:")
+}
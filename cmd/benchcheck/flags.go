@@ -0,0 +1,24 @@
+package benchcheck
+
+import (
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// bindRunFlags binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(grpcAddrFlag, flags.Lookup(grpcAddrFlag))
+		util.MustBindPFlag(apiTokenFlag, flags.Lookup(apiTokenFlag))
+		util.MustBindPFlag(tlsEnabledFlag, flags.Lookup(tlsEnabledFlag))
+		util.MustBindPFlag(storeIDFlag, flags.Lookup(storeIDFlag))
+		util.MustBindPFlag(modelIDFlag, flags.Lookup(modelIDFlag))
+		util.MustBindPFlag(tupleFlag, flags.Lookup(tupleFlag))
+		util.MustBindPFlag(tuplesFileFlag, flags.Lookup(tuplesFileFlag))
+		util.MustBindPFlag(qpsFlag, flags.Lookup(qpsFlag))
+		util.MustBindPFlag(durationFlag, flags.Lookup(durationFlag))
+		util.MustBindPFlag(concurrencyFlag, flags.Lookup(concurrencyFlag))
+	}
+}
@@ -0,0 +1,263 @@
+// Package clonestore contains the command to copy a store into a new store.
+package clonestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/mysql"
+	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	datastoreEngineFlag   = "datastore-engine"
+	datastoreURIFlag      = "datastore-uri"
+	sourceStoreIDFlag     = "source-store-id"
+	targetStoreIDFlag     = "target-store-id"
+	targetStoreNameFlag   = "target-store-name"
+	continuationTokenFlag = "continuation-token"
+	batchSizeFlag         = "batch-size"
+	maxTuplesFlag         = "max-tuples"
+)
+
+func NewCloneStoreCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clone-store",
+		Short: "Copy a store into a new store. NOTE: this command is in beta and may be removed in future releases.",
+		Long: `Create a new store, copy the latest authorization model of the source store into it, and copy all the tuples of the source store into it in batches.
+The progress is printed after every batch. To resume an interrupted copy, or to continue a copy bounded by --max-tuples, run the command again with the --target-store-id and --continuation-token of the last progress line.
+NOTE: this command is in beta and may be removed in future releases.`,
+		RunE: runCloneStore,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine")
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.String(sourceStoreIDFlag, "", "the id of the store to copy")
+	flags.String(targetStoreIDFlag, "", "the id of the store to copy into, when resuming a copy. If empty, a new store is created")
+	flags.String(targetStoreNameFlag, "", "the name of the new store. If empty, the name of the source store is used")
+	flags.String(continuationTokenFlag, "", "the continuation token of the last progress line of the copy being resumed")
+	flags.Int(batchSizeFlag, storage.DefaultMaxTuplesPerWrite, "the number of tuples read and written at a time. It is capped to the maximum number of tuples per write of the datastore")
+	flags.Int(maxTuplesFlag, 0, "the maximum number of tuples copied by this run. If 0, all the tuples are copied")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindRunFlagsFunc(flags)
+
+	return cmd
+}
+
+func runCloneStore(_ *cobra.Command, _ []string) error {
+	engine := viper.GetString(datastoreEngineFlag)
+	uri := viper.GetString(datastoreURIFlag)
+
+	ctx := context.Background()
+
+	var (
+		db  storage.OpenFGADatastore
+		err error
+	)
+	switch engine {
+	case "mysql":
+		db, err = mysql.New(uri, sqlcommon.NewConfig())
+	case "postgres":
+		db, err = postgres.New(uri, sqlcommon.NewConfig())
+	case "sqlite":
+		db, err = sqlite.New(uri, sqlcommon.NewConfig())
+	case "":
+		return fmt.Errorf("missing datastore engine type")
+	case "memory":
+		fallthrough
+	default:
+		return fmt.Errorf("storage engine '%s' is unsupported", engine)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open a connection to the datastore: %v", err)
+	}
+	defer db.Close()
+
+	_, err = CloneStore(ctx, db, Options{
+		SourceStoreID:     viper.GetString(sourceStoreIDFlag),
+		TargetStoreID:     viper.GetString(targetStoreIDFlag),
+		TargetStoreName:   viper.GetString(targetStoreNameFlag),
+		ContinuationToken: viper.GetString(continuationTokenFlag),
+		BatchSize:         viper.GetInt(batchSizeFlag),
+		MaxTuples:         viper.GetInt(maxTuplesFlag),
+		OnProgress: func(progress Progress) {
+			marshalled, err := json.Marshal(progress)
+			if err == nil {
+				fmt.Println(string(marshalled))
+			}
+		},
+	})
+
+	return err
+}
+
+// Options configures a CloneStore call.
+type Options struct {
+	// SourceStoreID is the id of the store to copy.
+	SourceStoreID string
+
+	// TargetStoreID is the id of the store to copy into when resuming a copy. If empty, a new store is created and
+	// the latest authorization model of the source store is copied into it.
+	TargetStoreID string
+
+	// TargetStoreName is the name of the new store. If empty, the name of the source store is used.
+	TargetStoreName string
+
+	// ContinuationToken is the token of the last progress of the copy being resumed.
+	ContinuationToken string
+
+	// BatchSize is the number of tuples read and written at a time. It is capped to the MaxTuplesPerWrite of the
+	// datastore.
+	BatchSize int
+
+	// MaxTuples bounds the number of tuples copied by the call. If 0, all the tuples are copied.
+	MaxTuples int
+
+	// OnProgress, if set, is called after every batch of tuples is written.
+	OnProgress func(Progress)
+}
+
+// Progress reports the progress of a CloneStore call. A copy can be resumed from any progress it reports.
+type Progress struct {
+	TargetStoreID string `json:"target_store_id"`
+	ModelID       string `json:"model_id,omitempty"`
+	TuplesCopied  int    `json:"tuples_copied"`
+
+	// ContinuationToken is empty once all the tuples have been copied.
+	ContinuationToken string `json:"continuation_token"`
+}
+
+// CloneStore copies the latest authorization model and the tuples of a store into another store. The tuples are
+// written in batches, each in a single transaction, so a copy that is interrupted can be resumed from the last
+// progress it reported.
+func CloneStore(ctx context.Context, db storage.OpenFGADatastore, opts Options) (Progress, error) {
+	if opts.SourceStoreID == "" {
+		return Progress{}, errors.New("missing source store id")
+	}
+
+	if opts.TargetStoreID == "" && opts.ContinuationToken != "" {
+		return Progress{}, errors.New("a continuation token can only be used with the target store id of the copy being resumed")
+	}
+
+	source, err := db.GetStore(ctx, opts.SourceStoreID)
+	if err != nil {
+		return Progress{}, fmt.Errorf("error reading source store: %w", err)
+	}
+
+	progress := Progress{
+		TargetStoreID:     opts.TargetStoreID,
+		ContinuationToken: opts.ContinuationToken,
+	}
+
+	if progress.TargetStoreID == "" {
+		progress.TargetStoreID, progress.ModelID, err = createTargetStore(ctx, db, source, opts.TargetStoreName)
+		if err != nil {
+			return Progress{}, err
+		}
+	} else if _, err := db.GetStore(ctx, progress.TargetStoreID); err != nil {
+		return Progress{}, fmt.Errorf("error reading target store: %w", err)
+	}
+
+	batchSize := opts.BatchSize
+	if batchSize <= 0 || batchSize > db.MaxTuplesPerWrite() {
+		batchSize = db.MaxTuplesPerWrite()
+	}
+
+	for {
+		pageSize := batchSize
+		if opts.MaxTuples > 0 {
+			if progress.TuplesCopied >= opts.MaxTuples {
+				return progress, nil
+			}
+
+			if remaining := opts.MaxTuples - progress.TuplesCopied; remaining < pageSize {
+				pageSize = remaining
+			}
+		}
+
+		tuples, contToken, err := db.ReadPage(ctx, opts.SourceStoreID, &openfgapb.TupleKey{}, storage.PaginationOptions{
+			PageSize: pageSize,
+			From:     progress.ContinuationToken,
+		})
+		if err != nil {
+			return progress, fmt.Errorf("error reading tuples: %w", err)
+		}
+
+		if len(tuples) > 0 {
+			writes := make([]*openfgapb.TupleKey, 0, len(tuples))
+			for _, t := range tuples {
+				writes = append(writes, t.GetKey())
+			}
+
+			if err := db.Write(ctx, progress.TargetStoreID, nil, writes); err != nil {
+				return progress, fmt.Errorf("error writing tuples: %w", err)
+			}
+		}
+
+		progress.TuplesCopied += len(tuples)
+		progress.ContinuationToken = string(contToken)
+
+		if opts.OnProgress != nil {
+			opts.OnProgress(progress)
+		}
+
+		if progress.ContinuationToken == "" || len(tuples) == 0 {
+			progress.ContinuationToken = ""
+			return progress, nil
+		}
+	}
+}
+
+// createTargetStore creates the store to copy into and copies the latest authorization model of the source store,
+// if any, into it. The model keeps its id, so that clients pinning it work against the copy as well.
+func createTargetStore(ctx context.Context, db storage.OpenFGADatastore, source *openfgapb.Store, name string) (string, string, error) {
+	if name == "" {
+		name = source.GetName()
+	}
+
+	target, err := db.CreateStore(ctx, &openfgapb.Store{
+		Id:        ulid.Make().String(),
+		Name:      name,
+		CreatedAt: timestamppb.Now(),
+		UpdatedAt: timestamppb.Now(),
+	})
+	if err != nil {
+		return "", "", fmt.Errorf("error creating target store: %w", err)
+	}
+
+	modelID, err := db.FindLatestAuthorizationModelID(ctx, source.GetId())
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return target.GetId(), "", nil
+		}
+
+		return "", "", fmt.Errorf("error reading latest authorization model: %w", err)
+	}
+
+	model, err := db.ReadAuthorizationModel(ctx, source.GetId(), modelID)
+	if err != nil {
+		return "", "", fmt.Errorf("error reading authorization model '%s': %w", modelID, err)
+	}
+
+	if err := db.WriteAuthorizationModel(ctx, target.GetId(), proto.Clone(model).(*openfgapb.AuthorizationModel)); err != nil {
+		return "", "", fmt.Errorf("error writing authorization model '%s': %w", modelID, err)
+	}
+
+	return target.GetId(), modelID, nil
+}
@@ -0,0 +1,146 @@
+package clonestore
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	parser "github.com/craigpastro/openfga-dsl-parser/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+const totalTuples = 25
+
+func setupSourceStore(t *testing.T, ds storage.OpenFGADatastore) (string, string) {
+	ctx := context.Background()
+
+	storeID := ulid.Make().String()
+	_, err := ds.CreateStore(ctx, &openfgapb.Store{Id: storeID, Name: "production"})
+	require.NoError(t, err)
+
+	modelID := ulid.Make().String()
+	err = ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define viewer: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	var writes []*openfgapb.TupleKey
+	for i := 0; i < totalTuples; i++ {
+		writes = append(writes, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:jon"))
+	}
+	err = ds.Write(ctx, storeID, nil, writes)
+	require.NoError(t, err)
+
+	return storeID, modelID
+}
+
+func readAllTuples(t *testing.T, ds storage.OpenFGADatastore, storeID string) []*openfgapb.TupleKey {
+	iter, err := ds.Read(context.Background(), storeID, &openfgapb.TupleKey{})
+	require.NoError(t, err)
+	defer iter.Stop()
+
+	var tuples []*openfgapb.TupleKey
+	for {
+		tp, err := iter.Next()
+		if err == storage.ErrIteratorDone {
+			return tuples
+		}
+		require.NoError(t, err)
+
+		tuples = append(tuples, tp.GetKey())
+	}
+}
+
+func TestCloneStore(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	defer ds.Close()
+
+	sourceStoreID, modelID := setupSourceStore(t, ds)
+
+	var reported []Progress
+	progress, err := CloneStore(ctx, ds, Options{
+		SourceStoreID: sourceStoreID,
+		BatchSize:     10,
+		OnProgress: func(p Progress) {
+			reported = append(reported, p)
+		},
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, sourceStoreID, progress.TargetStoreID)
+	require.Equal(t, modelID, progress.ModelID)
+	require.Equal(t, totalTuples, progress.TuplesCopied)
+	require.Empty(t, progress.ContinuationToken)
+	require.Len(t, reported, 3)
+
+	target, err := ds.GetStore(ctx, progress.TargetStoreID)
+	require.NoError(t, err)
+	require.Equal(t, "production", target.GetName())
+
+	latestModelID, err := ds.FindLatestAuthorizationModelID(ctx, progress.TargetStoreID)
+	require.NoError(t, err)
+	require.Equal(t, modelID, latestModelID)
+
+	require.ElementsMatch(t, readAllTuples(t, ds, sourceStoreID), readAllTuples(t, ds, progress.TargetStoreID))
+}
+
+func TestCloneStoreResumes(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	defer ds.Close()
+
+	sourceStoreID, _ := setupSourceStore(t, ds)
+
+	progress, err := CloneStore(ctx, ds, Options{
+		SourceStoreID:   sourceStoreID,
+		TargetStoreName: "staging",
+		BatchSize:       10,
+		MaxTuples:       15,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 15, progress.TuplesCopied)
+	require.NotEmpty(t, progress.ContinuationToken)
+	require.Len(t, readAllTuples(t, ds, progress.TargetStoreID), 15)
+
+	resumed, err := CloneStore(ctx, ds, Options{
+		SourceStoreID:     sourceStoreID,
+		TargetStoreID:     progress.TargetStoreID,
+		ContinuationToken: progress.ContinuationToken,
+		BatchSize:         10,
+	})
+	require.NoError(t, err)
+	require.Equal(t, progress.TargetStoreID, resumed.TargetStoreID)
+	require.Equal(t, totalTuples-15, resumed.TuplesCopied)
+	require.Empty(t, resumed.ContinuationToken)
+
+	require.ElementsMatch(t, readAllTuples(t, ds, sourceStoreID), readAllTuples(t, ds, progress.TargetStoreID))
+}
+
+func TestCloneStoreInvalidOptions(t *testing.T) {
+	ctx := context.Background()
+	ds := memory.New()
+	defer ds.Close()
+
+	_, err := CloneStore(ctx, ds, Options{})
+	require.EqualError(t, err, "missing source store id")
+
+	_, err = CloneStore(ctx, ds, Options{SourceStoreID: ulid.Make().String(), ContinuationToken: "10"})
+	require.EqualError(t, err, "a continuation token can only be used with the target store id of the copy being resumed")
+
+	_, err = CloneStore(ctx, ds, Options{SourceStoreID: ulid.Make().String()})
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
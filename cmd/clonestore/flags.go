@@ -0,0 +1,22 @@
+package clonestore
+
+import (
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// bindRunFlags binds the cobra cmd flags to the equivalent config value being managed
+// by viper. This bridges the config between cobra flags and viper flags.
+func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		util.MustBindPFlag(datastoreEngineFlag, flags.Lookup(datastoreEngineFlag))
+		util.MustBindPFlag(datastoreURIFlag, flags.Lookup(datastoreURIFlag))
+		util.MustBindPFlag(sourceStoreIDFlag, flags.Lookup(sourceStoreIDFlag))
+		util.MustBindPFlag(targetStoreIDFlag, flags.Lookup(targetStoreIDFlag))
+		util.MustBindPFlag(targetStoreNameFlag, flags.Lookup(targetStoreNameFlag))
+		util.MustBindPFlag(continuationTokenFlag, flags.Lookup(continuationTokenFlag))
+		util.MustBindPFlag(batchSizeFlag, flags.Lookup(batchSizeFlag))
+		util.MustBindPFlag(maxTuplesFlag, flags.Lookup(maxTuplesFlag))
+	}
+}
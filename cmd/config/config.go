@@ -0,0 +1,37 @@
+// Package config contains the commands to inspect the OpenFGA server configuration.
+package config
+
+import (
+	"github.com/openfga/openfga"
+	"github.com/spf13/cobra"
+)
+
+// NewConfigCommand returns the command that groups the config subcommands.
+func NewConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the OpenFGA server configuration",
+		Long:  "Inspect the OpenFGA server configuration.",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(NewSchemaCommand())
+
+	return cmd
+}
+
+// NewSchemaCommand returns the command that prints the JSON schema of the server configuration.
+func NewSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON schema of the server configuration",
+		Long:  "Print the JSON schema of the server configuration that this binary was built with.\nIt can be used to validate config files with standard JSON schema tooling.",
+		RunE:  printSchema,
+		Args:  cobra.NoArgs,
+	}
+}
+
+func printSchema(cmd *cobra.Command, _ []string) error {
+	_, err := cmd.OutOrStdout().Write(openfga.ConfigSchema)
+	return err
+}
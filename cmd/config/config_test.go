@@ -0,0 +1,26 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/openfga/openfga/cmd"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchemaCommandPrintsTheConfigSchema(t *testing.T) {
+	expected, err := os.ReadFile("../../.config-schema.json")
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	rootCmd := cmd.NewRootCommand()
+	rootCmd.AddCommand(NewConfigCommand())
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{"config", "schema"})
+	require.NoError(t, rootCmd.Execute())
+
+	require.Equal(t, string(expected), out.String())
+	require.True(t, json.Valid(out.Bytes()))
+}
@@ -0,0 +1,111 @@
+package inspectmodel
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+// ModelToDSL returns the DSL form of the type definitions of the model, which parses back into the same type
+// definitions. The relations of each type are sorted by name.
+func ModelToDSL(model *openfgapb.AuthorizationModel) (string, error) {
+	var sb strings.Builder
+
+	for i, typeDef := range model.GetTypeDefinitions() {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("type %s\n", typeDef.GetType()))
+
+		relations := typeDef.GetRelations()
+		if len(relations) == 0 {
+			continue
+		}
+
+		names := make([]string, 0, len(relations))
+		for name := range relations {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		sb.WriteString("  relations\n")
+		for _, name := range names {
+			rewrite, err := rewriteToDSL(relations[name])
+			if err != nil {
+				return "", fmt.Errorf("relation '%s' of type '%s': %w", name, typeDef.GetType(), err)
+			}
+
+			sb.WriteString(fmt.Sprintf("    define %s%s as %s\n", name, typeRestrictionToDSL(typeDef, name), rewrite))
+		}
+	}
+
+	return sb.String(), nil
+}
+
+func typeRestrictionToDSL(typeDef *openfgapb.TypeDefinition, relation string) string {
+	relationMetadata, ok := typeDef.GetMetadata().GetRelations()[relation]
+	if !ok || len(relationMetadata.GetDirectlyRelatedUserTypes()) == 0 {
+		return ""
+	}
+
+	references := make([]string, 0, len(relationMetadata.GetDirectlyRelatedUserTypes()))
+	for _, reference := range relationMetadata.GetDirectlyRelatedUserTypes() {
+		switch {
+		case reference.GetWildcard() != nil:
+			references = append(references, fmt.Sprintf("%s:*", reference.GetType()))
+		case reference.GetRelation() != "":
+			references = append(references, fmt.Sprintf("%s#%s", reference.GetType(), reference.GetRelation()))
+		default:
+			references = append(references, reference.GetType())
+		}
+	}
+
+	return fmt.Sprintf(": [%s]", strings.Join(references, ", "))
+}
+
+func rewriteToDSL(rewrite *openfgapb.Userset) (string, error) {
+	switch r := rewrite.GetUserset().(type) {
+	case *openfgapb.Userset_This:
+		return "self", nil
+	case *openfgapb.Userset_ComputedUserset:
+		return r.ComputedUserset.GetRelation(), nil
+	case *openfgapb.Userset_TupleToUserset:
+		return fmt.Sprintf("%s from %s", r.TupleToUserset.GetComputedUserset().GetRelation(), r.TupleToUserset.GetTupleset().GetRelation()), nil
+	case *openfgapb.Userset_Union:
+		return operandsToDSL(r.Union.GetChild(), " or ")
+	case *openfgapb.Userset_Intersection:
+		return operandsToDSL(r.Intersection.GetChild(), " and ")
+	case *openfgapb.Userset_Difference:
+		return operandsToDSL([]*openfgapb.Userset{r.Difference.GetBase(), r.Difference.GetSubtract()}, " but not ")
+	default:
+		return "", fmt.Errorf("unsupported rewrite %T", r)
+	}
+}
+
+// operandsToDSL joins the operands with the operator. The operands that are themselves operations are enclosed in
+// parentheses, so that the DSL parses back into the same tree whatever the precedence of the operators.
+func operandsToDSL(operands []*openfgapb.Userset, operator string) (string, error) {
+	if len(operands) == 0 {
+		return "", fmt.Errorf("missing operands")
+	}
+
+	dsl := make([]string, 0, len(operands))
+	for _, operand := range operands {
+		operandDSL, err := rewriteToDSL(operand)
+		if err != nil {
+			return "", err
+		}
+
+		switch operand.GetUserset().(type) {
+		case *openfgapb.Userset_Union, *openfgapb.Userset_Intersection, *openfgapb.Userset_Difference:
+			operandDSL = fmt.Sprintf("(%s)", operandDSL)
+		}
+
+		dsl = append(dsl, operandDSL)
+	}
+
+	return strings.Join(dsl, operator), nil
+}
@@ -0,0 +1,150 @@
+// Package inspectmodel contains the command to print an authorization model as it is stored in the datastore.
+package inspectmodel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/openfga/openfga/internal/build"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/mysql"
+	"github.com/openfga/openfga/pkg/storage/postgres"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	datastoreEngineFlag = "datastore-engine"
+	datastoreURIFlag    = "datastore-uri"
+	storeIDFlag         = "store-id"
+	modelIDFlag         = "model-id"
+	formatFlag          = "format"
+
+	formatDSL  = "dsl"
+	formatJSON = "json"
+)
+
+func NewInspectModelCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect-model",
+		Short: "Print an authorization model read from the datastore. NOTE: this command is in beta and may be removed in future releases.",
+		Long: `Read an authorization model of a store straight from the datastore, without going through the API, and print it as DSL or JSON.
+If no model ID is provided, the latest authorization model of the store is printed.
+NOTE: this command is in beta and may be removed in future releases.`,
+		RunE: runInspectModel,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(datastoreEngineFlag, "", "the datastore engine")
+	flags.String(datastoreURIFlag, "", "the connection uri to the datastore")
+	flags.String(storeIDFlag, "", "the id of the store of the authorization model")
+	flags.String(modelIDFlag, "", "the id of the authorization model to print. If empty, the latest authorization model of the store is printed")
+	flags.String(formatFlag, formatDSL, "the format the authorization model is printed in: 'dsl' or 'json'")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindRunFlagsFunc(flags)
+
+	return cmd
+}
+
+func runInspectModel(_ *cobra.Command, _ []string) error {
+	engine := viper.GetString(datastoreEngineFlag)
+	uri := viper.GetString(datastoreURIFlag)
+	storeID := viper.GetString(storeIDFlag)
+	format := viper.GetString(formatFlag)
+
+	if storeID == "" {
+		return errors.New("missing store id")
+	}
+
+	if format != formatDSL && format != formatJSON {
+		return fmt.Errorf("format '%s' is unsupported. Use 'dsl' or 'json'", format)
+	}
+
+	ctx := context.Background()
+
+	if engine != "" {
+		if err := build.CheckDatastoreEngine(engine); err != nil {
+			return err
+		}
+	}
+
+	var (
+		db  storage.OpenFGADatastore
+		err error
+	)
+	switch engine {
+	case "mysql":
+		db, err = mysql.New(uri, sqlcommon.NewConfig())
+	case "postgres":
+		db, err = postgres.New(uri, sqlcommon.NewConfig())
+	case "sqlite":
+		db, err = sqlite.New(uri, sqlcommon.NewConfig())
+	case "":
+		return fmt.Errorf("missing datastore engine type")
+	case "memory":
+		fallthrough
+	default:
+		return fmt.Errorf("storage engine '%s' is unsupported", engine)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to open a connection to the datastore: %v", err)
+	}
+	defer db.Close()
+
+	return InspectAuthorizationModel(ctx, db, storeID, viper.GetString(modelIDFlag), format, os.Stdout)
+}
+
+// InspectAuthorizationModel reads the authorization model of the store with the provided ID, or its latest model if
+// modelID is empty, and writes it to w in the provided format, which is either 'dsl' or 'json'.
+func InspectAuthorizationModel(ctx context.Context, db storage.AuthorizationModelReadBackend, storeID, modelID, format string, w io.Writer) error {
+	if modelID == "" {
+		latestModelID, err := db.FindLatestAuthorizationModelID(ctx, storeID)
+		if err != nil {
+			if errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("no authorization models in store '%s'", storeID)
+			}
+
+			return fmt.Errorf("error reading the latest authorization model: %w", err)
+		}
+
+		modelID = latestModelID
+	}
+
+	model, err := db.ReadAuthorizationModel(ctx, storeID, modelID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return fmt.Errorf("authorization model '%s' not found in store '%s'", modelID, storeID)
+		}
+
+		return fmt.Errorf("error reading the authorization model: %w", err)
+	}
+
+	var output string
+	switch format {
+	case formatJSON:
+		marshalled, err := protojson.MarshalOptions{Multiline: true, Indent: "    "}.Marshal(model)
+		if err != nil {
+			return fmt.Errorf("error marshalling the authorization model: %w", err)
+		}
+
+		output = string(marshalled) + "\n"
+	default:
+		output, err = ModelToDSL(model)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, output)
+	return err
+}
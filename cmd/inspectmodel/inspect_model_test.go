@@ -0,0 +1,101 @@
+package inspectmodel
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	parser "github.com/craigpastro/openfga-dsl-parser/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const modelDSL = `type user
+
+type group
+  relations
+    define member: [user, group#member] as self
+
+type folder
+  relations
+    define viewer: [user, user:*] as self
+
+type document
+  relations
+    define blocked: [user] as self
+    define editor: [user] as self
+    define owner: [user] as self
+    define parent: [folder] as self
+    define viewer: [user, group#member] as (self or editor or viewer from parent) but not blocked
+    define writer: [user] as self and (editor or owner)
+`
+
+func TestModelToDSLParsesBackIntoTheSameModel(t *testing.T) {
+	typeDefinitions := parser.MustParse(modelDSL)
+
+	dsl, err := ModelToDSL(&openfgapb.AuthorizationModel{
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: typeDefinitions,
+	})
+	require.NoError(t, err)
+
+	reparsed, err := parser.Parse(dsl)
+	require.NoError(t, err)
+	require.Len(t, reparsed, len(typeDefinitions))
+	for i := range typeDefinitions {
+		require.True(t, proto.Equal(typeDefinitions[i], reparsed[i]), "type %s: %s", typeDefinitions[i].GetType(), dsl)
+	}
+}
+
+func TestInspectAuthorizationModel(t *testing.T) {
+	ctx := context.Background()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:              ulid.Make().String(),
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(modelDSL),
+	}
+	require.NoError(t, ds.WriteAuthorizationModel(ctx, storeID, model))
+
+	t.Run("dsl_of_the_latest_model", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, InspectAuthorizationModel(ctx, ds, storeID, "", formatDSL, &out))
+
+		expected, err := ModelToDSL(model)
+		require.NoError(t, err)
+		require.Equal(t, expected, out.String())
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, InspectAuthorizationModel(ctx, ds, storeID, model.GetId(), formatJSON, &out))
+
+		var printed openfgapb.AuthorizationModel
+		require.NoError(t, protojson.Unmarshal(out.Bytes(), &printed))
+		require.Equal(t, model.GetId(), printed.GetId())
+		require.Len(t, printed.GetTypeDefinitions(), len(model.GetTypeDefinitions()))
+	})
+
+	t.Run("model_not_found", func(t *testing.T) {
+		modelID := ulid.Make().String()
+
+		err := InspectAuthorizationModel(ctx, ds, storeID, modelID, formatDSL, &bytes.Buffer{})
+		require.EqualError(t, err, "authorization model '"+modelID+"' not found in store '"+storeID+"'")
+	})
+
+	t.Run("store_without_models", func(t *testing.T) {
+		otherStoreID := ulid.Make().String()
+
+		err := InspectAuthorizationModel(ctx, ds, otherStoreID, "", formatDSL, &bytes.Buffer{})
+		require.EqualError(t, err, "no authorization models in store '"+otherStoreID+"'")
+	})
+}
@@ -5,8 +5,13 @@ import (
 	"os"
 
 	"github.com/openfga/openfga/cmd"
+	"github.com/openfga/openfga/cmd/benchcheck"
+	"github.com/openfga/openfga/cmd/clonestore"
+	"github.com/openfga/openfga/cmd/config"
+	"github.com/openfga/openfga/cmd/inspectmodel"
 	"github.com/openfga/openfga/cmd/migrate"
 	"github.com/openfga/openfga/cmd/run"
+	"github.com/openfga/openfga/cmd/selftest"
 	"github.com/openfga/openfga/cmd/validatemodels"
 )
 
@@ -22,6 +27,21 @@ func main() {
 	validateModelsCmd := validatemodels.NewValidateCommand()
 	rootCmd.AddCommand(validateModelsCmd)
 
+	selfTestCmd := selftest.NewSelfTestCommand()
+	rootCmd.AddCommand(selfTestCmd)
+
+	benchCheckCmd := benchcheck.NewBenchCheckCommand()
+	rootCmd.AddCommand(benchCheckCmd)
+
+	cloneStoreCmd := clonestore.NewCloneStoreCommand()
+	rootCmd.AddCommand(cloneStoreCmd)
+
+	inspectModelCmd := inspectmodel.NewInspectModelCommand()
+	rootCmd.AddCommand(inspectModelCmd)
+
+	configCmd := config.NewConfigCommand()
+	rootCmd.AddCommand(configCmd)
+
 	versionCmd := cmd.NewVersionCommand()
 	rootCmd.AddCommand(versionCmd)
 
@@ -0,0 +1,67 @@
+package run
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// validateListenAddr returns an error if addr is not a host:port address the server can listen on. IPv6 hosts
+// must be enclosed in square brackets, as in '[::1]:8080'.
+func validateListenAddr(addr string) error {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("'%s' is not a valid host:port address (IPv6 hosts must be enclosed in square brackets, e.g. '[::]:8080'): %w", addr, err)
+	}
+
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("'%s' does not have a valid port number", addr)
+	}
+
+	// link-local IPv6 addresses may carry a zone, as in '[fe80::1%eth0]:8080'
+	ipHost, _, _ := strings.Cut(host, "%")
+
+	if ip := net.ParseIP(ipHost); ip == nil && host != "" && !isValidHostname(host) {
+		return fmt.Errorf("'%s' does not have a valid host", addr)
+	}
+
+	return nil
+}
+
+func isValidHostname(host string) bool {
+	for _, r := range host {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// dialAddr returns the address to connect to a server listening on addr from the same host. A server listening
+// on an unspecified address, such as '0.0.0.0:8080', '[::]:8080' or ':8080', is reached on the loopback address
+// of the same IP family.
+func dialAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	if host == "" {
+		return net.JoinHostPort("localhost", port)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsUnspecified() {
+		return addr
+	}
+
+	if ip.To4() != nil {
+		return net.JoinHostPort("127.0.0.1", port)
+	}
+
+	return net.JoinHostPort("::1", port)
+}
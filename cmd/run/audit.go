@@ -0,0 +1,41 @@
+package run
+
+import (
+	"os"
+
+	"github.com/openfga/openfga/pkg/audit"
+	"github.com/openfga/openfga/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const auditLogOutputStdout = "stdout"
+
+// newAuditLogger returns the audit logger for the config, which discards all entries if the audit log is disabled.
+func newAuditLogger(config AuditLogConfig, logger logger.Logger) (audit.Logger, error) {
+	if !config.Enabled {
+		return audit.NoopLogger{}, nil
+	}
+
+	var auditLogger audit.Logger
+	if config.Output == auditLogOutputStdout {
+		auditLogger = audit.NewWriterLogger(os.Stdout)
+	} else {
+		var err error
+		auditLogger, err = audit.NewFileLogger(config.Output)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.QueueSize > 0 {
+		auditLogger = audit.NewBufferedLogger(auditLogger, config.QueueSize,
+			audit.WithFlushInterval(config.FlushInterval),
+			audit.WithQueueFullPolicy(audit.QueueFullPolicy(config.QueueFullPolicy)),
+			audit.WithErrorHandler(func(err error) {
+				logger.Error("failed to write the queued audit log entries, retrying on the next flush", zap.Error(err))
+			}),
+		)
+	}
+
+	return auditLogger, nil
+}
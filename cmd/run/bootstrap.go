@@ -0,0 +1,144 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/typesystem"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// bootstrapAuthorizationModel makes sure that the authorization model in the model file is the latest model of
+// the store, creating the store if it doesn't exist and writing the model if it differs from the latest one. It
+// returns the ID of the model. Since an unchanged model is not written again, it is safe to call on every startup.
+//
+// Instances that start at the same time may each write the model. The returned ID is the oldest of the identical
+// models at the head of the store, so that they all agree on the ID of the model they pin.
+func bootstrapAuthorizationModel(ctx context.Context, datastore storage.OpenFGADatastore, config BootstrapConfig, logger logger.Logger) (string, error) {
+	// the models written by the other instances must be visible
+	ctx = storage.ContextWithReadFromPrimary(storage.ContextWithModelCacheBypass(ctx))
+
+	contents, err := os.ReadFile(config.ModelFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the bootstrap model file: %w", err)
+	}
+
+	req := &openfgapb.WriteAuthorizationModelRequest{}
+	if err := protojson.Unmarshal(contents, req); err != nil {
+		return "", fmt.Errorf("failed to parse the bootstrap model file '%s': %w", config.ModelFile, err)
+	}
+	req.StoreId = config.StoreID
+
+	if req.GetSchemaVersion() == "" {
+		req.SchemaVersion = typesystem.SchemaVersion1_1
+	}
+
+	if _, err := datastore.GetStore(ctx, config.StoreID); err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			return "", fmt.Errorf("failed to get the bootstrap store: %w", err)
+		}
+
+		// another instance starting at the same time may have created the store in the meantime
+		_, err := datastore.CreateStore(ctx, &openfgapb.Store{Id: config.StoreID, Name: config.StoreID})
+		if err != nil && !errors.Is(err, storage.ErrCollision) {
+			return "", fmt.Errorf("failed to create the bootstrap store: %w", err)
+		}
+
+		logger.Info(fmt.Sprintf("created the bootstrap store '%s'", config.StoreID))
+	}
+
+	latestModelID, err := datastore.FindLatestAuthorizationModelID(ctx, config.StoreID)
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return "", fmt.Errorf("failed to find the latest authorization model of the bootstrap store: %w", err)
+	}
+
+	if latestModelID != "" {
+		latestModel, err := datastore.ReadAuthorizationModel(ctx, config.StoreID, latestModelID)
+		if err != nil {
+			return "", fmt.Errorf("failed to read the latest authorization model of the bootstrap store: %w", err)
+		}
+
+		if sameAuthorizationModel(latestModel, req) {
+			modelID, err := oldestIdenticalModelID(ctx, datastore, config.StoreID, latestModelID, req)
+			if err != nil {
+				return "", err
+			}
+
+			logger.Info(fmt.Sprintf("the bootstrap model '%s' is the latest model of store '%s'", modelID, config.StoreID))
+			return modelID, nil
+		}
+	}
+
+	res, err := commands.NewWriteAuthorizationModelCommand(datastore, logger).Execute(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to write the bootstrap model: %w", err)
+	}
+
+	logger.Info(fmt.Sprintf("wrote the bootstrap model '%s' to store '%s'", res.GetAuthorizationModelId(), config.StoreID))
+
+	return oldestIdenticalModelID(ctx, datastore, config.StoreID, res.GetAuthorizationModelId(), req)
+}
+
+// oldestIdenticalModelID returns the ID of the oldest model in the run of models identical to the request that
+// includes the model with the given ID, going from newest to oldest, so that instances that wrote the same model
+// concurrently all return the same ID.
+func oldestIdenticalModelID(ctx context.Context, datastore storage.OpenFGADatastore, storeID, modelID string, req *openfgapb.WriteAuthorizationModelRequest) (string, error) {
+	oldestID := modelID
+
+	var from string
+	for {
+		models, token, err := datastore.ReadAuthorizationModels(ctx, storeID, storage.PaginationOptions{
+			PageSize: storage.DefaultPageSize,
+			From:     from,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to read the authorization models of the bootstrap store: %w", err)
+		}
+
+		for _, model := range models {
+			// the models are listed from newest to oldest, and model IDs are ULIDs
+			if model.GetId() >= modelID {
+				continue
+			}
+
+			if !sameAuthorizationModel(model, req) {
+				return oldestID, nil
+			}
+
+			oldestID = model.GetId()
+		}
+
+		if len(token) == 0 {
+			return oldestID, nil
+		}
+		from = string(token)
+	}
+}
+
+// sameAuthorizationModel returns true if the model has the schema version and the type definitions of the request,
+// regardless of the order of the type definitions, which datastores don't necessarily preserve.
+func sameAuthorizationModel(model *openfgapb.AuthorizationModel, req *openfgapb.WriteAuthorizationModelRequest) bool {
+	if model.GetSchemaVersion() != req.GetSchemaVersion() || len(model.GetTypeDefinitions()) != len(req.GetTypeDefinitions()) {
+		return false
+	}
+
+	typedefs := make(map[string]*openfgapb.TypeDefinition, len(model.GetTypeDefinitions()))
+	for _, typedef := range model.GetTypeDefinitions() {
+		typedefs[typedef.GetType()] = typedef
+	}
+
+	for _, typedef := range req.GetTypeDefinitions() {
+		if !proto.Equal(typedefs[typedef.GetType()], typedef) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -10,12 +10,21 @@ import (
 // by viper. This bridges the config between cobra flags and viper flags.
 func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 	return func(command *cobra.Command, args []string) {
+		util.MustBindPFlag("config", flags.Lookup("config"))
+		util.MustBindEnv("config", "OPENFGA_CONFIG")
+
 		util.MustBindPFlag("experimentals", flags.Lookup("experimentals"))
 		util.MustBindEnv("experimentals", "OPENFGA_EXPERIMENTALS")
 
 		util.MustBindPFlag("grpc.addr", flags.Lookup("grpc-addr"))
 		util.MustBindEnv("grpc.addr", "OPENFGA_GRPC_ADDR")
 
+		util.MustBindPFlag("grpc.proxyProtocolEnabled", flags.Lookup("grpc-proxy-protocol-enabled"))
+		util.MustBindEnv("grpc.proxyProtocolEnabled", "OPENFGA_GRPC_PROXY_PROTOCOL_ENABLED")
+
+		util.MustBindPFlag("grpc.enableReflection", flags.Lookup("grpc-enable-reflection"))
+		util.MustBindEnv("grpc.enableReflection", "OPENFGA_GRPC_ENABLE_REFLECTION")
+
 		util.MustBindPFlag("grpc.tls.enabled", flags.Lookup("grpc-tls-enabled"))
 		util.MustBindEnv("grpc.tls.enabled", "OPENFGA_GRPC_TLS_ENABLED")
 
@@ -25,6 +34,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("grpc.tls.key", flags.Lookup("grpc-tls-key"))
 		util.MustBindEnv("grpc.tls.key", "OPENFGA_GRPC_TLS_KEY")
 
+		util.MustBindPFlag("grpc.tls.sessionTicketsEnabled", flags.Lookup("grpc-tls-session-tickets-enabled"))
+		util.MustBindEnv("grpc.tls.sessionTicketsEnabled", "OPENFGA_GRPC_TLS_SESSION_TICKETS_ENABLED")
+
+		util.MustBindPFlag("grpc.tls.sessionTicketKeyRotation", flags.Lookup("grpc-tls-session-ticket-key-rotation"))
+		util.MustBindEnv("grpc.tls.sessionTicketKeyRotation", "OPENFGA_GRPC_TLS_SESSION_TICKET_KEY_ROTATION")
+
+		util.MustBindPFlag("grpc.tls.nextProtos", flags.Lookup("grpc-tls-next-protos"))
+		util.MustBindEnv("grpc.tls.nextProtos", "OPENFGA_GRPC_TLS_NEXT_PROTOS")
+
 		command.MarkFlagsRequiredTogether("grpc-tls-enabled", "grpc-tls-cert", "grpc-tls-key")
 
 		util.MustBindPFlag("http.enabled", flags.Lookup("http-enabled"))
@@ -33,6 +51,9 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("http.addr", flags.Lookup("http-addr"))
 		util.MustBindEnv("http.addr", "OPENFGA_HTTP_ADDR")
 
+		util.MustBindPFlag("http.proxyProtocolEnabled", flags.Lookup("http-proxy-protocol-enabled"))
+		util.MustBindEnv("http.proxyProtocolEnabled", "OPENFGA_HTTP_PROXY_PROTOCOL_ENABLED")
+
 		util.MustBindPFlag("http.tls.enabled", flags.Lookup("http-tls-enabled"))
 		util.MustBindEnv("http.tls.enabled", "OPENFGA_HTTP_TLS_ENABLED")
 
@@ -42,6 +63,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("http.tls.key", flags.Lookup("http-tls-key"))
 		util.MustBindEnv("http.tls.key", "OPENFGA_HTTP_TLS_KEY")
 
+		util.MustBindPFlag("http.tls.sessionTicketsEnabled", flags.Lookup("http-tls-session-tickets-enabled"))
+		util.MustBindEnv("http.tls.sessionTicketsEnabled", "OPENFGA_HTTP_TLS_SESSION_TICKETS_ENABLED")
+
+		util.MustBindPFlag("http.tls.sessionTicketKeyRotation", flags.Lookup("http-tls-session-ticket-key-rotation"))
+		util.MustBindEnv("http.tls.sessionTicketKeyRotation", "OPENFGA_HTTP_TLS_SESSION_TICKET_KEY_ROTATION")
+
+		util.MustBindPFlag("http.tls.nextProtos", flags.Lookup("http-tls-next-protos"))
+		util.MustBindEnv("http.tls.nextProtos", "OPENFGA_HTTP_TLS_NEXT_PROTOS")
+
 		command.MarkFlagsRequiredTogether("http-tls-enabled", "http-tls-cert", "http-tls-key")
 
 		util.MustBindPFlag("http.upstreamTimeout", flags.Lookup("http-upstream-timeout"))
@@ -53,24 +83,63 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("http.corsAllowedHeaders", flags.Lookup("http-cors-allowed-headers"))
 		util.MustBindEnv("http.corsAllowedHeaders", "OPENFGA_HTTP_CORS_ALLOWED_HEADERS", "OPENFGA_HTTP_CORSALLOWEDHEADERS")
 
+		util.MustBindPFlag("http.trailingSlashPolicy", flags.Lookup("http-trailing-slash-policy"))
+		util.MustBindEnv("http.trailingSlashPolicy", "OPENFGA_HTTP_TRAILING_SLASH_POLICY")
+
+		util.MustBindPFlag("http.rejectUnknownJSONFields", flags.Lookup("http-reject-unknown-json-fields"))
+		util.MustBindEnv("http.rejectUnknownJSONFields", "OPENFGA_HTTP_REJECT_UNKNOWN_JSON_FIELDS")
+
 		util.MustBindPFlag("authn.method", flags.Lookup("authn-method"))
 		util.MustBindEnv("authn.method", "OPENFGA_AUTHN_METHOD")
 
 		util.MustBindPFlag("authn.preshared.keys", flags.Lookup("authn-preshared-keys"))
 		util.MustBindEnv("authn.preshared.keys", "OPENFGA_AUTHN_PRESHARED_KEYS")
 
+		util.MustBindPFlag("authn.preshared.reloadOnSighup", flags.Lookup("authn-preshared-reload-on-sighup"))
+		util.MustBindEnv("authn.preshared.reloadOnSighup", "OPENFGA_AUTHN_PRESHARED_RELOAD_ON_SIGHUP")
+
+		util.MustBindPFlag("authn.preshared.labels", flags.Lookup("authn-preshared-key-labels"))
+		util.MustBindEnv("authn.preshared.labels", "OPENFGA_AUTHN_PRESHARED_KEY_LABELS")
+
 		util.MustBindPFlag("authn.oidc.audience", flags.Lookup("authn-oidc-audience"))
 		util.MustBindEnv("authn.oidc.audience", "OPENFGA_AUTHN_OIDC_AUDIENCE")
 
+		util.MustBindPFlag("authn.oidc.audiences", flags.Lookup("authn-oidc-audiences"))
+		util.MustBindEnv("authn.oidc.audiences", "OPENFGA_AUTHN_OIDC_AUDIENCES")
+
 		util.MustBindPFlag("authn.oidc.issuer", flags.Lookup("authn-oidc-issuer"))
 		util.MustBindEnv("authn.oidc.issuer", "OPENFGA_AUTHN_OIDC_ISSUER")
 
+		util.MustBindPFlag("authn.oidc.caCertPath", flags.Lookup("authn-oidc-ca-cert-path"))
+		util.MustBindEnv("authn.oidc.caCertPath", "OPENFGA_AUTHN_OIDC_CA_CERT_PATH")
+
+		util.MustBindPFlag("authn.oidc.insecureSkipVerify", flags.Lookup("authn-oidc-insecure-skip-verify"))
+		util.MustBindEnv("authn.oidc.insecureSkipVerify", "OPENFGA_AUTHN_OIDC_INSECURE_SKIP_VERIFY")
+
+		util.MustBindPFlag("authn.oidc.maxResponseSize", flags.Lookup("authn-oidc-max-response-size"))
+		util.MustBindEnv("authn.oidc.maxResponseSize", "OPENFGA_AUTHN_OIDC_MAX_RESPONSE_SIZE")
+
+		util.MustBindPFlag("authn.oidc.subjectClaim", flags.Lookup("authn-oidc-subject-claim"))
+		util.MustBindEnv("authn.oidc.subjectClaim", "OPENFGA_AUTHN_OIDC_SUBJECT_CLAIM")
+
+		util.MustBindPFlag("authn.oidc.jwksRefreshInterval", flags.Lookup("authn-oidc-jwks-refresh-interval"))
+		util.MustBindEnv("authn.oidc.jwksRefreshInterval", "OPENFGA_AUTHN_OIDC_JWKS_REFRESH_INTERVAL")
+
+		util.MustBindPFlag("authn.mtls.caCertPath", flags.Lookup("authn-mtls-ca-cert-path"))
+		util.MustBindEnv("authn.mtls.caCertPath", "OPENFGA_AUTHN_MTLS_CA_CERT_PATH")
+
+		util.MustBindPFlag("authn.mtls.allowedSubjects", flags.Lookup("authn-mtls-allowed-subjects"))
+		util.MustBindEnv("authn.mtls.allowedSubjects", "OPENFGA_AUTHN_MTLS_ALLOWED_SUBJECTS")
+
 		util.MustBindPFlag("datastore.engine", flags.Lookup("datastore-engine"))
 		util.MustBindEnv("datastore.engine", "OPENFGA_DATASTORE_ENGINE")
 
 		util.MustBindPFlag("datastore.uri", flags.Lookup("datastore-uri"))
 		util.MustBindEnv("datastore.uri", "OPENFGA_DATASTORE_URI")
 
+		util.MustBindPFlag("datastore.readReplicaURIs", flags.Lookup("datastore-read-replica-uris"))
+		util.MustBindEnv("datastore.readReplicaURIs", "OPENFGA_DATASTORE_READ_REPLICA_URIS")
+
 		util.MustBindPFlag("datastore.username", flags.Lookup("datastore-username"))
 		util.MustBindEnv("datastore.username", "OPENFGA_DATASTORE_USERNAME")
 
@@ -80,6 +149,15 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("datastore.maxCacheSize", flags.Lookup("datastore-max-cache-size"))
 		util.MustBindEnv("datastore.maxCacheSize", "OPENFGA_DATASTORE_MAX_CACHE_SIZE", "OPENFGA_DATASTORE_MAXCACHESIZE")
 
+		util.MustBindPFlag("datastore.modelCacheTTL", flags.Lookup("datastore-model-cache-ttl"))
+		util.MustBindEnv("datastore.modelCacheTTL", "OPENFGA_DATASTORE_MODEL_CACHE_TTL")
+
+		util.MustBindPFlag("datastore.latestModelIDCacheTTL", flags.Lookup("datastore-latest-model-id-cache-ttl"))
+		util.MustBindEnv("datastore.latestModelIDCacheTTL", "OPENFGA_DATASTORE_LATEST_MODEL_ID_CACHE_TTL")
+
+		util.MustBindPFlag("datastore.modelReadRetries", flags.Lookup("datastore-model-read-retries"))
+		util.MustBindEnv("datastore.modelReadRetries", "OPENFGA_DATASTORE_MODEL_READ_RETRIES")
+
 		util.MustBindPFlag("datastore.maxOpenConns", flags.Lookup("datastore-max-open-conns"))
 		util.MustBindEnv("datastore.maxOpenConns", "OPENFGA_DATASTORE_MAX_OPEN_CONNS", "OPENFGA_DATASTORE_MAXOPENCONNS")
 
@@ -92,12 +170,36 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("datastore.connMaxLifetime", flags.Lookup("datastore-conn-max-lifetime"))
 		util.MustBindEnv("datastore.connMaxLifetime", "OPENFGA_DATASTORE_CONN_MAX_LIFETIME", "OPENFGA_DATASTORE_CONNMAXLIFETIME")
 
+		util.MustBindPFlag("datastore.connMaxLifetimeJitter", flags.Lookup("datastore-conn-max-lifetime-jitter"))
+		util.MustBindEnv("datastore.connMaxLifetimeJitter", "OPENFGA_DATASTORE_CONN_MAX_LIFETIME_JITTER")
+
+		util.MustBindPFlag("datastore.statementTimeout", flags.Lookup("datastore-statement-timeout"))
+		util.MustBindEnv("datastore.statementTimeout", "OPENFGA_DATASTORE_STATEMENT_TIMEOUT")
+
+		util.MustBindPFlag("datastore.queryTimeout", flags.Lookup("datastore-query-timeout"))
+		util.MustBindEnv("datastore.queryTimeout", "OPENFGA_DATASTORE_QUERY_TIMEOUT")
+
+		util.MustBindPFlag("datastore.connectTimeout", flags.Lookup("datastore-connect-timeout"))
+		util.MustBindEnv("datastore.connectTimeout", "OPENFGA_DATASTORE_CONNECT_TIMEOUT")
+
+		util.MustBindPFlag("datastore.startupRetry.maxAttempts", flags.Lookup("datastore-startup-retry-max-attempts"))
+		util.MustBindEnv("datastore.startupRetry.maxAttempts", "OPENFGA_DATASTORE_STARTUP_RETRY_MAX_ATTEMPTS")
+
+		util.MustBindPFlag("datastore.startupRetry.initialInterval", flags.Lookup("datastore-startup-retry-initial-interval"))
+		util.MustBindEnv("datastore.startupRetry.initialInterval", "OPENFGA_DATASTORE_STARTUP_RETRY_INITIAL_INTERVAL")
+
+		util.MustBindPFlag("datastore.autoMigrate", flags.Lookup("datastore-auto-migrate"))
+		util.MustBindEnv("datastore.autoMigrate", "OPENFGA_DATASTORE_AUTO_MIGRATE")
+
 		util.MustBindPFlag("playground.enabled", flags.Lookup("playground-enabled"))
 		util.MustBindEnv("playground.enabled", "OPENFGA_PLAYGROUND_ENABLED")
 
 		util.MustBindPFlag("playground.port", flags.Lookup("playground-port"))
 		util.MustBindEnv("playground.port", "OPENFGA_PLAYGROUND_PORT")
 
+		util.MustBindPFlag("playground.basePath", flags.Lookup("playground-base-path"))
+		util.MustBindEnv("playground.basePath", "OPENFGA_PLAYGROUND_BASE_PATH")
+
 		util.MustBindPFlag("profiler.enabled", flags.Lookup("profiler-enabled"))
 		util.MustBindEnv("profiler.enabled", "OPENFGA_PROFILER_ENABLED")
 
@@ -110,18 +212,74 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("log.level", flags.Lookup("log-level"))
 		util.MustBindEnv("log.level", "OPENFGA_LOG_LEVEL")
 
+		util.MustBindPFlag("log.redactFields", flags.Lookup("log-redact-fields"))
+		util.MustBindEnv("log.redactFields", "OPENFGA_LOG_REDACT_FIELDS")
+
+		util.MustBindPFlag("log.redactMode", flags.Lookup("log-redact-mode"))
+		util.MustBindEnv("log.redactMode", "OPENFGA_LOG_REDACT_MODE")
+
+		util.MustBindPFlag("log.redactHashKey", flags.Lookup("log-redact-hash-key"))
+		util.MustBindEnv("log.redactHashKey", "OPENFGA_LOG_REDACT_HASH_KEY")
+
 		util.MustBindPFlag("trace.enabled", flags.Lookup("trace-enabled"))
 		util.MustBindEnv("trace.enabled", "OPENFGA_TRACE_ENABLED")
 
 		util.MustBindPFlag("trace.otlp.endpoint", flags.Lookup("trace-otlp-endpoint"))
 		util.MustBindEnv("trace.otlp.endpoint", "OPENFGA_TRACE_OTLP_ENDPOINT")
 
+		util.MustBindPFlag("trace.otlp.protocol", flags.Lookup("trace-otlp-protocol"))
+		util.MustBindEnv("trace.otlp.protocol", "OPENFGA_TRACE_OTLP_PROTOCOL")
+
+		util.MustBindPFlag("trace.otlp.tls.enabled", flags.Lookup("trace-otlp-tls-enabled"))
+		util.MustBindEnv("trace.otlp.tls.enabled", "OPENFGA_TRACE_OTLP_TLS_ENABLED")
+
+		util.MustBindPFlag("trace.otlp.tls.caCertPath", flags.Lookup("trace-otlp-tls-ca-cert-path"))
+		util.MustBindEnv("trace.otlp.tls.caCertPath", "OPENFGA_TRACE_OTLP_TLS_CA_CERT_PATH")
+
+		util.MustBindPFlag("trace.otlp.tls.cert", flags.Lookup("trace-otlp-tls-cert"))
+		util.MustBindEnv("trace.otlp.tls.cert", "OPENFGA_TRACE_OTLP_TLS_CERT")
+
+		util.MustBindPFlag("trace.otlp.tls.key", flags.Lookup("trace-otlp-tls-key"))
+		util.MustBindEnv("trace.otlp.tls.key", "OPENFGA_TRACE_OTLP_TLS_KEY")
+
+		command.MarkFlagsRequiredTogether("trace-otlp-tls-cert", "trace-otlp-tls-key")
+
 		util.MustBindPFlag("trace.sampleRatio", flags.Lookup("trace-sample-ratio"))
 		util.MustBindEnv("trace.sampleRatio", "OPENFGA_TRACE_SAMPLE_RATIO")
 
+		util.MustBindPFlag("trace.parentBased", flags.Lookup("trace-parent-based"))
+		util.MustBindEnv("trace.parentBased", "OPENFGA_TRACE_PARENT_BASED")
+
 		util.MustBindPFlag("trace.serviceName", flags.Lookup("trace-service-name"))
 		util.MustBindEnv("trace.serviceName", "OPENFGA_TRACE_SERVICE_NAME")
 
+		util.MustBindPFlag("trace.serviceInstanceID", flags.Lookup("trace-service-instance-id"))
+		util.MustBindEnv("trace.serviceInstanceID", "OPENFGA_TRACE_SERVICE_INSTANCE_ID")
+
+		util.MustBindPFlag("trace.queueFullPolicy", flags.Lookup("trace-queue-full-policy"))
+		util.MustBindEnv("trace.queueFullPolicy", "OPENFGA_TRACE_QUEUE_FULL_POLICY")
+
+		util.MustBindPFlag("trace.queueFullBlockTimeout", flags.Lookup("trace-queue-full-block-timeout"))
+		util.MustBindEnv("trace.queueFullBlockTimeout", "OPENFGA_TRACE_QUEUE_FULL_BLOCK_TIMEOUT")
+
+		util.MustBindPFlag("trace.batch.maxQueueSize", flags.Lookup("trace-batch-max-queue-size"))
+		util.MustBindEnv("trace.batch.maxQueueSize", "OPENFGA_TRACE_BATCH_MAX_QUEUE_SIZE")
+
+		util.MustBindPFlag("trace.batch.maxExportBatchSize", flags.Lookup("trace-batch-max-export-batch-size"))
+		util.MustBindEnv("trace.batch.maxExportBatchSize", "OPENFGA_TRACE_BATCH_MAX_EXPORT_BATCH_SIZE")
+
+		util.MustBindPFlag("trace.batch.timeout", flags.Lookup("trace-batch-timeout"))
+		util.MustBindEnv("trace.batch.timeout", "OPENFGA_TRACE_BATCH_TIMEOUT")
+
+		util.MustBindPFlag("trace.forceSampleSecret", flags.Lookup("trace-force-sample-secret"))
+		util.MustBindEnv("trace.forceSampleSecret", "OPENFGA_TRACE_FORCE_SAMPLE_SECRET")
+
+		util.MustBindPFlag("trace.baggageAttributes", flags.Lookup("trace-baggage-attributes"))
+		util.MustBindEnv("trace.baggageAttributes", "OPENFGA_TRACE_BAGGAGE_ATTRIBUTES")
+
+		util.MustBindPFlag("trace.storeSampleRatios", flags.Lookup("trace-store-sample-ratios"))
+		util.MustBindEnv("trace.storeSampleRatios", "OPENFGA_TRACE_STORE_SAMPLE_RATIOS")
+
 		util.MustBindPFlag("metrics.enabled", flags.Lookup("metrics-enabled"))
 		util.MustBindEnv("metrics.enabled", "OPENFGA_METRICS_ENABLED")
 
@@ -131,22 +289,145 @@ func bindRunFlagsFunc(flags *pflag.FlagSet) func(*cobra.Command, []string) {
 		util.MustBindPFlag("metrics.enableRPCHistograms", flags.Lookup("metrics-enable-rpc-histograms"))
 		util.MustBindEnv("metrics.enableRPCHistograms", "OPENFGA_METRICS_ENABLE_RPC_HISTOGRAMS")
 
+		util.MustBindPFlag("metrics.rpcHistogramBuckets", flags.Lookup("metrics-rpc-histogram-buckets"))
+		util.MustBindEnv("metrics.rpcHistogramBuckets", "OPENFGA_METRICS_RPC_HISTOGRAM_BUCKETS")
+
+		util.MustBindPFlag("metrics.enableCheckResultStoreLabel", flags.Lookup("metrics-enable-check-result-store-label"))
+		util.MustBindEnv("metrics.enableCheckResultStoreLabel", "OPENFGA_METRICS_ENABLE_CHECK_RESULT_STORE_LABEL")
+
+		util.MustBindPFlag("metrics.enableModelCacheStoreLabel", flags.Lookup("metrics-enable-model-cache-store-label"))
+		util.MustBindEnv("metrics.enableModelCacheStoreLabel", "OPENFGA_METRICS_ENABLE_MODEL_CACHE_STORE_LABEL")
+
+		util.MustBindPFlag("metrics.modelCacheSampleInterval", flags.Lookup("metrics-model-cache-sample-interval"))
+		util.MustBindEnv("metrics.modelCacheSampleInterval", "OPENFGA_METRICS_MODEL_CACHE_SAMPLE_INTERVAL")
+
+		util.MustBindPFlag("metrics.namespace", flags.Lookup("metrics-namespace"))
+		util.MustBindEnv("metrics.namespace", "OPENFGA_METRICS_NAMESPACE")
+
+		util.MustBindPFlag("metrics.enableRuntimeMetrics", flags.Lookup("metrics-enable-runtime-metrics"))
+		util.MustBindEnv("metrics.enableRuntimeMetrics", "OPENFGA_METRICS_ENABLE_RUNTIME_METRICS")
+
+		util.MustBindPFlag("metrics.failOnListenError", flags.Lookup("metrics-fail-on-listen-error"))
+		util.MustBindEnv("metrics.failOnListenError", "OPENFGA_METRICS_FAIL_ON_LISTEN_ERROR")
+
+		util.MustBindPFlag("slowStart.duration", flags.Lookup("slow-start-duration"))
+		util.MustBindEnv("slowStart.duration", "OPENFGA_SLOW_START_DURATION")
+
+		util.MustBindPFlag("slowStart.maxConcurrency", flags.Lookup("slow-start-max-concurrency"))
+		util.MustBindEnv("slowStart.maxConcurrency", "OPENFGA_SLOW_START_MAX_CONCURRENCY")
+
+		util.MustBindPFlag("concurrencyLimit.maxConcurrency", flags.Lookup("concurrency-limit-max-concurrency"))
+		util.MustBindEnv("concurrencyLimit.maxConcurrency", "OPENFGA_CONCURRENCY_LIMIT_MAX_CONCURRENCY")
+
+		util.MustBindPFlag("concurrencyLimit.maxQueueSize", flags.Lookup("concurrency-limit-max-queue-size"))
+		util.MustBindEnv("concurrencyLimit.maxQueueSize", "OPENFGA_CONCURRENCY_LIMIT_MAX_QUEUE_SIZE")
+
+		util.MustBindPFlag("concurrencyLimit.queueTimeout", flags.Lookup("concurrency-limit-queue-timeout"))
+		util.MustBindEnv("concurrencyLimit.queueTimeout", "OPENFGA_CONCURRENCY_LIMIT_QUEUE_TIMEOUT")
+
+		util.MustBindPFlag("auditLog.enabled", flags.Lookup("audit-log-enabled"))
+		util.MustBindEnv("auditLog.enabled", "OPENFGA_AUDIT_LOG_ENABLED")
+
+		util.MustBindPFlag("auditLog.output", flags.Lookup("audit-log-output"))
+		util.MustBindEnv("auditLog.output", "OPENFGA_AUDIT_LOG_OUTPUT")
+
+		util.MustBindPFlag("auditLog.queueSize", flags.Lookup("audit-log-queue-size"))
+		util.MustBindEnv("auditLog.queueSize", "OPENFGA_AUDIT_LOG_QUEUE_SIZE")
+
+		util.MustBindPFlag("auditLog.flushInterval", flags.Lookup("audit-log-flush-interval"))
+		util.MustBindEnv("auditLog.flushInterval", "OPENFGA_AUDIT_LOG_FLUSH_INTERVAL")
+
+		util.MustBindPFlag("auditLog.queueFullPolicy", flags.Lookup("audit-log-queue-full-policy"))
+		util.MustBindEnv("auditLog.queueFullPolicy", "OPENFGA_AUDIT_LOG_QUEUE_FULL_POLICY")
+
+		util.MustBindPFlag("bootstrap.modelFile", flags.Lookup("bootstrap-model-file"))
+		util.MustBindEnv("bootstrap.modelFile", "OPENFGA_BOOTSTRAP_MODEL_FILE")
+
+		util.MustBindPFlag("bootstrap.storeId", flags.Lookup("bootstrap-store-id"))
+		util.MustBindEnv("bootstrap.storeId", "OPENFGA_BOOTSTRAP_STORE_ID")
+
+		util.MustBindPFlag("bootstrap.pinModel", flags.Lookup("bootstrap-pin-model"))
+		util.MustBindEnv("bootstrap.pinModel", "OPENFGA_BOOTSTRAP_PIN_MODEL")
+
 		util.MustBindPFlag("maxTuplesPerWrite", flags.Lookup("max-tuples-per-write"))
 		util.MustBindEnv("maxTuplesPerWrite", "OPENFGA_MAX_TUPLES_PER_WRITE", "OPENFGA_MAXTUPLESPERWRITE")
 
+		util.MustBindPFlag("maxTupleObjectLength", flags.Lookup("max-tuple-object-length"))
+		util.MustBindEnv("maxTupleObjectLength", "OPENFGA_MAX_TUPLE_OBJECT_LENGTH")
+
+		util.MustBindPFlag("maxTupleRelationLength", flags.Lookup("max-tuple-relation-length"))
+		util.MustBindEnv("maxTupleRelationLength", "OPENFGA_MAX_TUPLE_RELATION_LENGTH")
+
+		util.MustBindPFlag("maxTupleUserLength", flags.Lookup("max-tuple-user-length"))
+		util.MustBindEnv("maxTupleUserLength", "OPENFGA_MAX_TUPLE_USER_LENGTH")
+
 		util.MustBindPFlag("maxTypesPerAuthorizationModel", flags.Lookup("max-types-per-authorization-model"))
 		util.MustBindEnv("maxTypesPerAuthorizationModel", "OPENFGA_MAX_TYPES_PER_AUTHORIZATION_MODEL", "OPENFGA_MAXTYPESPERAUTHORIZATIONMODEL")
 
 		util.MustBindPFlag("changelogHorizonOffset", flags.Lookup("changelog-horizon-offset"))
 		util.MustBindEnv("changelogHorizonOffset", "OPENFGA_CHANGELOG_HORIZON_OFFSET", "OPENFGA_CHANGELOGHORIZONOFFSET")
 
+		util.MustBindPFlag("readChangesMaxPageSize", flags.Lookup("read-changes-max-page-size"))
+		util.MustBindEnv("readChangesMaxPageSize", "OPENFGA_READ_CHANGES_MAX_PAGE_SIZE")
+
+		util.MustBindPFlag("readAuthorizationModelsDefaultPageSize", flags.Lookup("read-authorization-models-default-page-size"))
+		util.MustBindEnv("readAuthorizationModelsDefaultPageSize", "OPENFGA_READ_AUTHORIZATION_MODELS_DEFAULT_PAGE_SIZE")
+
+		util.MustBindPFlag("readAuthorizationModelsMaxPageSize", flags.Lookup("read-authorization-models-max-page-size"))
+		util.MustBindEnv("readAuthorizationModelsMaxPageSize", "OPENFGA_READ_AUTHORIZATION_MODELS_MAX_PAGE_SIZE")
+
 		util.MustBindPFlag("resolveNodeLimit", flags.Lookup("resolve-node-limit"))
 		util.MustBindEnv("resolveNodeLimit", "OPENFGA_RESOLVE_NODE_LIMIT", "OPENFGA_RESOLVENODELIMIT")
 
+		util.MustBindPFlag("authorizationModelIDHeaderEnabled", flags.Lookup("authorization-model-id-header-enabled"))
+		util.MustBindEnv("authorizationModelIDHeaderEnabled", "OPENFGA_AUTHORIZATION_MODEL_ID_HEADER_ENABLED")
+
+		util.MustBindPFlag("requireLatestAuthorizationModel", flags.Lookup("require-latest-authorization-model"))
+		util.MustBindEnv("requireLatestAuthorizationModel", "OPENFGA_REQUIRE_LATEST_AUTHORIZATION_MODEL")
+
+		util.MustBindPFlag("cluster", flags.Lookup("cluster"))
+		util.MustBindEnv("cluster", "OPENFGA_CLUSTER")
+
+		util.MustBindPFlag("strictTupleValidation", flags.Lookup("strict-tuple-validation"))
+		util.MustBindEnv("strictTupleValidation", "OPENFGA_STRICT_TUPLE_VALIDATION")
+
+		util.MustBindPFlag("idempotentWrites", flags.Lookup("idempotent-writes"))
+		util.MustBindEnv("idempotentWrites", "OPENFGA_IDEMPOTENT_WRITES")
+
+		util.MustBindPFlag("idempotentDeletes", flags.Lookup("idempotent-deletes"))
+		util.MustBindEnv("idempotentDeletes", "OPENFGA_IDEMPOTENT_DELETES")
+
+		util.MustBindPFlag("modelCacheBypassEnabled", flags.Lookup("model-cache-bypass-enabled"))
+		util.MustBindEnv("modelCacheBypassEnabled", "OPENFGA_MODEL_CACHE_BYPASS_ENABLED")
+
 		util.MustBindPFlag("listObjectsDeadline", flags.Lookup("listObjects-deadline"))
 		util.MustBindEnv("listObjectsDeadline", "OPENFGA_LIST_OBJECTS_DEADLINE", "OPENFGA_LISTOBJECTSDEADLINE")
 
 		util.MustBindPFlag("listObjectsMaxResults", flags.Lookup("listObjects-max-results"))
 		util.MustBindEnv("listObjectsMaxResults", "OPENFGA_LIST_OBJECTS_MAX_RESULTS", "OPENFGA_LISTOBJECTSMAXRESULTS")
+
+		util.MustBindPFlag("listObjectsDeduplicationEnabled", flags.Lookup("listObjects-deduplication-enabled"))
+		util.MustBindEnv("listObjectsDeduplicationEnabled", "OPENFGA_LIST_OBJECTS_DEDUPLICATION_ENABLED")
+
+		util.MustBindPFlag("listObjectsMaxPathsExplored", flags.Lookup("listObjects-max-paths-explored"))
+		util.MustBindEnv("listObjectsMaxPathsExplored", "OPENFGA_LIST_OBJECTS_MAX_PATHS_EXPLORED")
+
+		util.MustBindPFlag("listObjectsMaxConcurrentStreamsPerClient", flags.Lookup("listObjects-max-concurrent-streams-per-client"))
+		util.MustBindEnv("listObjectsMaxConcurrentStreamsPerClient", "OPENFGA_LIST_OBJECTS_MAX_CONCURRENT_STREAMS_PER_CLIENT")
+
+		util.MustBindPFlag("maxConcurrentWritesPerStore", flags.Lookup("max-concurrent-writes-per-store"))
+		util.MustBindEnv("maxConcurrentWritesPerStore", "OPENFGA_MAX_CONCURRENT_WRITES_PER_STORE")
+
+		util.MustBindPFlag("maxContextualTuplesPerCheck", flags.Lookup("max-contextual-tuples-per-check"))
+		util.MustBindEnv("maxContextualTuplesPerCheck", "OPENFGA_MAX_CONTEXTUAL_TUPLES_PER_CHECK")
+
+		util.MustBindPFlag("checkCandidateModels", flags.Lookup("check-candidate-models"))
+		util.MustBindEnv("checkCandidateModels", "OPENFGA_CHECK_CANDIDATE_MODELS")
+
+		util.MustBindPFlag("tuplePurgeInterval", flags.Lookup("tuple-purge-interval"))
+		util.MustBindEnv("tuplePurgeInterval", "OPENFGA_TUPLE_PURGE_INTERVAL")
+
+		util.MustBindPFlag("gracefulShutdownTimeout", flags.Lookup("graceful-shutdown-timeout"))
+		util.MustBindEnv("gracefulShutdownTimeout", "OPENFGA_GRACEFUL_SHUTDOWN_TIMEOUT")
 	}
 }
@@ -0,0 +1,59 @@
+package run
+
+import (
+	"html/template"
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/openfga/openfga/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// playgroundTemplateData is the data the playground index.html template is executed with.
+type playgroundTemplateData struct {
+	HTTPServerURL      string
+	PlaygroundAPIToken string
+
+	// BasePath is the normalized base path the playground is served under, e.g. '/openfga', or empty.
+	BasePath string
+}
+
+// normalizePlaygroundBasePath returns the base path with a leading slash and without a trailing slash, so that
+// 'openfga', '/openfga' and '/openfga/' all serve the playground under '/openfga/playground'.
+func normalizePlaygroundBasePath(basePath string) string {
+	basePath = strings.Trim(basePath, "/")
+	if basePath == "" {
+		return ""
+	}
+
+	return "/" + basePath
+}
+
+// newPlaygroundHandler returns the handler that serves the playground, and the assets in files it references,
+// under '<basePath>/playground'.
+func newPlaygroundHandler(basePath string, tmpl *template.Template, files fs.FS, data playgroundTemplateData, logger logger.Logger) http.Handler {
+	basePath = normalizePlaygroundBasePath(basePath)
+	data.BasePath = basePath
+
+	prefix := basePath + "/playground"
+	fileServer := http.StripPrefix(basePath, http.FileServer(http.FS(files)))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prefix && !strings.HasPrefix(r.URL.Path, prefix+"/") {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.URL.Path == prefix || r.URL.Path == prefix+"/index.html" {
+			if err := tmpl.Execute(w, data); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				logger.Error("failed to execute/render the playground web template", zap.Error(err))
+			}
+
+			return
+		}
+
+		fileServer.ServeHTTP(w, r)
+	})
+}
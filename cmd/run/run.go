@@ -3,6 +3,8 @@ package run
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
 	"fmt"
 	"html/template"
@@ -11,8 +13,11 @@ import (
 	"net/http/pprof"
 	"os"
 	"os/signal"
+	"path/filepath"
 	goruntime "runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
@@ -22,20 +27,30 @@ import (
 	grpc_validator "github.com/grpc-ecosystem/go-grpc-middleware/validator"
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/oklog/ulid/v2"
 	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/internal/authn/mtls"
 	"github.com/openfga/openfga/internal/authn/oidc"
 	"github.com/openfga/openfga/internal/authn/presharedkey"
 	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/internal/gateway"
 	authnmw "github.com/openfga/openfga/internal/middleware/authn"
+	"github.com/openfga/openfga/internal/proxyprotocol"
+	"github.com/openfga/openfga/pkg/audit"
 	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/featureflags"
 	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/middleware/baggage"
+	"github.com/openfga/openfga/pkg/middleware/concurrency"
+	"github.com/openfga/openfga/pkg/middleware/forcetrace"
 	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
 	"github.com/openfga/openfga/pkg/middleware/logging"
 	"github.com/openfga/openfga/pkg/middleware/requestid"
+	"github.com/openfga/openfga/pkg/middleware/slowstart"
 	"github.com/openfga/openfga/pkg/middleware/storeid"
 	"github.com/openfga/openfga/pkg/server"
+	"github.com/openfga/openfga/pkg/server/commands"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/server/health"
 	"github.com/openfga/openfga/pkg/storage"
@@ -43,14 +58,18 @@ import (
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
 	"github.com/openfga/openfga/pkg/storage/storagewrappers"
 	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/otel/attribute"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
 	"go.uber.org/zap"
@@ -60,11 +79,21 @@ import (
 	healthv1pb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 const (
 	datastoreEngineFlag = "datastore-engine"
 	datastoreURIFlag    = "datastore-uri"
+
+	// modelReadRetryBackoff is the backoff before the first retry of a failed authorization model read
+	modelReadRetryBackoff = 20 * time.Millisecond
+
+	// tuplePurgeBatchSize is the number of expired tuples removed from the datastore at a time
+	tuplePurgeBatchSize = 1000
+
+	// traceFlushTimeout bounds how long exporting the spans of the last requests can delay the exit of the server
+	traceFlushTimeout = 5 * time.Second
 )
 
 func NewRunCommand() *cobra.Command {
@@ -79,9 +108,11 @@ func NewRunCommand() *cobra.Command {
 	defaultConfig := DefaultConfig()
 	flags := cmd.Flags()
 
+	flags.StringSlice("config", []string{}, "a list of config files, or directories of config files, to load instead of the default 'config.yaml'. The files are merged in order, so later files override earlier ones. Environment variables and flags override the config files")
+
 	flags.StringSlice("experimentals", defaultConfig.Experimentals, "a list of experimental features to enable")
 
-	flags.String("grpc-addr", defaultConfig.GRPC.Addr, "the host:port address to serve the grpc server on")
+	flags.String("grpc-addr", defaultConfig.GRPC.Addr, "the host:port address to serve the grpc server on. IPv6 hosts must be enclosed in square brackets, e.g. '[::]:8081', which also accepts IPv4 connections on dual-stack hosts")
 
 	flags.Bool("grpc-tls-enabled", defaultConfig.GRPC.TLS.Enabled, "enable/disable transport layer security (TLS)")
 
@@ -91,9 +122,21 @@ func NewRunCommand() *cobra.Command {
 
 	cmd.MarkFlagsRequiredTogether("grpc-tls-enabled", "grpc-tls-cert", "grpc-tls-key")
 
+	flags.Bool("grpc-tls-session-tickets-enabled", defaultConfig.GRPC.TLS.SessionTicketsEnabled, "enable/disable TLS session resumption with session tickets, which saves reconnecting clients a full handshake. Disable it if your security policy requires forward secrecy for every connection")
+
+	flags.Duration("grpc-tls-session-ticket-key-rotation", defaultConfig.GRPC.TLS.SessionTicketKeyRotation, "how often the TLS session ticket key is replaced. Tickets are accepted for up to twice this period. 0 keeps the automatic rotation of the Go standard library")
+
+	flags.StringSlice("grpc-tls-next-protos", defaultConfig.GRPC.TLS.NextProtos, "the application protocols advertised with ALPN, among 'h2', 'http/1.1' and 'http/1.0'. 'h2' is required since gRPC runs over HTTP/2. Empty keeps the default of 'h2'")
+
+	flags.Bool("grpc-proxy-protocol-enabled", defaultConfig.GRPC.ProxyProtocolEnabled, "decode the PROXY protocol header on the grpc server connections. Connections without the header are rejected unless they come from a loopback address")
+
+	flags.Bool("grpc-enable-reflection", defaultConfig.GRPC.EnableReflection, "register the grpc server reflection service, e.g. to debug with grpcurl. It exposes the full service descriptor set to any authenticated client")
+
 	flags.Bool("http-enabled", defaultConfig.HTTP.Enabled, "enable/disable the OpenFGA HTTP server")
 
-	flags.String("http-addr", defaultConfig.HTTP.Addr, "the host:port address to serve the HTTP server on")
+	flags.String("http-addr", defaultConfig.HTTP.Addr, "the host:port address to serve the HTTP server on. IPv6 hosts must be enclosed in square brackets, e.g. '[::]:8080', which also accepts IPv4 connections on dual-stack hosts")
+
+	flags.Bool("http-proxy-protocol-enabled", defaultConfig.HTTP.ProxyProtocolEnabled, "decode the PROXY protocol header on the HTTP server connections. Connections without the header are rejected unless they come from a loopback address")
 
 	flags.Bool("http-tls-enabled", defaultConfig.HTTP.TLS.Enabled, "enable/disable transport layer security (TLS)")
 
@@ -103,30 +146,68 @@ func NewRunCommand() *cobra.Command {
 
 	cmd.MarkFlagsRequiredTogether("http-tls-enabled", "http-tls-cert", "http-tls-key")
 
+	flags.Bool("http-tls-session-tickets-enabled", defaultConfig.HTTP.TLS.SessionTicketsEnabled, "enable/disable TLS session resumption with session tickets, which saves reconnecting clients a full handshake. Disable it if your security policy requires forward secrecy for every connection")
+
+	flags.Duration("http-tls-session-ticket-key-rotation", defaultConfig.HTTP.TLS.SessionTicketKeyRotation, "how often the TLS session ticket key is replaced. Tickets are accepted for up to twice this period. 0 keeps the automatic rotation of the Go standard library")
+
+	flags.StringSlice("http-tls-next-protos", defaultConfig.HTTP.TLS.NextProtos, "the application protocols advertised with ALPN, among 'h2', 'http/1.1' and 'http/1.0'. HTTP/2 is not served if 'h2' is left out. Empty keeps the default of 'h2' and 'http/1.1'")
+
 	flags.Duration("http-upstream-timeout", defaultConfig.HTTP.UpstreamTimeout, "the timeout duration for proxying HTTP requests upstream to the grpc endpoint")
 
 	flags.StringSlice("http-cors-allowed-origins", defaultConfig.HTTP.CORSAllowedOrigins, "specifies the CORS allowed origins")
 
 	flags.StringSlice("http-cors-allowed-headers", defaultConfig.HTTP.CORSAllowedHeaders, "specifies the CORS allowed headers")
 
+	flags.String("http-trailing-slash-policy", defaultConfig.HTTP.TrailingSlashPolicy, "how request paths with a trailing slash, such as '/stores/', are handled: 'strict' doesn't find them, 'accept' serves them like the path without the slash, and 'redirect' redirects them to the path without the slash. Paths are case-sensitive regardless")
+
+	flags.Bool("http-reject-unknown-json-fields", defaultConfig.HTTP.RejectUnknownJSONFields, "reject HTTP request bodies with JSON fields that aren't in the schema of the request with a 400 naming the unknown field, instead of ignoring them")
+
 	flags.String("authn-method", defaultConfig.Authn.Method, "the authentication method to use")
 
 	flags.StringSlice("authn-preshared-keys", defaultConfig.Authn.Keys, "one or more preshared keys to use for authentication")
 
+	flags.Bool("authn-preshared-reload-on-sighup", defaultConfig.Authn.ReloadOnSIGHUP, "reload the preshared keys and their labels from the config when the process receives a SIGHUP, so that keys can be added and retired without a restart")
+
+	flags.StringSlice("authn-preshared-key-labels", defaultConfig.Authn.Labels, "a label for each of the preshared keys, in the same order, partitioning the 'preshared_key_authentication_count' metric so that the keys that are still in use while rotating them can be observed")
+
 	flags.String("authn-oidc-audience", defaultConfig.Authn.Audience, "the OIDC audience of the tokens being signed by the authorization server")
 
+	flags.StringSlice("authn-oidc-audiences", defaultConfig.Authn.Audiences, "the OIDC audiences accepted in addition to the audience, e.g. when the authorization server sets a different audience depending on the caller")
+
 	flags.String("authn-oidc-issuer", defaultConfig.Authn.Issuer, "the OIDC issuer (authorization server) signing the tokens")
 
+	flags.String("authn-oidc-ca-cert-path", defaultConfig.Authn.AuthnOIDCConfig.CACertPath, "the (absolute) file path of the PEM encoded CA certificates trusted, in addition to the system ones, when fetching the OIDC configuration and keys from the issuer")
+
+	flags.Bool("authn-oidc-insecure-skip-verify", defaultConfig.Authn.InsecureSkipVerify, "skips the verification of the certificate of the issuer when fetching the OIDC configuration and keys. Only use this in development")
+
+	flags.Int64("authn-oidc-max-response-size", defaultConfig.Authn.MaxResponseSize, "the maximum size, in bytes, of the OIDC configuration and keys read from the issuer. Larger responses fail the fetch")
+
+	flags.String("authn-oidc-subject-claim", defaultConfig.Authn.SubjectClaim, "the claim of the OIDC tokens that the principal of the requests is read from. Tokens without a custom claim are rejected")
+
+	flags.Duration("authn-oidc-jwks-refresh-interval", defaultConfig.Authn.JWKSRefreshInterval, "the interval at which the keys of the OIDC issuer are refreshed in the background. The keys are also refreshed when a token is signed with an unknown key")
+
+	flags.String("authn-mtls-ca-cert-path", defaultConfig.Authn.AuthnMTLSConfig.CACertPath, "the (absolute) file path of the PEM encoded CA certificates that the client certificates must be signed by")
+
+	flags.StringSlice("authn-mtls-allowed-subjects", defaultConfig.Authn.AllowedSubjects, "regular expressions restricting the clients to those whose certificate has a matching subject or subject alternative name. Empty allows any client certificate signed by the CA")
+
 	flags.String("datastore-engine", defaultConfig.Datastore.Engine, "the datastore engine that will be used for persistence")
 
 	flags.String("datastore-uri", defaultConfig.Datastore.URI, "the connection uri to use to connect to the datastore (for any engine other than 'memory')")
 
+	flags.StringSlice("datastore-read-replica-uris", defaultConfig.Datastore.ReadReplicaURIs, "the connection uris of read replicas of the datastore, which serve the tuple reads and the authorization model reads in turn. Only supported by the 'mysql' and 'postgres' engines")
+
 	flags.String("datastore-username", "", "the connection username to use to connect to the datastore (overwrites any username provided in the connection uri)")
 
 	flags.String("datastore-password", "", "the connection password to use to connect to the datastore (overwrites any password provided in the connection uri)")
 
 	flags.Int("datastore-max-cache-size", defaultConfig.Datastore.MaxCacheSize, "the maximum number of cache keys that the storage cache can store before evicting old keys")
 
+	flags.Duration("datastore-model-cache-ttl", defaultConfig.Datastore.ModelCacheTTL, "how long an authorization model stays cached after it was read. 0 means the models never expire")
+
+	flags.Duration("datastore-latest-model-id-cache-ttl", defaultConfig.Datastore.LatestModelIDCacheTTL, "how long the latest authorization model ID of a store is cached. Models written through other instances of the server can take up to twice as long to be seen. 0 disables the cache")
+
+	flags.Int("datastore-model-read-retries", defaultConfig.Datastore.ModelReadRetries, "the number of times a failed read of an authorization model that isn't cached yet is retried before giving up. Not-found errors are never retried")
+
 	flags.Int("datastore-max-open-conns", defaultConfig.Datastore.MaxOpenConns, "the maximum number of open connections to the datastore")
 
 	flags.Int("datastore-max-idle-conns", defaultConfig.Datastore.MaxIdleConns, "the maximum number of connections to the datastore in the idle connection pool")
@@ -135,10 +216,26 @@ func NewRunCommand() *cobra.Command {
 
 	flags.Duration("datastore-conn-max-lifetime", defaultConfig.Datastore.ConnMaxLifetime, "the maximum amount of time a connection to the datastore may be reused")
 
+	flags.Float64("datastore-conn-max-lifetime-jitter", defaultConfig.Datastore.ConnMaxLifetimeJitter, "the maximum fraction (between 0 and 1) of the connection max lifetime by which the lifetime of each connection is randomly shortened")
+
+	flags.Duration("datastore-statement-timeout", defaultConfig.Datastore.StatementTimeout, "the maximum amount of time the database lets a single query run before aborting it. Postgres enforces it on all statements, MySQL only on SELECT statements. 0 means no timeout")
+
+	flags.Duration("datastore-query-timeout", defaultConfig.Datastore.QueryTimeout, "the maximum amount of time the server waits for a single datastore call before failing it with a DeadlineExceeded error. 0 means no timeout")
+
+	flags.Duration("datastore-connect-timeout", defaultConfig.Datastore.ConnectTimeout, "the maximum amount of time to wait at startup for the datastore to accept connections before the server fails to start")
+
+	flags.Int("datastore-startup-retry-max-attempts", defaultConfig.Datastore.StartupRetry.MaxAttempts, "the maximum number of times the datastore is pinged at startup before the server fails to start. 0 means the attempts are only bounded by the datastore-connect-timeout")
+
+	flags.Duration("datastore-startup-retry-initial-interval", defaultConfig.Datastore.StartupRetry.InitialInterval, "how long to wait after the first failed ping of the datastore at startup. The wait grows exponentially with each further attempt")
+
+	flags.Bool("datastore-auto-migrate", defaultConfig.Datastore.AutoMigrate, "run the pending migrations of the datastore schema at startup instead of failing to start when the schema is out of date")
+
 	flags.Bool("playground-enabled", defaultConfig.Playground.Enabled, "enable/disable the OpenFGA Playground")
 
 	flags.Int("playground-port", defaultConfig.Playground.Port, "the port to serve the local OpenFGA Playground on")
 
+	flags.String("playground-base-path", defaultConfig.Playground.BasePath, "the path prefix to serve the OpenFGA Playground and its assets under, e.g. '/openfga' to serve it at '/openfga/playground'")
+
 	flags.Bool("profiler-enabled", defaultConfig.Profiler.Enabled, "enable/disable pprof profiling")
 
 	flags.String("profiler-addr", defaultConfig.Profiler.Addr, "the host:port address to serve the pprof profiler server on")
@@ -147,32 +244,152 @@ func NewRunCommand() *cobra.Command {
 
 	flags.String("log-level", defaultConfig.Log.Level, "the log level to use")
 
+	flags.StringSlice("log-redact-fields", defaultConfig.Log.RedactFields, "a list of fields (e.g. 'user' or 'object') whose values are redacted in the logged requests and responses")
+
+	flags.String("log-redact-mode", defaultConfig.Log.RedactMode, "how the values of the redacted fields are logged. 'hash' replaces them with a hash and 'mask' replaces them with a placeholder")
+
+	flags.String("log-redact-hash-key", defaultConfig.Log.RedactHashKey, "the secret key of the HMAC that replaces the values of the redacted fields in the 'hash' redact mode")
+
 	flags.Bool("trace-enabled", defaultConfig.Trace.Enabled, "enable tracing")
 
-	flags.String("trace-otlp-endpoint", defaultConfig.Trace.OTLP.Endpoint, "the endpoint of the trace collector")
+	flags.String("trace-otlp-endpoint", defaultConfig.Trace.OTLP.Endpoint, "the endpoint of the trace collector. With the 'http/protobuf' protocol, it can be a full URL such as 'https://collector:4318/v1/traces'")
+
+	flags.String("trace-otlp-protocol", defaultConfig.Trace.OTLP.Protocol, "the protocol the traces are exported with: 'grpc' or 'http/protobuf'")
+
+	flags.Bool("trace-otlp-tls-enabled", defaultConfig.Trace.OTLP.TLS.Enabled, "export the traces to the collector over TLS")
+
+	flags.String("trace-otlp-tls-ca-cert-path", defaultConfig.Trace.OTLP.TLS.CACertPath, "the (absolute) file path of the PEM encoded CA certificates trusted, in addition to the system ones, to verify the trace collector")
+
+	flags.String("trace-otlp-tls-cert", defaultConfig.Trace.OTLP.TLS.CertPath, "the (absolute) file path of the client certificate presented to the trace collector, for mutual TLS")
+
+	flags.String("trace-otlp-tls-key", defaultConfig.Trace.OTLP.TLS.KeyPath, "the (absolute) file path of the key of the client certificate presented to the trace collector")
+
+	cmd.MarkFlagsRequiredTogether("trace-otlp-tls-cert", "trace-otlp-tls-key")
 
 	flags.Float64("trace-sample-ratio", defaultConfig.Trace.SampleRatio, "the fraction of traces to sample. 1 means all, 0 means none.")
 
+	flags.Bool("trace-parent-based", defaultConfig.Trace.ParentBased, "sample the requests that carry a remote trace context like their parent was, instead of with the trace-sample-ratio")
+
 	flags.String("trace-service-name", defaultConfig.Trace.ServiceName, "the service name included in sampled traces.")
 
+	flags.String("trace-service-instance-id", defaultConfig.Trace.ServiceInstanceID, "the service instance id included in sampled traces. Useful for telling apart multiple instances of the same service.")
+
+	flags.String("trace-queue-full-policy", defaultConfig.Trace.QueueFullPolicy, "what happens to sampled spans when the export queue is full. 'drop' drops them right away, 'block' waits up to the trace-queue-full-block-timeout for room in the queue before dropping them")
+
+	flags.Duration("trace-queue-full-block-timeout", defaultConfig.Trace.QueueFullBlockTimeout, "the maximum time ending a span can block when the trace-queue-full-policy is 'block'")
+
+	flags.Int("trace-batch-max-queue-size", defaultConfig.Trace.Batch.MaxQueueSize, "the maximum number of sampled spans waiting to be exported")
+
+	flags.Int("trace-batch-max-export-batch-size", defaultConfig.Trace.Batch.MaxExportBatchSize, "the maximum number of spans exported at once. It can't be greater than the trace-batch-max-queue-size")
+
+	flags.Duration("trace-batch-timeout", defaultConfig.Trace.Batch.Timeout, "how long sampled spans wait for a full batch before being exported anyway")
+
+	flags.String("trace-force-sample-secret", defaultConfig.Trace.ForceSampleSecret, "a shared secret that, when sent as the value of the 'x-openfga-force-trace' header, forces the request to be sampled regardless of the sample ratio. If empty, the header is ignored.")
+
+	flags.StringSlice("trace-baggage-attributes", defaultConfig.Trace.BaggageAttributes, "a list of OpenTelemetry baggage keys (e.g. 'tenant') whose values are set as 'baggage.<key>' attributes on the span of each request. Other baggage members are ignored.")
+
+	flags.StringSlice("trace-store-sample-ratios", defaultConfig.Trace.StoreSampleRatios, "a list of 'storeID=ratio' pairs (e.g. '01GXSA8YR785C4FYS3C0RTG7B1=1') that sample the traces of the requests on those stores with their own ratio instead of the trace-sample-ratio")
+
 	flags.Bool("metrics-enabled", defaultConfig.Metrics.Enabled, "enable/disable prometheus metrics on the '/metrics' endpoint")
 
 	flags.String("metrics-addr", defaultConfig.Metrics.Addr, "the host:port address to serve the prometheus metrics server on")
 
 	flags.Bool("metrics-enable-rpc-histograms", defaultConfig.Metrics.EnableRPCHistograms, "enables prometheus histogram metrics for RPC latency distributions")
 
+	flags.StringSlice("metrics-rpc-histogram-buckets", []string{}, "the upper bounds, in seconds, of the buckets of the RPC latency histograms, in increasing order (e.g. '0.001,0.005,0.025'). If empty, the default prometheus buckets are used")
+
+	flags.Bool("metrics-enable-check-result-store-label", defaultConfig.Metrics.EnableCheckResultStoreLabel, "partitions the Check allowed/denied metric by store. The cardinality of the metric grows with the number of stores")
+
+	flags.Bool("metrics-enable-model-cache-store-label", defaultConfig.Metrics.EnableModelCacheStoreLabel, "partitions the authorization model cache hit/miss metrics by store. The cardinality of the metrics grows with the number of stores")
+
+	flags.Duration("metrics-model-cache-sample-interval", defaultConfig.Metrics.ModelCacheSampleInterval, "how often the number of authorization models in the model cache, and the number of models evicted from it, are sampled for the metrics")
+
+	flags.String("metrics-namespace", defaultConfig.Metrics.Namespace, "a prefix, joined with an underscore, for the name of every metric on the '/metrics' endpoint. If empty, metric names are not prefixed")
+
+	flags.Bool("metrics-fail-on-listen-error", defaultConfig.Metrics.FailOnListenError, "stop the server from starting if the metrics server can't listen on its address. By default a warning is logged and the API is served without the metrics endpoint")
+
+	flags.Bool("metrics-enable-runtime-metrics", defaultConfig.Metrics.EnableRuntimeMetrics, "enables the standard Go runtime and process metrics on the '/metrics' endpoint")
+
+	flags.Duration("slow-start-duration", defaultConfig.SlowStart.Duration, "the duration over which the number of concurrently admitted requests ramps up after startup. Requests over the limit are rejected with a retryable error. If 0, slow start is disabled")
+
+	flags.Uint32("slow-start-max-concurrency", defaultConfig.SlowStart.MaxConcurrency, "the number of concurrently admitted requests at the end of the slow start ramp")
+
+	flags.Uint32("concurrency-limit-max-concurrency", defaultConfig.ConcurrencyLimit.MaxConcurrency, "the maximum number of requests served concurrently by each of the gRPC and HTTP servers. Requests over the limit are rejected with a 429 (HTTP) or ResourceExhausted (gRPC) error. If 0, requests are not limited")
+
+	flags.Uint32("concurrency-limit-max-queue-size", defaultConfig.ConcurrencyLimit.MaxQueueSize, "the maximum number of requests over the concurrency limit that wait for a request to finish instead of being rejected right away. If 0, requests are not queued")
+
+	flags.Duration("concurrency-limit-queue-timeout", defaultConfig.ConcurrencyLimit.QueueTimeout, "how long a queued request waits for a request to finish before being rejected")
+
+	flags.Bool("audit-log-enabled", defaultConfig.AuditLog.Enabled, "enables the audit log, which records the CreateStore, DeleteStore and WriteAuthorizationModel operations along with the authenticated principal that performed them")
+
+	flags.String("audit-log-output", defaultConfig.AuditLog.Output, "where the audit log is written: 'stdout' or the path of a file that entries are appended to")
+
+	flags.Int("audit-log-queue-size", defaultConfig.AuditLog.QueueSize, "the number of audit log entries queued to be written in the background instead of before the operation completes. The queued entries are written on a graceful shutdown. If 0, entries are written synchronously")
+
+	flags.Duration("audit-log-flush-interval", defaultConfig.AuditLog.FlushInterval, "how often the queued audit log entries are written when the audit log queue size is set")
+
+	flags.String("audit-log-queue-full-policy", defaultConfig.AuditLog.QueueFullPolicy, "what happens to an audit log entry when the queue is full. 'block' makes the operation wait for room in the queue, 'drop' drops the entry and counts it with the 'audit_log_dropped_entries_count' metric")
+
+	flags.String("bootstrap-model-file", defaultConfig.Bootstrap.ModelFile, "the path of a JSON file with an authorization model, in the format of the body of WriteAuthorizationModel requests, that is written to the bootstrap store at startup unless it is already the latest model of the store")
+
+	flags.String("bootstrap-store-id", defaultConfig.Bootstrap.StoreID, "the ID of the store the bootstrap model is written to. The store is created if it doesn't exist")
+
+	flags.Bool("bootstrap-pin-model", defaultConfig.Bootstrap.PinModel, "pins the bootstrap store to the bootstrap model, so that requests on the store are evaluated with it even after other models are written")
+
 	flags.Int("max-tuples-per-write", defaultConfig.MaxTuplesPerWrite, "the maximum allowed number of tuples per Write transaction")
 
+	flags.Int("max-tuple-object-length", defaultConfig.MaxTupleObjectLength, "the maximum length, in bytes, of the 'object' field of a tuple. A value of 0 means no limit")
+
+	flags.Int("max-tuple-relation-length", defaultConfig.MaxTupleRelationLength, "the maximum length, in bytes, of the 'relation' field of a tuple. A value of 0 means no limit")
+
+	flags.Int("max-tuple-user-length", defaultConfig.MaxTupleUserLength, "the maximum length, in bytes, of the 'user' field of a tuple. A value of 0 means no limit")
+
 	flags.Int("max-types-per-authorization-model", defaultConfig.MaxTypesPerAuthorizationModel, "the maximum allowed number of type definitions per authorization model")
 
 	flags.Int("changelog-horizon-offset", defaultConfig.ChangelogHorizonOffset, "the offset (in minutes) from the current time. Changes that occur after this offset will not be included in the response of ReadChanges")
 
+	flags.Int32("read-changes-max-page-size", defaultConfig.ReadChangesMaxPageSize, "the maximum number of changes returned in a single ReadChanges response. Larger page sizes requested by clients are reduced to this value")
+
+	flags.Int32("read-authorization-models-default-page-size", defaultConfig.ReadAuthorizationModelsDefaultPageSize, "the number of authorization models returned by a ReadAuthorizationModels request that doesn't set a page size")
+
+	flags.Int32("read-authorization-models-max-page-size", defaultConfig.ReadAuthorizationModelsMaxPageSize, "the maximum number of authorization models returned in a single ReadAuthorizationModels response. Larger page sizes requested by clients are reduced to this value")
+
 	flags.Uint32("resolve-node-limit", defaultConfig.ResolveNodeLimit, "defines how deeply nested an authorization model can be")
 
+	flags.Bool("authorization-model-id-header-enabled", defaultConfig.AuthorizationModelIDHeaderEnabled, "sets the 'openfga-authorization-model-id' response header to the ID of the model that answered the request")
+
+	flags.Bool("require-latest-authorization-model", defaultConfig.RequireLatestAuthorizationModel, "rejects Check and ListObjects requests that set an authorization model ID other than the latest model of the store")
+
+	flags.String("cluster", defaultConfig.Cluster, "identifies the cluster or deployment this instance runs in. If set, it is added as a resource attribute on traces and as a label on every metric")
+
+	flags.Bool("strict-tuple-validation", defaultConfig.StrictTupleValidation, "rejects written and contextual tuples whose user does not follow the 'type:id' format with a type defined in the authorization model")
+
+	flags.Bool("idempotent-writes", defaultConfig.IdempotentWrites, "makes writing a tuple that already exists a no-op instead of an error that fails the whole Write request")
+
+	flags.Bool("idempotent-deletes", defaultConfig.IdempotentDeletes, "makes deleting a tuple that does not exist a no-op instead of an error that fails the whole Write request")
+
+	flags.Bool("model-cache-bypass-enabled", defaultConfig.ModelCacheBypassEnabled, "allows Check requests to set the 'openfga-bypass-model-cache: true' header to read the authorization model from the datastore instead of the cache. It adds load to the datastore, so only enable it while debugging")
+
 	flags.Duration("listObjects-deadline", defaultConfig.ListObjectsDeadline, "the timeout deadline for serving ListObjects requests")
 
 	flags.Uint32("listObjects-max-results", defaultConfig.ListObjectsMaxResults, "the maximum results to return in non-streaming ListObjects API responses. If 0, all results can be returned")
 
+	flags.Bool("listObjects-deduplication-enabled", defaultConfig.ListObjectsDeduplicationEnabled, "makes sure that ListObjects returns each object only once, even if it is related to the user through multiple paths. Duplicates are dropped before the results are capped by listObjects-max-results")
+
+	flags.Uint32("listObjects-max-paths-explored", defaultConfig.ListObjectsMaxPathsExplored, "the maximum number of tuples a single ListObjects request reads while searching for objects. Once reached, the objects found so far are returned and the 'openfga-list-objects-truncated' header is set. If 0, there is no limit")
+
+	flags.Uint32("listObjects-max-concurrent-streams-per-client", defaultConfig.ListObjectsMaxConcurrentStreamsPerClient, "the maximum number of concurrent streaming ListObjects requests per client. If 0, there is no limit")
+
+	flags.Uint32("max-concurrent-writes-per-store", defaultConfig.MaxConcurrentWritesPerStore, "the maximum number of concurrent Write requests per store. Writes over the limit are rejected with a ResourceExhausted error. If 0, there is no limit")
+
+	flags.Uint32("max-contextual-tuples-per-check", defaultConfig.MaxContextualTuplesPerCheck, "the maximum number of contextual tuples in a Check request. Requests with more are rejected with an InvalidArgument error. If 0, there is no limit")
+
+	flags.StringSlice("check-candidate-models", defaultConfig.CheckCandidateModels, "a list of 'storeID=modelID' pairs of candidate authorization models. The Check requests on those stores are also evaluated with the candidate model in the background, and the results that would differ are logged and counted, without affecting the responses")
+
+	flags.Duration("tuple-purge-interval", defaultConfig.TuplePurgeInterval, "how often tuples written with a TTL that have expired are removed from the datastore. Expired tuples are excluded from reads right away. If 0, they are never removed")
+
+	flags.Duration("graceful-shutdown-timeout", defaultConfig.GracefulShutdownTimeout, "how long the gRPC and HTTP servers wait for the active requests to finish on shutdown before closing their connections")
+
 	// NOTE: if you add a new flag here, update the function below, too
 
 	cmd.PreRun = bindRunFlagsFunc(flags)
@@ -189,11 +406,34 @@ type DatastoreConfig struct {
 	Username string
 	Password string
 
+	// ReadReplicaURIs are the connection uris of read replicas of the datastore. The tuple reads and the
+	// authorization model reads are sent to the replicas in turn while everything else goes to the primary at URI,
+	// so these reads may not see the latest writes until they are replicated. The replicas use the same engine,
+	// credentials and connection settings as the primary. Only the 'mysql' and 'postgres' engines support them.
+	ReadReplicaURIs []string
+
 	// MaxCacheSize is the maximum number of cache keys that the storage cache can store before evicting
 	// old keys. The storage cache is used to cache query results for various static resources
 	// such as type definitions.
 	MaxCacheSize int
 
+	// ModelCacheTTL is how long an authorization model stays cached after it was read. Models are immutable, so
+	// this only matters when stores are recreated with the same IDs, e.g. in integration tests. 0 means the models
+	// never expire, and are only evicted when the cache is full.
+	ModelCacheTTL time.Duration
+
+	// LatestModelIDCacheTTL is how long the latest authorization model ID of a store is cached, so that requests
+	// that don't specify a model don't look it up in the datastore every time. Stale entries are served for up to
+	// another ttl while they are refreshed in the background, so the models written through other instances of the
+	// server can take up to twice as long to be seen. The models written through this instance are seen right away.
+	// 0 disables the cache.
+	LatestModelIDCacheTTL time.Duration
+
+	// ModelReadRetries is the number of times a failed read of an authorization model that isn't cached yet is
+	// retried, with a short backoff, before the error is returned. Concurrent reads of the same model share a
+	// single read and its retries. Not-found errors are never retried.
+	ModelReadRetries int
+
 	// MaxOpenConns is the maximum number of open connections to the database.
 	MaxOpenConns int
 
@@ -205,19 +445,84 @@ type DatastoreConfig struct {
 
 	// ConnMaxLifetime is the maximum amount of time a connection to the datastore may be reused.
 	ConnMaxLifetime time.Duration
+
+	// ConnMaxLifetimeJitter is the maximum fraction (between 0 and 1) of ConnMaxLifetime by which the
+	// lifetime of each connection is randomly shortened, so that connections opened at the same time
+	// don't all expire at once. It has no effect if ConnMaxLifetime is not set.
+	ConnMaxLifetimeJitter float64
+
+	// StatementTimeout is the maximum amount of time the database lets a single query run before aborting it,
+	// as a safeguard against a stuck query using up the whole deadline of a request. It is enforced by the
+	// database: Postgres sets the 'statement_timeout' parameter on every connection and cancels any statement
+	// that runs for longer, while MySQL sets the 'max_execution_time' session variable, which only applies to
+	// read-only SELECT statements. It has no effect on the 'memory' and 'sqlite' engines. Zero means no timeout.
+	StatementTimeout time.Duration
+
+	// QueryTimeout is the maximum amount of time the server waits for a single datastore call, such as reading the
+	// tuples or writing an authorization model. Unlike the StatementTimeout, it is enforced by the server, so it also
+	// bounds the calls stalled by the network. A call that times out fails the request with a DeadlineExceeded error
+	// that tells it apart from the deadline of the request. For the calls that return an iterator of tuples, it
+	// covers reading the iterator. Zero means no timeout.
+	QueryTimeout time.Duration
+
+	// ConnectTimeout is the maximum amount of time to wait at startup for the datastore to accept connections.
+	// The server fails to start with an error naming the datastore if it doesn't within the timeout. It has no
+	// effect on the 'memory' engine.
+	ConnectTimeout time.Duration
+
+	// StartupRetry bounds how the datastore is pinged at startup while it doesn't accept connections yet.
+	StartupRetry DatastoreStartupRetryConfig
+
+	// AutoMigrate runs the pending migrations of the datastore schema at startup. If it is disabled and the schema
+	// is older than the one the server requires, the server fails to start and asks to run 'openfga migrate'. It
+	// has no effect on the 'memory' engine.
+	AutoMigrate bool
+}
+
+// DatastoreStartupRetryConfig defines how the initial ping of the datastore is retried with an exponential backoff,
+// for example while the database is still starting next to the server. Retries stop at the ConnectTimeout even if
+// attempts are left.
+type DatastoreStartupRetryConfig struct {
+	// MaxAttempts is the maximum number of times the datastore is pinged before the server fails to start. Zero
+	// means the attempts are only bounded by the ConnectTimeout.
+	MaxAttempts int
+
+	// InitialInterval is how long to wait after the first failed ping. The wait grows exponentially with each
+	// further attempt.
+	InitialInterval time.Duration
 }
 
 // GRPCConfig defines OpenFGA server configurations for grpc server specific settings.
 type GRPCConfig struct {
+	// Addr is the host:port address to listen on. IPv6 hosts must be enclosed in square brackets (e.g.
+	// '[::1]:8081'). '[::]:8081' or ':8081' listen on all the addresses of the host, and also accept IPv4
+	// connections on dual-stack hosts, unless the OS is configured to bind IPv6 sockets to IPv6 only.
+	// '0.0.0.0:8081' only listens on IPv4 addresses.
 	Addr string
 	TLS  *TLSConfig
+
+	// ProxyProtocolEnabled decodes the PROXY protocol header sent by L4 load balancers, so that the address of
+	// the original client is preserved. When enabled, connections without the header are rejected unless they
+	// come from a loopback address.
+	ProxyProtocolEnabled bool
+
+	// EnableReflection registers the gRPC server reflection service, e.g. to debug with grpcurl. It exposes the full
+	// service descriptor set of the server to any authenticated client, so it should be left off in production.
+	EnableReflection bool
 }
 
 // HTTPConfig defines OpenFGA server configurations for HTTP server specific settings.
 type HTTPConfig struct {
 	Enabled bool
-	Addr    string
-	TLS     *TLSConfig
+
+	// Addr is the host:port address to listen on. See GRPCConfig.Addr for IPv6 and dual-stack addresses.
+	Addr string
+	TLS  *TLSConfig
+
+	// ProxyProtocolEnabled decodes the PROXY protocol header sent by L4 load balancers, so that the address of
+	// the original client is preserved. When enabled, connections without the header are rejected unless they
+	// come from a loopback address.
+	ProxyProtocolEnabled bool
 
 	// UpstreamTimeout is the timeout duration for proxying HTTP requests upstream
 	// to the grpc endpoint. It cannot be smaller than Config.ListObjectsDeadline.
@@ -225,6 +530,16 @@ type HTTPConfig struct {
 
 	CORSAllowedOrigins []string
 	CORSAllowedHeaders []string
+
+	// TrailingSlashPolicy is how request paths with a trailing slash, such as '/stores/', are handled: 'strict'
+	// doesn't find them, 'accept' serves them like the path without the slash, and 'redirect' redirects them to it.
+	// Paths are matched case-sensitively regardless of the policy.
+	TrailingSlashPolicy string
+
+	// RejectUnknownJSONFields rejects request bodies with fields that aren't in the schema of the request, instead
+	// of ignoring them, so that typos in client integrations are caught early. It is off by default for the
+	// clients that rely on lenient parsing.
+	RejectUnknownJSONFields bool
 }
 
 // TLSConfig defines configuration specific to Transport Layer Security (TLS) settings.
@@ -232,27 +547,92 @@ type TLSConfig struct {
 	Enabled  bool
 	CertPath string `mapstructure:"cert"`
 	KeyPath  string `mapstructure:"key"`
+
+	// SessionTicketsEnabled lets clients resume TLS sessions with session tickets, which saves them a full
+	// handshake when they reconnect. Security policies that require forward secrecy for every connection may
+	// forbid them, since a leaked ticket key exposes the sessions resumed with it.
+	SessionTicketsEnabled bool
+
+	// SessionTicketKeyRotation is how often the session ticket key is replaced. Tickets are accepted for up to
+	// twice this period. Shorter periods limit the exposure of a leaked key at the cost of more full handshakes.
+	// Zero keeps the automatic rotation of the Go standard library.
+	SessionTicketKeyRotation time.Duration
+
+	// NextProtos are the application protocols advertised with ALPN, e.g. to match what a load balancer expects.
+	// Empty keeps the defaults of the listener: 'h2' for the gRPC server, and 'h2' and 'http/1.1' for the HTTP
+	// server. The gRPC server only speaks 'h2', so it must be among them.
+	NextProtos []string
 }
 
 // AuthnConfig defines OpenFGA server configurations for authentication specific settings.
 type AuthnConfig struct {
 
-	// Method is the authentication method that should be enforced (e.g. 'none', 'preshared', 'oidc')
+	// Method is the authentication method that should be enforced (e.g. 'none', 'preshared', 'oidc', 'mtls')
 	Method                   string
 	*AuthnOIDCConfig         `mapstructure:"oidc"`
 	*AuthnPresharedKeyConfig `mapstructure:"preshared"`
+	*AuthnMTLSConfig         `mapstructure:"mtls"`
 }
 
 // AuthnOIDCConfig defines configurations for the 'oidc' method of authentication.
 type AuthnOIDCConfig struct {
 	Issuer   string
 	Audience string
+
+	// Audiences are accepted in addition to Audience, e.g. when the issuer sets a different audience depending on
+	// the caller. The 'aud' claim of the tokens must contain at least one of them.
+	Audiences []string
+
+	// CACertPath is the path of the PEM encoded CA certificates that are trusted, in addition to the system ones,
+	// when fetching the OIDC configuration and keys from the issuer, e.g. an internal issuer signed by a private CA.
+	CACertPath string
+
+	// InsecureSkipVerify skips the verification of the certificate of the issuer. It must only be used in
+	// development.
+	InsecureSkipVerify bool
+
+	// MaxResponseSize is the maximum size, in bytes, of the OIDC configuration and keys read from the issuer, so
+	// that a hostile or misconfigured issuer can't exhaust the memory of the server.
+	MaxResponseSize int64
+
+	// JWKSRefreshInterval is the interval at which the keys of the issuer are refreshed in the background. The keys
+	// are also refreshed, at most once a minute, when a token is signed with a key that isn't known yet, e.g. after
+	// the issuer rotated its keys. If a refresh fails, the last keys fetched keep being used.
+	JWKSRefreshInterval time.Duration
+
+	// SubjectClaim is the claim that the principal of the requests is read from, e.g. when the issuer sets the
+	// identity of the user in a custom claim rather than in 'sub'. Unlike 'sub', which is optional, the tokens
+	// without a custom claim are rejected.
+	SubjectClaim string
 }
 
 // AuthnPresharedKeyConfig defines configurations for the 'preshared' method of authentication.
 type AuthnPresharedKeyConfig struct {
 	// Keys define the preshared keys to verify authn tokens against.
 	Keys []string
+
+	// Labels optionally label each of the Keys, at the same index, e.g. with the date they were issued. The number
+	// of authentications with each key is counted by label, so that a key being phased out can be removed once it
+	// is no longer used. Labels must not contain the keys themselves.
+	Labels []string
+
+	// ReloadOnSIGHUP reloads the Keys and Labels from the config when the process receives a SIGHUP, so that a key
+	// can be added and an old one retired without restarting the server. If the reloaded keys are invalid, the
+	// current keys are kept.
+	ReloadOnSIGHUP bool
+}
+
+// AuthnMTLSConfig defines configurations for the 'mtls' method of authentication. The clients authenticate with the
+// certificate they present in the TLS handshake, so TLS must be enabled on the gRPC server, and on the HTTP server
+// if it is enabled.
+type AuthnMTLSConfig struct {
+	// CACertPath is the path of the PEM encoded CA certificates that the client certificates must be signed by.
+	CACertPath string
+
+	// AllowedSubjects optionally restricts the clients to those whose certificate has a subject or a subject
+	// alternative name that matches one of these regular expressions in full, e.g. 'CN=billing,O=Acme' or
+	// 'spiffe://acme.internal/ns/prod/.*'. The subject of the certificate is the principal of the requests.
+	AllowedSubjects []string
 }
 
 // LogConfig defines OpenFGA server configurations for log specific settings. For production we
@@ -263,6 +643,18 @@ type LogConfig struct {
 
 	// Level is the log level to use in the log output (e.g. 'none', 'debug', or 'info')
 	Level string
+
+	// RedactFields is a list of fields (e.g. 'user' or 'object') whose values are redacted in the
+	// logged requests and responses. By default no fields are redacted.
+	RedactFields []string
+
+	// RedactMode is how the values of RedactFields are redacted. 'hash' replaces each value with a
+	// hash of it, so that log entries can still be correlated, and 'mask' replaces it with a placeholder.
+	RedactMode string
+
+	// RedactHashKey is the secret key of the HMAC that replaces the values of RedactFields in the 'hash'
+	// RedactMode. It is required in that mode, so that the values can't be recovered by hashing candidates.
+	RedactHashKey string
 }
 
 type TraceConfig struct {
@@ -270,16 +662,91 @@ type TraceConfig struct {
 	OTLP        OTLPTraceConfig `mapstructure:"otlp"`
 	SampleRatio float64
 	ServiceName string
+
+	// ParentBased samples the requests that carry the trace context of a sampled remote parent, e.g. from a gateway
+	// that made the sampling decision upstream, and drops the ones whose remote parent was not sampled, instead of
+	// sampling them with the SampleRatio. The requests without a remote parent are still sampled with the
+	// SampleRatio. Disable it to ignore the sampling decisions of the clients.
+	ParentBased bool
+
+	// ServiceInstanceID is included as the 'service.instance.id' resource attribute in sampled traces.
+	// If empty, the attribute is omitted.
+	ServiceInstanceID string
+
+	// QueueFullPolicy is what happens to sampled spans when the export queue is full: 'drop' drops them right
+	// away, while 'block' makes the request wait up to QueueFullBlockTimeout for room in the queue before dropping
+	// them. Dropped spans are counted by the 'trace_dropped_spans_count' metric.
+	QueueFullPolicy string
+
+	// QueueFullBlockTimeout bounds how long ending a span can block when QueueFullPolicy is 'block'.
+	QueueFullBlockTimeout time.Duration
+
+	Batch TraceBatchConfig
+
+	// ForceSampleSecret is a shared secret that, when sent by a client as the value of the
+	// 'x-openfga-force-trace' header, forces the traces of that request to be sampled regardless
+	// of the SampleRatio. If empty, forced sampling is disabled.
+	ForceSampleSecret string
+
+	// BaggageAttributes is a list of OpenTelemetry baggage keys whose values, when propagated by the client, are
+	// set as 'baggage.<key>' attributes on the span of the request, so that traces can be filtered by upstream
+	// metadata such as the tenant. Baggage members with other keys are not copied.
+	BaggageAttributes []string
+
+	// StoreSampleRatios is a list of 'storeID=ratio' pairs that sample the traces of the requests on those stores
+	// with their own ratio instead of the SampleRatio, so that important stores can be traced more than bulk ones.
+	// Streaming requests are sampled with the SampleRatio whatever their store.
+	StoreSampleRatios []string
+}
+
+// TraceBatchConfig defines how the sampled spans are queued and batched before they are exported. The defaults are
+// the ones of the OpenTelemetry SDK.
+type TraceBatchConfig struct {
+	// MaxQueueSize is the maximum number of spans waiting to be exported. Spans ended when the queue is full are
+	// handled according to the QueueFullPolicy.
+	MaxQueueSize int
+
+	// MaxExportBatchSize is the maximum number of spans exported at once. It can't be greater than MaxQueueSize.
+	MaxExportBatchSize int
+
+	// Timeout is how long spans wait for a full batch before being exported anyway.
+	Timeout time.Duration
 }
 
 type OTLPTraceConfig struct {
+	// Endpoint is the 'host:port' of the collector. With the 'http/protobuf' Protocol, it can also be a full URL,
+	// such as 'https://collector:4318/v1/traces'.
 	Endpoint string
+
+	// Protocol is the protocol the traces are exported with: 'grpc' or 'http/protobuf'.
+	Protocol string
+
+	TLS OTLPTLSConfig
+}
+
+// OTLPTLSConfig defines the TLS configuration of the connection to the trace collector. When it is disabled, the
+// spans are exported in plaintext.
+type OTLPTLSConfig struct {
+	Enabled bool
+
+	// CACertPath is the path of the PEM encoded CA certificates that are trusted, in addition to the system ones,
+	// to verify the certificate of the collector.
+	CACertPath string
+
+	// CertPath and KeyPath are the client certificate and key presented to a collector that requires mutual TLS.
+	CertPath string `mapstructure:"cert"`
+	KeyPath  string `mapstructure:"key"`
 }
 
 // PlaygroundConfig defines OpenFGA server configurations for the Playground specific settings.
 type PlaygroundConfig struct {
 	Enabled bool
 	Port    int
+
+	// BasePath is the path prefix the playground and its assets are served under, e.g. '/openfga' to serve it at
+	// '/openfga/playground' when OpenFGA is hosted under a sub-path behind a reverse proxy. Empty serves it at
+	// '/playground'.
+	BasePath string
 }
 
 // ProfilerConfig defines server configurations specific to pprof profiling.
@@ -288,11 +755,104 @@ type ProfilerConfig struct {
 	Addr    string
 }
 
+// SlowStartConfig defines configurations for gradually increasing the number of requests the server admits
+// concurrently after it starts, so that its caches can warm up before it receives full traffic.
+type SlowStartConfig struct {
+	// Duration is how long the ramp lasts. The concurrency limit increases linearly from 1 to MaxConcurrency
+	// during the ramp, and requests are no longer limited afterwards. If 0, slow start is disabled.
+	Duration time.Duration
+
+	// MaxConcurrency is the concurrency limit at the end of the ramp.
+	MaxConcurrency uint32
+}
+
+// ConcurrencyLimitConfig defines configurations for capping the number of requests the server serves concurrently,
+// to protect the instance against overload. The limit applies in front of all the other limits of the server.
+type ConcurrencyLimitConfig struct {
+	// MaxConcurrency is the maximum number of requests served concurrently by each of the gRPC and HTTP servers.
+	// Since the HTTP server serves its requests through the gRPC server, HTTP requests count against the gRPC limit
+	// too. If 0, requests are not limited.
+	MaxConcurrency uint32
+
+	// MaxQueueSize is the maximum number of requests over the limit that wait for up to QueueTimeout for a request to
+	// finish, instead of being rejected right away. If 0, requests are not queued.
+	MaxQueueSize uint32
+
+	// QueueTimeout is how long a queued request waits for a request to finish before it is rejected.
+	QueueTimeout time.Duration
+}
+
+// AuditLogConfig defines configurations for the audit log, which records the administrative operations that mutate
+// the server state. An operation that succeeded isn't failed if its audit log entry cannot be written, the failure is
+// logged and counted by the 'audit_log_failed_entries_count' metric instead.
+type AuditLogConfig struct {
+	Enabled bool
+
+	// Output is 'stdout', or the path of the file that entries are appended to as lines of JSON.
+	Output string
+
+	// QueueSize is the number of entries queued to be written in the background, every FlushInterval, instead of
+	// being written before the operation completes. The queued entries are written on a graceful shutdown. If 0,
+	// entries are written synchronously.
+	QueueSize int
+
+	// FlushInterval is how often the queued entries are written when QueueSize is set.
+	FlushInterval time.Duration
+
+	// QueueFullPolicy is what happens to an entry when the queue is full: 'block' makes the operation wait for room
+	// in the queue, while 'drop' drops the entry and counts it with the 'audit_log_dropped_entries_count' metric.
+	QueueFullPolicy string
+}
+
+// BootstrapConfig defines an authorization model that is made the latest model of a store at startup, so that the
+// model can be shipped with the deployment instead of being provisioned separately.
+type BootstrapConfig struct {
+	// ModelFile is the path of a JSON file with the authorization model, in the format of the body of
+	// WriteAuthorizationModel requests. If empty, no model is bootstrapped.
+	ModelFile string
+
+	// StoreID is the ID of the store the model is written to. The store is created if it doesn't exist.
+	StoreID string
+
+	// PinModel pins the store to the model, so that requests on the store are evaluated with it even after other
+	// models are written.
+	PinModel bool
+}
+
 // MetricConfig defines configurations for serving custom metrics from OpenFGA.
 type MetricConfig struct {
 	Enabled             bool
 	Addr                string
 	EnableRPCHistograms bool
+
+	// RPCHistogramBuckets are the upper bounds, in seconds, of the buckets of the RPC latency histograms, in
+	// increasing order, so that they can match the boundaries of SLOs. If empty, prometheus.DefBuckets are used.
+	RPCHistogramBuckets []float64
+
+	// EnableCheckResultStoreLabel partitions the Check allowed/denied metric by store. It is disabled
+	// by default because the cardinality of the metric grows with the number of stores.
+	EnableCheckResultStoreLabel bool
+
+	// EnableModelCacheStoreLabel partitions the authorization model cache hit/miss metrics by store. It is
+	// disabled by default because the cardinality of the metrics grows with the number of stores.
+	EnableModelCacheStoreLabel bool
+
+	// ModelCacheSampleInterval is how often the number of authorization models in the model cache, and the number
+	// of models evicted from it, are sampled for the 'model_cache_items' and 'model_cache_eviction_count' metrics.
+	ModelCacheSampleInterval time.Duration
+
+	// Namespace, if set, is prepended (joined with an underscore) to the name of every metric exposed
+	// on the metrics endpoint, including the RPC and runtime metrics.
+	Namespace string
+
+	// EnableRuntimeMetrics exposes the standard Go runtime ('go_*') and process ('process_*') metrics
+	// on the metrics endpoint, alongside the OpenFGA metrics.
+	EnableRuntimeMetrics bool
+
+	// FailOnListenError stops the server from starting if the metrics server can't listen on Addr, e.g. because the
+	// port is in use. By default a warning is logged and the API is served without the metrics endpoint, since
+	// metrics aren't needed to answer authorization requests.
+	FailOnListenError bool
 }
 
 type Config struct {
@@ -308,21 +868,115 @@ type Config struct {
 	// This is to protect the server from misuse of the ListObjects endpoints.
 	ListObjectsMaxResults uint32
 
+	// ListObjectsDeduplicationEnabled makes sure that the ListObjects endpoints return each object only once, even
+	// if it is related to the user through multiple paths. Duplicates are dropped before the results are counted
+	// against ListObjectsMaxResults. Disabling it saves tracking the objects returned so far.
+	ListObjectsDeduplicationEnabled bool
+
+	// ListObjectsMaxPathsExplored bounds the number of tuples a single ListObjects request reads while searching
+	// for the objects related to the user. Once it is reached, the objects found so far are returned with the
+	// 'openfga-list-objects-truncated' header (a trailer for StreamedListObjects) set to 'true', so that clients
+	// know the results are incomplete. A value of 0 means no limit.
+	ListObjectsMaxPathsExplored uint32
+
+	// ListObjectsMaxConcurrentStreamsPerClient defines the maximum number of concurrent streaming
+	// ListObjects requests a single client can have open. Clients are identified by their
+	// authenticated subject, or by their address if unauthenticated. A value of 0 means no limit.
+	ListObjectsMaxConcurrentStreamsPerClient uint32
+
+	// MaxConcurrentWritesPerStore defines the maximum number of concurrent Write requests per store. Writes
+	// over the limit are rejected with a ResourceExhausted error, so that a client importing data into a
+	// store can't degrade the whole instance. A value of 0 means no limit.
+	MaxConcurrentWritesPerStore uint32
+
+	// MaxContextualTuplesPerCheck defines the maximum number of contextual tuples in a Check request. Requests
+	// with more are rejected. A value of 0 means no limit.
+	MaxContextualTuplesPerCheck uint32
+
+	// CheckCandidateModels is a list of 'storeID=modelID' pairs of candidate authorization models. The Check
+	// requests on those stores are also evaluated with the candidate model in the background, and the results that
+	// would differ are logged and counted in the 'shadow_check_count' metric, so that a new model can be validated
+	// against real traffic before it is promoted. Responses are never affected.
+	CheckCandidateModels []string
+
+	// TuplePurgeInterval defines how often the tuples that were written with a TTL and have expired are removed
+	// from the datastore. Expired tuples are excluded from reads as soon as they expire, so this only bounds how
+	// long they take up space. A value of 0 disables the purge.
+	TuplePurgeInterval time.Duration
+
+	// GracefulShutdownTimeout defines how long the gRPC and HTTP servers wait for the active requests to finish when
+	// the server shuts down, e.g. during a rolling deploy. The connections still open after it are closed, and
+	// their number is logged.
+	GracefulShutdownTimeout time.Duration
+
 	// MaxTuplesPerWrite defines the maximum number of tuples per Write endpoint.
 	MaxTuplesPerWrite int
 
+	// MaxTupleObjectLength, MaxTupleRelationLength and MaxTupleUserLength define the maximum length, in bytes, of the
+	// 'object', 'relation' and 'user' fields of the tuples written and of the contextual tuples. A value of 0 means no limit.
+	MaxTupleObjectLength   int
+	MaxTupleRelationLength int
+	MaxTupleUserLength     int
+
 	// MaxTypesPerAuthorizationModel defines the maximum number of type definitions per authorization model for the WriteAuthorizationModel endpoint.
 	MaxTypesPerAuthorizationModel int
 
 	// ChangelogHorizonOffset is an offset in minutes from the current time. Changes that occur after this offset will not be included in the response of ReadChanges.
+	// A negative offset is treated as 0.
 	ChangelogHorizonOffset int
 
+	// ReadChangesMaxPageSize is the maximum number of changes returned in a single ReadChanges response.
+	// Requests for larger pages are clamped to this value and the client continues with the continuation
+	// token. It is independent of ChangelogHorizonOffset: changes newer than the horizon are never returned,
+	// whatever the page size or continuation token.
+	ReadChangesMaxPageSize int32
+
+	// ReadAuthorizationModelsDefaultPageSize is the number of authorization models returned by a
+	// ReadAuthorizationModels request that doesn't set a page size.
+	ReadAuthorizationModelsDefaultPageSize int32
+
+	// ReadAuthorizationModelsMaxPageSize is the maximum number of authorization models returned in a single
+	// ReadAuthorizationModels response. Requests for larger pages are clamped to this value and the client
+	// continues with the continuation token.
+	ReadAuthorizationModelsMaxPageSize int32
+
 	// Experimentals is a list of the experimental features to enable in the OpenFGA server.
 	Experimentals []string
 
 	// ResolveNodeLimit indicates how deeply nested an authorization model can be.
 	ResolveNodeLimit uint32
 
+	// AuthorizationModelIDHeaderEnabled sets the 'openfga-authorization-model-id' response header to the ID of
+	// the model that answered Check, ListObjects, Expand and other requests that take an optional model ID.
+	// Clients that omit the model ID can use it to learn which model was resolved as the latest.
+	AuthorizationModelIDHeaderEnabled bool
+
+	// RequireLatestAuthorizationModel rejects Check and ListObjects requests that pin an authorization model
+	// other than the latest model of the store. It is disabled by default because some clients pin a model on purpose.
+	RequireLatestAuthorizationModel bool
+
+	// Cluster identifies the cluster or deployment this instance runs in. If set, it is added as the
+	// 'openfga.cluster' resource attribute on traces and as the 'cluster' label on every metric.
+	Cluster string
+
+	// StrictTupleValidation rejects written and contextual tuples whose 'user' field does not follow the
+	// 'type:id' format with a type defined in the authorization model, even for 1.0 models. Defaults to false.
+	StrictTupleValidation bool
+
+	// IdempotentWrites makes writing a tuple that already exists a no-op instead of failing the whole Write
+	// request, so that clients with at-least-once delivery can safely retry. Defaults to false.
+	IdempotentWrites bool
+
+	// IdempotentDeletes makes deleting a tuple that does not exist a no-op instead of failing the whole Write
+	// request, so that clients with at-least-once delivery can safely retry. Defaults to false.
+	IdempotentDeletes bool
+
+	// ModelCacheBypassEnabled allows Check requests to set the 'openfga-bypass-model-cache: true' header, which
+	// makes them read the authorization model from the datastore instead of the model caches, to confirm whether
+	// a discrepancy is caused by a stale cache. Every such request adds load to the datastore, so it should only be
+	// enabled while debugging. Defaults to false.
+	ModelCacheBypassEnabled bool
+
 	Datastore  DatastoreConfig
 	GRPC       GRPCConfig
 	HTTP       HTTPConfig
@@ -332,52 +986,102 @@ type Config struct {
 	Playground PlaygroundConfig
 	Profiler   ProfilerConfig
 	Metrics    MetricConfig
+	SlowStart  SlowStartConfig
+
+	ConcurrencyLimit ConcurrencyLimitConfig
+	AuditLog         AuditLogConfig
+	Bootstrap        BootstrapConfig
 }
 
 // DefaultConfig returns the OpenFGA server default configurations.
 func DefaultConfig() *Config {
 	return &Config{
 		MaxTuplesPerWrite:             100,
+		MaxTupleObjectLength:          256,
+		MaxTupleRelationLength:        50,
+		MaxTupleUserLength:            512,
 		MaxTypesPerAuthorizationModel: 100,
 		ChangelogHorizonOffset:        0,
-		ResolveNodeLimit:              25,
-		Experimentals:                 []string{},
-		ListObjectsDeadline:           3 * time.Second, // there is a 3-second timeout elsewhere
-		ListObjectsMaxResults:         1000,
+		ReadChangesMaxPageSize:        100,
+
+		ReadAuthorizationModelsDefaultPageSize: storage.DefaultPageSize,
+		ReadAuthorizationModelsMaxPageSize:     commands.MaxReadAuthorizationModelsPageSize,
+
+		ResolveNodeLimit:        25,
+		Experimentals:           []string{},
+		CheckCandidateModels:    []string{},
+		ListObjectsDeadline:     3 * time.Second, // there is a 3-second timeout elsewhere
+		ListObjectsMaxResults:   1000,
+		TuplePurgeInterval:      30 * time.Second,
+		GracefulShutdownTimeout: 30 * time.Second,
+
+		AuthorizationModelIDHeaderEnabled: true,
+		ListObjectsDeduplicationEnabled:   true,
+
 		Datastore: DatastoreConfig{
-			Engine:       "memory",
-			MaxCacheSize: 100000,
+			Engine:                "memory",
+			ReadReplicaURIs:       []string{},
+			MaxCacheSize:          100000,
+			ModelCacheTTL:         168 * time.Hour,
+			LatestModelIDCacheTTL: 3 * time.Second,
+			ModelReadRetries:      2,
+			ConnectTimeout:        sqlcommon.DefaultConnectTimeout,
+			StartupRetry: DatastoreStartupRetryConfig{
+				MaxAttempts:     0,
+				InitialInterval: 500 * time.Millisecond,
+			},
 			MaxIdleConns: 10,
 			MaxOpenConns: 30,
 		},
 		GRPC: GRPCConfig{
 			Addr: "0.0.0.0:8081",
-			TLS:  &TLSConfig{Enabled: false},
+			TLS:  &TLSConfig{Enabled: false, SessionTicketsEnabled: true, NextProtos: []string{}},
 		},
 		HTTP: HTTPConfig{
 			Enabled:            true,
 			Addr:               "0.0.0.0:8080",
-			TLS:                &TLSConfig{Enabled: false},
+			TLS:                &TLSConfig{Enabled: false, SessionTicketsEnabled: true, NextProtos: []string{}},
 			UpstreamTimeout:    5 * time.Second,
 			CORSAllowedOrigins: []string{"*"},
 			CORSAllowedHeaders: []string{"*"},
+
+			TrailingSlashPolicy: string(httpmiddleware.TrailingSlashStrict),
 		},
 		Authn: AuthnConfig{
 			Method:                  "none",
 			AuthnPresharedKeyConfig: &AuthnPresharedKeyConfig{},
-			AuthnOIDCConfig:         &AuthnOIDCConfig{},
+			AuthnMTLSConfig:         &AuthnMTLSConfig{AllowedSubjects: []string{}},
+			AuthnOIDCConfig: &AuthnOIDCConfig{
+				Audiences:           []string{},
+				MaxResponseSize:     oidc.DefaultMaxResponseSize,
+				JWKSRefreshInterval: oidc.DefaultJWKSRefreshInterval,
+				SubjectClaim:        oidc.DefaultSubjectClaim,
+			},
 		},
 		Log: LogConfig{
-			Format: "text",
-			Level:  "info",
+			Format:       "text",
+			Level:        "info",
+			RedactFields: []string{},
+			RedactMode:   logging.RedactionModeHash,
 		},
 		Trace: TraceConfig{
 			Enabled: false,
 			OTLP: OTLPTraceConfig{
 				Endpoint: "0.0.0.0:4317",
+				Protocol: string(telemetry.OTLPProtocolGRPC),
+			},
+			SampleRatio:           0.2,
+			ParentBased:           true,
+			ServiceName:           "openfga",
+			QueueFullPolicy:       string(telemetry.QueueFullPolicyDrop),
+			QueueFullBlockTimeout: 10 * time.Millisecond,
+			Batch: TraceBatchConfig{
+				MaxQueueSize:       sdktrace.DefaultMaxQueueSize,
+				MaxExportBatchSize: sdktrace.DefaultMaxExportBatchSize,
+				Timeout:            sdktrace.DefaultScheduleDelay * time.Millisecond,
 			},
-			SampleRatio: 0.2,
-			ServiceName: "openfga",
+			BaggageAttributes: []string{},
+			StoreSampleRatios: []string{},
 		},
 		Playground: PlaygroundConfig{
 			Enabled: true,
@@ -388,11 +1092,72 @@ func DefaultConfig() *Config {
 			Addr:    ":3001",
 		},
 		Metrics: MetricConfig{
-			Enabled:             true,
-			Addr:                "0.0.0.0:2112",
-			EnableRPCHistograms: false,
+			Enabled:                     true,
+			Addr:                        "0.0.0.0:2112",
+			EnableRPCHistograms:         false,
+			RPCHistogramBuckets:         []float64{},
+			EnableCheckResultStoreLabel: false,
+			EnableModelCacheStoreLabel:  false,
+			ModelCacheSampleInterval:    15 * time.Second,
+			EnableRuntimeMetrics:        true,
+			Namespace:                   "",
+		},
+		SlowStart: SlowStartConfig{
+			Duration:       0,
+			MaxConcurrency: 1000,
+		},
+		ConcurrencyLimit: ConcurrencyLimitConfig{
+			MaxConcurrency: 0,
+			MaxQueueSize:   0,
+			QueueTimeout:   time.Second,
 		},
+		AuditLog: AuditLogConfig{
+			Enabled:         false,
+			Output:          auditLogOutputStdout,
+			QueueSize:       0,
+			FlushInterval:   audit.DefaultFlushInterval,
+			QueueFullPolicy: string(audit.QueueFullPolicyBlock),
+		},
+		Bootstrap: BootstrapConfig{
+			ModelFile: "",
+			StoreID:   "",
+			PinModel:  false,
+		},
+	}
+}
+
+// configureRuntimeMetrics registers the Go runtime and process collectors with the registerer if
+// enabled, or unregisters them otherwise. The default prometheus registerer comes with both collectors
+// already registered, so registering them again is not an error.
+func configureRuntimeMetrics(registerer prometheus.Registerer, enabled bool) error {
+	runtimeCollectors := []prometheus.Collector{
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	}
+
+	for _, c := range runtimeCollectors {
+		if !enabled {
+			registerer.Unregister(c)
+			continue
+		}
+
+		if err := registerer.Register(c); err != nil {
+			var alreadyRegistered prometheus.AlreadyRegisteredError
+			if !errors.As(err, &alreadyRegistered) {
+				return err
+			}
+		}
 	}
+
+	return nil
+}
+
+// newMetricsHandler returns the handler of the metrics endpoint. It serves the OpenMetrics format, which carries
+// exemplars, to scrapers that ask for it in their Accept header, and the classic Prometheus text format otherwise.
+func newMetricsHandler(registerer prometheus.Registerer, gatherer prometheus.Gatherer) http.Handler {
+	return promhttp.InstrumentMetricHandler(
+		registerer, promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{EnableOpenMetrics: true}),
+	)
 }
 
 // TCPRandomPort tries to find a random TCP Port. If it can't find one, it panics. Else, it returns the port and a function that releases the port.
@@ -430,11 +1195,18 @@ func MustDefaultConfigWithRandomPorts() *Config {
 // ReadConfig returns the OpenFGA server configuration based on the values provided in the server's 'config.yaml' file.
 // The 'config.yaml' file is loaded from '/etc/openfga', '$HOME/.openfga', or the current working directory. If no configuration
 // file is present, the default values are returned.
+//
+// If the 'config' setting lists config files or directories, those are loaded instead and merged in order, so later files
+// override earlier ones. The files of a directory are merged in lexical order. In all cases the precedence is:
+// config files (in order) < environment variables < flags.
 func ReadConfig() (*Config, error) {
 	config := DefaultConfig()
 
-	err := viper.ReadInConfig()
-	if err != nil {
+	if configPaths := viper.GetStringSlice("config"); len(configPaths) > 0 {
+		if err := mergeConfigFiles(configPaths); err != nil {
+			return nil, err
+		}
+	} else if err := viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return nil, fmt.Errorf("failed to load server config: %w", err)
 		}
@@ -444,12 +1216,296 @@ func ReadConfig() (*Config, error) {
 		return nil, fmt.Errorf("failed to unmarshal server config: %w", err)
 	}
 
-	return config, nil
-}
+	return config, nil
+}
+
+// parseStoreSampleRatios parses a list of 'storeID=ratio' pairs into a map of store IDs to sampling ratios.
+func parseStoreSampleRatios(pairs []string) (map[string]float64, error) {
+	ratios := make(map[string]float64, len(pairs))
+	for _, pair := range pairs {
+		storeID, value, ok := strings.Cut(pair, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("'%s' is not a 'storeID=ratio' pair", pair)
+		}
+
+		ratio, err := strconv.ParseFloat(value, 64)
+		if err != nil || ratio < 0 || ratio > 1 {
+			return nil, fmt.Errorf("the ratio of store '%s' must be a number between 0 and 1", storeID)
+		}
+
+		ratios[storeID] = ratio
+	}
+
+	return ratios, nil
+}
+
+// parseCandidateModels parses a list of 'storeID=modelID' pairs into a map of store IDs to authorization model IDs.
+func parseCandidateModels(pairs []string) (map[string]string, error) {
+	modelIDs := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		storeID, modelID, ok := strings.Cut(pair, "=")
+		if !ok || storeID == "" {
+			return nil, fmt.Errorf("'%s' is not a 'storeID=modelID' pair", pair)
+		}
+
+		if _, err := ulid.Parse(modelID); err != nil {
+			return nil, fmt.Errorf("the model ID of store '%s' is not a valid ULID", storeID)
+		}
+
+		modelIDs[storeID] = modelID
+	}
+
+	return modelIDs, nil
+}
+
+// mergeConfigFiles loads the provided config files, and the config files in the provided directories, in order.
+func mergeConfigFiles(configPaths []string) error {
+	var configFiles []string
+	for _, configPath := range configPaths {
+		info, err := os.Stat(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load server config: %w", err)
+		}
+
+		if !info.IsDir() {
+			configFiles = append(configFiles, configPath)
+			continue
+		}
+
+		entries, err := os.ReadDir(configPath)
+		if err != nil {
+			return fmt.Errorf("failed to load server config: %w", err)
+		}
+
+		// os.ReadDir returns the entries sorted by filename
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml" || ext == ".json") {
+				configFiles = append(configFiles, filepath.Join(configPath, entry.Name()))
+			}
+		}
+	}
+
+	for i, configFile := range configFiles {
+		viper.SetConfigFile(configFile)
+
+		readConfig := viper.MergeInConfig
+		if i == 0 {
+			// replace any config that was loaded from the default locations
+			readConfig = viper.ReadInConfig
+		}
+
+		if err := readConfig(); err != nil {
+			return fmt.Errorf("failed to load server config '%s': %w", configFile, err)
+		}
+	}
+
+	return nil
+}
+
+func VerifyConfig(cfg *Config) error {
+	if cfg.ListObjectsDeadline > cfg.HTTP.UpstreamTimeout {
+		return fmt.Errorf("config 'http.upstreamTimeout' (%s) cannot be lower than 'listObjectsDeadline' config (%s)", cfg.HTTP.UpstreamTimeout, cfg.ListObjectsDeadline)
+	}
+
+	if err := validateListenAddr(cfg.GRPC.Addr); err != nil {
+		return fmt.Errorf("config 'grpc.addr' is invalid: %w", err)
+	}
+
+	if cfg.HTTP.Enabled {
+		if err := validateListenAddr(cfg.HTTP.Addr); err != nil {
+			return fmt.Errorf("config 'http.addr' is invalid: %w", err)
+		}
+	}
+
+	if cfg.Metrics.Enabled {
+		if err := validateListenAddr(cfg.Metrics.Addr); err != nil {
+			return fmt.Errorf("config 'metrics.addr' is invalid: %w", err)
+		}
+
+		if cfg.Metrics.ModelCacheSampleInterval <= 0 {
+			return errors.New("config 'metrics.modelCacheSampleInterval' must be greater than 0")
+		}
+	}
+
+	if cfg.Profiler.Enabled {
+		if err := validateListenAddr(cfg.Profiler.Addr); err != nil {
+			return fmt.Errorf("config 'profiler.addr' is invalid: %w", err)
+		}
+	}
+
+	var unknownExperimentals []string
+	for _, feature := range cfg.Experimentals {
+		if !featureflags.IsKnown(featureflags.Flag(feature)) {
+			unknownExperimentals = append(unknownExperimentals, feature)
+		}
+	}
+	if len(unknownExperimentals) > 0 {
+		return fmt.Errorf("config 'experimentals' contains unknown features: %s", strings.Join(unknownExperimentals, ", "))
+	}
+
+	if cfg.TuplePurgeInterval < 0 {
+		return errors.New("config 'tuplePurgeInterval' cannot be negative")
+	}
+
+	if cfg.GracefulShutdownTimeout <= 0 {
+		return errors.New("config 'gracefulShutdownTimeout' must be greater than 0")
+	}
+
+	if cfg.MaxTupleObjectLength < 0 {
+		return errors.New("config 'maxTupleObjectLength' cannot be negative")
+	}
+
+	if cfg.MaxTupleRelationLength < 0 {
+		return errors.New("config 'maxTupleRelationLength' cannot be negative")
+	}
+
+	if cfg.MaxTupleUserLength < 0 {
+		return errors.New("config 'maxTupleUserLength' cannot be negative")
+	}
+
+	if cfg.ReadChangesMaxPageSize <= 0 {
+		return errors.New("config 'readChangesMaxPageSize' must be greater than 0")
+	}
+
+	if cfg.ReadAuthorizationModelsDefaultPageSize <= 0 {
+		return errors.New("config 'readAuthorizationModelsDefaultPageSize' must be greater than 0")
+	}
+
+	if cfg.ReadAuthorizationModelsMaxPageSize <= 0 {
+		return errors.New("config 'readAuthorizationModelsMaxPageSize' must be greater than 0")
+	}
+
+	if cfg.ReadAuthorizationModelsDefaultPageSize > cfg.ReadAuthorizationModelsMaxPageSize {
+		return errors.New("config 'readAuthorizationModelsDefaultPageSize' cannot be greater than 'readAuthorizationModelsMaxPageSize'")
+	}
+
+	if cfg.Datastore.ConnMaxLifetimeJitter < 0 || cfg.Datastore.ConnMaxLifetimeJitter > 1 {
+		return fmt.Errorf("config 'datastore.connMaxLifetimeJitter' must be between 0 and 1")
+	}
+
+	if len(cfg.Datastore.ReadReplicaURIs) > 0 && cfg.Datastore.Engine != "mysql" && cfg.Datastore.Engine != "postgres" {
+		return fmt.Errorf("config 'datastore.readReplicaURIs' is only supported by the 'mysql' and 'postgres' engines")
+	}
+
+	if cfg.Datastore.ConnectTimeout <= 0 {
+		return fmt.Errorf("config 'datastore.connectTimeout' must be greater than 0")
+	}
+
+	if cfg.Datastore.StartupRetry.MaxAttempts < 0 {
+		return fmt.Errorf("config 'datastore.startupRetry.maxAttempts' must be greater than or equal to 0")
+	}
+
+	if cfg.Datastore.StartupRetry.InitialInterval <= 0 {
+		return fmt.Errorf("config 'datastore.startupRetry.initialInterval' must be greater than 0")
+	}
+
+	if cfg.Datastore.StatementTimeout < 0 {
+		return fmt.Errorf("config 'datastore.statementTimeout' must be greater than or equal to 0")
+	}
+
+	if cfg.Datastore.QueryTimeout < 0 {
+		return fmt.Errorf("config 'datastore.queryTimeout' must be greater than or equal to 0")
+	}
+
+	if cfg.Datastore.ModelCacheTTL < 0 {
+		return fmt.Errorf("config 'datastore.modelCacheTTL' must be greater than or equal to 0")
+	}
+
+	if cfg.Datastore.LatestModelIDCacheTTL < 0 {
+		return fmt.Errorf("config 'datastore.latestModelIDCacheTTL' must be greater than or equal to 0")
+	}
+
+	if cfg.Datastore.ModelReadRetries < 0 {
+		return fmt.Errorf("config 'datastore.modelReadRetries' must be greater than or equal to 0")
+	}
+
+	if cfg.SlowStart.Duration > 0 && cfg.SlowStart.MaxConcurrency == 0 {
+		return errors.New("config 'slowStart.maxConcurrency' must be greater than 0 when slow start is enabled")
+	}
+
+	if cfg.ConcurrencyLimit.MaxQueueSize > 0 && cfg.ConcurrencyLimit.QueueTimeout <= 0 {
+		return errors.New("config 'concurrencyLimit.queueTimeout' must be greater than 0 when requests are queued")
+	}
+
+	if cfg.AuditLog.Enabled && cfg.AuditLog.Output == "" {
+		return errors.New("config 'auditLog.output' must be set when the audit log is enabled")
+	}
+
+	if cfg.AuditLog.QueueSize < 0 {
+		return errors.New("config 'auditLog.queueSize' cannot be negative")
+	}
+
+	if cfg.AuditLog.QueueSize > 0 {
+		if cfg.AuditLog.FlushInterval <= 0 {
+			return errors.New("config 'auditLog.flushInterval' must be greater than 0 when 'auditLog.queueSize' is set")
+		}
+
+		switch audit.QueueFullPolicy(cfg.AuditLog.QueueFullPolicy) {
+		case audit.QueueFullPolicyBlock, audit.QueueFullPolicyDrop:
+		default:
+			return errors.New("config 'auditLog.queueFullPolicy' must be one of ['block', 'drop']")
+		}
+	}
+
+	if cfg.Bootstrap.ModelFile != "" {
+		if _, err := ulid.Parse(cfg.Bootstrap.StoreID); err != nil {
+			return errors.New("config 'bootstrap.storeId' must be a valid store ID when 'bootstrap.modelFile' is set")
+		}
+	}
+
+	if cfg.Bootstrap.PinModel && cfg.Bootstrap.ModelFile == "" {
+		return errors.New("config 'bootstrap.pinModel' requires 'bootstrap.modelFile' to be set")
+	}
+
+	if cfg.Trace.Batch.MaxQueueSize <= 0 {
+		return errors.New("config 'trace.batch.maxQueueSize' must be greater than 0")
+	}
+
+	if cfg.Trace.Batch.MaxExportBatchSize <= 0 {
+		return errors.New("config 'trace.batch.maxExportBatchSize' must be greater than 0")
+	}
+
+	if cfg.Trace.Batch.MaxExportBatchSize > cfg.Trace.Batch.MaxQueueSize {
+		return errors.New("config 'trace.batch.maxExportBatchSize' cannot be greater than 'trace.batch.maxQueueSize'")
+	}
+
+	if cfg.Trace.Batch.Timeout <= 0 {
+		return errors.New("config 'trace.batch.timeout' must be greater than 0")
+	}
+
+	switch telemetry.OTLPProtocol(cfg.Trace.OTLP.Protocol) {
+	case telemetry.OTLPProtocolGRPC, telemetry.OTLPProtocolHTTPProtobuf:
+	default:
+		return fmt.Errorf("config 'trace.otlp.protocol' must be one of ['grpc', 'http/protobuf']")
+	}
+
+	switch telemetry.QueueFullPolicy(cfg.Trace.QueueFullPolicy) {
+	case telemetry.QueueFullPolicyDrop:
+	case telemetry.QueueFullPolicyBlock:
+		if cfg.Trace.QueueFullBlockTimeout <= 0 {
+			return errors.New("config 'trace.queueFullBlockTimeout' must be greater than 0 when 'trace.queueFullPolicy' is 'block'")
+		}
+	default:
+		return fmt.Errorf("config 'trace.queueFullPolicy' must be one of ['drop', 'block']")
+	}
+
+	if _, err := parseStoreSampleRatios(cfg.Trace.StoreSampleRatios); err != nil {
+		return fmt.Errorf("config 'trace.storeSampleRatios' is invalid: %w", err)
+	}
+
+	for i, bucket := range cfg.Metrics.RPCHistogramBuckets {
+		if bucket <= 0 {
+			return errors.New("config 'metrics.rpcHistogramBuckets' must only contain positive values")
+		}
 
-func VerifyConfig(cfg *Config) error {
-	if cfg.ListObjectsDeadline > cfg.HTTP.UpstreamTimeout {
-		return fmt.Errorf("config 'http.upstreamTimeout' (%s) cannot be lower than 'listObjectsDeadline' config (%s)", cfg.HTTP.UpstreamTimeout, cfg.ListObjectsDeadline)
+		if i > 0 && bucket <= cfg.Metrics.RPCHistogramBuckets[i-1] {
+			return errors.New("config 'metrics.rpcHistogramBuckets' must be sorted in increasing order")
+		}
+	}
+
+	if err := telemetry.ValidateMetricsNamespace(cfg.Metrics.Namespace); err != nil {
+		return fmt.Errorf("config 'metrics.namespace' is invalid: %w", err)
 	}
 
 	if cfg.Log.Format != "text" && cfg.Log.Format != "json" {
@@ -466,6 +1522,48 @@ func VerifyConfig(cfg *Config) error {
 		return fmt.Errorf("config 'log.level' must be one of ['none', 'debug', 'info', 'warn', 'error', 'panic', 'fatal']")
 	}
 
+	if cfg.Log.RedactMode != logging.RedactionModeHash && cfg.Log.RedactMode != logging.RedactionModeMask {
+		return fmt.Errorf("config 'log.redactMode' must be one of ['hash', 'mask']")
+	}
+
+	if len(cfg.Log.RedactFields) > 0 && cfg.Log.RedactMode == logging.RedactionModeHash && cfg.Log.RedactHashKey == "" {
+		return errors.New("config 'log.redactHashKey' is required when 'log.redactFields' are hashed")
+	}
+
+	if cfg.Authn.Method == "oidc" && cfg.Authn.MaxResponseSize <= 0 {
+		return errors.New("config 'authn.oidc.maxResponseSize' must be greater than 0")
+	}
+
+	if cfg.Authn.Method == "oidc" && cfg.Authn.JWKSRefreshInterval <= 0 {
+		return errors.New("config 'authn.oidc.jwksRefreshInterval' must be greater than 0")
+	}
+
+	if cfg.Authn.Method == "oidc" && cfg.Authn.SubjectClaim == "" {
+		return errors.New("config 'authn.oidc.subjectClaim' must be set")
+	}
+
+	if cfg.Authn.Method == "oidc" && cfg.Authn.Audience == "" && len(cfg.Authn.Audiences) == 0 {
+		return errors.New("config 'authn.oidc.audience' or 'authn.oidc.audiences' must be set when 'authn.method' is 'oidc'")
+	}
+
+	if cfg.Authn.Method == "mtls" {
+		if cfg.Authn.AuthnMTLSConfig == nil || cfg.Authn.AuthnMTLSConfig.CACertPath == "" {
+			return errors.New("config 'authn.mtls.caCertPath' must be set when 'authn.method' is 'mtls'")
+		}
+
+		if !cfg.GRPC.TLS.Enabled {
+			return errors.New("config 'grpc.tls.enabled' must be true when 'authn.method' is 'mtls'")
+		}
+
+		if cfg.HTTP.Enabled && !cfg.HTTP.TLS.Enabled {
+			return errors.New("config 'http.tls.enabled' must be true when 'authn.method' is 'mtls' and the HTTP server is enabled")
+		}
+	}
+
+	if cfg.Authn.Method == "preshared" && len(cfg.Authn.Labels) > 0 && len(cfg.Authn.Labels) != len(cfg.Authn.Keys) {
+		return errors.New("config 'authn.preshared.labels' must have a label for each key in 'authn.preshared.keys'")
+	}
+
 	if cfg.Playground.Enabled {
 		if !cfg.HTTP.Enabled {
 			return errors.New("the HTTP server must be enabled to run the openfga playground")
@@ -476,6 +1574,12 @@ func VerifyConfig(cfg *Config) error {
 		}
 	}
 
+	switch httpmiddleware.TrailingSlashPolicy(cfg.HTTP.TrailingSlashPolicy) {
+	case httpmiddleware.TrailingSlashStrict, httpmiddleware.TrailingSlashAccept, httpmiddleware.TrailingSlashRedirect:
+	default:
+		return fmt.Errorf("config 'http.trailingSlashPolicy' must be one of ['strict', 'accept', 'redirect']")
+	}
+
 	if cfg.HTTP.TLS.Enabled {
 		if cfg.HTTP.TLS.CertPath == "" || cfg.HTTP.TLS.KeyPath == "" {
 			return errors.New("'http.tls.cert' and 'http.tls.key' configs must be set")
@@ -488,6 +1592,34 @@ func VerifyConfig(cfg *Config) error {
 		}
 	}
 
+	if (cfg.Trace.OTLP.TLS.CertPath == "") != (cfg.Trace.OTLP.TLS.KeyPath == "") {
+		return errors.New("'trace.otlp.tls.cert' and 'trace.otlp.tls.key' configs must be set together")
+	}
+
+	if cfg.GRPC.TLS.SessionTicketKeyRotation < 0 {
+		return errors.New("'grpc.tls.sessionTicketKeyRotation' config must be greater than or equal to 0")
+	}
+
+	if cfg.HTTP.TLS.SessionTicketKeyRotation < 0 {
+		return errors.New("'http.tls.sessionTicketKeyRotation' config must be greater than or equal to 0")
+	}
+
+	if _, err := parseCandidateModels(cfg.CheckCandidateModels); err != nil {
+		return fmt.Errorf("config 'checkCandidateModels' is invalid: %w", err)
+	}
+
+	if err := verifyNextProtos(cfg.GRPC.TLS.NextProtos); err != nil {
+		return fmt.Errorf("config 'grpc.tls.nextProtos' is invalid: %w", err)
+	}
+
+	if len(cfg.GRPC.TLS.NextProtos) > 0 && !containsNextProto(cfg.GRPC.TLS.NextProtos, http2NextProto) {
+		return fmt.Errorf("config 'grpc.tls.nextProtos' must include '%s'", http2NextProto)
+	}
+
+	if err := verifyNextProtos(cfg.HTTP.TLS.NextProtos); err != nil {
+		return fmt.Errorf("config 'http.tls.nextProtos' is invalid: %w", err)
+	}
+
 	return nil
 }
 
@@ -502,6 +1634,85 @@ func run(_ *cobra.Command, _ []string) {
 	}
 }
 
+// newReadReplicaDatastore connects to a read replica of a datastore of the given engine.
+func newReadReplicaDatastore(engine, uri string, dsCfg *sqlcommon.Config) (storage.OpenFGADatastore, error) {
+	switch engine {
+	case "mysql":
+		return mysql.New(uri, dsCfg)
+	case "postgres":
+		return postgres.New(uri, dsCfg)
+	default:
+		return nil, fmt.Errorf("storage engine '%s' doesn't support read replicas", engine)
+	}
+}
+
+// purgeExpiredTuples removes the expired tuples from the datastore every interval, until ctx is done.
+func purgeExpiredTuples(ctx context.Context, purger storage.TupleExpirationPurger, interval time.Duration, logger logger.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		before := time.Now()
+		for {
+			purged, err := purger.PurgeExpiredTuples(ctx, before, tuplePurgeBatchSize)
+			if err != nil {
+				if ctx.Err() != nil {
+					// stopped while purging
+					return
+				}
+
+				logger.Error("failed to purge expired tuples", zap.Error(err))
+				break
+			}
+
+			if purged < tuplePurgeBatchSize {
+				break
+			}
+		}
+	}
+}
+
+// reloadPresharedKeys replaces the keys of the authenticator with the preshared keys of the config read by readConfig
+// every time a signal is received, until ctx is done. If the config can't be read or its keys are invalid, the
+// current keys are kept.
+func reloadPresharedKeys(ctx context.Context, signals <-chan os.Signal, authenticator *presharedkey.PresharedKeyAuthenticator, readConfig func() (*Config, error), logger logger.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-signals:
+		}
+
+		config, err := readConfig()
+		if err != nil {
+			logger.Error("failed to reload the preshared keys, the current keys are kept", zap.Error(err))
+			continue
+		}
+
+		var keys, labels []string
+		if config.Authn.AuthnPresharedKeyConfig != nil {
+			keys, labels = config.Authn.Keys, config.Authn.Labels
+		}
+
+		if err := authenticator.UpdateKeys(keys, labels); err != nil {
+			logger.Error("failed to reload the preshared keys, the current keys are kept", zap.Error(err))
+			continue
+		}
+
+		logger.Info(fmt.Sprintf("reloaded %d preshared keys", len(keys)))
+	}
+}
+
+// RunServer starts the server with the provided config and blocks until ctx is done or the process receives an
+// interrupt signal. The dependencies of the server are initialized in a fixed order, so that a failure aborts the
+// startup before the later ones are started: the datastore (including migrations and bootstrapping), then the
+// authenticator, then telemetry, and finally the listeners (profiler, metrics, gRPC, HTTP and playground).
 func RunServer(ctx context.Context, config *Config) error {
 	if err := VerifyConfig(config); err != nil {
 		return err
@@ -509,19 +1720,6 @@ func RunServer(ctx context.Context, config *Config) error {
 
 	logger := logger.MustNewLogger(config.Log.Format, config.Log.Level)
 
-	tp := sdktrace.NewTracerProvider()
-	if config.Trace.Enabled {
-		logger.Info(fmt.Sprintf("🕵 tracing enabled: sampling ratio is %v and sending traces to '%s'", config.Trace.SampleRatio, config.Trace.OTLP.Endpoint))
-		tp = telemetry.MustNewTracerProvider(
-			telemetry.WithOTLPEndpoint(config.Trace.OTLP.Endpoint),
-			telemetry.WithAttributes(
-				semconv.ServiceNameKey.String(config.Trace.ServiceName),
-				semconv.ServiceVersionKey.String(build.Version),
-			),
-			telemetry.WithSamplingRatio(config.Trace.SampleRatio),
-		)
-	}
-
 	logger.Info(fmt.Sprintf("🧪 experimental features enabled: %v", config.Experimentals))
 
 	var experimentals []server.ExperimentalFeatureFlag
@@ -539,8 +1737,16 @@ func RunServer(ctx context.Context, config *Config) error {
 		sqlcommon.WithMaxIdleConns(config.Datastore.MaxIdleConns),
 		sqlcommon.WithConnMaxIdleTime(config.Datastore.ConnMaxIdleTime),
 		sqlcommon.WithConnMaxLifetime(config.Datastore.ConnMaxLifetime),
+		sqlcommon.WithConnMaxLifetimeJitter(config.Datastore.ConnMaxLifetimeJitter),
+		sqlcommon.WithStatementTimeout(config.Datastore.StatementTimeout),
+		sqlcommon.WithConnectTimeout(config.Datastore.ConnectTimeout),
+		sqlcommon.WithConnectRetry(config.Datastore.StartupRetry.MaxAttempts, config.Datastore.StartupRetry.InitialInterval),
 	)
 
+	if err := build.CheckDatastoreEngine(config.Datastore.Engine); err != nil {
+		return err
+	}
+
 	var datastore storage.OpenFGADatastore
 	var err error
 	switch config.Datastore.Engine {
@@ -551,39 +1757,216 @@ func RunServer(ctx context.Context, config *Config) error {
 		}
 		datastore = memory.New(opts...)
 	case "mysql":
+		logger.Info(fmt.Sprintf("connecting to the mysql datastore (timeout %s)", config.Datastore.ConnectTimeout))
 		datastore, err = mysql.New(config.Datastore.URI, dsCfg)
 		if err != nil {
 			return fmt.Errorf("failed to initialize mysql datastore: %w", err)
 		}
 	case "postgres":
+		logger.Info(fmt.Sprintf("connecting to the postgres datastore (timeout %s)", config.Datastore.ConnectTimeout))
 		datastore, err = postgres.New(config.Datastore.URI, dsCfg)
 		if err != nil {
 			return fmt.Errorf("failed to initialize postgres datastore: %w", err)
 		}
+	case "sqlite":
+		datastore, err = sqlite.New(config.Datastore.URI, dsCfg)
+		if err != nil {
+			return fmt.Errorf("failed to initialize sqlite datastore: %w", err)
+		}
 	default:
 		return fmt.Errorf("storage engine '%s' is unsupported", config.Datastore.Engine)
 	}
-	datastore = storagewrappers.NewCachedOpenFGADatastore(storage.NewContextWrapper(datastore), config.Datastore.MaxCacheSize)
+
+	// the background loops that use the datastore run until the server shuts down, and are stopped before the
+	// datastore is closed
+	backgroundCtx, cancelBackground := context.WithCancel(ctx)
+	var background sync.WaitGroup
+	stopBackground := func() {
+		cancelBackground()
+		background.Wait()
+	}
+
+	// if the startup fails after this point, the dependencies initialized so far are closed, in the reverse order
+	// of their initialization, before returning
+	started := false
+	defer func() {
+		if !started {
+			stopBackground()
+			datastore.Close()
+		}
+	}()
+
+	if migrator, ok := datastore.(storage.SchemaMigrator); ok {
+		if err := migrator.MigrateSchema(ctx, config.Datastore.AutoMigrate); err != nil {
+			return fmt.Errorf("failed to initialize %s datastore: %w", config.Datastore.Engine, err)
+		}
+	}
+
+	if purger, ok := datastore.(storage.TupleExpirationPurger); ok && config.TuplePurgeInterval > 0 {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			purgeExpiredTuples(backgroundCtx, purger, config.TuplePurgeInterval, logger)
+		}()
+	}
+
+	if len(config.Datastore.ReadReplicaURIs) > 0 {
+		replicas := make([]storage.OpenFGADatastore, 0, len(config.Datastore.ReadReplicaURIs))
+		for i, uri := range config.Datastore.ReadReplicaURIs {
+			logger.Info(fmt.Sprintf("connecting to read replica %d of the %s datastore (timeout %s)", i, config.Datastore.Engine, config.Datastore.ConnectTimeout))
+			replica, err := newReadReplicaDatastore(config.Datastore.Engine, uri, dsCfg)
+			if err != nil {
+				for _, r := range replicas {
+					r.Close()
+				}
+				return fmt.Errorf("failed to initialize read replica %d of the %s datastore: %w", i, config.Datastore.Engine, err)
+			}
+			replicas = append(replicas, replica)
+		}
+
+		datastore = storagewrappers.NewReadReplicaOpenFGADatastore(datastore, replicas)
+	}
+
+	if config.Datastore.QueryTimeout > 0 {
+		datastore = storagewrappers.NewQueryTimeoutOpenFGADatastore(datastore, config.Datastore.QueryTimeout)
+	}
+
+	cachedDatastore := storagewrappers.NewCachedOpenFGADatastore(storage.NewContextWrapper(datastore), config.Datastore.MaxCacheSize,
+		storagewrappers.WithModelCacheTTL(config.Datastore.ModelCacheTTL),
+		storagewrappers.WithLatestModelIDTTL(config.Datastore.LatestModelIDCacheTTL),
+		storagewrappers.WithModelReadRetry(config.Datastore.ModelReadRetries, modelReadRetryBackoff),
+		storagewrappers.WithModelCacheMetricsByStore(config.Metrics.EnableModelCacheStoreLabel),
+	)
+	datastore = cachedDatastore
+
+	if config.Metrics.Enabled {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			cachedDatastore.ReportModelCacheMetrics(backgroundCtx, config.Metrics.ModelCacheSampleInterval)
+		}()
+	}
 
 	logger.Info(fmt.Sprintf("using '%v' storage engine", config.Datastore.Engine))
 
+	var pinnedModelIDs map[string]string
+	if config.Bootstrap.ModelFile != "" {
+		modelID, err := bootstrapAuthorizationModel(ctx, datastore, config.Bootstrap, logger)
+		if err != nil {
+			return err
+		}
+
+		if config.Bootstrap.PinModel {
+			pinnedModelIDs = map[string]string{config.Bootstrap.StoreID: modelID}
+		}
+	}
+
+	// already validated by VerifyConfig
+	candidateModelIDs, _ := parseCandidateModels(config.CheckCandidateModels)
+	for storeID, modelID := range candidateModelIDs {
+		logger.Info("evaluating Check requests with a candidate authorization model", zap.String("store_id", storeID), zap.String("candidate_authorization_model_id", modelID))
+	}
+
 	var authenticator authn.Authenticator
+	var presharedKeyAuthenticator *presharedkey.PresharedKeyAuthenticator
+	var mtlsAuthenticator *mtls.MTLSAuthenticator
+	var clientCAs *x509.CertPool
 	switch config.Authn.Method {
 	case "none":
 		logger.Warn("authentication is disabled")
 		authenticator = authn.NoopAuthenticator{}
 	case "preshared":
 		logger.Info("using 'preshared' authentication")
-		authenticator, err = presharedkey.NewPresharedKeyAuthenticator(config.Authn.Keys)
+		presharedKeyAuthenticator, err = presharedkey.NewPresharedKeyAuthenticator(config.Authn.Keys, config.Authn.Labels)
+		authenticator = presharedKeyAuthenticator
 	case "oidc":
 		logger.Info("using 'oidc' authentication")
-		authenticator, err = oidc.NewRemoteOidcAuthenticator(config.Authn.Issuer, config.Authn.Audience)
+		if config.Authn.InsecureSkipVerify {
+			logger.Warn("the certificate of the OIDC issuer is not verified")
+		}
+
+		var tlsConfig *tls.Config
+		tlsConfig, err = newOIDCClientTLSConfig(config.Authn.AuthnOIDCConfig)
+		if err != nil {
+			return err
+		}
+
+		authenticator, err = oidc.NewRemoteOidcAuthenticator(
+			config.Authn.Issuer,
+			config.Authn.Audience,
+			oidc.WithAudiences(config.Authn.Audiences...),
+			oidc.WithTLSConfig(tlsConfig),
+			oidc.WithMaxResponseSize(config.Authn.MaxResponseSize),
+			oidc.WithJWKSRefreshInterval(config.Authn.JWKSRefreshInterval),
+			oidc.WithSubjectClaim(config.Authn.SubjectClaim),
+			oidc.WithBackgroundKeyFetch(logger),
+		)
+	case "mtls":
+		logger.Info("using 'mtls' authentication")
+		clientCAs, err = newMTLSClientCAs(config.Authn.AuthnMTLSConfig)
+		if err != nil {
+			return err
+		}
+
+		mtlsAuthenticator, err = mtls.NewMTLSAuthenticator(config.Authn.AllowedSubjects)
+		authenticator = mtlsAuthenticator
 	default:
 		return fmt.Errorf("unsupported authentication method '%v'", config.Authn.Method)
 	}
 	if err != nil {
 		return fmt.Errorf("failed to initialize authenticator: %w", err)
 	}
+	defer func() {
+		if !started {
+			authenticator.Close()
+		}
+	}()
+
+	tp := sdktrace.NewTracerProvider()
+	var traceExportStatus *telemetry.ExportStatus
+	if config.Trace.Enabled {
+		logger.Info(fmt.Sprintf("🕵 tracing enabled: sampling ratio is %v and sending traces to '%s'", config.Trace.SampleRatio, config.Trace.OTLP.Endpoint))
+
+		attrs := []attribute.KeyValue{
+			semconv.ServiceNameKey.String(config.Trace.ServiceName),
+			semconv.ServiceVersionKey.String(build.Version),
+		}
+		if config.Trace.ServiceInstanceID != "" {
+			attrs = append(attrs, semconv.ServiceInstanceIDKey.String(config.Trace.ServiceInstanceID))
+		}
+		if config.Cluster != "" {
+			attrs = append(attrs, telemetry.ClusterKey.String(config.Cluster))
+		}
+
+		// already validated by VerifyConfig
+		storeSampleRatios, _ := parseStoreSampleRatios(config.Trace.StoreSampleRatios)
+
+		otlpTLSConfig, err := newOTLPClientTLSConfig(config.Trace.OTLP.TLS)
+		if err != nil {
+			return err
+		}
+
+		traceExportStatus = &telemetry.ExportStatus{}
+		tp = telemetry.MustNewTracerProvider(
+			telemetry.WithOTLPEndpoint(config.Trace.OTLP.Endpoint),
+			telemetry.WithOTLPProtocol(telemetry.OTLPProtocol(config.Trace.OTLP.Protocol)),
+			telemetry.WithOTLPTLS(otlpTLSConfig),
+			telemetry.WithAttributes(attrs...),
+			telemetry.WithSamplingRatio(config.Trace.SampleRatio),
+			telemetry.WithParentBasedSampling(config.Trace.ParentBased),
+			telemetry.WithStoreSamplingRatios(storeSampleRatios),
+			telemetry.WithQueueFullPolicy(telemetry.QueueFullPolicy(config.Trace.QueueFullPolicy), config.Trace.QueueFullBlockTimeout),
+			telemetry.WithBatchMaxQueueSize(config.Trace.Batch.MaxQueueSize),
+			telemetry.WithBatchMaxExportBatchSize(config.Trace.Batch.MaxExportBatchSize),
+			telemetry.WithBatchTimeout(config.Trace.Batch.Timeout),
+			telemetry.WithExportStatus(traceExportStatus),
+		)
+	}
+	defer func() {
+		if !started {
+			_ = tp.Shutdown(ctx)
+		}
+	}()
 
 	unaryInterceptors := []grpc.UnaryServerInterceptor{
 		requestid.NewUnaryInterceptor(),
@@ -602,27 +1985,70 @@ func RunServer(ctx context.Context, config *Config) error {
 		streamingInterceptors = append(streamingInterceptors, grpc_prometheus.StreamServerInterceptor)
 
 		if config.Metrics.EnableRPCHistograms {
-			grpc_prometheus.EnableHandlingTimeHistogram()
+			var histogramOpts []grpc_prometheus.HistogramOption
+			if len(config.Metrics.RPCHistogramBuckets) > 0 {
+				histogramOpts = append(histogramOpts, grpc_prometheus.WithHistogramBuckets(config.Metrics.RPCHistogramBuckets))
+			}
+
+			grpc_prometheus.EnableHandlingTimeHistogram(histogramOpts...)
 		}
 	}
 
+	if config.ConcurrencyLimit.MaxConcurrency > 0 {
+		// must come before the slow start and the per-operation limits so that it bounds all the work the server does
+		concurrencyLimiter := concurrency.NewLimiter(config.ConcurrencyLimit.MaxConcurrency, config.ConcurrencyLimit.MaxQueueSize, config.ConcurrencyLimit.QueueTimeout)
+		unaryInterceptors = append(unaryInterceptors, concurrency.NewUnaryInterceptor(concurrencyLimiter))
+		streamingInterceptors = append(streamingInterceptors, concurrency.NewStreamingInterceptor(concurrencyLimiter))
+	}
+
+	if config.SlowStart.Duration > 0 {
+		slowStartLimiter := slowstart.NewLimiter(config.SlowStart.Duration, config.SlowStart.MaxConcurrency)
+		unaryInterceptors = append(unaryInterceptors, slowstart.NewUnaryInterceptor(slowStartLimiter))
+		streamingInterceptors = append(streamingInterceptors, slowstart.NewStreamingInterceptor(slowStartLimiter))
+	}
+
 	if config.Trace.Enabled {
+		if len(config.Trace.StoreSampleRatios) > 0 {
+			// must come before the trace interceptors so the sampling decision is affected
+			unaryInterceptors = append(unaryInterceptors, storeid.NewSamplingUnaryInterceptor())
+		}
+
+		if config.Trace.ForceSampleSecret != "" {
+			// must come before the trace interceptors so the sampling decision is affected
+			unaryInterceptors = append(unaryInterceptors, forcetrace.NewUnaryInterceptor(config.Trace.ForceSampleSecret))
+			streamingInterceptors = append(streamingInterceptors, forcetrace.NewStreamingInterceptor(config.Trace.ForceSampleSecret))
+		}
+
 		unaryInterceptors = append(unaryInterceptors, otelgrpc.UnaryServerInterceptor())
 		streamingInterceptors = append(streamingInterceptors, otelgrpc.StreamServerInterceptor())
+
+		if len(config.Trace.BaggageAttributes) > 0 {
+			// must come after the trace interceptors, which extract the baggage and start the request's span
+			unaryInterceptors = append(unaryInterceptors, baggage.NewUnaryInterceptor(config.Trace.BaggageAttributes))
+			streamingInterceptors = append(streamingInterceptors, baggage.NewStreamingInterceptor(config.Trace.BaggageAttributes))
+		}
+	}
+
+	loggingOpts := []logging.Option{
+		logging.WithRedactedFields(config.Log.RedactMode, config.Log.RedactFields...),
+		logging.WithRedactionHashKey([]byte(config.Log.RedactHashKey)),
 	}
 
 	unaryInterceptors = append(unaryInterceptors,
 		storeid.NewUnaryInterceptor(),
-		logging.NewLoggingInterceptor(logger),
+		logging.NewLoggingInterceptor(logger, loggingOpts...),
 		grpc_auth.UnaryServerInterceptor(authnmw.AuthFunc(authenticator)),
 	)
+	// the interceptors registered by embedders come after the built-in ones, see server.RegisterUnaryInterceptors
+	unaryInterceptors = append(unaryInterceptors, server.RegisteredUnaryInterceptors()...)
 
+	streamingInterceptors = append(streamingInterceptors, grpc_auth.StreamServerInterceptor(authnmw.AuthFunc(authenticator)))
+	streamingInterceptors = append(streamingInterceptors, server.RegisteredStreamInterceptors()...)
 	streamingInterceptors = append(streamingInterceptors,
-		grpc_auth.StreamServerInterceptor(authnmw.AuthFunc(authenticator)),
 		// The following interceptors wrap the server stream with our own
 		// wrapper and must come last.
 		storeid.NewStreamingInterceptor(),
-		logging.NewStreamingLoggingInterceptor(logger),
+		logging.NewStreamingLoggingInterceptor(logger, loggingOpts...),
 	)
 
 	opts := []grpc.ServerOption{
@@ -634,12 +2060,12 @@ func RunServer(ctx context.Context, config *Config) error {
 		if config.GRPC.TLS.CertPath == "" || config.GRPC.TLS.KeyPath == "" {
 			return errors.New("'grpc.tls.cert' and 'grpc.tls.key' configs must be set")
 		}
-		creds, err := credentials.NewServerTLSFromFile(config.GRPC.TLS.CertPath, config.GRPC.TLS.KeyPath)
+		tlsConfig, err := newServerTLSConfig(ctx, config.GRPC.TLS, []string{http2NextProto}, clientCAs)
 		if err != nil {
 			return err
 		}
 
-		opts = append(opts, grpc.Creds(creds))
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
 
 		logger.Info("grpc TLS is enabled, serving connections using the provided certificate")
 	} else {
@@ -666,16 +2092,43 @@ func RunServer(ctx context.Context, config *Config) error {
 	}
 
 	if config.Metrics.Enabled {
-		logger.Info(fmt.Sprintf("📈 starting metrics server on '%s'", config.Metrics.Addr))
+		if err := configureRuntimeMetrics(prometheus.DefaultRegisterer, config.Metrics.EnableRuntimeMetrics); err != nil {
+			return fmt.Errorf("failed to configure runtime metrics: %w", err)
+		}
 
-		go func() {
-			http.Handle("/metrics", promhttp.Handler())
-			if err := http.ListenAndServe(config.Metrics.Addr, nil); err != nil {
-				if err != http.ErrServerClosed {
-					logger.Fatal("failed to start prometheus metrics server", zap.Error(err))
-				}
+		metricsLis, err := net.Listen("tcp", config.Metrics.Addr)
+		if err != nil {
+			if config.Metrics.FailOnListenError {
+				return fmt.Errorf("failed to start prometheus metrics server: %w", err)
 			}
-		}()
+
+			logger.Warn(
+				"failed to start prometheus metrics server, serving the API without the metrics endpoint",
+				zap.String("addr", config.Metrics.Addr),
+				zap.Error(err),
+			)
+		} else {
+			logger.Info(fmt.Sprintf("📈 starting metrics server on '%s'", config.Metrics.Addr))
+
+			go func() {
+				var gatherer prometheus.Gatherer = prometheus.DefaultGatherer
+				if config.Cluster != "" {
+					gatherer = telemetry.NewConstLabelsGatherer(prometheus.Labels{telemetry.ClusterLabel: config.Cluster}, gatherer)
+				}
+				gatherer = telemetry.NewPrefixedGatherer(config.Metrics.Namespace, gatherer)
+				http.Handle("/metrics", newMetricsHandler(prometheus.DefaultRegisterer, gatherer))
+				if err := http.Serve(metricsLis, nil); err != nil {
+					if err != http.ErrServerClosed {
+						logger.Fatal("prometheus metrics server closed with unexpected error", zap.Error(err))
+					}
+				}
+			}()
+		}
+	}
+
+	auditLogger, err := newAuditLogger(config.AuditLog, logger)
+	if err != nil {
+		return err
 	}
 
 	svr := server.New(&server.Dependencies{
@@ -683,12 +2136,35 @@ func RunServer(ctx context.Context, config *Config) error {
 		Logger:       logger,
 		TokenEncoder: encoder.NewBase64Encoder(),
 		Transport:    gateway.NewRPCTransport(logger),
+		AuditLogger:  auditLogger,
 	}, &server.Config{
 		ResolveNodeLimit:       config.ResolveNodeLimit,
 		ChangelogHorizonOffset: config.ChangelogHorizonOffset,
 		ListObjectsDeadline:    config.ListObjectsDeadline,
 		ListObjectsMaxResults:  config.ListObjectsMaxResults,
 		Experimentals:          experimentals,
+		ReadChangesMaxPageSize: config.ReadChangesMaxPageSize,
+
+		ReadAuthorizationModelsDefaultPageSize: config.ReadAuthorizationModelsDefaultPageSize,
+		ReadAuthorizationModelsMaxPageSize:     config.ReadAuthorizationModelsMaxPageSize,
+
+		DisableListObjectsDeduplication:          !config.ListObjectsDeduplicationEnabled,
+		ListObjectsMaxPathsExplored:              config.ListObjectsMaxPathsExplored,
+		ListObjectsMaxConcurrentStreamsPerClient: config.ListObjectsMaxConcurrentStreamsPerClient,
+		MaxConcurrentWritesPerStore:              config.MaxConcurrentWritesPerStore,
+		MaxContextualTuplesPerCheck:              config.MaxContextualTuplesPerCheck,
+		CheckResultMetricsByStore:                config.Metrics.EnableCheckResultStoreLabel,
+		StrictTupleValidation:                    config.StrictTupleValidation,
+		IdempotentWrites:                         config.IdempotentWrites,
+		IdempotentDeletes:                        config.IdempotentDeletes,
+		ModelCacheBypassEnabled:                  config.ModelCacheBypassEnabled,
+		DisableAuthorizationModelIDHeader:        !config.AuthorizationModelIDHeaderEnabled,
+		RequireLatestAuthorizationModel:          config.RequireLatestAuthorizationModel,
+		PinnedAuthorizationModelIDs:              pinnedModelIDs,
+		CandidateAuthorizationModelIDs:           candidateModelIDs,
+		MaxTupleObjectLength:                     config.MaxTupleObjectLength,
+		MaxTupleRelationLength:                   config.MaxTupleRelationLength,
+		MaxTupleUserLength:                       config.MaxTupleUserLength,
 	})
 
 	logger.Info(
@@ -702,17 +2178,25 @@ func RunServer(ctx context.Context, config *Config) error {
 	// nosemgrep: grpc-server-insecure-connection
 	grpcServer := grpc.NewServer(opts...)
 	openfgapb.RegisterOpenFGAServiceServer(grpcServer, svr)
-	healthServer := &health.Checker{TargetService: svr, TargetServiceName: openfgapb.OpenFGAService_ServiceDesc.ServiceName}
+	healthServer := &health.Checker{TargetService: svr, TargetServiceName: openfgapb.OpenFGAService_ServiceDesc.ServiceName, Logger: logger}
 	healthv1pb.RegisterHealthServer(grpcServer, healthServer)
-	reflection.Register(grpcServer)
+	if config.GRPC.EnableReflection {
+		logger.Warn("grpc server reflection is enabled, the service descriptors are exposed to the authenticated clients")
+		reflection.Register(grpcServer)
+	}
 
 	lis, err := net.Listen("tcp", config.GRPC.Addr)
 	if err != nil {
 		return fmt.Errorf("failed to listen: %w", err)
 	}
 
+	if config.GRPC.ProxyProtocolEnabled {
+		lis = proxyprotocol.NewListener(lis)
+	}
+
+	grpcLis := newTrackedListener(lis)
 	go func() {
-		if err := grpcServer.Serve(lis); err != nil {
+		if err := grpcServer.Serve(grpcLis); err != nil {
 			if !errors.Is(err, grpc.ErrServerStopped) {
 				logger.Fatal("failed to start grpc server", zap.Error(err))
 			}
@@ -723,6 +2207,7 @@ func RunServer(ctx context.Context, config *Config) error {
 	logger.Info(fmt.Sprintf("grpc server listening on '%s'...", config.GRPC.Addr))
 
 	var httpServer *http.Server
+	var trackedHTTPLis *trackedListener
 	if config.HTTP.Enabled {
 		// Set a request timeout.
 		runtime.DefaultContextTimeout = config.HTTP.UpstreamTimeout
@@ -743,7 +2228,7 @@ func RunServer(ctx context.Context, config *Config) error {
 		timeoutCtx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 		defer cancel()
 
-		conn, err := grpc.DialContext(timeoutCtx, config.GRPC.Addr, dialOpts...)
+		conn, err := grpc.DialContext(timeoutCtx, dialAddr(config.GRPC.Addr), dialOpts...)
 		if err != nil {
 			logger.Fatal("", zap.Error(err))
 		}
@@ -762,32 +2247,121 @@ func RunServer(ctx context.Context, config *Config) error {
 			}),
 			runtime.WithHealthzEndpoint(healthv1pb.NewHealthClient(conn)),
 			runtime.WithOutgoingHeaderMatcher(func(s string) (string, bool) { return s, true }),
+			runtime.WithIncomingHeaderMatcher(func(s string) (string, bool) {
+				if strings.EqualFold(s, forcetrace.ForceTraceHeader) {
+					return forcetrace.ForceTraceHeader, true
+				}
+
+				if len(config.Trace.BaggageAttributes) > 0 && strings.EqualFold(s, baggage.BaggageHeader) {
+					return baggage.BaggageHeader, true
+				}
+
+				if strings.EqualFold(s, server.TupleTTLHeader) {
+					return server.TupleTTLHeader, true
+				}
+
+				if strings.EqualFold(s, server.ModelCacheBypassHeader) {
+					return server.ModelCacheBypassHeader, true
+				}
+
+				if strings.EqualFold(s, server.CheckTraceHeader) {
+					return server.CheckTraceHeader, true
+				}
+
+				return runtime.DefaultHeaderMatcher(s)
+			}),
+		}
+		// the peer of the proxied requests is the gateway, so the address of the HTTP client is forwarded to attribute
+		// them to their client
+		muxOpts = append(muxOpts, runtime.WithMetadata(svr.ForwardClientAddress))
+		if mtlsAuthenticator != nil {
+			// the gateway reaches the gRPC server on its own connection, so the client certificates verified by the
+			// HTTP server are forwarded to the authenticator
+			muxOpts = append(muxOpts, runtime.WithMetadata(mtlsAuthenticator.ForwardClientCertificate))
+		}
+		if config.HTTP.RejectUnknownJSONFields {
+			// same as the default marshaler of the gateway, except for unknown fields
+			muxOpts = append(muxOpts, runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.HTTPBodyMarshaler{
+				Marshaler: &runtime.JSONPb{
+					MarshalOptions: protojson.MarshalOptions{
+						EmitUnpopulated: true,
+					},
+					UnmarshalOptions: protojson.UnmarshalOptions{
+						DiscardUnknown: false,
+					},
+				},
+			}))
 		}
 		mux := runtime.NewServeMux(muxOpts...)
 		if err := openfgapb.RegisterOpenFGAServiceHandler(ctx, mux, conn); err != nil {
 			return err
 		}
 
+		statusHandler := health.NewStatusHandler(newStatusChecks(statusDependencies{
+			datastoreEngine:   config.Datastore.Engine,
+			datastore:         svr,
+			modelCache:        cachedDatastore,
+			maxCacheSize:      config.Datastore.MaxCacheSize,
+			authenticator:     authenticator,
+			traceExportStatus: traceExportStatus,
+		}), statusCheckTimeout)
+		if err := mux.HandlePath(http.MethodGet, statusPath, func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+			statusHandler.ServeHTTP(w, r)
+		}); err != nil {
+			return err
+		}
+
+		var handler http.Handler = cors.New(cors.Options{
+			AllowedOrigins:   config.HTTP.CORSAllowedOrigins,
+			AllowCredentials: true,
+			AllowedHeaders:   config.HTTP.CORSAllowedHeaders,
+			AllowedMethods: []string{http.MethodGet, http.MethodPost,
+				http.MethodHead, http.MethodPatch, http.MethodDelete, http.MethodPut},
+		}).Handler(httpmiddleware.NewTrailingSlashHandler(httpmiddleware.TrailingSlashPolicy(config.HTTP.TrailingSlashPolicy), mux))
+
+		if config.ConcurrencyLimit.MaxConcurrency > 0 {
+			// the HTTP server has its own limiter so that the requests it rejects get a 429 response
+			handler = concurrency.NewHTTPHandler(concurrency.NewLimiter(config.ConcurrencyLimit.MaxConcurrency, config.ConcurrencyLimit.MaxQueueSize, config.ConcurrencyLimit.QueueTimeout), handler)
+		}
+
 		httpServer = &http.Server{
-			Addr: config.HTTP.Addr,
-			Handler: cors.New(cors.Options{
-				AllowedOrigins:   config.HTTP.CORSAllowedOrigins,
-				AllowCredentials: true,
-				AllowedHeaders:   config.HTTP.CORSAllowedHeaders,
-				AllowedMethods: []string{http.MethodGet, http.MethodPost,
-					http.MethodHead, http.MethodPatch, http.MethodDelete, http.MethodPut},
-			}).Handler(mux),
+			Addr:    config.HTTP.Addr,
+			Handler: handler,
+		}
+
+		if config.HTTP.TLS.Enabled {
+			if config.HTTP.TLS.CertPath == "" || config.HTTP.TLS.KeyPath == "" {
+				return errors.New("'http.tls.cert' and 'http.tls.key' configs must be set")
+			}
+
+			httpServer.TLSConfig, err = newServerTLSConfig(ctx, config.HTTP.TLS, []string{http2NextProto, "http/1.1"}, clientCAs)
+			if err != nil {
+				return err
+			}
+
+			if len(config.HTTP.TLS.NextProtos) > 0 && !containsNextProto(config.HTTP.TLS.NextProtos, http2NextProto) {
+				// a non-nil map stops net/http from advertising and serving HTTP/2 on its own
+				httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+			}
+		}
+
+		httpLis, err := net.Listen("tcp", config.HTTP.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to listen: %w", err)
 		}
 
+		if config.HTTP.ProxyProtocolEnabled {
+			httpLis = proxyprotocol.NewListener(httpLis)
+		}
+
+		trackedHTTPLis = newTrackedListener(httpLis)
 		go func() {
 			var err error
 			if config.HTTP.TLS.Enabled {
-				if config.HTTP.TLS.CertPath == "" || config.HTTP.TLS.KeyPath == "" {
-					logger.Fatal("'http.tls.cert' and 'http.tls.key' configs must be set")
-				}
-				err = httpServer.ListenAndServeTLS(config.HTTP.TLS.CertPath, config.HTTP.TLS.KeyPath)
+				// the certificate is already in httpServer.TLSConfig
+				err = httpServer.ServeTLS(trackedHTTPLis, "", "")
 			} else {
-				err = httpServer.ListenAndServe()
+				err = httpServer.Serve(trackedHTTPLis)
 			}
 			if err != http.ErrServerClosed {
 				logger.Fatal("HTTP server closed with unexpected error", zap.Error(err))
@@ -808,22 +2382,20 @@ func RunServer(ctx context.Context, config *Config) error {
 		}
 
 		playgroundAddr := fmt.Sprintf(":%d", config.Playground.Port)
-		logger.Info(fmt.Sprintf("🛝 starting openfga playground on http://localhost%s/playground", playgroundAddr))
+		logger.Info(fmt.Sprintf("🛝 starting openfga playground on http://localhost%s%s/playground", playgroundAddr, normalizePlaygroundBasePath(config.Playground.BasePath)))
 
 		tmpl, err := template.ParseFS(assets.EmbedPlayground, "playground/index.html")
 		if err != nil {
 			return fmt.Errorf("failed to parse playground index.html as Go template: %w", err)
 		}
 
-		fileServer := http.FileServer(http.FS(assets.EmbedPlayground))
-
 		policy := backoff.NewExponentialBackOff()
 		policy.MaxElapsedTime = 3 * time.Second
 
 		var conn net.Conn
 		err = backoff.Retry(
 			func() error {
-				conn, err = net.Dial("tcp", config.HTTP.Addr)
+				conn, err = net.Dial("tcp", dialAddr(config.HTTP.Addr))
 				return err
 			},
 			policy,
@@ -838,31 +2410,10 @@ func RunServer(ctx context.Context, config *Config) error {
 		}
 
 		mux := http.NewServeMux()
-		mux.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-
-			if strings.HasPrefix(r.URL.Path, "/playground") {
-				if r.URL.Path == "/playground" || r.URL.Path == "/playground/index.html" {
-					err = tmpl.Execute(w, struct {
-						HTTPServerURL      string
-						PlaygroundAPIToken string
-					}{
-						HTTPServerURL:      conn.RemoteAddr().String(),
-						PlaygroundAPIToken: playgroundAPIToken,
-					})
-					if err != nil {
-						w.WriteHeader(http.StatusInternalServerError)
-						logger.Error("failed to execute/render the playground web template", zap.Error(err))
-					}
-
-					return
-				}
-
-				fileServer.ServeHTTP(w, r)
-				return
-			}
-
-			http.NotFound(w, r)
-		}))
+		mux.Handle("/", newPlaygroundHandler(config.Playground.BasePath, tmpl, assets.EmbedPlayground, playgroundTemplateData{
+			HTTPServerURL:      conn.RemoteAddr().String(),
+			PlaygroundAPIToken: playgroundAPIToken,
+		}, logger))
 
 		playground = &http.Server{Addr: playgroundAddr, Handler: mux}
 
@@ -875,6 +2426,20 @@ func RunServer(ctx context.Context, config *Config) error {
 		}()
 	}
 
+	if presharedKeyAuthenticator != nil && config.Authn.ReloadOnSIGHUP {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+
+		reloadCtx, stopReload := context.WithCancel(ctx)
+		defer stopReload()
+
+		logger.Info("the preshared keys are reloaded from the config on SIGHUP")
+		go reloadPresharedKeys(reloadCtx, reload, presharedKeyAuthenticator, ReadConfig, logger)
+	}
+
+	started = true
+
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
@@ -884,7 +2449,8 @@ func RunServer(ctx context.Context, config *Config) error {
 	}
 	logger.Info("attempting to shutdown gracefully")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// the servers get GracefulShutdownTimeout to finish the active requests, after which their connections are closed
+	ctx, cancel := context.WithTimeout(context.Background(), config.GracefulShutdownTimeout)
 	defer cancel()
 
 	if playground != nil {
@@ -893,20 +2459,52 @@ func RunServer(ctx context.Context, config *Config) error {
 		}
 	}
 
+	// the streams proxied by the http gateway keep its connections open, so they are drained while it shuts down
+	drained := make(chan error, 1)
+	go func() {
+		drained <- svr.Drain(ctx)
+	}()
+
 	if httpServer != nil {
-		if err := httpServer.Shutdown(ctx); err != nil {
-			logger.Info("failed to shutdown the http server", zap.Error(err))
+		// the streams still in progress at the deadline are cancelled by Drain, and get the time Drain gives them to
+		// send the retryable error through the gateway before its connections are closed
+		httpCtx, httpCancel := context.WithTimeout(context.Background(), config.GracefulShutdownTimeout+server.DrainCancelledStreamsTimeout)
+		if err := httpServer.Shutdown(httpCtx); err != nil {
+			abandoned := trackedHTTPLis.OpenConns()
+			logger.Warn(fmt.Sprintf("the http server didn't shut down within %s, closing %d connections", config.GracefulShutdownTimeout, abandoned), zap.Error(err))
+			httpServer.Close()
 		}
+		httpCancel()
+	}
+
+	if err := <-drained; err != nil {
+		logger.Info("failed to drain the streaming requests", zap.Error(err))
+	}
+
+	if abandoned := stopGRPCServer(ctx, grpcServer, grpcLis); abandoned > 0 {
+		logger.Warn(fmt.Sprintf("the grpc server didn't shut down within %s, closed %d connections", config.GracefulShutdownTimeout, abandoned))
 	}
 
-	grpcServer.GracefulStop()
+	if err := auditLogger.Close(); err != nil {
+		logger.Info("failed to close the audit log", zap.Error(err))
+	}
 
 	authenticator.Close()
 
+	stopBackground()
 	datastore.Close()
 
-	_ = tp.ForceFlush(ctx)
-	_ = tp.Shutdown(ctx)
+	// the spans get a timeout of their own, so that a slow shutdown of the servers doesn't leave no time to export them
+	flushCtx, flushCancel := context.WithTimeout(context.Background(), traceFlushTimeout)
+	defer flushCancel()
+
+	if err := tp.ForceFlush(flushCtx); err != nil {
+		logger.Warn("failed to flush the traces", zap.Error(err))
+	}
+
+	if err := tp.Shutdown(flushCtx); err != nil {
+		logger.Warn("failed to shutdown the tracer provider", zap.Error(err))
+	}
 
 	logger.Info("server exited. goodbye 👋")
 
@@ -0,0 +1,76 @@
+package run
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+)
+
+// trackedListener counts the connections it accepted that are still open, so that the connections a forced shutdown
+// abandons can be reported.
+type trackedListener struct {
+	net.Listener
+
+	openConns atomic.Int64
+}
+
+func newTrackedListener(lis net.Listener) *trackedListener {
+	return &trackedListener{Listener: lis}
+}
+
+func (l *trackedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	l.openConns.Add(1)
+
+	return &trackedConn{Conn: conn, listener: l}, nil
+}
+
+// OpenConns returns the number of accepted connections that are still open.
+func (l *trackedListener) OpenConns() int64 {
+	return l.openConns.Load()
+}
+
+type trackedConn struct {
+	net.Conn
+
+	listener  *trackedListener
+	closeOnce sync.Once
+}
+
+func (c *trackedConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.listener.openConns.Add(-1)
+	})
+
+	return c.Conn.Close()
+}
+
+// stopGRPCServer stops the server gracefully, letting the active RPCs finish, until ctx is done, at which point the
+// remaining connections are closed. It returns the number of connections that were still open when they were
+// closed, or 0 if the server stopped gracefully.
+func stopGRPCServer(ctx context.Context, server *grpc.Server, lis *trackedListener) int64 {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return 0
+	case <-ctx.Done():
+	}
+
+	abandoned := lis.OpenConns()
+	server.Stop()
+	<-stopped
+
+	return abandoned
+}
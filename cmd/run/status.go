@@ -0,0 +1,91 @@
+package run
+
+import (
+	"context"
+	"time"
+
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/pkg/server/health"
+	"github.com/openfga/openfga/pkg/telemetry"
+)
+
+const (
+	statusPath         = "/status"
+	statusCheckTimeout = 5 * time.Second
+)
+
+// modelCache reports the number of authorization models cached by the datastore.
+type modelCache interface {
+	ModelCacheItemCount() (int, bool)
+}
+
+// statusDependencies are the subsystems whose health is reported on the status endpoint.
+type statusDependencies struct {
+	datastoreEngine string
+	datastore       health.TargetService
+	modelCache      modelCache
+	maxCacheSize    int
+
+	// authenticator is checked if it fetches its keys from an OIDC issuer.
+	authenticator authn.Authenticator
+
+	// traceExportStatus is nil if tracing is disabled.
+	traceExportStatus *telemetry.ExportStatus
+}
+
+// newStatusChecks returns the checks of the subsystems reported on the status endpoint.
+func newStatusChecks(deps statusDependencies) map[string]health.SubsystemCheck {
+	checks := map[string]health.SubsystemCheck{
+		"datastore": func(ctx context.Context) health.SubsystemStatus {
+			status := health.SubsystemStatus{Details: map[string]any{"engine": deps.datastoreEngine}}
+
+			ready, err := deps.datastore.IsReady(ctx)
+			switch {
+			case err != nil:
+				status.Error = err.Error()
+			case !ready:
+				status.Error = "the datastore is not ready"
+			default:
+				status.Healthy = true
+			}
+
+			return status
+		},
+		"modelCache": func(context.Context) health.SubsystemStatus {
+			details := map[string]any{"maxSize": deps.maxCacheSize}
+			if items, ok := deps.modelCache.ModelCacheItemCount(); ok {
+				details["items"] = items
+			}
+
+			return health.SubsystemStatus{Healthy: true, Details: details}
+		},
+	}
+
+	if oidcAuthenticator, ok := deps.authenticator.(authn.OIDCAuthenticator); ok {
+		checks["oidcIssuer"] = func(context.Context) health.SubsystemStatus {
+			if _, err := oidcAuthenticator.GetConfiguration(); err != nil {
+				return health.SubsystemStatus{Error: err.Error()}
+			}
+
+			return health.SubsystemStatus{Healthy: true}
+		}
+	}
+
+	if deps.traceExportStatus != nil {
+		checks["traceExporter"] = func(context.Context) health.SubsystemStatus {
+			lastExport, err := deps.traceExportStatus.Last()
+
+			status := health.SubsystemStatus{Healthy: err == nil, Details: map[string]any{}}
+			if err != nil {
+				status.Error = err.Error()
+			}
+			if !lastExport.IsZero() {
+				status.Details["lastExport"] = lastExport.UTC().Format(time.RFC3339)
+			}
+
+			return status
+		}
+	}
+
+	return checks
+}
@@ -0,0 +1,220 @@
+package run
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// http2NextProto is the ALPN protocol of HTTP/2 over TLS.
+const http2NextProto = "h2"
+
+// knownNextProtos are the ALPN protocols that the servers can be configured to advertise.
+var knownNextProtos = []string{http2NextProto, "http/1.1", "http/1.0"}
+
+// verifyNextProtos makes sure that the configured ALPN protocols are known ones, so that a typo doesn't make the
+// negotiation fail for every client.
+func verifyNextProtos(nextProtos []string) error {
+	for _, proto := range nextProtos {
+		if !containsNextProto(knownNextProtos, proto) {
+			return fmt.Errorf("unknown protocol '%s', it must be one of ['%s']", proto, strings.Join(knownNextProtos, "', '"))
+		}
+	}
+
+	return nil
+}
+
+func containsNextProto(nextProtos []string, proto string) bool {
+	for _, p := range nextProtos {
+		if p == proto {
+			return true
+		}
+	}
+
+	return false
+}
+
+// newServerTLSConfig returns the tls.Config of a server that presents the certificate of the provided TLSConfig and
+// negotiates its NextProtos, or defaultNextProtos if it has none.
+//
+// If session tickets are enabled and a session ticket key rotation period is set, the session ticket keys are
+// rotated on that period until ctx is done, instead of relying on the automatic rotation of the standard library.
+// The previous key is kept for one more period, so that tickets issued just before a rotation can still be used.
+//
+// If clientCAs is set, the clients may present a certificate, which must be signed by one of clientCAs. Whether they
+// must present one is up to the authenticator, so that the endpoints that don't require authentication, such as
+// the health checks, can still be reached without a certificate.
+func newServerTLSConfig(ctx context.Context, cfg *TLSConfig, defaultNextProtos []string, clientCAs *x509.CertPool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+	if err != nil {
+		return nil, err
+	}
+
+	nextProtos := defaultNextProtos
+	if len(cfg.NextProtos) > 0 {
+		nextProtos = cfg.NextProtos
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates:           []tls.Certificate{cert},
+		NextProtos:             nextProtos,
+		SessionTicketsDisabled: !cfg.SessionTicketsEnabled,
+	}
+
+	if clientCAs != nil {
+		tlsConfig.ClientCAs = clientCAs
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	if !cfg.SessionTicketsEnabled || cfg.SessionTicketKeyRotation <= 0 {
+		return tlsConfig, nil
+	}
+
+	if err := rotateSessionTicketKeys(ctx, tlsConfig, cfg.SessionTicketKeyRotation); err != nil {
+		return nil, err
+	}
+
+	// grpc and net/http clone the tls.Config they are given, so the keys set on tlsConfig later on would not be
+	// seen by the handshakes. Handing out tlsConfig itself for every handshake makes the rotation take effect.
+	return &tls.Config{
+		Certificates: tlsConfig.Certificates,
+		NextProtos:   tlsConfig.NextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return tlsConfig, nil
+		},
+	}, nil
+}
+
+// rotateSessionTicketKeys sets a new random session ticket key on the tls.Config and replaces it every period until
+// ctx is done, keeping the previous key to decrypt tickets issued with it.
+func rotateSessionTicketKeys(ctx context.Context, tlsConfig *tls.Config, period time.Duration) error {
+	current, err := newSessionTicketKey()
+	if err != nil {
+		return err
+	}
+	tlsConfig.SetSessionTicketKeys([][32]byte{current})
+
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				next, err := newSessionTicketKey()
+				if err != nil {
+					// keep using the current key, and try again on the next tick
+					continue
+				}
+
+				tlsConfig.SetSessionTicketKeys([][32]byte{next, current})
+				current = next
+			}
+		}
+	}()
+
+	return nil
+}
+
+func newSessionTicketKey() ([32]byte, error) {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return key, fmt.Errorf("failed to generate a session ticket key: %w", err)
+	}
+
+	return key, nil
+}
+
+// newOIDCClientTLSConfig returns the tls.Config of the client that fetches the OIDC configuration and keys from the
+// issuer. It trusts the CA certificates in the CACertPath file in addition to the system ones.
+func newOIDCClientTLSConfig(cfg *AuthnOIDCConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // only enabled on purpose, for development
+	}
+
+	if cfg.CACertPath == "" {
+		return tlsConfig, nil
+	}
+
+	caCerts, err := os.ReadFile(cfg.CACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the OIDC CA certificates: %w", err)
+	}
+
+	tlsConfig.RootCAs, err = systemCertPoolWith(caCerts, cfg.CACertPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return tlsConfig, nil
+}
+
+// newMTLSClientCAs returns the pool of the CA certificates in the CACertPath file, which the client certificates must
+// be signed by. Unlike the clients of the server, it doesn't trust the system CA certificates.
+func newMTLSClientCAs(cfg *AuthnMTLSConfig) (*x509.CertPool, error) {
+	caCerts, err := os.ReadFile(cfg.CACertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mTLS CA certificates: %w", err)
+	}
+
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caCerts) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in '%s'", cfg.CACertPath)
+	}
+
+	return clientCAs, nil
+}
+
+// newOTLPClientTLSConfig returns the TLS config of the connection to the trace collector, or nil if TLS is
+// disabled, in which case the spans are exported in plaintext.
+func newOTLPClientTLSConfig(cfg OTLPTLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if cfg.CACertPath != "" {
+		caCerts, err := os.ReadFile(cfg.CACertPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the trace collector CA certificates: %w", err)
+		}
+
+		tlsConfig.RootCAs, err = systemCertPoolWith(caCerts, cfg.CACertPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.CertPath != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertPath, cfg.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the trace collector client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// systemCertPoolWith returns the system cert pool with the PEM encoded caCerts, read from path, added to it.
+func systemCertPoolWith(caCerts []byte, path string) (*x509.CertPool, error) {
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if !rootCAs.AppendCertsFromPEM(caCerts) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in '%s'", path)
+	}
+
+	return rootCAs, nil
+}
@@ -0,0 +1,153 @@
+// Package selftest contains the command to run a write-then-check smoke test against a running OpenFGA server.
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	grpcAddrFlag   = "grpc-addr"
+	apiTokenFlag   = "api-token"
+	tlsEnabledFlag = "tls-enabled"
+	timeoutFlag    = "timeout"
+
+	// deleteStoreTimeout bounds the deletion of the temporary store, which is attempted even if the test ran out of
+	// time.
+	deleteStoreTimeout = 5 * time.Second
+)
+
+func NewSelfTestCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "self-test",
+		Short: "Run a write-then-check smoke test against a running OpenFGA server",
+		Long: "Connect to a running OpenFGA server, create a temporary store and authorization model, write a tuple, " +
+			"run a Check and delete the store. The command exits with a non-zero status if any step fails.",
+		RunE: runSelfTest,
+		Args: cobra.NoArgs,
+	}
+
+	flags := cmd.Flags()
+	flags.String(grpcAddrFlag, "localhost:8081", "the address of the grpc server to test")
+	flags.String(apiTokenFlag, "", "the token sent as a bearer token, if the server requires authentication")
+	flags.Bool(tlsEnabledFlag, false, "connect to the grpc server using TLS")
+	flags.Duration(timeoutFlag, 30*time.Second, "the maximum amount of time the test may take")
+
+	// NOTE: if you add a new flag here, update the function below, too
+
+	cmd.PreRun = bindRunFlagsFunc(flags)
+
+	return cmd
+}
+
+func runSelfTest(_ *cobra.Command, _ []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), viper.GetDuration(timeoutFlag))
+	defer cancel()
+
+	conn, err := util.DialGRPCServer(ctx, viper.GetString(grpcAddrFlag), viper.GetBool(tlsEnabledFlag))
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx = util.ContextWithAPIToken(ctx, viper.GetString(apiTokenFlag))
+
+	if err := RunSelfTest(ctx, openfgapb.NewOpenFGAServiceClient(conn)); err != nil {
+		return fmt.Errorf("self-test failed: %w", err)
+	}
+
+	fmt.Println("self-test passed")
+	return nil
+}
+
+// RunSelfTest creates a temporary store and authorization model, writes a tuple, checks that the
+// tuple grants access and finally deletes the store. The store is deleted even if a step fails.
+func RunSelfTest(ctx context.Context, client openfgapb.OpenFGAServiceClient) (err error) {
+	createStoreResp, err := client.CreateStore(ctx, &openfgapb.CreateStoreRequest{
+		Name: "openfga-self-test-" + ulid.Make().String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create store: %w", err)
+	}
+	storeID := createStoreResp.GetId()
+
+	defer func() {
+		// ctx may be done by now, so the store is deleted with a context of its own that keeps the credentials
+		deleteCtx, cancel := context.WithTimeout(context.Background(), deleteStoreTimeout)
+		defer cancel()
+
+		if md, ok := metadata.FromOutgoingContext(ctx); ok {
+			deleteCtx = metadata.NewOutgoingContext(deleteCtx, md)
+		}
+
+		_, deleteErr := client.DeleteStore(deleteCtx, &openfgapb.DeleteStoreRequest{StoreId: storeID})
+		if deleteErr != nil && err == nil {
+			err = fmt.Errorf("failed to delete store '%s': %w", storeID, deleteErr)
+		}
+	}()
+
+	writeModelResp, err := client.WriteAuthorizationModel(ctx, &openfgapb.WriteAuthorizationModelRequest{
+		StoreId:       storeID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgapb.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgapb.Userset{
+					"viewer": typesystem.This(),
+				},
+				Metadata: &openfgapb.Metadata{
+					Relations: map[string]*openfgapb.RelationMetadata{
+						"viewer": {
+							DirectlyRelatedUserTypes: []*openfgapb.RelationReference{
+								typesystem.DirectRelationReference("user", ""),
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write authorization model: %w", err)
+	}
+	modelID := writeModelResp.GetAuthorizationModelId()
+
+	tk := tuple.NewTupleKey("document:self-test", "viewer", "user:self-test")
+
+	_, err = client.Write(ctx, &openfgapb.WriteRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		Writes:               &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{tk}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tuple: %w", err)
+	}
+
+	checkResp, err := client.Check(ctx, &openfgapb.CheckRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		TupleKey:             tk,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to check: %w", err)
+	}
+
+	if !checkResp.GetAllowed() {
+		return fmt.Errorf("expected check of the written tuple '%s' to be allowed", tuple.TupleKeyToString(tk))
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package selftest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openfga/openfga/cmd/run"
+	"github.com/openfga/openfga/tests"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// cancellingClient cancels the context of the self-test once the store is created.
+type cancellingClient struct {
+	openfgapb.OpenFGAServiceClient
+
+	cancel context.CancelFunc
+}
+
+func (c *cancellingClient) WriteAuthorizationModel(ctx context.Context, req *openfgapb.WriteAuthorizationModelRequest, opts ...grpc.CallOption) (*openfgapb.WriteAuthorizationModelResponse, error) {
+	c.cancel()
+	return c.OpenFGAServiceClient.WriteAuthorizationModel(ctx, req, opts...)
+}
+
+func TestRunSelfTest(t *testing.T) {
+	cfg := run.MustDefaultConfigWithRandomPorts()
+	cfg.Log.Level = "none"
+	cfg.Datastore.Engine = "memory"
+
+	cancel := tests.StartServer(t, cfg)
+	defer cancel()
+
+	conn, err := grpc.Dial(cfg.GRPC.Addr,
+		grpc.WithBlock(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := openfgapb.NewOpenFGAServiceClient(conn)
+
+	t.Run("passes", func(t *testing.T) {
+		err := RunSelfTest(context.Background(), client)
+		require.NoError(t, err)
+
+		// the temporary store is cleaned up
+		resp, err := client.ListStores(context.Background(), &openfgapb.ListStoresRequest{})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetStores())
+	})
+
+	t.Run("store_deleted_after_the_context_is_done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		err := RunSelfTest(ctx, &cancellingClient{OpenFGAServiceClient: client, cancel: cancel})
+		require.ErrorContains(t, err, "failed to write authorization model")
+
+		resp, err := client.ListStores(context.Background(), &openfgapb.ListStoresRequest{})
+		require.NoError(t, err)
+		require.Empty(t, resp.GetStores())
+	})
+}
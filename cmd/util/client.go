@@ -0,0 +1,41 @@
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+)
+
+// DialGRPCServer connects to the OpenFGA grpc server at addr, using TLS if tlsEnabled is set, and blocks until the
+// connection is up or ctx is done.
+func DialGRPCServer(ctx context.Context, addr string, tlsEnabled bool) (*grpc.ClientConn, error) {
+	creds := insecure.NewCredentials()
+	if tlsEnabled {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+
+	conn, err := grpc.DialContext(ctx, addr,
+		grpc.WithBlock(),
+		grpc.WithTransportCredentials(creds),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to the grpc server: %w", err)
+	}
+
+	return conn, nil
+}
+
+// ContextWithAPIToken returns a context that sends the token as a bearer token on outgoing requests.
+// If the token is empty, ctx is returned as is.
+func ContextWithAPIToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
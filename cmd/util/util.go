@@ -12,6 +12,7 @@ import (
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
 	storagefixtures "github.com/openfga/openfga/pkg/testfixtures/storage"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
@@ -58,6 +59,8 @@ func MustBootstrapDatastore(t testing.TB, engine string) (storagefixtures.Datast
 		ds, err = postgres.New(uri, sqlcommon.NewConfig())
 	case "mysql":
 		ds, err = mysql.New(uri, sqlcommon.NewConfig())
+	case "sqlite":
+		ds, err = sqlite.New(uri, sqlcommon.NewConfig())
 	default:
 		return nil, nil, "", fmt.Errorf("'%s' is not a supported datastore engine", engine)
 	}
@@ -6,10 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/mysql"
 	"github.com/openfga/openfga/pkg/storage/postgres"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/sqlite"
 	"github.com/openfga/openfga/pkg/typesystem"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -53,6 +55,12 @@ func runValidate(_ *cobra.Command, _ []string) error {
 
 	ctx := context.Background()
 
+	if engine != "" {
+		if err := build.CheckDatastoreEngine(engine); err != nil {
+			return err
+		}
+	}
+
 	var (
 		db  storage.OpenFGADatastore
 		err error
@@ -62,6 +70,8 @@ func runValidate(_ *cobra.Command, _ []string) error {
 		db, err = mysql.New(uri, sqlcommon.NewConfig())
 	case "postgres":
 		db, err = postgres.New(uri, sqlcommon.NewConfig())
+	case "sqlite":
+		db, err = sqlite.New(uri, sqlcommon.NewConfig())
 	case "":
 		return fmt.Errorf("missing datastore engine type")
 	case "memory":
@@ -9,6 +9,7 @@ import (
 	"github.com/oklog/ulid/v2"
 	"github.com/openfga/openfga/cmd"
 	"github.com/openfga/openfga/cmd/util"
+	"github.com/openfga/openfga/internal/build"
 	"github.com/openfga/openfga/pkg/typesystem"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -17,7 +18,7 @@ import (
 )
 
 func TestValidationResult(t *testing.T) {
-	engines := []string{"postgres", "mysql"}
+	engines := []string{"postgres", "mysql", "sqlite"}
 
 	totalStores := 200
 	totalModelsForOneStore := 200
@@ -95,6 +96,19 @@ func TestValidateModelsCommandWhenInvalidEngine(t *testing.T) {
 	}
 }
 
+func TestValidateModelsCommandWhenEngineNotPermitted(t *testing.T) {
+	permittedDatastoreEngines := build.PermittedDatastoreEngines
+	t.Cleanup(func() {
+		build.PermittedDatastoreEngines = permittedDatastoreEngines
+	})
+	build.PermittedDatastoreEngines = "postgres"
+
+	validateModelsCommand := NewValidateCommand()
+	validateModelsCommand.SetArgs([]string{"--datastore-engine", "mysql", "--datastore-uri", ""})
+	err := validateModelsCommand.Execute()
+	require.ErrorIs(t, err, build.ErrDatastoreEngineNotPermitted)
+}
+
 func TestValidateModelsCommandNoConfigDefaultValues(t *testing.T) {
 	util.PrepareTempConfigDir(t)
 	validateCommand := NewValidateCommand()
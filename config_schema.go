@@ -0,0 +1,9 @@
+// Package openfga contains the files that are embedded into the OpenFGA binary from the root of the repository.
+package openfga
+
+import _ "embed"
+
+// ConfigSchema is the JSON schema of the OpenFGA server configuration.
+//
+//go:embed .config-schema.json
+var ConfigSchema []byte
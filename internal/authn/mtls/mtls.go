@@ -0,0 +1,167 @@
+// Package mtls authenticates the clients by the certificates they present in the TLS handshake.
+package mtls
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/openfga/openfga/internal/authn"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// gatewayTokenHeader carries the token that proves that a request was proxied by the HTTP gateway of the server.
+	gatewayTokenHeader = "x-openfga-mtls-gateway-token"
+
+	// clientCertificateHeader carries the base64 encoded client certificate verified by the HTTP server.
+	clientCertificateHeader = "x-openfga-mtls-client-certificate"
+)
+
+var ErrMissingClientCertificate = status.Error(codes.Code(openfgapb.AuthErrorCode_unauthenticated), "missing client certificate")
+
+type MTLSAuthenticator struct {
+	allowedSubjects []*regexp.Regexp
+
+	// gatewayToken is a random token, only known to this server, that the HTTP gateway sends along with the client
+	// certificates it forwards, so that they can't be forged by the clients.
+	gatewayToken string
+}
+
+var _ authn.Authenticator = (*MTLSAuthenticator)(nil)
+
+// NewMTLSAuthenticator returns an authenticator that accepts the clients that presented a certificate verified by
+// the server. If allowedSubjects is not empty, the subject or one of the subject alternative names of the
+// certificate must also match one of these regular expressions in full. The subject of the certificate is the
+// principal of the requests.
+func NewMTLSAuthenticator(allowedSubjects []string) (*MTLSAuthenticator, error) {
+	patterns := make([]*regexp.Regexp, 0, len(allowedSubjects))
+	for _, subject := range allowedSubjects {
+		pattern, err := regexp.Compile("^(?:" + subject + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid auth configuration, allowed subject '%s' is not a valid regular expression: %w", subject, err)
+		}
+
+		patterns = append(patterns, pattern)
+	}
+
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate the gateway token: %w", err)
+	}
+
+	return &MTLSAuthenticator{
+		allowedSubjects: patterns,
+		gatewayToken:    hex.EncodeToString(token),
+	}, nil
+}
+
+func (m *MTLSAuthenticator) Authenticate(ctx context.Context) (*authn.AuthClaims, error) {
+	cert, err := m.clientCertificate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !m.isAllowed(cert) {
+		return nil, authn.ErrUnauthenticated
+	}
+
+	return &authn.AuthClaims{
+		Subject: cert.Subject.String(),
+	}, nil
+}
+
+// clientCertificate returns the certificate the client presented to the gRPC server, or to the HTTP server for the
+// requests proxied by the HTTP gateway.
+func (m *MTLSAuthenticator) clientCertificate(ctx context.Context) (*x509.Certificate, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if tokens := md.Get(gatewayTokenHeader); len(tokens) > 0 {
+		if len(tokens) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(m.gatewayToken)) != 1 {
+			return nil, authn.ErrUnauthenticated
+		}
+
+		certs := md.Get(clientCertificateHeader)
+		if len(certs) != 1 {
+			return nil, ErrMissingClientCertificate
+		}
+
+		der, err := base64.StdEncoding.DecodeString(certs[0])
+		if err != nil {
+			return nil, authn.ErrUnauthenticated
+		}
+
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, authn.ErrUnauthenticated
+		}
+
+		return cert, nil
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, ErrMissingClientCertificate
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, ErrMissingClientCertificate
+	}
+
+	return tlsInfo.State.VerifiedChains[0][0], nil
+}
+
+// isAllowed reports whether the subject or one of the subject alternative names of the certificate match one of
+// the allowed subjects.
+func (m *MTLSAuthenticator) isAllowed(cert *x509.Certificate) bool {
+	if len(m.allowedSubjects) == 0 {
+		return true
+	}
+
+	names := []string{cert.Subject.String()}
+	names = append(names, cert.DNSNames...)
+	names = append(names, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		names = append(names, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+
+	for _, pattern := range m.allowedSubjects {
+		for _, name := range names {
+			if pattern.MatchString(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ForwardClientCertificate returns the gRPC metadata that forwards the client certificate verified by the HTTP
+// server to the gRPC server, for the HTTP gateway. It returns no metadata if the client didn't present a
+// certificate.
+func (m *MTLSAuthenticator) ForwardClientCertificate(_ context.Context, r *http.Request) metadata.MD {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return metadata.Pairs(
+		gatewayTokenHeader, m.gatewayToken,
+		clientCertificateHeader, base64.StdEncoding.EncodeToString(r.TLS.VerifiedChains[0][0].Raw),
+	)
+}
+
+func (m *MTLSAuthenticator) Close() {}
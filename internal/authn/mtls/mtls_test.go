@@ -0,0 +1,155 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func clientCertificate(t *testing.T, commonName string, uris ...string) *x509.Certificate {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Acme"}},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	for _, uri := range uris {
+		u, err := url.Parse(uri)
+		require.NoError(t, err)
+		template.URIs = append(template.URIs, u)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &priv.PublicKey, priv)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert
+}
+
+func peerContext(cert *x509.Certificate) context.Context {
+	state := tls.ConnectionState{}
+	if cert != nil {
+		state.VerifiedChains = [][]*x509.Certificate{{cert}}
+	}
+
+	return peer.NewContext(context.Background(), &peer.Peer{AuthInfo: credentials.TLSInfo{State: state}})
+}
+
+func TestAuthenticateWithVerifiedClientCertificate(t *testing.T) {
+	authenticator, err := NewMTLSAuthenticator(nil)
+	require.NoError(t, err)
+
+	claims, err := authenticator.Authenticate(peerContext(clientCertificate(t, "billing")))
+	require.NoError(t, err)
+	require.Equal(t, "CN=billing,O=Acme", claims.Subject)
+
+	_, err = authenticator.Authenticate(peerContext(nil))
+	require.ErrorIs(t, err, ErrMissingClientCertificate)
+
+	_, err = authenticator.Authenticate(context.Background())
+	require.ErrorIs(t, err, ErrMissingClientCertificate)
+}
+
+func TestAuthenticateRestrictsToAllowedSubjects(t *testing.T) {
+	authenticator, err := NewMTLSAuthenticator([]string{"CN=billing,O=Acme", "spiffe://acme.internal/ns/prod/.*"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		_name   string
+		cert    *x509.Certificate
+		allowed bool
+	}{{
+		_name:   "matching_subject",
+		cert:    clientCertificate(t, "billing"),
+		allowed: true,
+	}, {
+		_name:   "matching_uri_san",
+		cert:    clientCertificate(t, "orders", "spiffe://acme.internal/ns/prod/sa/orders"),
+		allowed: true,
+	}, {
+		_name: "partially_matching_subject",
+		cert:  clientCertificate(t, "billing-dev"),
+	}, {
+		_name: "no_matching_name",
+		cert:  clientCertificate(t, "orders", "spiffe://acme.internal/ns/dev/sa/orders"),
+	}}
+
+	for _, test := range tests {
+		t.Run(test._name, func(t *testing.T) {
+			claims, err := authenticator.Authenticate(peerContext(test.cert))
+			if !test.allowed {
+				require.ErrorIs(t, err, authn.ErrUnauthenticated)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, test.cert.Subject.String(), claims.Subject)
+		})
+	}
+}
+
+func TestNewMTLSAuthenticatorRejectsInvalidAllowedSubjects(t *testing.T) {
+	_, err := NewMTLSAuthenticator([]string{"CN=(billing"})
+	require.ErrorContains(t, err, "invalid auth configuration, allowed subject 'CN=(billing' is not a valid regular expression")
+}
+
+func TestAuthenticateForwardedClientCertificate(t *testing.T) {
+	authenticator, err := NewMTLSAuthenticator([]string{"CN=billing,O=Acme"})
+	require.NoError(t, err)
+
+	cert := clientCertificate(t, "billing")
+
+	t.Run("forwarded_by_the_gateway", func(t *testing.T) {
+		md := authenticator.ForwardClientCertificate(context.Background(), &http.Request{
+			TLS: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}},
+		})
+
+		claims, err := authenticator.Authenticate(metadata.NewIncomingContext(peerContext(nil), md))
+		require.NoError(t, err)
+		require.Equal(t, "CN=billing,O=Acme", claims.Subject)
+	})
+
+	t.Run("nothing_forwarded_without_client_certificate", func(t *testing.T) {
+		require.Nil(t, authenticator.ForwardClientCertificate(context.Background(), &http.Request{}))
+		require.Nil(t, authenticator.ForwardClientCertificate(context.Background(), &http.Request{TLS: &tls.ConnectionState{}}))
+	})
+
+	t.Run("forged_gateway_token_fails", func(t *testing.T) {
+		md := metadata.Pairs(
+			gatewayTokenHeader, "forged",
+			clientCertificateHeader, base64.StdEncoding.EncodeToString(cert.Raw),
+		)
+
+		_, err := authenticator.Authenticate(metadata.NewIncomingContext(peerContext(nil), md))
+		require.ErrorIs(t, err, authn.ErrUnauthenticated)
+	})
+
+	t.Run("gateway_token_without_certificate_fails", func(t *testing.T) {
+		md := metadata.Pairs(gatewayTokenHeader, authenticator.gatewayToken)
+
+		_, err := authenticator.Authenticate(metadata.NewIncomingContext(peerContext(nil), md))
+		require.ErrorIs(t, err, ErrMissingClientCertificate)
+	})
+}
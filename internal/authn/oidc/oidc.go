@@ -2,12 +2,14 @@ package oidc
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/MicahParks/keyfunc"
@@ -15,40 +17,162 @@ import (
 	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"github.com/hashicorp/go-retryablehttp"
 	"github.com/openfga/openfga/internal/authn"
+	"github.com/openfga/openfga/pkg/logger"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
 type RemoteOidcAuthenticator struct {
 	IssuerURL string
-	Audience  string
+
+	// Audiences are the audiences accepted by the authenticator. The 'aud' claim of the tokens must contain at least
+	// one of them.
+	Audiences []string
 
 	JwksURI string
 	JWKs    *keyfunc.JWKS
 
-	httpClient *http.Client
+	httpClient           *http.Client
+	maxResponseSize      int64
+	jwksRefreshInterval  time.Duration
+	jwksRefreshRateLimit time.Duration
+	subjectClaim         string
+
+	// keysLock guards JwksURI and JWKs, which are set after the construction of the authenticator when the keys
+	// are fetched in the background.
+	keysLock           sync.Mutex
+	closed             bool
+	backgroundKeyFetch bool
+	logger             logger.Logger
 }
 
-var (
-	jwkRefreshInterval, _ = time.ParseDuration("48h")
+const (
+	// DefaultMaxResponseSize is the default maximum size, in bytes, of the OIDC configuration and keys read from the issuer.
+	DefaultMaxResponseSize = 1 << 20
+
+	// DefaultSubjectClaim is the default claim that the principal of the requests is read from.
+	DefaultSubjectClaim = "sub"
 
+	// DefaultJWKSRefreshInterval is the default interval at which the keys of the issuer are refreshed in the background.
+	DefaultJWKSRefreshInterval = 48 * time.Hour
+
+	// jwksRefreshRateLimit bounds how often the keys are refreshed because of tokens signed with an unknown key, so
+	// that such tokens can't be used to hammer the issuer.
+	jwksRefreshRateLimit = time.Minute
+)
+
+var (
 	errInvalidAudience = status.Error(codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_audience), "invalid audience")
 	errInvalidClaims   = status.Error(codes.Code(openfgapb.AuthErrorCode_invalid_claims), "invalid claims")
 	errInvalidIssuer   = status.Error(codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_issuer), "invalid issuer")
 	errInvalidSubject  = status.Error(codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_subject), "invalid subject")
 	errInvalidToken    = status.Error(codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_bearer_token), "invalid bearer token")
+	errKeysUnavailable = status.Error(codes.Unavailable, "the keys of the OIDC issuer are unavailable")
 )
 
 var _ authn.Authenticator = (*RemoteOidcAuthenticator)(nil)
 var _ authn.OIDCAuthenticator = (*RemoteOidcAuthenticator)(nil)
 
-func NewRemoteOidcAuthenticator(issuerURL, audience string) (*RemoteOidcAuthenticator, error) {
+// Option configures a RemoteOidcAuthenticator.
+type Option func(*RemoteOidcAuthenticator)
+
+// WithTLSConfig sets the TLS configuration of the client that fetches the OIDC configuration and keys from the
+// issuer, e.g. to trust the private CA of an internal issuer.
+func WithTLSConfig(tlsConfig *tls.Config) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+
+		client := retryablehttp.NewClient()
+		client.HTTPClient = &http.Client{Transport: transport}
+		oidc.httpClient = client.StandardClient()
+	}
+}
+
+// WithMaxResponseSize bounds the size, in bytes, of the OIDC configuration and keys read from the issuer, so that a
+// hostile or misconfigured issuer can't exhaust the memory of the server. If it is not greater than 0,
+// DefaultMaxResponseSize is used.
+func WithMaxResponseSize(maxResponseSize int64) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		if maxResponseSize > 0 {
+			oidc.maxResponseSize = maxResponseSize
+		}
+	}
+}
+
+// WithJWKSRefreshInterval sets the interval at which the keys of the issuer are refreshed in the background. If it is
+// not greater than 0, DefaultJWKSRefreshInterval is used.
+func WithJWKSRefreshInterval(interval time.Duration) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		if interval > 0 {
+			oidc.jwksRefreshInterval = interval
+		}
+	}
+}
+
+// WithSubjectClaim reads the principal of the requests from the claim instead of DefaultSubjectClaim, e.g. when the
+// issuer sets the identity of the user in a custom claim. Unlike DefaultSubjectClaim, which is optional, the tokens
+// that don't have the claim are rejected. If it is empty, DefaultSubjectClaim is used.
+func WithSubjectClaim(claim string) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		if claim != "" {
+			oidc.subjectClaim = claim
+		}
+	}
+}
+
+// WithAudiences accepts the tokens issued for any of the audiences, in addition to the audience the authenticator
+// was constructed with, e.g. when the issuer sets a different audience depending on the caller.
+func WithAudiences(audiences ...string) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		for _, audience := range audiences {
+			if audience != "" {
+				oidc.Audiences = append(oidc.Audiences, audience)
+			}
+		}
+	}
+}
+
+// WithBackgroundKeyFetch fetches the OIDC configuration and keys of the issuer in the background instead of
+// failing the construction of the authenticator when they can't be fetched. The first authenticated request
+// doesn't pay for the fetch if it succeeds. If it fails, a warning is logged and the keys are fetched by the
+// next request that authenticates.
+func WithBackgroundKeyFetch(logger logger.Logger) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		oidc.backgroundKeyFetch = true
+		oidc.logger = logger
+	}
+}
+
+func NewRemoteOidcAuthenticator(issuerURL, audience string, opts ...Option) (*RemoteOidcAuthenticator, error) {
 	oidc := &RemoteOidcAuthenticator{
-		IssuerURL:  issuerURL,
-		Audience:   audience,
-		httpClient: retryablehttp.NewClient().StandardClient(),
+		IssuerURL:            issuerURL,
+		httpClient:           retryablehttp.NewClient().StandardClient(),
+		maxResponseSize:      DefaultMaxResponseSize,
+		jwksRefreshInterval:  DefaultJWKSRefreshInterval,
+		jwksRefreshRateLimit: jwksRefreshRateLimit,
+		subjectClaim:         DefaultSubjectClaim,
+	}
+	if audience != "" {
+		oidc.Audiences = []string{audience}
+	}
+
+	for _, opt := range opts {
+		opt(oidc)
+	}
+
+	if oidc.backgroundKeyFetch {
+		go func() {
+			if _, err := oidc.keys(); err != nil {
+				oidc.logger.Warn("failed to fetch the OIDC keys at startup, they will be fetched by the first authenticated request", zap.Error(err))
+			}
+		}()
+
+		return oidc, nil
 	}
+
 	err := oidc.fetchKeys()
 	if err != nil {
 		return nil, err
@@ -62,10 +186,15 @@ func (oidc *RemoteOidcAuthenticator) Authenticate(requestContext context.Context
 		return nil, authn.ErrMissingBearerToken
 	}
 
+	jwks, err := oidc.keys()
+	if err != nil {
+		return nil, errKeysUnavailable
+	}
+
 	jwtParser := jwt.NewParser(jwt.WithValidMethods([]string{"RS256"}))
 
 	token, err := jwtParser.Parse(authHeader, func(token *jwt.Token) (any, error) {
-		return oidc.JWKs.Keyfunc(token)
+		return jwks.Keyfunc(token)
 	})
 	if err != nil {
 		return nil, errInvalidToken
@@ -84,16 +213,18 @@ func (oidc *RemoteOidcAuthenticator) Authenticate(requestContext context.Context
 		return nil, errInvalidIssuer
 	}
 
-	if ok := claims.VerifyAudience(oidc.Audience, true); !ok {
+	if ok := oidc.verifyAudience(claims); !ok {
 		return nil, errInvalidAudience
 	}
 
-	// optional subject
+	// the subject is optional, unless it is read from a custom claim
 	var subject = ""
-	if subjectClaim, ok := claims["sub"]; ok {
+	if subjectClaim, ok := claims[oidc.subjectClaim]; ok {
 		if subject, ok = subjectClaim.(string); !ok {
 			return nil, errInvalidSubject
 		}
+	} else if oidc.subjectClaim != DefaultSubjectClaim {
+		return nil, status.Errorf(codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_subject), "missing subject claim '%s'", oidc.subjectClaim)
 	}
 
 	principal := &authn.AuthClaims{
@@ -114,6 +245,35 @@ func (oidc *RemoteOidcAuthenticator) Authenticate(requestContext context.Context
 	return principal, nil
 }
 
+// verifyAudience reports whether the 'aud' claim contains one of the accepted audiences.
+func (oidc *RemoteOidcAuthenticator) verifyAudience(claims jwt.MapClaims) bool {
+	for _, audience := range oidc.Audiences {
+		if claims.VerifyAudience(audience, true) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keys returns the keys of the issuer, fetching them first if they haven't been fetched yet.
+func (oidc *RemoteOidcAuthenticator) keys() (*keyfunc.JWKS, error) {
+	oidc.keysLock.Lock()
+	defer oidc.keysLock.Unlock()
+
+	if oidc.JWKs == nil {
+		if oidc.closed {
+			return nil, errors.New("the authenticator is closed")
+		}
+
+		if err := oidc.fetchKeys(); err != nil {
+			return nil, err
+		}
+	}
+
+	return oidc.JWKs, nil
+}
+
 func (oidc *RemoteOidcAuthenticator) fetchKeys() error {
 	oidcConfig, err := oidc.GetConfiguration()
 	if err != nil {
@@ -132,10 +292,29 @@ func (oidc *RemoteOidcAuthenticator) fetchKeys() error {
 	return nil
 }
 
+// GetKeys fetches the keys of the issuer. The keys are cached and refreshed in the background on the refresh
+// interval, and right away, at most once per jwksRefreshRateLimit, when a token is signed with a key that isn't
+// known yet, e.g. after the issuer rotated its keys. If a refresh fails, the last keys fetched keep being used.
 func (oidc *RemoteOidcAuthenticator) GetKeys() (*keyfunc.JWKS, error) {
 	jwks, err := keyfunc.Get(oidc.JwksURI, keyfunc.Options{
-		Client:          oidc.httpClient,
-		RefreshInterval: jwkRefreshInterval,
+		Client:            oidc.httpClient,
+		RefreshInterval:   oidc.jwksRefreshInterval,
+		RefreshRateLimit:  oidc.jwksRefreshRateLimit,
+		RefreshUnknownKID: true,
+		RefreshErrorHandler: func(err error) {
+			if oidc.logger != nil {
+				oidc.logger.Warn("failed to refresh the OIDC keys, the last keys fetched are still used", zap.Error(err))
+			}
+		},
+		ResponseExtractor: func(ctx context.Context, res *http.Response) (json.RawMessage, error) {
+			defer res.Body.Close()
+
+			if res.StatusCode != http.StatusOK {
+				return nil, fmt.Errorf("%w: %d", keyfunc.ErrInvalidHTTPStatusCode, res.StatusCode)
+			}
+
+			return oidc.readBody(res.Body)
+		},
 	})
 	if err != nil {
 		return nil, fmt.Errorf("error fetching keys from %v: %w", oidc.JwksURI, err)
@@ -160,9 +339,9 @@ func (oidc *RemoteOidcAuthenticator) GetConfiguration() (*authn.OidcConfig, erro
 		return nil, fmt.Errorf("unexpected status code getting OIDC: %v", res.StatusCode)
 	}
 
-	body, err := io.ReadAll(res.Body)
+	body, err := oidc.readBody(res.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error reading response body: %w", err)
+		return nil, err
 	}
 
 	oidcConfig := &authn.OidcConfig{}
@@ -180,6 +359,26 @@ func (oidc *RemoteOidcAuthenticator) GetConfiguration() (*authn.OidcConfig, erro
 	return oidcConfig, nil
 }
 
+// readBody reads a response body from the issuer, failing if it is larger than the maximum response size.
+func (oidc *RemoteOidcAuthenticator) readBody(body io.Reader) ([]byte, error) {
+	b, err := io.ReadAll(io.LimitReader(body, oidc.maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading response body: %w", err)
+	}
+
+	if int64(len(b)) > oidc.maxResponseSize {
+		return nil, fmt.Errorf("the response exceeds the maximum size of %d bytes", oidc.maxResponseSize)
+	}
+
+	return b, nil
+}
+
 func (oidc *RemoteOidcAuthenticator) Close() {
-	oidc.JWKs.EndBackground()
+	oidc.keysLock.Lock()
+	defer oidc.keysLock.Unlock()
+
+	oidc.closed = true
+	if oidc.JWKs != nil {
+		oidc.JWKs.EndBackground()
+	}
 }
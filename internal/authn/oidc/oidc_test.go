@@ -0,0 +1,329 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestMaxResponseSize(t *testing.T) {
+	const maxResponseSize = 1024
+
+	oversized := strings.Repeat("a", maxResponseSize)
+
+	newIssuer := func(t *testing.T, discoveryPadding, jwksPadding string) *httptest.Server {
+		mux := http.NewServeMux()
+		server := httptest.NewServer(mux)
+		t.Cleanup(server.Close)
+
+		mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"issuer":   server.URL,
+				"jwks_uri": fmt.Sprintf("%s/jwks.json", server.URL),
+				"padding":  discoveryPadding,
+			})
+		})
+		mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"keys":    []map[string]string{},
+				"padding": jwksPadding,
+			})
+		})
+
+		return server
+	}
+
+	t.Run("within_the_limit", func(t *testing.T) {
+		issuer := newIssuer(t, "", "")
+
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithMaxResponseSize(maxResponseSize))
+		require.NoError(t, err)
+		authenticator.Close()
+	})
+
+	t.Run("oversized_discovery_document", func(t *testing.T) {
+		issuer := newIssuer(t, oversized, "")
+
+		_, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithMaxResponseSize(maxResponseSize))
+		require.ErrorContains(t, err, "error fetching OIDC configuration: the response exceeds the maximum size of 1024 bytes")
+	})
+
+	t.Run("oversized_jwks", func(t *testing.T) {
+		issuer := newIssuer(t, "", oversized)
+
+		_, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithMaxResponseSize(maxResponseSize))
+		require.ErrorContains(t, err, "the response exceeds the maximum size of 1024 bytes")
+	})
+}
+
+func TestBackgroundKeyFetch(t *testing.T) {
+	var available atomic.Bool
+	var discoveryRequests atomic.Int32
+
+	mux := http.NewServeMux()
+	issuer := httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		discoveryRequests.Add(1)
+
+		// not found isn't retried by the client, unlike server errors
+		if !available.Load() {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL,
+			"jwks_uri": fmt.Sprintf("%s/jwks.json", issuer.URL),
+		})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{}})
+	})
+
+	authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithBackgroundKeyFetch(logger.NewNoopLogger()))
+	require.NoError(t, err)
+	t.Cleanup(authenticator.Close)
+
+	// the fetch at startup fails and is not retried until the keys are needed
+	require.Eventually(t, func() bool { return discoveryRequests.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	_, err = authenticator.keys()
+	require.ErrorContains(t, err, "error fetching OIDC configuration")
+
+	available.Store(true)
+
+	jwks, err := authenticator.keys()
+	require.NoError(t, err)
+	require.NotNil(t, jwks)
+	require.EqualValues(t, 3, discoveryRequests.Load())
+
+	// the keys are cached once fetched
+	_, err = authenticator.keys()
+	require.NoError(t, err)
+	require.EqualValues(t, 3, discoveryRequests.Load())
+}
+
+// withJWKSRefreshRateLimit overrides jwksRefreshRateLimit, so that the tests can refresh the keys more than once.
+func withJWKSRefreshRateLimit(rateLimit time.Duration) Option {
+	return func(oidc *RemoteOidcAuthenticator) {
+		oidc.jwksRefreshRateLimit = rateLimit
+	}
+}
+
+// rotatingIssuer is an OIDC issuer whose keys can be rotated, and whose keys endpoint can be made to fail.
+type rotatingIssuer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	keys         map[string]*rsa.PrivateKey
+	unavailable  bool
+	jwksRequests atomic.Int32
+}
+
+func newRotatingIssuer(t *testing.T) *rotatingIssuer {
+	issuer := &rotatingIssuer{keys: map[string]*rsa.PrivateKey{}}
+
+	mux := http.NewServeMux()
+	issuer.Server = httptest.NewServer(mux)
+	t.Cleanup(issuer.Close)
+
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   issuer.URL,
+			"jwks_uri": fmt.Sprintf("%s/jwks.json", issuer.URL),
+		})
+	})
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+		issuer.jwksRequests.Add(1)
+
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+
+		// not found isn't retried by the client, unlike server errors
+		if issuer.unavailable {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		keys := []map[string]string{}
+		for kid, key := range issuer.keys {
+			keys = append(keys, map[string]string{
+				"kid": kid,
+				"kty": "RSA",
+				"n":   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": keys})
+	})
+
+	return issuer
+}
+
+// rotate makes the issuer publish a new key with the kid, in place of the previous ones, and returns a token
+// signed with it.
+func (issuer *rotatingIssuer) rotate(t *testing.T, kid string) string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	issuer.mu.Lock()
+	issuer.keys = map[string]*rsa.PrivateKey{kid: key}
+	issuer.mu.Unlock()
+
+	return issuer.sign(t, kid, jwt.MapClaims{"sub": "some-user"})
+}
+
+// sign returns a token with the claims, in addition to the issuer and the audience, signed with the key of the kid.
+func (issuer *rotatingIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	issuer.mu.Lock()
+	key := issuer.keys[kid]
+	issuer.mu.Unlock()
+
+	claims["iss"] = issuer.URL
+	claims["aud"] = "openfga"
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func (issuer *rotatingIssuer) setUnavailable(unavailable bool) {
+	issuer.mu.Lock()
+	defer issuer.mu.Unlock()
+
+	issuer.unavailable = unavailable
+}
+
+func bearerContext(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func TestJWKSRefresh(t *testing.T) {
+	t.Run("refreshes_on_unknown_kid", func(t *testing.T) {
+		issuer := newRotatingIssuer(t)
+		firstToken := issuer.rotate(t, "1")
+
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", withJWKSRefreshRateLimit(0))
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		_, err = authenticator.Authenticate(bearerContext(firstToken))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, issuer.jwksRequests.Load())
+
+		// the keys are cached
+		_, err = authenticator.Authenticate(bearerContext(firstToken))
+		require.NoError(t, err)
+		require.EqualValues(t, 1, issuer.jwksRequests.Load())
+
+		rotatedToken := issuer.rotate(t, "2")
+
+		claims, err := authenticator.Authenticate(bearerContext(rotatedToken))
+		require.NoError(t, err)
+		require.Equal(t, "some-user", claims.Subject)
+		require.EqualValues(t, 2, issuer.jwksRequests.Load())
+	})
+
+	t.Run("keeps_the_last_keys_when_the_refresh_fails", func(t *testing.T) {
+		issuer := newRotatingIssuer(t)
+		firstToken := issuer.rotate(t, "1")
+
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", withJWKSRefreshRateLimit(0))
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		issuer.setUnavailable(true)
+		issuer.mu.Lock()
+		key := issuer.keys["1"]
+		issuer.mu.Unlock()
+
+		// a token signed with an unknown key triggers a refresh, which fails
+		unknownKeyToken := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Issuer:   issuer.URL,
+			Audience: []string{"openfga"},
+		})
+		unknownKeyToken.Header["kid"] = "2"
+		signed, err := unknownKeyToken.SignedString(key)
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(bearerContext(signed))
+		require.ErrorIs(t, err, errInvalidToken)
+		require.EqualValues(t, 2, issuer.jwksRequests.Load())
+
+		_, err = authenticator.Authenticate(bearerContext(firstToken))
+		require.NoError(t, err)
+	})
+
+	t.Run("refreshes_on_the_interval", func(t *testing.T) {
+		issuer := newRotatingIssuer(t)
+		issuer.rotate(t, "1")
+
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithJWKSRefreshInterval(50*time.Millisecond))
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		require.Eventually(t, func() bool { return issuer.jwksRequests.Load() >= 3 }, 5*time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSubjectClaim(t *testing.T) {
+	issuer := newRotatingIssuer(t)
+	issuer.rotate(t, "1")
+
+	t.Run("default_claim", func(t *testing.T) {
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga")
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		claims, err := authenticator.Authenticate(bearerContext(issuer.sign(t, "1", jwt.MapClaims{"sub": "some-user"})))
+		require.NoError(t, err)
+		require.Equal(t, "some-user", claims.Subject)
+
+		// the default claim is optional
+		claims, err = authenticator.Authenticate(bearerContext(issuer.sign(t, "1", jwt.MapClaims{})))
+		require.NoError(t, err)
+		require.Empty(t, claims.Subject)
+	})
+
+	t.Run("custom_claim", func(t *testing.T) {
+		authenticator, err := NewRemoteOidcAuthenticator(issuer.URL, "openfga", WithSubjectClaim("email"))
+		require.NoError(t, err)
+		t.Cleanup(authenticator.Close)
+
+		claims, err := authenticator.Authenticate(bearerContext(issuer.sign(t, "1", jwt.MapClaims{"sub": "opaque-id", "email": "jane@openfga.dev"})))
+		require.NoError(t, err)
+		require.Equal(t, "jane@openfga.dev", claims.Subject)
+
+		_, err = authenticator.Authenticate(bearerContext(issuer.sign(t, "1", jwt.MapClaims{"sub": "opaque-id"})))
+		require.Equal(t, codes.Code(openfgapb.AuthErrorCode_auth_failed_invalid_subject), status.Code(err))
+		require.Equal(t, "missing subject claim 'email'", status.Convert(err).Message())
+
+		_, err = authenticator.Authenticate(bearerContext(issuer.sign(t, "1", jwt.MapClaims{"email": 42})))
+		require.ErrorIs(t, err, errInvalidSubject)
+	})
+}
@@ -2,28 +2,87 @@ package presharedkey
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"errors"
+	"sync"
 
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
 	"github.com/openfga/openfga/internal/authn"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// UnlabeledKey is the label of the keys that weren't given one.
+const UnlabeledKey = "unlabeled"
+
+var authenticationsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "preshared_key_authentication_count",
+	Help: "The number of requests authenticated with a preshared key, partitioned by the label of the key. A key can be removed safely once its count stops increasing",
+}, []string{"key_label"})
+
+// hashedKey is the SHA-256 hash of a valid key, so that the keys themselves aren't kept by the authenticator.
+type hashedKey struct {
+	hash  [sha256.Size]byte
+	label string
+}
+
 type PresharedKeyAuthenticator struct {
-	ValidKeys map[string]struct{}
+	// validKeysLock guards validKeys, which are replaced by UpdateKeys.
+	validKeysLock sync.RWMutex
+	validKeys     []hashedKey
 }
 
 var _ authn.Authenticator = (*PresharedKeyAuthenticator)(nil)
 
-func NewPresharedKeyAuthenticator(validKeys []string) (*PresharedKeyAuthenticator, error) {
+// NewPresharedKeyAuthenticator returns an authenticator that accepts any of the validKeys as a bearer token. If
+// labels is not empty, it must have a label for each key, at the same index, which partitions the authentication
+// metric so that the keys that are still in use while rotating them can be observed.
+func NewPresharedKeyAuthenticator(validKeys []string, labels []string) (*PresharedKeyAuthenticator, error) {
+	vKeys, err := hashKeys(validKeys, labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PresharedKeyAuthenticator{validKeys: vKeys}, nil
+}
+
+// UpdateKeys replaces the valid keys and their labels, e.g. to add a new key and retire an old one without
+// restarting the server. The requests that were authenticated before the update aren't affected. If the keys are
+// invalid, the current keys are kept.
+func (pka *PresharedKeyAuthenticator) UpdateKeys(validKeys []string, labels []string) error {
+	vKeys, err := hashKeys(validKeys, labels)
+	if err != nil {
+		return err
+	}
+
+	pka.validKeysLock.Lock()
+	defer pka.validKeysLock.Unlock()
+
+	pka.validKeys = vKeys
+
+	return nil
+}
+
+func hashKeys(validKeys []string, labels []string) ([]hashedKey, error) {
 	if len(validKeys) < 1 {
 		return nil, errors.New("invalid auth configuration, please specify at least one key")
 	}
-	vKeys := make(map[string]struct{})
-	for _, k := range validKeys {
-		vKeys[k] = struct{}{}
+	if len(labels) > 0 && len(labels) != len(validKeys) {
+		return nil, errors.New("invalid auth configuration, please specify a label for each key or none at all")
 	}
 
-	return &PresharedKeyAuthenticator{ValidKeys: vKeys}, nil
+	vKeys := make([]hashedKey, 0, len(validKeys))
+	for i, k := range validKeys {
+		label := UnlabeledKey
+		if len(labels) > 0 && labels[i] != "" {
+			label = labels[i]
+		}
+
+		vKeys = append(vKeys, hashedKey{hash: sha256.Sum256([]byte(k)), label: label})
+	}
+
+	return vKeys, nil
 }
 
 func (pka *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (*authn.AuthClaims, error) {
@@ -32,7 +91,9 @@ func (pka *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (*authn.
 		return nil, authn.ErrMissingBearerToken
 	}
 
-	if _, found := pka.ValidKeys[authHeader]; found {
+	if label, found := pka.match(authHeader); found {
+		authenticationsCounter.WithLabelValues(label).Inc()
+
 		return &authn.AuthClaims{
 			Subject: "", // no user information in this auth method
 		}, nil
@@ -41,4 +102,23 @@ func (pka *PresharedKeyAuthenticator) Authenticate(ctx context.Context) (*authn.
 	return nil, authn.ErrUnauthenticated
 }
 
+// match returns the label of the valid key that the token is, if any. The hash of the token is compared in constant
+// time with the hash of every valid key, so that the time it takes doesn't tell how close the token is to a key.
+func (pka *PresharedKeyAuthenticator) match(token string) (string, bool) {
+	hash := sha256.Sum256([]byte(token))
+
+	pka.validKeysLock.RLock()
+	validKeys := pka.validKeys
+	pka.validKeysLock.RUnlock()
+
+	label, found := "", false
+	for _, key := range validKeys {
+		if subtle.ConstantTimeCompare(hash[:], key.hash[:]) == 1 && !found {
+			label, found = key.label, true
+		}
+	}
+
+	return label, found
+}
+
 func (pka *PresharedKeyAuthenticator) Close() {}
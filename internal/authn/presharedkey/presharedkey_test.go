@@ -0,0 +1,97 @@
+package presharedkey
+
+import (
+	"context"
+	"crypto/sha256"
+	"testing"
+
+	"github.com/openfga/openfga/internal/authn"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func bearerContext(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+key))
+}
+
+func TestAuthenticateCountsByKeyLabel(t *testing.T) {
+	authenticator, err := NewPresharedKeyAuthenticator([]string{"old-key", "new-key", "other-key"}, []string{"2023-q1", "2023-q2", ""})
+	require.NoError(t, err)
+
+	oldBefore := testutil.ToFloat64(authenticationsCounter.WithLabelValues("2023-q1"))
+	newBefore := testutil.ToFloat64(authenticationsCounter.WithLabelValues("2023-q2"))
+	unlabeledBefore := testutil.ToFloat64(authenticationsCounter.WithLabelValues(UnlabeledKey))
+
+	for _, key := range []string{"new-key", "new-key", "other-key"} {
+		_, err := authenticator.Authenticate(bearerContext(key))
+		require.NoError(t, err)
+	}
+
+	_, err = authenticator.Authenticate(bearerContext("unknown-key"))
+	require.ErrorIs(t, err, authn.ErrUnauthenticated)
+
+	require.Equal(t, oldBefore, testutil.ToFloat64(authenticationsCounter.WithLabelValues("2023-q1")))
+	require.Equal(t, newBefore+2, testutil.ToFloat64(authenticationsCounter.WithLabelValues("2023-q2")))
+	require.Equal(t, unlabeledBefore+1, testutil.ToFloat64(authenticationsCounter.WithLabelValues(UnlabeledKey)))
+}
+
+func TestNewPresharedKeyAuthenticatorRequiresALabelPerKey(t *testing.T) {
+	_, err := NewPresharedKeyAuthenticator([]string{"old-key", "new-key"}, []string{"2023-q1"})
+	require.EqualError(t, err, "invalid auth configuration, please specify a label for each key or none at all")
+
+	authenticator, err := NewPresharedKeyAuthenticator([]string{"old-key", "new-key"}, nil)
+	require.NoError(t, err)
+
+	for _, key := range []string{"old-key", "new-key"} {
+		label, found := authenticator.match(key)
+		require.True(t, found)
+		require.Equal(t, UnlabeledKey, label)
+	}
+}
+
+func TestKeysAreHashed(t *testing.T) {
+	authenticator, err := NewPresharedKeyAuthenticator([]string{"old-key", "new-key"}, []string{"2023-q1", "2023-q2"})
+	require.NoError(t, err)
+
+	require.Equal(t, []hashedKey{
+		{hash: sha256.Sum256([]byte("old-key")), label: "2023-q1"},
+		{hash: sha256.Sum256([]byte("new-key")), label: "2023-q2"},
+	}, authenticator.validKeys)
+
+	label, found := authenticator.match("new-key")
+	require.True(t, found)
+	require.Equal(t, "2023-q2", label)
+
+	for _, token := range []string{"", "new-ke", "new-key ", "NEW-KEY"} {
+		_, found := authenticator.match(token)
+		require.False(t, found, token)
+	}
+}
+
+func TestUpdateKeys(t *testing.T) {
+	authenticator, err := NewPresharedKeyAuthenticator([]string{"old-key"}, nil)
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(bearerContext("old-key"))
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(bearerContext("new-key"))
+	require.ErrorIs(t, err, authn.ErrUnauthenticated)
+
+	err = authenticator.UpdateKeys([]string{"new-key"}, []string{"2023-q2"})
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(bearerContext("new-key"))
+	require.NoError(t, err)
+
+	_, err = authenticator.Authenticate(bearerContext("old-key"))
+	require.ErrorIs(t, err, authn.ErrUnauthenticated)
+
+	// invalid keys are rejected and the current keys are kept
+	err = authenticator.UpdateKeys(nil, nil)
+	require.EqualError(t, err, "invalid auth configuration, please specify at least one key")
+
+	_, err = authenticator.Authenticate(bearerContext("new-key"))
+	require.NoError(t, err)
+}
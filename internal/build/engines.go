@@ -0,0 +1,34 @@
+package build
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// PermittedDatastoreEngines is the comma-separated list of the datastore engines the app is permitted to use
+// (e.g. 'postgres,mysql'). It is empty by default, which permits all of them. Set it at build time with
+// '-ldflags "-X github.com/openfga/openfga/internal/build.PermittedDatastoreEngines=postgres"' to enforce platform
+// standards in binaries that can't be reconfigured to use another engine.
+var PermittedDatastoreEngines = ""
+
+// ErrDatastoreEngineNotPermitted is returned when a datastore engine is not in PermittedDatastoreEngines.
+var ErrDatastoreEngineNotPermitted = errors.New("datastore engine not permitted")
+
+// CheckDatastoreEngine returns an error wrapping ErrDatastoreEngineNotPermitted if the engine is not in
+// PermittedDatastoreEngines.
+func CheckDatastoreEngine(engine string) error {
+	if PermittedDatastoreEngines == "" {
+		return nil
+	}
+
+	permitted := strings.Split(PermittedDatastoreEngines, ",")
+	for i, permittedEngine := range permitted {
+		permitted[i] = strings.TrimSpace(permittedEngine)
+		if permitted[i] == engine {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: '%s' is not one of the engines permitted by this build %v", ErrDatastoreEngineNotPermitted, engine, permitted)
+}
@@ -0,0 +1,29 @@
+package build
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDatastoreEngine(t *testing.T) {
+	t.Run("all_engines_permitted_by_default", func(t *testing.T) {
+		require.NoError(t, CheckDatastoreEngine("memory"))
+		require.NoError(t, CheckDatastoreEngine("postgres"))
+	})
+
+	t.Run("allowlist", func(t *testing.T) {
+		permittedDatastoreEngines := PermittedDatastoreEngines
+		t.Cleanup(func() {
+			PermittedDatastoreEngines = permittedDatastoreEngines
+		})
+		PermittedDatastoreEngines = "postgres, mysql"
+
+		require.NoError(t, CheckDatastoreEngine("postgres"))
+		require.NoError(t, CheckDatastoreEngine("mysql"))
+
+		err := CheckDatastoreEngine("memory")
+		require.ErrorIs(t, err, ErrDatastoreEngineNotPermitted)
+		require.EqualError(t, err, "datastore engine not permitted: 'memory' is not one of the engines permitted by this build [postgres mysql]")
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/openfga/openfga/internal/validation"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
@@ -16,7 +17,10 @@ import (
 	"go.opentelemetry.io/otel/trace"
 )
 
-var tracer = otel.Tracer("internal/graph/check")
+var (
+	tracer             = otel.Tracer("internal/graph/check")
+	componentAttribute = telemetry.ComponentKey.String("resolver")
+)
 
 // CheckResolver represents an interface that can be implemented to provide recursive resolution
 // of a Check.
@@ -333,7 +337,7 @@ func (c *LocalChecker) ResolveCheck(
 	ctx context.Context,
 	req *ResolveCheckRequest,
 ) (*ResolveCheckResponse, error) {
-	ctx, span := tracer.Start(ctx, "ResolveCheck")
+	ctx, span := tracer.Start(ctx, "ResolveCheck", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	span.SetAttributes(attribute.String("tuple_key", req.GetTupleKey().String()))
@@ -356,11 +360,17 @@ func (c *LocalChecker) ResolveCheck(
 		return nil, fmt.Errorf("relation '%s' undefined for object type '%s'", relation, objectType)
 	}
 
+	ctx, traceNode := startCheckTraceNode(ctx, req.GetTupleKey())
+
 	resp, err := union(ctx, c.concurrencyLimit, c.checkRewrite(ctx, req, rel.GetRewrite()))
 	if err != nil {
 		return nil, err
 	}
 
+	if traceNode != nil {
+		traceNode.setAllowed(resp.Allowed)
+	}
+
 	return &ResolveCheckResponse{
 		Allowed: resp.Allowed,
 	}, nil
@@ -404,6 +414,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 
 			if t != nil && err == nil {
 				span.SetAttributes(attribute.Bool("allowed", true))
+				recordCheckTraceMatch(ctx, CheckTraceRuleDirect, tk, tk)
 				return &openfgapb.CheckResponse{Allowed: true}, nil
 			}
 			return &openfgapb.CheckResponse{Allowed: false}, nil
@@ -467,6 +478,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				// for 1.0 models, if the user is '*' then we're done searching
 				if usersetObject == tuple.Wildcard && typesys.GetSchemaVersion() == typesystem.SchemaVersion1_0 {
 					span.SetAttributes(attribute.Bool("allowed", true))
+					recordCheckTraceMatch(ctx, CheckTraceRuleWildcard, tk, t)
 					return &openfgapb.CheckResponse{Allowed: true}, nil
 				}
 
@@ -478,6 +490,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 
 					if tuple.GetType(tk.GetUser()) == wildcardType {
 						span.SetAttributes(attribute.Bool("allowed", true))
+						recordCheckTraceMatch(ctx, CheckTraceRuleWildcard, tk, t)
 						return &openfgapb.CheckResponse{Allowed: true}, nil
 					}
 
@@ -485,7 +498,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 				}
 
 				if usersetRelation != "" {
-					handlers = append(handlers, c.dispatch(
+					handlers = append(handlers, withCheckTraceVia(CheckTraceRuleUserset, t, c.dispatch(
 						ctx,
 						&ResolveCheckRequest{
 							StoreID:              storeID,
@@ -494,7 +507,7 @@ func (c *LocalChecker) checkDirect(parentctx context.Context, req *ResolveCheckR
 							ResolutionMetadata: &ResolutionMetadata{
 								Depth: req.GetResolutionMetadata().Depth - 1,
 							},
-						}))
+						})))
 				}
 			}
 
@@ -518,6 +531,8 @@ func (c *LocalChecker) checkComputedUserset(parentctx context.Context, req *Reso
 		ctx, span := tracer.Start(ctx, "checkComputedUserset")
 		defer span.End()
 
+		ctx = contextWithCheckTraceVia(ctx, CheckTraceRuleComputedUserset, nil)
+
 		return c.dispatch(
 			ctx,
 			&ResolveCheckRequest{
@@ -601,7 +616,7 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 				}
 			}
 
-			handlers = append(handlers, c.dispatch(
+			handlers = append(handlers, withCheckTraceVia(CheckTraceRuleTupleToUserset, t, c.dispatch(
 				ctx,
 				&ResolveCheckRequest{
 					StoreID:              req.GetStoreID(),
@@ -610,7 +625,7 @@ func (c *LocalChecker) checkTTU(parentctx context.Context, req *ResolveCheckRequ
 					ResolutionMetadata: &ResolutionMetadata{
 						Depth: req.GetResolutionMetadata().Depth - 1,
 					},
-				}))
+				})))
 		}
 
 		if len(handlers) == 0 {
@@ -657,7 +672,32 @@ func (c *LocalChecker) checkSetOperation(
 		ctx, span := tracer.Start(ctx, reducerKey)
 		defer span.End()
 
-		return reducer(ctx, c.concurrencyLimit, handlers...)
+		traceNode := checkTraceNodeFromContext(ctx)
+		if traceNode == nil {
+			return reducer(ctx, c.concurrencyLimit, handlers...)
+		}
+
+		// each operand is traced on its own, and only the operands that contributed to an allowed result are
+		// added to the trace: all of them for a union or an intersection, and only the base of an exclusion
+		traceGroups := make([]*CheckTraceNode, len(handlers))
+		tracedHandlers := make([]CheckHandlerFunc, len(handlers))
+		for i, handler := range handlers {
+			traceGroups[i] = &CheckTraceNode{}
+			tracedHandlers[i] = withCheckTraceNode(traceGroups[i], handler)
+		}
+
+		resp, err := reducer(ctx, c.concurrencyLimit, tracedHandlers...)
+		if err == nil && resp.GetAllowed() {
+			if setOpType == exclusionSetOperator {
+				traceGroups = traceGroups[:1]
+			}
+
+			for _, group := range traceGroups {
+				traceNode.adopt(group)
+			}
+		}
+
+		return resp, err
 	}
 }
 
@@ -119,3 +119,103 @@ func TestCheckWithOneConcurrentGoroutineCausesNoDeadlock(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, resp.Allowed)
 }
+
+func TestCheckEvaluatesWithTheSchemaVersionOfTheModel(t *testing.T) {
+	ds := memory.New()
+	defer ds.Close()
+
+	storeID := ulid.Make().String()
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "*"),
+		tuple.NewTupleKey("document:2", "viewer", "user:*"),
+	})
+	require.NoError(t, err)
+
+	checker := NewLocalChecker(ds, 100)
+
+	model1_0 := &openfgav1.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_0,
+		TypeDefinitions: []*openfgav1.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "employee",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgav1.Userset{
+					"viewer": typesystem.This(),
+				},
+			},
+		},
+	}
+
+	model1_1 := &openfgav1.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+		type employee
+		type document
+		  relations
+		    define viewer: [user, user:*] as self
+		`),
+	}
+
+	tests := []struct {
+		name     string
+		model    *openfgav1.AuthorizationModel
+		tuple    *openfgav1.TupleKey
+		expected bool
+	}{
+		// in 1.0 models, the untyped wildcard relates every user, typed or not
+		{
+			name:     "1.0_untyped_wildcard_relates_untyped_user",
+			model:    model1_0,
+			tuple:    tuple.NewTupleKey("document:1", "viewer", "anne"),
+			expected: true,
+		},
+		{
+			name:     "1.0_untyped_wildcard_relates_user_of_any_type",
+			model:    model1_0,
+			tuple:    tuple.NewTupleKey("document:1", "viewer", "employee:bob"),
+			expected: true,
+		},
+		// in 1.1 models, a typed wildcard only relates users of its type, and the untyped wildcard is ignored
+		{
+			name:     "1.1_typed_wildcard_relates_user_of_its_type",
+			model:    model1_1,
+			tuple:    tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+			expected: true,
+		},
+		{
+			name:     "1.1_typed_wildcard_does_not_relate_user_of_another_type",
+			model:    model1_1,
+			tuple:    tuple.NewTupleKey("document:2", "viewer", "employee:bob"),
+			expected: false,
+		},
+		{
+			name:     "1.1_untyped_wildcard_is_ignored",
+			model:    model1_1,
+			tuple:    tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := typesystem.ContextWithTypesystem(context.Background(), typesystem.New(test.model))
+
+			resp, err := checker.ResolveCheck(ctx, &ResolveCheckRequest{
+				StoreID:            storeID,
+				TupleKey:           test.tuple,
+				ResolutionMetadata: &ResolutionMetadata{Depth: 25},
+			})
+			require.NoError(t, err)
+			require.Equal(t, test.expected, resp.Allowed)
+		})
+	}
+}
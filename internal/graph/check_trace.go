@@ -0,0 +1,242 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/openfga/openfga/pkg/tuple"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+// The rules that lead from a CheckTraceNode to its children.
+const (
+	// CheckTraceRuleCheck is the rule of the node of the tuple key of the Check request.
+	CheckTraceRuleCheck = "check"
+
+	// CheckTraceRuleDirect is the rule of a tuple that matches the 'object#relation@user' of its parent.
+	CheckTraceRuleDirect = "direct"
+
+	// CheckTraceRuleWildcard is the rule of a wildcard tuple that matches the user of its parent.
+	CheckTraceRuleWildcard = "wildcard"
+
+	// CheckTraceRuleUserset is the rule of a node reached through a tuple whose user is a userset, such as
+	// 'document:1#viewer@group:eng#member'.
+	CheckTraceRuleUserset = "userset"
+
+	// CheckTraceRuleComputedUserset is the rule of a node reached through a relation rewritten as another
+	// relation of the same object, such as 'define viewer: editor'.
+	CheckTraceRuleComputedUserset = "computed_userset"
+
+	// CheckTraceRuleTupleToUserset is the rule of a node reached through a tupleset tuple, such as
+	// 'document:1#parent@folder:x' for 'define viewer: viewer from parent'.
+	CheckTraceRuleTupleToUserset = "tuple_to_userset"
+)
+
+type checkTraceCtxKey struct{}
+
+type checkTraceNodeCtxKey struct{}
+
+type checkTraceViaCtxKey struct{}
+
+type checkTraceVia struct {
+	rule  string
+	tuple *openfgapb.TupleKey
+}
+
+// CheckTraceNode is a step of the resolution of a Check: the 'object#relation@user' that was evaluated, the rule
+// that led to it from its parent and, if the rule followed a tuple, that tuple.
+type CheckTraceNode struct {
+	Rule     string            `json:"rule"`
+	TupleKey string            `json:"tuple_key"`
+	Tuple    string            `json:"tuple,omitempty"`
+	Children []*CheckTraceNode `json:"children,omitempty"`
+
+	mu      sync.Mutex
+	allowed bool
+}
+
+func (n *CheckTraceNode) addChild(child *CheckTraceNode) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Children = append(n.Children, child)
+}
+
+func (n *CheckTraceNode) setAllowed(allowed bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.allowed = allowed
+}
+
+func (n *CheckTraceNode) isAllowed() bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	return n.allowed
+}
+
+// adopt moves the children of the provided node to n.
+func (n *CheckTraceNode) adopt(other *CheckTraceNode) {
+	other.mu.Lock()
+	children := other.Children
+	other.Children = nil
+	other.mu.Unlock()
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.Children = append(n.Children, children...)
+}
+
+// allowedPath returns a copy of n with only the children that resolved to allowed.
+func (n *CheckTraceNode) allowedPath() *CheckTraceNode {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	path := &CheckTraceNode{
+		Rule:     n.Rule,
+		TupleKey: n.TupleKey,
+		Tuple:    n.Tuple,
+		allowed:  n.allowed,
+	}
+
+	for _, child := range n.Children {
+		if child.isAllowed() {
+			path.Children = append(path.Children, child.allowedPath())
+		}
+	}
+
+	return path
+}
+
+// CheckTrace records the tuples and the relation rewrites that a Check evaluates, so that the reasons for an
+// allowed result can be explained. Recording adds work to every step of the resolution, so it should only be
+// enabled on request. The depth of the trace is bounded by the depth of the resolution, which is limited by the
+// ResolutionMetadata of the request, and its number of nodes by the limit it was created with.
+type CheckTrace struct {
+	root CheckTraceNode
+
+	maxNodes  uint32
+	nodes     atomic.Uint32
+	truncated atomic.Bool
+}
+
+// NewCheckTrace returns an empty CheckTrace, to be provided to ResolveCheck with ContextWithCheckTrace. The trace
+// records up to maxNodes nodes, the steps of the resolution past that aren't recorded. A maxNodes of 0 means no
+// limit.
+func NewCheckTrace(maxNodes uint32) *CheckTrace {
+	return &CheckTrace{maxNodes: maxNodes}
+}
+
+// ContextWithCheckTrace returns a context that makes ResolveCheck record its resolution in the provided trace.
+func ContextWithCheckTrace(ctx context.Context, trace *CheckTrace) context.Context {
+	ctx = context.WithValue(ctx, checkTraceCtxKey{}, trace)
+	return context.WithValue(ctx, checkTraceNodeCtxKey{}, &trace.root)
+}
+
+// Truncated reports whether steps of the resolution weren't recorded because the trace reached its limit of nodes,
+// in which case the path may be incomplete.
+func (t *CheckTrace) Truncated() bool {
+	return t.truncated.Load()
+}
+
+// reserveCheckTraceNode reports whether one more node can be recorded in the trace of the context.
+func reserveCheckTraceNode(ctx context.Context) bool {
+	t, _ := ctx.Value(checkTraceCtxKey{}).(*CheckTrace)
+	if t == nil || t.maxNodes == 0 {
+		return true
+	}
+
+	if t.nodes.Add(1) > t.maxNodes {
+		t.truncated.Store(true)
+		return false
+	}
+
+	return true
+}
+
+// Path returns the steps of the resolution that led to an allowed result, starting from the tuple key of the
+// Check request. It is empty if the Check resolved to not allowed.
+func (t *CheckTrace) Path() []*CheckTraceNode {
+	return t.root.allowedPath().Children
+}
+
+func checkTraceNodeFromContext(ctx context.Context) *CheckTraceNode {
+	node, _ := ctx.Value(checkTraceNodeCtxKey{}).(*CheckTraceNode)
+	return node
+}
+
+// contextWithCheckTraceVia sets the rule, and the tuple it followed, that leads to the next tuple key resolved
+// with the returned context. It is a no-op if the resolution is not traced.
+func contextWithCheckTraceVia(ctx context.Context, rule string, t *openfgapb.TupleKey) context.Context {
+	if checkTraceNodeFromContext(ctx) == nil {
+		return ctx
+	}
+
+	return context.WithValue(ctx, checkTraceViaCtxKey{}, &checkTraceVia{rule: rule, tuple: t})
+}
+
+// withCheckTraceVia wraps the handler so that the tuple key it resolves is traced as reached through the provided
+// rule and tuple.
+func withCheckTraceVia(rule string, t *openfgapb.TupleKey, handler CheckHandlerFunc) CheckHandlerFunc {
+	return func(ctx context.Context) (*openfgapb.CheckResponse, error) {
+		return handler(contextWithCheckTraceVia(ctx, rule, t))
+	}
+}
+
+// withCheckTraceNode wraps the handler so that what it resolves is traced under the provided node.
+func withCheckTraceNode(node *CheckTraceNode, handler CheckHandlerFunc) CheckHandlerFunc {
+	return func(ctx context.Context) (*openfgapb.CheckResponse, error) {
+		return handler(context.WithValue(ctx, checkTraceNodeCtxKey{}, node))
+	}
+}
+
+// startCheckTraceNode adds a node for the provided tuple key to the trace of the context, if the resolution is
+// traced, and returns a context to resolve the tuple key with. The returned node is nil if the resolution is not
+// traced.
+func startCheckTraceNode(ctx context.Context, tk *openfgapb.TupleKey) (context.Context, *CheckTraceNode) {
+	parent := checkTraceNodeFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+
+	if !reserveCheckTraceNode(ctx) {
+		// the trace is full, so neither the tuple key nor what it leads to are recorded
+		return context.WithValue(ctx, checkTraceNodeCtxKey{}, (*CheckTraceNode)(nil)), nil
+	}
+
+	node := &CheckTraceNode{
+		Rule:     CheckTraceRuleCheck,
+		TupleKey: tuple.TupleKeyToString(tk),
+	}
+
+	if via, ok := ctx.Value(checkTraceViaCtxKey{}).(*checkTraceVia); ok && via != nil {
+		node.Rule = via.rule
+		if via.tuple != nil {
+			node.Tuple = tuple.TupleKeyToString(via.tuple)
+		}
+	}
+
+	parent.addChild(node)
+
+	ctx = context.WithValue(ctx, checkTraceViaCtxKey{}, (*checkTraceVia)(nil))
+
+	return context.WithValue(ctx, checkTraceNodeCtxKey{}, node), node
+}
+
+// recordCheckTraceMatch records that the provided tuple matched the tuple key being resolved.
+func recordCheckTraceMatch(ctx context.Context, rule string, tk, t *openfgapb.TupleKey) {
+	parent := checkTraceNodeFromContext(ctx)
+	if parent == nil || !reserveCheckTraceNode(ctx) {
+		return
+	}
+
+	parent.addChild(&CheckTraceNode{
+		Rule:     rule,
+		TupleKey: tuple.TupleKeyToString(tk),
+		Tuple:    tuple.TupleKeyToString(t),
+		allowed:  true,
+	})
+}
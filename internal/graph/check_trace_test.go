@@ -0,0 +1,159 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	parser "github.com/craigpastro/openfga-dsl-parser/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/require"
+	openfgav1 "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+func TestCheckTrace(t *testing.T) {
+	ds := memory.New()
+	defer ds.Close()
+
+	storeID := ulid.Make().String()
+
+	err := ds.Write(context.Background(), storeID, nil, []*openfgav1.TupleKey{
+		tuple.NewTupleKey("document:1", "parent", "folder:x"),
+		tuple.NewTupleKey("folder:x", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+		tuple.NewTupleKey("document:1", "writer", "user:bob"),
+		tuple.NewTupleKey("document:1", "blocked", "user:bob"),
+		tuple.NewTupleKey("document:1", "writer", "user:charlie"),
+	})
+	require.NoError(t, err)
+
+	checker := NewLocalChecker(ds, 100)
+
+	typedefs := parser.MustParse(`
+	type user
+
+	type group
+	  relations
+	    define member: [user] as self
+
+	type folder
+	  relations
+	    define viewer: [group#member] as self
+
+	type document
+	  relations
+	    define parent: [folder] as self
+	    define blocked: [user] as self
+	    define writer: [user] as self
+	    define editor: writer but not blocked
+	    define viewer: [user] as self or editor or viewer from parent
+	`)
+
+	ctx := typesystem.ContextWithTypesystem(context.Background(), typesystem.New(
+		&openfgav1.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			TypeDefinitions: typedefs,
+			SchemaVersion:   typesystem.SchemaVersion1_1,
+		},
+	))
+
+	tests := []struct {
+		name         string
+		tupleKey     *openfgav1.TupleKey
+		expectedPath string
+	}{
+		{
+			name:     "through_a_parent_and_a_group",
+			tupleKey: tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			expectedPath: `[{
+				"rule": "check",
+				"tuple_key": "document:1#viewer@user:anne",
+				"children": [{
+					"rule": "tuple_to_userset",
+					"tuple_key": "folder:x#viewer@user:anne",
+					"tuple": "document:1#parent@folder:x",
+					"children": [{
+						"rule": "userset",
+						"tuple_key": "group:eng#member@user:anne",
+						"tuple": "folder:x#viewer@group:eng#member",
+						"children": [{
+							"rule": "direct",
+							"tuple_key": "group:eng#member@user:anne",
+							"tuple": "group:eng#member@user:anne"
+						}]
+					}]
+				}]
+			}]`,
+		},
+		{
+			name:     "through_an_exclusion",
+			tupleKey: tuple.NewTupleKey("document:1", "viewer", "user:charlie"),
+			expectedPath: `[{
+				"rule": "check",
+				"tuple_key": "document:1#viewer@user:charlie",
+				"children": [{
+					"rule": "computed_userset",
+					"tuple_key": "document:1#editor@user:charlie",
+					"children": [{
+						"rule": "computed_userset",
+						"tuple_key": "document:1#writer@user:charlie",
+						"children": [{
+							"rule": "direct",
+							"tuple_key": "document:1#writer@user:charlie",
+							"tuple": "document:1#writer@user:charlie"
+						}]
+					}]
+				}]
+			}]`,
+		},
+		{
+			name:         "not_allowed",
+			tupleKey:     tuple.NewTupleKey("document:1", "viewer", "user:bob"),
+			expectedPath: `null`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			trace := NewCheckTrace(0)
+
+			_, err := checker.ResolveCheck(ContextWithCheckTrace(ctx, trace), &ResolveCheckRequest{
+				StoreID:            storeID,
+				TupleKey:           test.tupleKey,
+				ResolutionMetadata: &ResolutionMetadata{Depth: 25},
+			})
+			require.NoError(t, err)
+
+			path, err := json.Marshal(trace.Path())
+			require.NoError(t, err)
+			require.JSONEq(t, test.expectedPath, string(path))
+			require.False(t, trace.Truncated())
+		})
+	}
+
+	t.Run("limited_number_of_nodes", func(t *testing.T) {
+		trace := NewCheckTrace(2)
+
+		resp, err := checker.ResolveCheck(ContextWithCheckTrace(ctx, trace), &ResolveCheckRequest{
+			StoreID:            storeID,
+			TupleKey:           tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			ResolutionMetadata: &ResolutionMetadata{Depth: 25},
+		})
+		require.NoError(t, err)
+		require.True(t, resp.Allowed)
+		require.True(t, trace.Truncated())
+		require.LessOrEqual(t, countCheckTraceNodes(&trace.root)-1, 2)
+	})
+}
+
+// countCheckTraceNodes returns the number of nodes of the trace under node, including node.
+func countCheckTraceNodes(node *CheckTraceNode) int {
+	count := 1
+	for _, child := range node.Children {
+		count += countCheckTraceNodes(child)
+	}
+	return count
+}
@@ -23,25 +23,54 @@ type mockOidcServer struct {
 const kidHeader = "1"
 
 func NewMockOidcServer(issuerURL string) (*mockOidcServer, error) {
+	mockServer, err := newMockOidcServer(issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		log.Fatal(http.ListenAndServe(":"+mockServer.port(), mockServer.handler()))
+	}()
+
+	return mockServer, nil
+}
+
+// NewMockTLSOidcServer is like NewMockOidcServer, but serves the 'https' issuerURL with the certificate and key
+// in the provided files.
+func NewMockTLSOidcServer(issuerURL, certFile, keyFile string) (*mockOidcServer, error) {
+	mockServer, err := newMockOidcServer(issuerURL)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		log.Fatal(http.ListenAndServeTLS(":"+mockServer.port(), certFile, keyFile, mockServer.handler()))
+	}()
+
+	return mockServer, nil
+}
+
+func newMockOidcServer(issuerURL string) (*mockOidcServer, error) {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 4096)
 	if err != nil {
 		return nil, err
 	}
 
-	mockServer := &mockOidcServer{
+	return &mockOidcServer{
 		issuerURL:  issuerURL,
 		privateKey: privateKey,
 		publicKey:  privateKey.Public().(*rsa.PublicKey),
-	}
+	}, nil
+}
 
-	mockServer.start()
-	return mockServer, nil
+func (server mockOidcServer) port() string {
+	return strings.Split(server.issuerURL, ":")[2]
 }
 
-func (server mockOidcServer) start() {
-	port := strings.Split(server.issuerURL, ":")[2]
+func (server mockOidcServer) handler() http.Handler {
+	mux := http.NewServeMux()
 
-	http.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
 		err := json.NewEncoder(w).Encode(map[string]string{
 			"issuer":   server.issuerURL,
 			"jwks_uri": fmt.Sprintf("%s/jwks.json", server.issuerURL),
@@ -51,7 +80,7 @@ func (server mockOidcServer) start() {
 		}
 	})
 
-	http.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
+	mux.HandleFunc("/jwks.json", func(w http.ResponseWriter, r *http.Request) {
 
 		err := json.NewEncoder(w).Encode(map[string]interface{}{
 			"keys": []map[string]string{
@@ -68,9 +97,7 @@ func (server mockOidcServer) start() {
 		}
 	})
 
-	go func() {
-		log.Fatal(http.ListenAndServe(":"+port, nil))
-	}()
+	return mux
 }
 
 func (server mockOidcServer) GetToken(audience, subject string) (string, error) {
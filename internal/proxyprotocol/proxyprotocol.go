@@ -0,0 +1,249 @@
+// Package proxyprotocol implements a net.Listener that decodes the PROXY protocol (v1 and v2) header
+// sent by L4 load balancers, so that the address of the original client is preserved.
+//
+// See https://www.haproxy.org/download/2.8/doc/proxy-protocol.txt
+package proxyprotocol
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultHeaderTimeout is the maximum amount of time to wait for the PROXY protocol header.
+	DefaultHeaderTimeout = 5 * time.Second
+
+	v1Prefix       = "PROXY "
+	v1MaxHeaderLen = 107
+
+	v2HeaderLen  = 16
+	v2CmdLocal   = 0x0
+	v2CmdProxy   = 0x1
+	v2FamilyTCP4 = 0x11
+	v2FamilyTCP6 = 0x21
+)
+
+var (
+	v2Signature = []byte{0x0D, 0x0A, 0x0D, 0x0A, 0x00, 0x0D, 0x0A, 0x51, 0x55, 0x49, 0x54, 0x0A}
+
+	// ErrMissingHeader is returned when reading from a connection that did not start with a PROXY protocol header.
+	ErrMissingHeader = errors.New("proxy protocol: missing header")
+
+	// ErrInvalidHeader is returned when reading from a connection whose PROXY protocol header is malformed.
+	ErrInvalidHeader = errors.New("proxy protocol: invalid header")
+)
+
+// Listener wraps a net.Listener and decodes the PROXY protocol header of every accepted connection.
+// The RemoteAddr of the accepted connections is the address of the original client.
+//
+// The header is required on every connection, except on connections from loopback addresses where it
+// is optional, so that the server can still be reached locally (e.g. by the HTTP gateway).
+type Listener struct {
+	net.Listener
+
+	// HeaderTimeout is the maximum amount of time to wait for the header. Defaults to DefaultHeaderTimeout.
+	HeaderTimeout time.Duration
+}
+
+// NewListener returns a Listener that decodes the PROXY protocol header of the connections accepted by inner.
+func NewListener(inner net.Listener) *Listener {
+	return &Listener{
+		Listener:      inner,
+		HeaderTimeout: DefaultHeaderTimeout,
+	}
+}
+
+// Accept waits for and returns the next connection. The header is decoded lazily on the first
+// Read or RemoteAddr call, so that a slow client does not block the accept loop.
+func (l *Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+
+	headerTimeout := l.HeaderTimeout
+	if headerTimeout == 0 {
+		headerTimeout = DefaultHeaderTimeout
+	}
+
+	return &Conn{
+		Conn:           conn,
+		reader:         bufio.NewReader(conn),
+		headerTimeout:  headerTimeout,
+		headerOptional: isLoopback(conn.RemoteAddr()),
+	}, nil
+}
+
+// Conn is a net.Conn whose RemoteAddr is the address of the original client, as reported by the
+// PROXY protocol header.
+type Conn struct {
+	net.Conn
+
+	reader         *bufio.Reader
+	headerTimeout  time.Duration
+	headerOptional bool
+
+	once       sync.Once
+	remoteAddr net.Addr
+	headerErr  error
+}
+
+func (c *Conn) Read(b []byte) (int, error) {
+	c.once.Do(c.readHeader)
+	if c.headerErr != nil {
+		return 0, c.headerErr
+	}
+
+	return c.reader.Read(b)
+}
+
+// RemoteAddr returns the address of the original client if the connection was proxied, otherwise
+// it returns the address of the peer.
+func (c *Conn) RemoteAddr() net.Addr {
+	c.once.Do(c.readHeader)
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+
+	return c.Conn.RemoteAddr()
+}
+
+func (c *Conn) readHeader() {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.headerTimeout)); err != nil {
+		c.headerErr = err
+		return
+	}
+	defer func() {
+		if err := c.Conn.SetReadDeadline(time.Time{}); err != nil && c.headerErr == nil {
+			c.headerErr = err
+		}
+	}()
+
+	c.remoteAddr, c.headerErr = c.parseHeader()
+	if c.headerErr != nil {
+		c.Conn.Close()
+	}
+}
+
+func (c *Conn) parseHeader() (net.Addr, error) {
+	prefix, err := c.reader.Peek(len(v1Prefix))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+
+	switch {
+	case string(prefix) == v1Prefix:
+		return c.parseV1()
+	case len(prefix) == len(v1Prefix) && bytes.HasPrefix(v2Signature, prefix):
+		prefix, err = c.reader.Peek(len(v2Signature))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+
+		if bytes.Equal(prefix, v2Signature) {
+			return c.parseV2()
+		}
+	}
+
+	if c.headerOptional {
+		return nil, nil
+	}
+
+	return nil, ErrMissingHeader
+}
+
+// parseV1 parses a header of the form 'PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\n'.
+func (c *Conn) parseV1() (net.Addr, error) {
+	var line []byte
+	for {
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+		}
+
+		line = append(line, b)
+		if len(line) > v1MaxHeaderLen {
+			return nil, ErrInvalidHeader
+		}
+
+		if bytes.HasSuffix(line, []byte("\r\n")) {
+			break
+		}
+	}
+
+	fields := strings.Fields(strings.TrimSuffix(string(line), "\r\n"))
+	if len(fields) >= 2 && fields[1] == "UNKNOWN" {
+		return nil, nil
+	}
+
+	if len(fields) != 6 || (fields[1] != "TCP4" && fields[1] != "TCP6") {
+		return nil, ErrInvalidHeader
+	}
+
+	ip := net.ParseIP(fields[2])
+	if ip == nil {
+		return nil, ErrInvalidHeader
+	}
+
+	port, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, ErrInvalidHeader
+	}
+
+	return &net.TCPAddr{IP: ip, Port: int(port)}, nil
+}
+
+func (c *Conn) parseV2() (net.Addr, error) {
+	header := make([]byte, v2HeaderLen)
+	if _, err := io.ReadFull(c.reader, header); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	if header[12]>>4 != 0x2 {
+		return nil, ErrInvalidHeader
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint16(header[14:16]))
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+
+	switch header[12] & 0x0F {
+	case v2CmdLocal:
+		// health checks from the proxy itself, the connection is not proxied
+		return nil, nil
+	case v2CmdProxy:
+	default:
+		return nil, ErrInvalidHeader
+	}
+
+	switch header[13] {
+	case v2FamilyTCP4:
+		if len(payload) < 12 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:4]), Port: int(binary.BigEndian.Uint16(payload[8:10]))}, nil
+	case v2FamilyTCP6:
+		if len(payload) < 36 {
+			return nil, ErrInvalidHeader
+		}
+		return &net.TCPAddr{IP: net.IP(payload[0:16]), Port: int(binary.BigEndian.Uint16(payload[32:34]))}, nil
+	default:
+		// unsupported address families (e.g. unix sockets) are accepted but the address is ignored
+		return nil, nil
+	}
+}
+
+func isLoopback(addr net.Addr) bool {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	return ok && tcpAddr.IP.IsLoopback()
+}
@@ -0,0 +1,94 @@
+package proxyprotocol
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func acceptWithPayload(t *testing.T, payload []byte) (net.Conn, string) {
+	t.Helper()
+
+	inner, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { inner.Close() })
+
+	listener := NewListener(inner)
+
+	go func() {
+		client, err := net.Dial("tcp", inner.Addr().String())
+		if err != nil {
+			return
+		}
+		defer client.Close()
+
+		_, _ = client.Write(payload)
+	}()
+
+	conn, err := listener.Accept()
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	body, err := io.ReadAll(conn)
+	require.NoError(t, err)
+
+	return conn, string(body)
+}
+
+func TestListener(t *testing.T) {
+	t.Run("v1_header", func(t *testing.T) {
+		conn, body := acceptWithPayload(t, []byte("PROXY TCP4 192.168.0.1 192.168.0.11 56324 443\r\nhello"))
+
+		require.Equal(t, "192.168.0.1:56324", conn.RemoteAddr().String())
+		require.Equal(t, "hello", body)
+	})
+
+	t.Run("v1_unknown_header", func(t *testing.T) {
+		conn, body := acceptWithPayload(t, []byte("PROXY UNKNOWN\r\nhello"))
+
+		require.Equal(t, "127.0.0.1", conn.RemoteAddr().(*net.TCPAddr).IP.String())
+		require.Equal(t, "hello", body)
+	})
+
+	t.Run("v2_header", func(t *testing.T) {
+		header := append([]byte{}, v2Signature...)
+		header = append(header, 0x21, v2FamilyTCP4, 0x00, 0x0C)
+		header = append(header, 10, 0, 0, 1, 10, 0, 0, 2)
+		header = binary.BigEndian.AppendUint16(header, 4242)
+		header = binary.BigEndian.AppendUint16(header, 443)
+
+		conn, body := acceptWithPayload(t, append(header, []byte("hello")...))
+
+		require.Equal(t, "10.0.0.1:4242", conn.RemoteAddr().String())
+		require.Equal(t, "hello", body)
+	})
+
+	t.Run("no_header_from_loopback", func(t *testing.T) {
+		conn, body := acceptWithPayload(t, []byte("hello world"))
+
+		require.Equal(t, "127.0.0.1", conn.RemoteAddr().(*net.TCPAddr).IP.String())
+		require.Equal(t, "hello world", body)
+	})
+}
+
+func TestConnWithoutRequiredHeader(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	conn := &Conn{
+		Conn:          server,
+		reader:        bufio.NewReader(server),
+		headerTimeout: DefaultHeaderTimeout,
+	}
+
+	go func() {
+		_, _ = client.Write([]byte("hello world"))
+	}()
+
+	_, err := conn.Read(make([]byte, 16))
+	require.ErrorIs(t, err, ErrMissingHeader)
+}
@@ -11,6 +11,38 @@ import (
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 )
 
+// TupleKeyLimits bounds the length, in bytes, of the fields of a tuple key, so that tuples with very long fields can't
+// bloat the indexes of the datastore. A limit of 0 means no limit.
+type TupleKeyLimits struct {
+	MaxObjectLength   int
+	MaxRelationLength int
+	MaxUserLength     int
+}
+
+// ValidateTupleKeyLimits checks that the fields of a tuple key are not longer than the provided limits.
+func ValidateTupleKeyLimits(tk *openfgapb.TupleKey, limits TupleKeyLimits) error {
+	fields := []struct {
+		name      string
+		value     string
+		maxLength int
+	}{
+		{name: "object", value: tk.GetObject(), maxLength: limits.MaxObjectLength},
+		{name: "relation", value: tk.GetRelation(), maxLength: limits.MaxRelationLength},
+		{name: "user", value: tk.GetUser(), maxLength: limits.MaxUserLength},
+	}
+
+	for _, field := range fields {
+		if field.maxLength > 0 && len(field.value) > field.maxLength {
+			return &tuple.InvalidTupleError{
+				Cause:    fmt.Errorf("the '%s' field is longer than the maximum of %d bytes", field.name, field.maxLength),
+				TupleKey: tk,
+			}
+		}
+	}
+
+	return nil
+}
+
 // ValidateUserObjectRelation checks whether a tuple is well formed
 func ValidateUserObjectRelation(typesys *typesystem.TypeSystem, tk *openfgapb.TupleKey) error {
 
@@ -49,7 +81,7 @@ func ValidateTuple(typesys *typesystem.TypeSystem, tk *openfgapb.TupleKey) error
 
 	hasTypeInfo, err := typesys.HasTypeInfo(objectType, relation)
 	if err != nil {
-		return err
+		return &tuple.InvalidTupleError{Cause: err, TupleKey: tk}
 	}
 
 	if hasTypeInfo {
@@ -62,6 +94,58 @@ func ValidateTuple(typesys *typesystem.TypeSystem, tk *openfgapb.TupleKey) error
 	return nil
 }
 
+// ValidateTupleStrict checks whether a tuple is valid according to the provided model, like ValidateTuple,
+// and additionally requires the 'user' field to follow the 'type:id' format (or 'type:id#relation', or a
+// typed wildcard) with a type that is defined in the model, regardless of the model's schema version.
+func ValidateTupleStrict(typesys *typesystem.TypeSystem, tk *openfgapb.TupleKey) error {
+	if err := ValidateTuple(typesys, tk); err != nil {
+		return err
+	}
+
+	user := tk.GetUser()
+
+	if !tuple.IsValidObject(user) && !tuple.IsObjectRelation(user) {
+		return &tuple.InvalidTupleError{
+			Cause:    fmt.Errorf("the 'user' field must be an object (e.g. document:1) or an 'object#relation' or a typed wildcard (e.g. group:*)"),
+			TupleKey: tk,
+		}
+	}
+
+	userObject, _ := tuple.SplitObjectRelation(user)
+	userObjectType, userObjectID := tuple.SplitObject(userObject)
+	if userObjectType == "" || userObjectID == "" {
+		return &tuple.InvalidTupleError{
+			Cause:    fmt.Errorf("the 'user' field must follow the 'type:id' format"),
+			TupleKey: tk,
+		}
+	}
+
+	if _, ok := typesys.GetTypeDefinition(userObjectType); !ok {
+		return &tuple.InvalidTupleError{Cause: &tuple.TypeNotFoundError{TypeName: userObjectType}, TupleKey: tk}
+	}
+
+	return nil
+}
+
+// ValidateContextualTuple checks whether a contextual tuple is valid according to the provided model, like
+// ValidateTuple, and additionally requires the type of a 'user' field in the 'type:id' format (or 'type:id#relation',
+// or a typed wildcard) to be defined in the model, regardless of the model's schema version. Contextual tuples
+// are never written, so a malformed one would otherwise only surface when it is evaluated.
+func ValidateContextualTuple(typesys *typesystem.TypeSystem, tk *openfgapb.TupleKey) error {
+	user := tk.GetUser()
+
+	if tuple.IsValidUser(user) {
+		userObject, _ := tuple.SplitObjectRelation(user)
+		if userObjectType, _ := tuple.SplitObject(userObject); userObjectType != "" {
+			if _, ok := typesys.GetTypeDefinition(userObjectType); !ok {
+				return &tuple.InvalidTupleError{Cause: &tuple.TypeNotFoundError{TypeName: userObjectType}, TupleKey: tk}
+			}
+		}
+	}
+
+	return ValidateTuple(typesys, tk)
+}
+
 // validateTuplesetRestrictions validates the provided TupleKey against tupleset restrictions.
 //
 // Given a rewrite definition such as 'viewer from parent', the 'parent' relation is known as the
@@ -680,3 +680,181 @@ func TestValidateTuple(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTupleStrict(t *testing.T) {
+	model := &openfgapb.AuthorizationModel{
+		SchemaVersion: typesystem.SchemaVersion1_0,
+		TypeDefinitions: []*openfgapb.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgapb.Userset{
+					"viewer": typesystem.This(),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		tuple         *openfgapb.TupleKey
+		expectedError error
+	}{
+		{
+			name:  "valid_user_object",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		},
+		{
+			name:  "valid_user_userset",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "document:2#viewer"),
+		},
+		{
+			name:  "user_id_without_type",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "anne"),
+			expectedError: &tuple.InvalidTupleError{
+				Cause:    fmt.Errorf("the 'user' field must be an object (e.g. document:1) or an 'object#relation' or a typed wildcard (e.g. group:*)"),
+				TupleKey: tuple.NewTupleKey("document:1", "viewer", "anne"),
+			},
+		},
+		{
+			name:  "user_with_undefined_type",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "employee:anne"),
+			expectedError: &tuple.InvalidTupleError{
+				Cause:    &tuple.TypeNotFoundError{TypeName: "employee"},
+				TupleKey: tuple.NewTupleKey("document:1", "viewer", "employee:anne"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			typesys := typesystem.New(model)
+
+			// the lenient validation accepts all of these tuples for 1.0 models
+			require.NoError(t, ValidateTuple(typesys, test.tuple))
+
+			err := ValidateTupleStrict(typesys, test.tuple)
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, test.expectedError)
+		})
+	}
+}
+
+func TestValidateContextualTuple(t *testing.T) {
+	model := &openfgapb.AuthorizationModel{
+		SchemaVersion: typesystem.SchemaVersion1_0,
+		TypeDefinitions: []*openfgapb.TypeDefinition{
+			{
+				Type: "user",
+			},
+			{
+				Type: "document",
+				Relations: map[string]*openfgapb.Userset{
+					"viewer": typesystem.This(),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		tuple         *openfgapb.TupleKey
+		expectedError error
+	}{
+		{
+			name:  "valid_user_object",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		},
+		{
+			name:  "valid_user_id_without_type",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "anne"),
+		},
+		{
+			name:  "object_with_undefined_type",
+			tuple: tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+			expectedError: &tuple.InvalidTupleError{
+				Cause:    &tuple.TypeNotFoundError{TypeName: "folder"},
+				TupleKey: tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+			},
+		},
+		{
+			name:  "user_with_undefined_type",
+			tuple: tuple.NewTupleKey("document:1", "viewer", "employee:anne"),
+			expectedError: &tuple.InvalidTupleError{
+				Cause:    &tuple.TypeNotFoundError{TypeName: "employee"},
+				TupleKey: tuple.NewTupleKey("document:1", "viewer", "employee:anne"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateContextualTuple(typesystem.New(model), test.tuple)
+			if test.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, test.expectedError.Error())
+		})
+	}
+}
+
+func TestValidateTupleKeyLimits(t *testing.T) {
+	limits := TupleKeyLimits{MaxObjectLength: 12, MaxRelationLength: 6, MaxUserLength: 9}
+
+	tests := []struct {
+		name          string
+		tuple         *openfgapb.TupleKey
+		limits        TupleKeyLimits
+		expectedCause string
+	}{
+		{
+			name:   "at_the_limits",
+			tuple:  tuple.NewTupleKey("document:123", "viewer", "user:anne"),
+			limits: limits,
+		},
+		{
+			name:          "object_too_long",
+			tuple:         tuple.NewTupleKey("document:1234", "viewer", "user:anne"),
+			limits:        limits,
+			expectedCause: "the 'object' field is longer than the maximum of 12 bytes",
+		},
+		{
+			name:          "relation_too_long",
+			tuple:         tuple.NewTupleKey("document:1", "viewers", "user:anne"),
+			limits:        limits,
+			expectedCause: "the 'relation' field is longer than the maximum of 6 bytes",
+		},
+		{
+			name:          "user_too_long",
+			tuple:         tuple.NewTupleKey("document:1", "viewer", "user:annie"),
+			limits:        limits,
+			expectedCause: "the 'user' field is longer than the maximum of 9 bytes",
+		},
+		{
+			name:  "no_limits",
+			tuple: tuple.NewTupleKey("document:1234", "viewers", "user:annie"),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTupleKeyLimits(test.tuple, test.limits)
+			if test.expectedCause == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			var invalidTupleErr *tuple.InvalidTupleError
+			require.ErrorAs(t, err, &invalidTupleErr)
+			require.EqualError(t, invalidTupleErr.Cause, test.expectedCause)
+		})
+	}
+}
@@ -0,0 +1,128 @@
+// Package audit contains the audit log, which records the administrative operations that mutate the server state
+// for compliance purposes. It is separate from the access logs, and has stricter delivery guarantees: an entry is
+// written before the operation it records is reported as successful, unless the Logger is buffered with
+// NewBufferedLogger, in which case it is queued before, and written shortly after.
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	OperationCreateStore             = "CreateStore"
+	OperationDeleteStore             = "DeleteStore"
+	OperationWriteAuthorizationModel = "WriteAuthorizationModel"
+)
+
+// Entry is a single record of the audit log.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+
+	// Principal is the authenticated subject that performed the operation. It is empty if the authentication
+	// method carries no subject, such as preshared keys.
+	Principal string `json:"principal"`
+
+	RequestID string `json:"request_id,omitempty"`
+	StoreID   string `json:"store_id"`
+
+	// Request and Response are the JSON encoded request and response of the operation.
+	Request  json.RawMessage `json:"request,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+}
+
+// Logger writes audit log entries.
+type Logger interface {
+	// Log durably writes the entry, or returns an error if it could not. Entries are never dropped silently.
+	Log(ctx context.Context, entry Entry) error
+
+	// Close releases the resources of the Logger.
+	Close() error
+}
+
+// NoopLogger is a Logger that discards all entries. It is used when the audit log is disabled.
+type NoopLogger struct{}
+
+var _ Logger = (*NoopLogger)(nil)
+
+func (NoopLogger) Log(context.Context, Entry) error { return nil }
+
+func (NoopLogger) Close() error { return nil }
+
+// jsonLogger writes each entry as a line of JSON.
+type jsonLogger struct {
+	mu     sync.Mutex
+	w      io.Writer
+	sync   func() error
+	closer func() error
+}
+
+var _ Logger = (*jsonLogger)(nil)
+var _ batchWriter = (*jsonLogger)(nil)
+
+// NewWriterLogger returns a Logger that writes each entry as a line of JSON to w, such as os.Stdout.
+func NewWriterLogger(w io.Writer) Logger {
+	return &jsonLogger{
+		w:      w,
+		sync:   func() error { return nil },
+		closer: func() error { return nil },
+	}
+}
+
+// NewFileLogger returns a Logger that appends each entry as a line of JSON to the file at path, which is created if
+// it does not exist. The file is synced after every entry, so that entries survive a crash of the server.
+func NewFileLogger(path string) (Logger, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log file '%s': %w", path, err)
+	}
+
+	return &jsonLogger{
+		w:      f,
+		sync:   f.Sync,
+		closer: f.Close,
+	}, nil
+}
+
+func (l *jsonLogger) Log(_ context.Context, entry Entry) error {
+	return l.logBatch([]Entry{entry})
+}
+
+// logBatch writes the entries and syncs them once, which is cheaper than syncing after each of them.
+func (l *jsonLogger) logBatch(entries []Entry) error {
+	var lines []byte
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit log entry: %w", err)
+		}
+		lines = append(lines, line...)
+		lines = append(lines, '\n')
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.w.Write(lines); err != nil {
+		return fmt.Errorf("failed to write audit log entry: %w", err)
+	}
+
+	if err := l.sync(); err != nil {
+		return fmt.Errorf("failed to sync audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (l *jsonLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.closer()
+}
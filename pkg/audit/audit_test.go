@@ -0,0 +1,242 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileLogger(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+
+	l, err := NewFileLogger(path)
+	require.NoError(t, err)
+
+	now := time.Now().UTC()
+	entries := []Entry{
+		{Time: now, Operation: OperationCreateStore, Principal: "alice", StoreID: "1", Response: json.RawMessage(`{"id":"1"}`)},
+		{Time: now, Operation: OperationDeleteStore, Principal: "bob", StoreID: "1"},
+	}
+	for _, entry := range entries {
+		require.NoError(t, l.Log(context.Background(), entry))
+	}
+	require.NoError(t, l.Close())
+
+	// entries are appended to an existing file
+	l, err = NewFileLogger(path)
+	require.NoError(t, err)
+	entries = append(entries, Entry{Time: now, Operation: OperationWriteAuthorizationModel, StoreID: "2"})
+	require.NoError(t, l.Log(context.Background(), entries[2]))
+	require.NoError(t, l.Close())
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var logged []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		logged = append(logged, entry)
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, entries, logged)
+}
+
+func TestWriterLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewWriterLogger(&buf)
+
+	err := l.Log(context.Background(), Entry{Operation: OperationCreateStore, Principal: "alice", StoreID: "1"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"time":"0001-01-01T00:00:00Z","operation":"CreateStore","principal":"alice","store_id":"1"}`, buf.String())
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestLoggerReportsWriteErrors(t *testing.T) {
+	l := NewWriterLogger(failingWriter{})
+
+	err := l.Log(context.Background(), Entry{Operation: OperationCreateStore})
+	require.EqualError(t, err, "failed to write audit log entry: disk full")
+}
+
+// blockingLogger records the entries logged with it, and blocks every Log call until unblock is closed.
+type blockingLogger struct {
+	mu      sync.Mutex
+	entries []Entry
+	unblock chan struct{}
+	closed  bool
+}
+
+func (l *blockingLogger) Log(_ context.Context, entry Entry) error {
+	<-l.unblock
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *blockingLogger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.closed = true
+	return nil
+}
+
+func (l *blockingLogger) logged() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Entry(nil), l.entries...)
+}
+
+func TestBufferedLogger(t *testing.T) {
+	t.Run("writes_the_entries_every_flush_interval", func(t *testing.T) {
+		next := &blockingLogger{unblock: make(chan struct{})}
+		close(next.unblock)
+
+		l := NewBufferedLogger(next, 10, WithFlushInterval(10*time.Millisecond))
+		t.Cleanup(func() { _ = l.Close() })
+
+		require.NoError(t, l.Log(context.Background(), Entry{StoreID: "1"}))
+
+		require.Eventually(t, func() bool {
+			return len(next.logged()) == 1
+		}, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("close_writes_the_queued_entries", func(t *testing.T) {
+		next := &blockingLogger{unblock: make(chan struct{})}
+		close(next.unblock)
+
+		l := NewBufferedLogger(next, 10, WithFlushInterval(time.Hour))
+		for _, storeID := range []string{"1", "2", "3"} {
+			require.NoError(t, l.Log(context.Background(), Entry{StoreID: storeID}))
+		}
+		require.NoError(t, l.Close())
+
+		require.Equal(t, []Entry{{StoreID: "1"}, {StoreID: "2"}, {StoreID: "3"}}, next.logged())
+		require.True(t, next.closed)
+
+		err := l.Log(context.Background(), Entry{StoreID: "4"})
+		require.ErrorIs(t, err, ErrLoggerClosed)
+	})
+
+	t.Run("file_entries_survive_close", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "audit.log")
+		fileLogger, err := NewFileLogger(path)
+		require.NoError(t, err)
+
+		l := NewBufferedLogger(fileLogger, 10, WithFlushInterval(time.Hour))
+		require.NoError(t, l.Log(context.Background(), Entry{Operation: OperationCreateStore, StoreID: "1"}))
+		require.NoError(t, l.Log(context.Background(), Entry{Operation: OperationDeleteStore, StoreID: "1"}))
+		require.NoError(t, l.Close())
+
+		contents, err := os.ReadFile(path)
+		require.NoError(t, err)
+		require.Len(t, bytes.Split(bytes.TrimSpace(contents), []byte("\n")), 2)
+	})
+
+	// with a queue of 1, the first entry is being written, the second one is queued, and the queue is full
+	fillQueue := func(t *testing.T, l Logger) {
+		require.NoError(t, l.Log(context.Background(), Entry{StoreID: "1"}))
+		require.Eventually(t, func() bool {
+			return len(l.(*bufferedLogger).queue) == 0
+		}, time.Second, 5*time.Millisecond)
+		require.NoError(t, l.Log(context.Background(), Entry{StoreID: "2"}))
+	}
+
+	t.Run("block_policy_waits_for_room_in_the_queue", func(t *testing.T) {
+		next := &blockingLogger{unblock: make(chan struct{})}
+		l := NewBufferedLogger(next, 1, WithFlushInterval(time.Hour), WithQueueFullPolicy(QueueFullPolicyBlock))
+		fillQueue(t, l)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		err := l.Log(ctx, Entry{StoreID: "3"})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		close(next.unblock)
+		require.NoError(t, l.Close())
+		require.Equal(t, []Entry{{StoreID: "1"}, {StoreID: "2"}}, next.logged())
+	})
+
+	t.Run("drop_policy_counts_the_dropped_entries", func(t *testing.T) {
+		next := &blockingLogger{unblock: make(chan struct{})}
+		l := NewBufferedLogger(next, 1, WithFlushInterval(time.Hour), WithQueueFullPolicy(QueueFullPolicyDrop))
+		fillQueue(t, l)
+
+		dropped := testutil.ToFloat64(droppedEntriesCounter)
+		require.NoError(t, l.Log(context.Background(), Entry{StoreID: "3"}))
+		require.Equal(t, dropped+1, testutil.ToFloat64(droppedEntriesCounter))
+
+		close(next.unblock)
+		require.NoError(t, l.Close())
+		require.Equal(t, []Entry{{StoreID: "1"}, {StoreID: "2"}}, next.logged())
+	})
+
+	t.Run("failed_writes_are_retried", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := &flakyWriter{w: &buf, failures: 1}
+
+		var errs []error
+		var mu sync.Mutex
+		l := NewBufferedLogger(NewWriterLogger(w), 10, WithFlushInterval(10*time.Millisecond), WithErrorHandler(func(err error) {
+			mu.Lock()
+			defer mu.Unlock()
+			errs = append(errs, err)
+		}))
+
+		require.NoError(t, l.Log(context.Background(), Entry{StoreID: "1"}))
+		require.Eventually(t, func() bool {
+			return w.written() > 0
+		}, time.Second, 5*time.Millisecond)
+		require.NoError(t, l.Close())
+
+		mu.Lock()
+		defer mu.Unlock()
+		require.Len(t, errs, 1)
+		require.Len(t, bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")), 1)
+	})
+}
+
+// flakyWriter fails the first failures writes.
+type flakyWriter struct {
+	mu       sync.Mutex
+	w        *bytes.Buffer
+	failures int
+}
+
+func (f *flakyWriter) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.failures > 0 {
+		f.failures--
+		return 0, errors.New("disk full")
+	}
+
+	return f.w.Write(p)
+}
+
+func (f *flakyWriter) written() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.w.Len()
+}
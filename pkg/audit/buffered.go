@@ -0,0 +1,230 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// QueueFullPolicy decides what happens to an entry when the queue of a buffered Logger is full.
+type QueueFullPolicy string
+
+const (
+	// QueueFullPolicyBlock blocks the operation until there is room in the queue, or its context is done. It never
+	// drops entries.
+	QueueFullPolicyBlock QueueFullPolicy = "block"
+
+	// QueueFullPolicyDrop drops the entry right away, and counts it with the 'audit_log_dropped_entries_count'
+	// metric.
+	QueueFullPolicyDrop QueueFullPolicy = "drop"
+
+	// DefaultFlushInterval is how often a buffered Logger writes its queued entries by default.
+	DefaultFlushInterval = time.Second
+)
+
+// ErrLoggerClosed is returned when an entry is logged with a Logger that is closed.
+var ErrLoggerClosed = errors.New("the audit log is closed")
+
+var droppedEntriesCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "audit_log_dropped_entries_count",
+	Help: "Number of audit log entries dropped without being written because the queue of the audit log was full",
+})
+
+// batchWriter is implemented by the Loggers that can write several entries at once more cheaply than one by one.
+type batchWriter interface {
+	logBatch(entries []Entry) error
+}
+
+type BufferedLoggerOption func(*bufferedLogger)
+
+// WithFlushInterval sets how often the queued entries are written. Defaults to DefaultFlushInterval.
+func WithFlushInterval(interval time.Duration) BufferedLoggerOption {
+	return func(l *bufferedLogger) {
+		l.flushInterval = interval
+	}
+}
+
+// WithQueueFullPolicy sets what happens to an entry when the queue is full. Defaults to QueueFullPolicyBlock.
+func WithQueueFullPolicy(policy QueueFullPolicy) BufferedLoggerOption {
+	return func(l *bufferedLogger) {
+		l.policy = policy
+	}
+}
+
+// WithErrorHandler sets a function called with the errors of the writes made in the background. The entries of a
+// failed write are kept and written again on the next flush.
+func WithErrorHandler(onError func(error)) BufferedLoggerOption {
+	return func(l *bufferedLogger) {
+		l.onError = onError
+	}
+}
+
+// bufferedLogger queues the entries and writes them to the next Logger in the background.
+type bufferedLogger struct {
+	next Logger
+
+	queueSize     int
+	flushInterval time.Duration
+	policy        QueueFullPolicy
+	onError       func(error)
+
+	queue    chan Entry
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	closeErr error
+}
+
+var _ Logger = (*bufferedLogger)(nil)
+
+// NewBufferedLogger returns a Logger that queues up to queueSize entries, and writes them to next every flush
+// interval, or as soon as queueSize entries are waiting to be written. Unlike the other Loggers, Log returns once
+// the entry is queued rather than written, which takes the write off the path of the operation. Close writes the
+// queued entries before closing next, so that no entry is lost on a graceful shutdown.
+func NewBufferedLogger(next Logger, queueSize int, opts ...BufferedLoggerOption) Logger {
+	l := &bufferedLogger{
+		next:          next,
+		queueSize:     queueSize,
+		flushInterval: DefaultFlushInterval,
+		policy:        QueueFullPolicyBlock,
+		onError:       func(error) {},
+		queue:         make(chan Entry, queueSize),
+		stopCh:        make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	go l.run()
+
+	return l
+}
+
+// Log queues the entry. If the queue is full, it either waits for room in the queue until ctx is done, or drops the
+// entry, depending on the QueueFullPolicy.
+func (l *bufferedLogger) Log(ctx context.Context, entry Entry) error {
+	select {
+	case <-l.stopCh:
+		return ErrLoggerClosed
+	default:
+	}
+
+	select {
+	case l.queue <- entry:
+		return nil
+	default:
+	}
+
+	if l.policy == QueueFullPolicyDrop {
+		droppedEntriesCounter.Inc()
+		return nil
+	}
+
+	select {
+	case l.queue <- entry:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to queue audit log entry: %w", ctx.Err())
+	case <-l.stopCh:
+		return ErrLoggerClosed
+	}
+}
+
+func (l *bufferedLogger) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.flushInterval)
+	defer ticker.Stop()
+
+	var batch []Entry
+	failed := false
+	for {
+		// stop taking entries from the queue while a failed batch is pending, so that the queue fills up and
+		// the QueueFullPolicy applies instead of the batch growing without a bound
+		queue := l.queue
+		if failed || len(batch) >= l.queueSize {
+			queue = nil
+		}
+
+		select {
+		case entry := <-queue:
+			batch = append(batch, entry)
+			if len(batch) >= l.queueSize {
+				batch, failed = l.flush(batch)
+			}
+		case <-ticker.C:
+			batch, failed = l.flush(batch)
+		case <-l.stopCh:
+			// write what is left in the queue before stopping
+			for {
+				select {
+				case entry := <-l.queue:
+					batch = append(batch, entry)
+				default:
+					if len(batch) > 0 {
+						_, l.closeErr = l.write(batch)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// flush writes the batch. It returns the batch back if the write failed, so that it is written again later.
+func (l *bufferedLogger) flush(batch []Entry) ([]Entry, bool) {
+	if len(batch) == 0 {
+		return batch, false
+	}
+
+	remaining, err := l.write(batch)
+	if err != nil {
+		l.onError(err)
+		return remaining, true
+	}
+
+	return remaining, false
+}
+
+// write writes the batch, and returns the entries that were not written.
+func (l *bufferedLogger) write(batch []Entry) ([]Entry, error) {
+	if w, ok := l.next.(batchWriter); ok {
+		if err := w.logBatch(batch); err != nil {
+			return batch, err
+		}
+
+		return batch[:0], nil
+	}
+
+	for i, entry := range batch {
+		if err := l.next.Log(context.Background(), entry); err != nil {
+			return batch[i:], err
+		}
+	}
+
+	return batch[:0], nil
+}
+
+// Close writes the queued entries, and closes the next Logger. It returns an error if some entries could not be
+// written.
+func (l *bufferedLogger) Close() error {
+	l.stopOnce.Do(func() {
+		close(l.stopCh)
+	})
+
+	<-l.done
+
+	if l.closeErr != nil {
+		_ = l.next.Close()
+		return fmt.Errorf("failed to write the queued audit log entries: %w", l.closeErr)
+	}
+
+	return l.next.Close()
+}
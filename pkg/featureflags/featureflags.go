@@ -0,0 +1,67 @@
+// Package featureflags contains the registry of the experimental features that can be enabled in OpenFGA.
+package featureflags
+
+// Flag is the name of an experimental feature, as set in the 'experimentals' config.
+type Flag string
+
+// CheckTrace enables the CheckTraceHeader of the server, which makes a Check request return the path of tuples and
+// relation rewrites that granted access.
+const CheckTrace Flag = "check-trace"
+
+// Feature describes an experimental feature.
+type Feature struct {
+	// Description is a short, human-readable description of the feature.
+	Description string
+
+	// Graduated features are enabled whether or not they are listed in the config. Graduating a feature,
+	// instead of removing it from the registry, keeps configs that still list it valid.
+	Graduated bool
+}
+
+// Registry maps the name of every known experimental feature to its description.
+type Registry map[Flag]Feature
+
+// defaultRegistry is the single source of truth for the experimental features of this version of OpenFGA.
+// It is used both to validate the config and to gate features at runtime.
+var defaultRegistry = Registry{
+	CheckTrace: {Description: "return the path that granted access to a Check request with the openfga-check-trace header"},
+}
+
+// IsKnown reports whether flag names a feature in the registry.
+func (r Registry) IsKnown(flag Flag) bool {
+	_, ok := r[flag]
+	return ok
+}
+
+// Enabled reports whether the feature named by flag is enabled, either because it has graduated or because it
+// is listed in experimentals. Unknown features are never enabled.
+func (r Registry) Enabled(experimentals []Flag, flag Flag) bool {
+	feature, ok := r[flag]
+	if !ok {
+		return false
+	}
+
+	if feature.Graduated {
+		return true
+	}
+
+	for _, experimental := range experimentals {
+		if experimental == flag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsKnown reports whether flag names an experimental feature of this version of OpenFGA.
+func IsKnown(flag Flag) bool {
+	return defaultRegistry.IsKnown(flag)
+}
+
+// Enabled reports whether the experimental feature named by flag is enabled given the experimentals
+// enabled in the config. Handlers should guard experimental code paths with it rather than inspecting
+// the config themselves.
+func Enabled(experimentals []Flag, flag Flag) bool {
+	return defaultRegistry.Enabled(experimentals, flag)
+}
@@ -0,0 +1,65 @@
+package featureflags
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	sampleFeature    Flag = "sample-feature"
+	graduatedFeature Flag = "graduated-feature"
+)
+
+var testRegistry = Registry{
+	sampleFeature:    {Description: "a sample experimental feature"},
+	graduatedFeature: {Description: "a sample graduated feature", Graduated: true},
+}
+
+func TestEnabled(t *testing.T) {
+	tests := []struct {
+		name          string
+		experimentals []Flag
+		flag          Flag
+		expected      bool
+	}{
+		{
+			name:          "feature_listed_in_experimentals_is_enabled",
+			experimentals: []Flag{sampleFeature},
+			flag:          sampleFeature,
+			expected:      true,
+		},
+		{
+			name:          "feature_not_listed_in_experimentals_is_disabled",
+			experimentals: []Flag{},
+			flag:          sampleFeature,
+			expected:      false,
+		},
+		{
+			name:          "graduated_feature_is_enabled_without_being_listed",
+			experimentals: []Flag{},
+			flag:          graduatedFeature,
+			expected:      true,
+		},
+		{
+			name:          "unknown_feature_is_disabled_even_if_listed",
+			experimentals: []Flag{"unknown-feature"},
+			flag:          "unknown-feature",
+			expected:      false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.expected, testRegistry.Enabled(test.experimentals, test.flag))
+		})
+	}
+}
+
+func TestIsKnown(t *testing.T) {
+	require.True(t, testRegistry.IsKnown(sampleFeature))
+	require.True(t, testRegistry.IsKnown(graduatedFeature))
+	require.False(t, testRegistry.IsKnown("unknown-feature"))
+
+	require.True(t, IsKnown(CheckTrace))
+}
@@ -0,0 +1,59 @@
+// Package baggage contains middleware to copy OpenTelemetry baggage members onto a request's span.
+package baggage
+
+import (
+	"context"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+)
+
+// BaggageHeader is the header that carries the OpenTelemetry baggage of a request.
+const BaggageHeader = "baggage"
+
+// AttributePrefix prefixes the name of the span attributes set from baggage members, so that the member
+// 'tenant' is set as the attribute 'baggage.tenant'.
+const AttributePrefix = "baggage."
+
+// NewUnaryInterceptor creates a grpc.UnaryServerInterceptor which sets the baggage members of the request
+// with one of the provided keys as attributes of the request's span. It must come after the trace
+// interceptor, which extracts the baggage from the request and starts the span.
+func NewUnaryInterceptor(keys []string) grpc.UnaryServerInterceptor {
+	return interceptors.UnaryServerInterceptor(reportable(keys))
+}
+
+// NewStreamingInterceptor creates a grpc.StreamServerInterceptor which sets the baggage members of the
+// request with one of the provided keys as attributes of the request's span. It must come after the trace
+// interceptor, which extracts the baggage from the request and starts the span.
+func NewStreamingInterceptor(keys []string) grpc.StreamServerInterceptor {
+	return interceptors.StreamServerInterceptor(reportable(keys))
+}
+
+func reportable(keys []string) interceptors.CommonReportableFunc {
+	return func(ctx context.Context, c interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+		if attrs := Attributes(baggage.FromContext(ctx), keys); len(attrs) > 0 {
+			trace.SpanFromContext(ctx).SetAttributes(attrs...)
+		}
+
+		return interceptors.NoopReporter{}, ctx
+	}
+}
+
+// Attributes returns the members of the baggage with one of the provided keys as span attributes. Only the
+// provided keys are copied, so that arbitrary baggage sent by clients does not end up in the traces.
+func Attributes(b baggage.Baggage, keys []string) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+	for _, key := range keys {
+		member := b.Member(key)
+		if member.Key() == "" {
+			continue
+		}
+
+		attrs = append(attrs, attribute.String(AttributePrefix+key, member.Value()))
+	}
+
+	return attrs
+}
@@ -0,0 +1,64 @@
+package baggage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"google.golang.org/grpc"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	tenant, err := baggage.NewMember("tenant", "acme")
+	require.NoError(t, err)
+	region, err := baggage.NewMember("region", "eu")
+	require.NoError(t, err)
+	secret, err := baggage.NewMember("secret", "s3cret")
+	require.NoError(t, err)
+
+	b, err := baggage.New(tenant, region, secret)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		keys     []string
+		expected []attribute.KeyValue
+	}{
+		{
+			name: "no_keys",
+		},
+		{
+			name: "only_the_configured_keys_are_copied",
+			keys: []string{"tenant", "region", "missing"},
+			expected: []attribute.KeyValue{
+				attribute.String("baggage.tenant", "acme"),
+				attribute.String("baggage.region", "eu"),
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := tracetest.NewSpanRecorder()
+			tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+			ctx, span := tp.Tracer("test").Start(baggage.ContextWithBaggage(context.Background(), b), "Check")
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return nil, nil
+			}
+
+			_, err := NewUnaryInterceptor(test.keys)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+			span.End()
+
+			spans := recorder.Ended()
+			require.Len(t, spans, 1)
+			require.ElementsMatch(t, test.expected, spans[0].Attributes())
+		})
+	}
+}
@@ -0,0 +1,155 @@
+// Package concurrency contains middleware that caps the number of requests the server serves concurrently, as a
+// blunt protection of the instance against overload.
+package concurrency
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	healthServicePrefix = "/grpc.health.v1.Health/"
+	healthzPath         = "/healthz"
+
+	transportGRPC = "grpc"
+	transportHTTP = "http"
+)
+
+// ErrTooManyRequests is returned when a request is rejected because the server is serving the maximum number of
+// concurrent requests. It uses the standard ResourceExhausted code so that clients treat it as retryable.
+var ErrTooManyRequests = status.Error(codes.ResourceExhausted, "Too many concurrent requests. Please retry the request")
+
+var (
+	rejectedRequestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "concurrency_limit_rejected_requests_count",
+		Help: "The number of requests rejected because the server was serving the maximum number of concurrent requests",
+	}, []string{"transport"})
+
+	queuedRequestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "concurrency_limit_queued_requests_count",
+		Help: "The number of requests that waited in the queue for a concurrency slot",
+	}, []string{"transport"})
+)
+
+// Limiter limits the number of requests served concurrently. Requests over the limit wait in a bounded queue for
+// up to the queue timeout, and are rejected if the queue is full or the timeout expires.
+type Limiter struct {
+	slots        chan struct{}
+	queue        chan struct{}
+	queueTimeout time.Duration
+}
+
+// NewLimiter returns a Limiter that serves up to maxConcurrency requests at a time, with up to maxQueueSize
+// requests waiting for up to queueTimeout. Requests are not queued if maxQueueSize or queueTimeout is 0.
+func NewLimiter(maxConcurrency, maxQueueSize uint32, queueTimeout time.Duration) *Limiter {
+	if queueTimeout <= 0 {
+		maxQueueSize = 0
+	}
+
+	return &Limiter{
+		slots:        make(chan struct{}, maxConcurrency),
+		queue:        make(chan struct{}, maxQueueSize),
+		queueTimeout: queueTimeout,
+	}
+}
+
+// acquire admits a request if there is a free slot, waiting in the queue for one if needed. If it returns true,
+// release must be called once the request has finished. The transport labels the metrics.
+func (l *Limiter) acquire(ctx context.Context, transport string) bool {
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	select {
+	case l.queue <- struct{}{}:
+	default:
+		rejectedRequestsCounter.WithLabelValues(transport).Inc()
+		return false
+	}
+	defer func() { <-l.queue }()
+
+	queuedRequestsCounter.WithLabelValues(transport).Inc()
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	rejectedRequestsCounter.WithLabelValues(transport).Inc()
+	return false
+}
+
+func (l *Limiter) release() {
+	<-l.slots
+}
+
+// NewUnaryInterceptor creates a grpc.UnaryServerInterceptor which admits requests through the Limiter, and rejects
+// the requests it does not admit with ErrTooManyRequests. Health checks are never limited.
+func NewUnaryInterceptor(l *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if !l.acquire(ctx, transportGRPC) {
+			return nil, ErrTooManyRequests
+		}
+		defer l.release()
+
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamingInterceptor creates a grpc.StreamServerInterceptor which limits the concurrent requests like
+// NewUnaryInterceptor does.
+func NewStreamingInterceptor(l *Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, stream)
+		}
+
+		if !l.acquire(stream.Context(), transportGRPC) {
+			return ErrTooManyRequests
+		}
+		defer l.release()
+
+		return handler(srv, stream)
+	}
+}
+
+// NewHTTPHandler wraps an http.Handler so that it admits requests through the Limiter like NewUnaryInterceptor
+// does. Requests that are not admitted get a 429 Too Many Requests response. The health check endpoint is never
+// limited.
+func NewHTTPHandler(l *Limiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == healthzPath {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !l.acquire(r.Context(), transportHTTP) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = w.Write([]byte(`{"code":"resource_exhausted","message":"Too many concurrent requests. Please retry the request"}`))
+			return
+		}
+		defer l.release()
+
+		next.ServeHTTP(w, r)
+	})
+}
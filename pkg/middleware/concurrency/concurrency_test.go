@@ -0,0 +1,109 @@
+package concurrency
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	l := NewLimiter(2, 0, 0)
+	require.True(t, l.acquire(ctx, transportGRPC))
+	require.True(t, l.acquire(ctx, transportGRPC))
+
+	// without a queue requests over the limit are rejected right away
+	require.False(t, l.acquire(ctx, transportGRPC))
+
+	l.release()
+	require.True(t, l.acquire(ctx, transportGRPC))
+}
+
+func TestLimiterQueue(t *testing.T) {
+	ctx := context.Background()
+
+	l := NewLimiter(1, 1, time.Hour)
+	require.True(t, l.acquire(ctx, transportGRPC))
+
+	admitted := make(chan bool)
+	go func() {
+		admitted <- l.acquire(ctx, transportGRPC)
+	}()
+
+	// wait until the request above is queued, so that the queue is full
+	require.Eventually(t, func() bool { return len(l.queue) == 1 }, time.Second, time.Millisecond)
+	require.False(t, l.acquire(ctx, transportGRPC))
+
+	l.release()
+	require.True(t, <-admitted)
+}
+
+func TestLimiterQueueTimeout(t *testing.T) {
+	l := NewLimiter(1, 1, 10*time.Millisecond)
+	require.True(t, l.acquire(context.Background(), transportGRPC))
+	require.False(t, l.acquire(context.Background(), transportGRPC))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	l = NewLimiter(1, 1, time.Hour)
+	require.True(t, l.acquire(ctx, transportGRPC))
+	require.False(t, l.acquire(ctx, transportGRPC))
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	l := NewLimiter(1, 0, 0)
+	interceptor := NewUnaryInterceptor(l)
+
+	blockingHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.ErrorIs(t, err, ErrTooManyRequests)
+
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, blockingHandler)
+	require.NoError(t, err)
+
+	// the slot is released once the request has finished
+	_, err = interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	l := NewLimiter(1, 0, 0)
+
+	var handler http.Handler
+	handler = NewHTTPHandler(l, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stores" {
+			return
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stores/1", nil))
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+		rec = httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/stores", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+}
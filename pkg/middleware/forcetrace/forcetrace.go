@@ -0,0 +1,55 @@
+// Package forcetrace contains middleware to force the sampling of a request's traces.
+package forcetrace
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"github.com/openfga/openfga/pkg/telemetry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// ForceTraceHeader is the header a client sends to force the sampling of its request. The header
+// value must match the configured shared secret, otherwise the header is ignored.
+const ForceTraceHeader = "x-openfga-force-trace"
+
+// NewUnaryInterceptor creates a grpc.UnaryServerInterceptor which forces the request's spans to be
+// sampled if the ForceTraceHeader matches the provided secret. It must come before the trace
+// interceptor so that the sampling decision of the request's root span is affected.
+func NewUnaryInterceptor(secret string) grpc.UnaryServerInterceptor {
+	return interceptors.UnaryServerInterceptor(reportable(secret))
+}
+
+// NewStreamingInterceptor creates a grpc.StreamServerInterceptor which forces the request's spans to
+// be sampled if the ForceTraceHeader matches the provided secret. It must come before the trace
+// interceptor so that the sampling decision of the request's root span is affected.
+func NewStreamingInterceptor(secret string) grpc.StreamServerInterceptor {
+	return interceptors.StreamServerInterceptor(reportable(secret))
+}
+
+func reportable(secret string) interceptors.CommonReportableFunc {
+	return func(ctx context.Context, c interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+		if secret != "" && headerMatches(ctx, secret) {
+			ctx = telemetry.ContextWithForcedSampling(ctx)
+		}
+
+		return interceptors.NoopReporter{}, ctx
+	}
+}
+
+func headerMatches(ctx context.Context, secret string) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+
+	for _, v := range md.Get(ForceTraceHeader) {
+		if subtle.ConstantTimeCompare([]byte(v), []byte(secret)) == 1 {
+			return true
+		}
+	}
+
+	return false
+}
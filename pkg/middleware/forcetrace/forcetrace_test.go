@@ -0,0 +1,61 @@
+package forcetrace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openfga/openfga/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestUnaryInterceptor(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		header   []string
+		expected bool
+	}{
+		{
+			name:     "no_header",
+			secret:   "s3cret",
+			expected: false,
+		},
+		{
+			name:     "header_with_matching_secret",
+			secret:   "s3cret",
+			header:   []string{ForceTraceHeader, "s3cret"},
+			expected: true,
+		},
+		{
+			name:     "header_with_wrong_secret",
+			secret:   "s3cret",
+			header:   []string{ForceTraceHeader, "true"},
+			expected: false,
+		},
+		{
+			name:     "disabled_when_secret_is_empty",
+			secret:   "",
+			header:   []string{ForceTraceHeader, ""},
+			expected: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.header != nil {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(test.header...))
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				require.Equal(t, test.expected, telemetry.ForcedSamplingFromContext(ctx))
+				return nil, nil
+			}
+
+			_, err := NewUnaryInterceptor(test.secret)(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+			require.NoError(t, err)
+		})
+	}
+}
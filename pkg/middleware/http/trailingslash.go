@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TrailingSlashPolicy is how the HTTP gateway handles request paths with a trailing slash, such as '/stores/'.
+// Whatever the policy, paths are matched case-sensitively, since they carry case-sensitive IDs.
+type TrailingSlashPolicy string
+
+const (
+	// TrailingSlashStrict routes paths exactly as they are, so '/stores/' is not found. This is the default.
+	TrailingSlashStrict TrailingSlashPolicy = "strict"
+
+	// TrailingSlashAccept serves '/stores/' like '/stores'.
+	TrailingSlashAccept TrailingSlashPolicy = "accept"
+
+	// TrailingSlashRedirect redirects '/stores/' to '/stores' with a 308 Permanent Redirect, which preserves the
+	// method and the body of the request.
+	TrailingSlashRedirect TrailingSlashPolicy = "redirect"
+)
+
+// NewTrailingSlashHandler wraps an http.Handler so that request paths with a trailing slash are handled according
+// to the policy. The root path '/' is always left as it is.
+func NewTrailingSlashHandler(policy TrailingSlashPolicy, next http.Handler) http.Handler {
+	if policy != TrailingSlashAccept && policy != TrailingSlashRedirect {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimRight(r.URL.Path, "/")
+		if path == r.URL.Path || path == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		u := *r.URL
+		u.Path = path
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+
+		if policy == TrailingSlashRedirect {
+			http.Redirect(w, r, u.RequestURI(), http.StatusPermanentRedirect)
+			return
+		}
+
+		r2 := r.Clone(r.Context())
+		r2.URL = &u
+		r2.RequestURI = u.RequestURI()
+		next.ServeHTTP(w, r2)
+	})
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrailingSlashHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/stores", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.RequestURI()))
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("root"))
+	})
+
+	tests := []struct {
+		policy           TrailingSlashPolicy
+		path             string
+		expectedCode     int
+		expectedBody     string
+		expectedLocation string
+	}{
+		{policy: TrailingSlashStrict, path: "/stores", expectedCode: http.StatusOK, expectedBody: "/stores"},
+		{policy: TrailingSlashStrict, path: "/stores/", expectedCode: http.StatusNotFound},
+		{policy: TrailingSlashAccept, path: "/stores", expectedCode: http.StatusOK, expectedBody: "/stores"},
+		{policy: TrailingSlashAccept, path: "/stores/", expectedCode: http.StatusOK, expectedBody: "/stores"},
+		{policy: TrailingSlashAccept, path: "/stores/?page_size=1", expectedCode: http.StatusOK, expectedBody: "/stores?page_size=1"},
+		{policy: TrailingSlashAccept, path: "/", expectedCode: http.StatusOK, expectedBody: "root"},
+		{policy: TrailingSlashRedirect, path: "/stores", expectedCode: http.StatusOK, expectedBody: "/stores"},
+		{policy: TrailingSlashRedirect, path: "/stores/?page_size=1", expectedCode: http.StatusPermanentRedirect, expectedLocation: "/stores?page_size=1"},
+		{policy: TrailingSlashRedirect, path: "/", expectedCode: http.StatusOK, expectedBody: "root"},
+		// paths are case-sensitive whatever the policy
+		{policy: TrailingSlashAccept, path: "/Stores", expectedCode: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.policy)+test.path, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewTrailingSlashHandler(test.policy, mux).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+
+			require.Equal(t, test.expectedCode, rec.Code)
+			if test.expectedBody != "" {
+				require.Equal(t, test.expectedBody, rec.Body.String())
+			}
+			if test.expectedLocation != "" {
+				require.Equal(t, test.expectedLocation, rec.Header().Get("Location"))
+			}
+		})
+	}
+}
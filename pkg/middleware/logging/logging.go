@@ -32,12 +32,12 @@ const (
 	grpcReqCompleteKey = "grpc_req_complete"
 )
 
-func NewLoggingInterceptor(logger logger.Logger) grpc.UnaryServerInterceptor {
-	return interceptors.UnaryServerInterceptor(reportable(logger))
+func NewLoggingInterceptor(logger logger.Logger, opts ...Option) grpc.UnaryServerInterceptor {
+	return interceptors.UnaryServerInterceptor(reportable(logger, opts...))
 }
 
-func NewStreamingLoggingInterceptor(logger logger.Logger) grpc.StreamServerInterceptor {
-	return interceptors.StreamServerInterceptor(reportable(logger))
+func NewStreamingLoggingInterceptor(logger logger.Logger, opts ...Option) grpc.StreamServerInterceptor {
+	return interceptors.StreamServerInterceptor(reportable(logger, opts...))
 }
 
 type reporter struct {
@@ -45,6 +45,7 @@ type reporter struct {
 	logger         logger.Logger
 	fields         []zap.Field
 	protomarshaler protojson.MarshalOptions
+	options        *reporterOptions
 }
 
 func (r *reporter) PostCall(err error, _ time.Duration) {
@@ -77,7 +78,7 @@ func (r *reporter) PostMsgSend(msg interface{}, err error, _ time.Duration) {
 	protomsg, ok := msg.(protoreflect.ProtoMessage)
 	if ok {
 		if resp, err := r.protomarshaler.Marshal(protomsg); err == nil {
-			r.fields = append(r.fields, zap.Any(rawResponseKey, json.RawMessage(resp)))
+			r.fields = append(r.fields, zap.Any(rawResponseKey, json.RawMessage(r.options.redact(resp))))
 		}
 	}
 }
@@ -87,12 +88,17 @@ func (r *reporter) PostMsgReceive(msg interface{}, _ error, _ time.Duration) {
 	protomsg, ok := msg.(protoreflect.ProtoMessage)
 	if ok {
 		if req, err := r.protomarshaler.Marshal(protomsg); err == nil {
-			r.fields = append(r.fields, zap.Any(rawRequestKey, json.RawMessage(req)))
+			r.fields = append(r.fields, zap.Any(rawRequestKey, json.RawMessage(r.options.redact(req))))
 		}
 	}
 }
 
-func reportable(l logger.Logger) interceptors.CommonReportableFunc {
+func reportable(l logger.Logger, opts ...Option) interceptors.CommonReportableFunc {
+	options := &reporterOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
 	return func(ctx context.Context, c interceptors.CallMeta) (interceptors.Reporter, context.Context) {
 		fields := []zap.Field{
 			zap.String(grpcServiceKey, c.Service),
@@ -116,6 +122,7 @@ func reportable(l logger.Logger) interceptors.CommonReportableFunc {
 			logger:         l,
 			fields:         fields,
 			protomarshaler: protojson.MarshalOptions{EmitUnpopulated: true},
+			options:        options,
 		}, ctx
 	}
 }
@@ -0,0 +1,102 @@
+package logging
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
+const (
+	// RedactionModeHash replaces the value of a redacted field with an HMAC-SHA256 of the value keyed with the
+	// key set by WithRedactionHashKey, so that the same value always results in the same hash and log entries
+	// can still be correlated, while the values can't be recovered by hashing candidates without the key.
+	RedactionModeHash = "hash"
+
+	// RedactionModeMask replaces the value of a redacted field with a fixed placeholder.
+	RedactionModeMask = "mask"
+
+	redactedPlaceholder = "[REDACTED]"
+)
+
+// Option configures the logging interceptors.
+type Option func(r *reporterOptions)
+
+type reporterOptions struct {
+	redactedFields   map[string]struct{}
+	redactionMode    string
+	redactionHashKey []byte
+}
+
+// WithRedactedFields redacts the string values of the given fields (e.g. 'user' or 'object') wherever
+// they appear in the logged requests and responses. The mode must be RedactionModeHash or RedactionModeMask.
+func WithRedactedFields(mode string, fields ...string) Option {
+	return func(r *reporterOptions) {
+		if len(fields) == 0 {
+			return
+		}
+
+		r.redactionMode = mode
+		r.redactedFields = make(map[string]struct{}, len(fields))
+		for _, field := range fields {
+			r.redactedFields[field] = struct{}{}
+		}
+	}
+}
+
+// WithRedactionHashKey sets the secret key of the HMAC that replaces the values of the redacted fields in
+// RedactionModeHash.
+func WithRedactionHashKey(key []byte) Option {
+	return func(r *reporterOptions) {
+		r.redactionHashKey = key
+	}
+}
+
+// redact returns a copy of the JSON message with the values of the redacted fields replaced.
+func (r *reporterOptions) redact(msg []byte) []byte {
+	if len(r.redactedFields) == 0 {
+		return msg
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(msg, &decoded); err != nil {
+		return msg
+	}
+
+	redacted, err := json.Marshal(r.redactValue(decoded, false))
+	if err != nil {
+		return msg
+	}
+
+	return redacted
+}
+
+func (r *reporterOptions) redactValue(value interface{}, redact bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			_, redactField := r.redactedFields[key]
+			v[key] = r.redactValue(nested, redactField)
+		}
+		return v
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = r.redactValue(nested, redact)
+		}
+		return v
+	case string:
+		if !redact {
+			return v
+		}
+
+		if r.redactionMode == RedactionModeMask {
+			return redactedPlaceholder
+		}
+
+		mac := hmac.New(sha256.New, r.redactionHashKey)
+		mac.Write([]byte(v))
+		return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+	default:
+		return v
+	}
+}
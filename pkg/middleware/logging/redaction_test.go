@@ -0,0 +1,59 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedact(t *testing.T) {
+	msg := []byte(`{"store_id":"01GXSA8YR785C4FYS3C0RTG7B1","tuple_key":{"object":"document:1","relation":"viewer","user":"user:anne"}}`)
+
+	t.Run("no_redacted_fields", func(t *testing.T) {
+		options := &reporterOptions{}
+		WithRedactedFields(RedactionModeHash)(options)
+
+		require.Equal(t, msg, options.redact(msg))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		options := &reporterOptions{}
+		WithRedactedFields(RedactionModeHash, "user", "object")(options)
+		WithRedactionHashKey([]byte("secret"))(options)
+
+		redacted := options.redact(msg)
+		require.JSONEq(t, `{
+			"store_id":"01GXSA8YR785C4FYS3C0RTG7B1",
+			"tuple_key":{
+				"object":"hmac-sha256:a45fbc1e2068f9f0154ff754e3afbcf2722e4792227809fe49a005fd40a42b55",
+				"relation":"viewer",
+				"user":"hmac-sha256:c5202ee49f97173e6b36bbece7445c59855b5b45cfa7127f7618078711013904"
+			}
+		}`, string(redacted))
+
+		// the same input always results in the same hash
+		require.Equal(t, redacted, options.redact(msg))
+
+		// the hash depends on the key
+		WithRedactionHashKey([]byte("other"))(options)
+		require.JSONEq(t, `{
+			"store_id":"01GXSA8YR785C4FYS3C0RTG7B1",
+			"tuple_key":{
+				"object":"hmac-sha256:a37ce43af97188792f4dcb2fda023525eeb4ffa83cb872b65f117067f132d657",
+				"relation":"viewer",
+				"user":"hmac-sha256:21ebe6a09976a74319760c4b46251ee1a61d2bc050fcb6508f63259fa8136937"
+			}
+		}`, string(options.redact(msg)))
+	})
+
+	t.Run("mask", func(t *testing.T) {
+		options := &reporterOptions{}
+		WithRedactedFields(RedactionModeMask, "user")(options)
+
+		redacted := options.redact(msg)
+		require.JSONEq(t, `{
+			"store_id":"01GXSA8YR785C4FYS3C0RTG7B1",
+			"tuple_key":{"object":"document:1","relation":"viewer","user":"[REDACTED]"}
+		}`, string(redacted))
+	})
+}
@@ -0,0 +1,115 @@
+// Package slowstart contains middleware that gradually increases the number of requests the server
+// admits concurrently after it starts, so that its caches can warm up before it receives full traffic.
+package slowstart
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const healthServicePrefix = "/grpc.health.v1.Health/"
+
+// ErrWarmingUp is returned when a request is rejected because the server is still warming up. It uses
+// the standard Unavailable code so that clients treat it as retryable.
+var ErrWarmingUp = status.Error(codes.Unavailable, "Server is warming up. Please retry the request")
+
+// Limiter limits the number of requests served concurrently while the server warms up. The limit
+// increases linearly from 1 to maxConcurrency over the ramp duration, after which requests are no
+// longer limited.
+type Limiter struct {
+	start          time.Time
+	duration       time.Duration
+	maxConcurrency uint32
+
+	mu       sync.Mutex
+	inflight uint32
+
+	now func() time.Time
+}
+
+// NewLimiter returns a Limiter whose ramp starts now and lasts for the provided duration.
+func NewLimiter(duration time.Duration, maxConcurrency uint32) *Limiter {
+	return &Limiter{
+		start:          time.Now(),
+		duration:       duration,
+		maxConcurrency: maxConcurrency,
+		now:            time.Now,
+	}
+}
+
+// limit returns the current concurrency limit, and false if the ramp has ended.
+func (l *Limiter) limit() (uint32, bool) {
+	elapsed := l.now().Sub(l.start)
+	if elapsed >= l.duration {
+		return 0, false
+	}
+
+	limit := uint32(float64(l.maxConcurrency) * float64(elapsed) / float64(l.duration))
+	if limit < 1 {
+		limit = 1
+	}
+
+	return limit, true
+}
+
+// acquire admits a request if the limit allows it. If it returns true, release must be called once the
+// request has finished.
+func (l *Limiter) acquire() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limit, ramping := l.limit()
+	if ramping && l.inflight >= limit {
+		return false
+	}
+
+	l.inflight++
+	return true
+}
+
+func (l *Limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inflight--
+}
+
+// NewUnaryInterceptor creates a grpc.UnaryServerInterceptor which rejects requests that exceed the
+// current limit of the Limiter with ErrWarmingUp. Health checks are never rejected.
+func NewUnaryInterceptor(l *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if !l.acquire() {
+			return nil, ErrWarmingUp
+		}
+		defer l.release()
+
+		return handler(ctx, req)
+	}
+}
+
+// NewStreamingInterceptor creates a grpc.StreamServerInterceptor which rejects requests that exceed the
+// current limit of the Limiter with ErrWarmingUp. Health checks are never rejected.
+func NewStreamingInterceptor(l *Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if strings.HasPrefix(info.FullMethod, healthServicePrefix) {
+			return handler(srv, stream)
+		}
+
+		if !l.acquire() {
+			return ErrWarmingUp
+		}
+		defer l.release()
+
+		return handler(srv, stream)
+	}
+}
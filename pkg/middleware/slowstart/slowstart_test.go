@@ -0,0 +1,60 @@
+package slowstart
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+
+	l := NewLimiter(10*time.Second, 100)
+	l.start = now
+	l.now = func() time.Time { return now }
+
+	// at the start of the ramp only a single request is admitted
+	require.True(t, l.acquire())
+	require.False(t, l.acquire())
+
+	// half way through the ramp half of the max concurrency is admitted
+	now = now.Add(5 * time.Second)
+	for i := 1; i < 50; i++ {
+		require.True(t, l.acquire())
+	}
+	require.False(t, l.acquire())
+
+	l.release()
+	require.True(t, l.acquire())
+
+	// once the ramp has ended requests are no longer limited
+	now = now.Add(5 * time.Second)
+	for i := 0; i < 1000; i++ {
+		require.True(t, l.acquire())
+	}
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	l := NewLimiter(time.Hour, 100)
+	interceptor := NewUnaryInterceptor(l)
+
+	blockingHandler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.ErrorIs(t, err, ErrWarmingUp)
+
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, nil
+		})
+		require.NoError(t, err)
+
+		return nil, nil
+	}
+
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/openfga.v1.OpenFGAService/Check"}, blockingHandler)
+	require.NoError(t, err)
+}
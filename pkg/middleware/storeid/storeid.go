@@ -7,6 +7,7 @@ import (
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"github.com/openfga/openfga/pkg/telemetry"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
@@ -68,6 +69,20 @@ func NewStreamingInterceptor() grpc.StreamServerInterceptor {
 	return interceptors.StreamServerInterceptor(reportable())
 }
 
+// NewSamplingUnaryInterceptor creates a grpc.UnaryServerInterceptor which makes the store ID of the request available
+// to the trace sampler, so that the store sampling ratios set with telemetry.WithStoreSamplingRatios apply. It must
+// come before the trace interceptor so that the sampling decision of the request's root span is affected. Streaming
+// requests receive their message after the root span is started, so they are sampled with the default ratio.
+func NewSamplingUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if r, ok := req.(hasGetStoreID); ok && r.GetStoreId() != "" {
+			ctx = telemetry.ContextWithSamplingStoreID(ctx, r.GetStoreId())
+		}
+
+		return handler(ctx, req)
+	}
+}
+
 type reporter struct {
 	ctx context.Context
 }
@@ -1,6 +1,24 @@
 // Package commands contains the code that handles each endpoint.
 package commands
 
-import "go.opentelemetry.io/otel"
+import (
+	"github.com/openfga/openfga/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// resolverComponent is the component of the commands that resolve relationships.
+	resolverComponent = "resolver"
+
+	// writerComponent is the component of the commands that write relationships.
+	writerComponent = "writer"
+)
 
 var tracer = otel.Tracer("openfga/pkg/server/commands")
+
+// componentAttribute returns the telemetry.ComponentKey span attribute of the given component, which each command
+// sets on the spans it starts.
+func componentAttribute(component string) attribute.KeyValue {
+	return telemetry.ComponentKey.String(component)
+}
@@ -97,6 +97,34 @@ type ConnectedObjectsCommand struct {
 
 	// Limit limits the results yielded by the ConnectedObjects API.
 	Limit uint32
+
+	// MaxPathsExplored limits the number of tuples (edges of the relationship graph) read while searching for
+	// connected objects. Once it is reached, the search stops and Truncated reports true. If 0, there is no limit.
+	MaxPathsExplored uint32
+
+	pathsExplored uint32
+	truncated     uint32
+}
+
+// explorePath counts a tuple read while searching for connected objects against MaxPathsExplored, and reports
+// whether the search may continue.
+func (c *ConnectedObjectsCommand) explorePath() bool {
+	if c.MaxPathsExplored == 0 {
+		return true
+	}
+
+	if atomic.AddUint32(&c.pathsExplored, 1) > c.MaxPathsExplored {
+		atomic.StoreUint32(&c.truncated, 1)
+		return false
+	}
+
+	return true
+}
+
+// Truncated reports whether the search for connected objects stopped early because MaxPathsExplored was reached,
+// in which case the objects yielded are incomplete.
+func (c *ConnectedObjectsCommand) Truncated() bool {
+	return atomic.LoadUint32(&c.truncated) == 1
 }
 
 type ConditionalResultStatus int
@@ -230,6 +258,7 @@ func (c *ConnectedObjectsCommand) StreamedConnectedObjects(
 	resultChan chan<- *ConnectedObjectsResult, // object string (e.g. document:1)
 ) error {
 	ctx, span := tracer.Start(ctx, "StreamedConnectedObjects", trace.WithAttributes(
+		componentAttribute(resolverComponent),
 		attribute.String("object_type", req.ObjectType),
 		attribute.String("relation", req.Relation),
 		attribute.String("user", req.User.String()),
@@ -320,6 +349,10 @@ func (c *ConnectedObjectsCommand) reverseExpandTupleToUserset(
 			return err
 		}
 
+		if !c.explorePath() {
+			break
+		}
+
 		tk := t.GetKey()
 
 		foundObject := tk.GetObject()
@@ -469,6 +502,10 @@ func (c *ConnectedObjectsCommand) reverseExpandDirect(
 			return err
 		}
 
+		if !c.explorePath() {
+			break
+		}
+
 		tk := t.GetKey()
 
 		foundObject := tk.GetObject()
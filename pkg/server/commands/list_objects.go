@@ -47,6 +47,27 @@ type ListObjectsQuery struct {
 	ListObjectsMaxResults uint32
 	ResolveNodeLimit      uint32
 	CheckConcurrencyLimit uint32
+
+	// StrictTupleValidation validates the contextual tuples with validation.ValidateTupleStrict.
+	StrictTupleValidation bool
+
+	// TupleKeyLimits bounds the length of the fields of the contextual tuples.
+	TupleKeyLimits validation.TupleKeyLimits
+
+	// DeduplicateResults makes sure that an object related to the user through multiple paths is returned only
+	// once. Duplicates are dropped before the results are counted against ListObjectsMaxResults, so the cap
+	// counts distinct objects.
+	DeduplicateResults bool
+
+	// MaxPathsExplored bounds the number of tuples read while searching for the objects related to the user, so
+	// that a user broadly related to many objects through deep relations can't make a single request monopolize
+	// resources. Once it is reached, the objects found so far are returned and OnTruncated is called. If 0, there
+	// is no limit.
+	MaxPathsExplored uint32
+
+	// OnTruncated, if set, is called before the results are returned when they are incomplete because
+	// MaxPathsExplored was reached.
+	OnTruncated func()
 }
 
 type ListObjectsResult struct {
@@ -71,6 +92,7 @@ func (q *ListObjectsQuery) evaluate(
 	req listObjectsRequest,
 	resultsChan chan<- ListObjectsResult,
 	maxResults uint32,
+	truncated *uint32,
 ) error {
 
 	targetObjectType := req.GetType()
@@ -85,8 +107,17 @@ func (q *ListObjectsQuery) evaluate(
 		return serverErrors.ValidationError(typesystem.ErrInvalidSchemaVersion)
 	}
 
+	validateTuple := validation.ValidateTuple
+	if q.StrictTupleValidation {
+		validateTuple = validation.ValidateTupleStrict
+	}
+
 	for _, ctxTuple := range req.GetContextualTuples().GetTupleKeys() {
-		if err := validation.ValidateTuple(typesys, ctxTuple); err != nil {
+		if err := validation.ValidateTupleKeyLimits(ctxTuple, q.TupleKeyLimits); err != nil {
+			return serverErrors.HandleTupleValidateError(err)
+		}
+
+		if err := validateTuple(typesys, ctxTuple); err != nil {
 			return serverErrors.HandleTupleValidateError(err)
 		}
 	}
@@ -136,12 +167,28 @@ func (q *ListObjectsQuery) evaluate(
 
 		connectedObjectsResChan := make(chan *ConnectedObjectsResult, 1)
 		var objectsFound = new(uint32)
+		var returnedObjects sync.Map
+
+		// sendResult sends an object that is related to the user, unless the maximum number of results has
+		// been reached or the object has already been sent.
+		sendResult := func(object string) {
+			if q.DeduplicateResults {
+				if _, sent := returnedObjects.LoadOrStore(object, struct{}{}); sent {
+					return
+				}
+			}
+
+			if atomic.AddUint32(objectsFound, 1) <= maxResults {
+				resultsChan <- ListObjectsResult{ObjectID: object}
+			}
+		}
 
 		connectedObjectsCmd := &ConnectedObjectsCommand{
 			Datastore:        q.Datastore,
 			Typesystem:       typesys,
 			ResolveNodeLimit: q.ResolveNodeLimit,
 			Limit:            maxResults,
+			MaxPathsExplored: q.MaxPathsExplored,
 		}
 
 		go func() {
@@ -156,13 +203,21 @@ func (q *ListObjectsQuery) evaluate(
 				resultsChan <- ListObjectsResult{Err: err}
 			}
 
+			if connectedObjectsCmd.Truncated() {
+				atomic.StoreUint32(truncated, 1)
+			}
+
 			close(connectedObjectsResChan)
 		}()
 
 		limitedTupleReader := storagewrappers.NewBoundedConcurrencyTupleReader(q.Datastore, q.CheckConcurrencyLimit)
 
+		// the checks of the candidate objects often read the same tuples, e.g. those of a common parent,
+		// so identical reads are coalesced for the duration of the request
+		coalescingTupleReader := storagewrappers.NewCoalescingTupleReader(limitedTupleReader)
+
 		checkResolver := graph.NewLocalChecker(
-			storage.NewCombinedTupleReader(limitedTupleReader, req.GetContextualTuples().GetTupleKeys()),
+			storage.NewCombinedTupleReader(coalescingTupleReader, req.GetContextualTuples().GetTupleKeys()),
 			q.CheckConcurrencyLimit,
 		)
 
@@ -175,9 +230,7 @@ func (q *ListObjectsQuery) evaluate(
 			if res.ResultStatus == NoFurtherEvalStatus {
 				noFurtherEvalRequiredCounter.Inc()
 
-				if atomic.AddUint32(objectsFound, 1) <= maxResults {
-					resultsChan <- ListObjectsResult{ObjectID: res.Object}
-				}
+				sendResult(res.Object)
 
 				continue
 			}
@@ -207,8 +260,8 @@ func (q *ListObjectsQuery) evaluate(
 					return
 				}
 
-				if resp.Allowed && atomic.AddUint32(objectsFound, 1) <= maxResults {
-					resultsChan <- ListObjectsResult{ObjectID: res.Object}
+				if resp.Allowed {
+					sendResult(res.Object)
 				}
 			}(res)
 		}
@@ -243,7 +296,8 @@ func (q *ListObjectsQuery) Execute(
 		defer cancel()
 	}
 
-	err := q.evaluate(timeoutCtx, req, resultsChan, maxResults)
+	var truncated uint32
+	err := q.evaluate(timeoutCtx, req, resultsChan, maxResults, &truncated)
 	if err != nil {
 		return nil, err
 	}
@@ -254,10 +308,7 @@ func (q *ListObjectsQuery) Execute(
 		select {
 
 		case <-timeoutCtx.Done():
-			q.Logger.WarnWithContext(
-				ctx, "list objects timeout with list object configuration timeout",
-				zap.String("timeout duration", q.ListObjectsDeadline.String()),
-			)
+			q.logDeadlineExceeded(ctx)
 			return &openfgapb.ListObjectsResponse{
 				Objects: objects,
 			}, nil
@@ -267,10 +318,20 @@ func (q *ListObjectsQuery) Execute(
 				if errors.Is(result.Err, serverErrors.AuthorizationModelResolutionTooComplex) {
 					return nil, result.Err
 				}
+
+				if exceededListObjectsDeadline(ctx, result.Err) {
+					q.logDeadlineExceeded(ctx)
+					return &openfgapb.ListObjectsResponse{
+						Objects: objects,
+					}, nil
+				}
+
 				return nil, serverErrors.HandleError("", result.Err)
 			}
 
 			if !channelOpen {
+				q.reportTruncated(ctx, &truncated)
+
 				return &openfgapb.ListObjectsResponse{
 					Objects: objects,
 				}, nil
@@ -300,7 +361,8 @@ func (q *ListObjectsQuery) ExecuteStreamed(
 		defer cancel()
 	}
 
-	err := q.evaluate(timeoutCtx, req, resultsChan, maxResults)
+	var truncated uint32
+	err := q.evaluate(timeoutCtx, req, resultsChan, maxResults, &truncated)
 	if err != nil {
 		return err
 	}
@@ -309,15 +371,14 @@ func (q *ListObjectsQuery) ExecuteStreamed(
 		select {
 
 		case <-timeoutCtx.Done():
-			q.Logger.WarnWithContext(
-				ctx, "list objects timeout with list object configuration timeout",
-				zap.String("timeout duration", q.ListObjectsDeadline.String()),
-			)
+			q.logDeadlineExceeded(ctx)
 			return nil
 
 		case result, channelOpen := <-resultsChan:
 			if !channelOpen {
 				// Channel closed! No more results.
+				q.reportTruncated(ctx, &truncated)
+
 				return nil
 			}
 
@@ -326,6 +387,11 @@ func (q *ListObjectsQuery) ExecuteStreamed(
 					return result.Err
 				}
 
+				if exceededListObjectsDeadline(ctx, result.Err) {
+					q.logDeadlineExceeded(ctx)
+					return nil
+				}
+
 				return serverErrors.HandleError("", result.Err)
 			}
 
@@ -337,3 +403,32 @@ func (q *ListObjectsQuery) ExecuteStreamed(
 		}
 	}
 }
+
+// exceededListObjectsDeadline reports whether err was caused by the ListObjectsDeadline rather than by the deadline
+// of the request, in which case the objects found so far are returned like when the deadline fires between results.
+func exceededListObjectsDeadline(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil
+}
+
+func (q *ListObjectsQuery) logDeadlineExceeded(ctx context.Context) {
+	q.Logger.WarnWithContext(
+		ctx, "list objects timeout with list object configuration timeout",
+		zap.String("timeout duration", q.ListObjectsDeadline.String()),
+	)
+}
+
+// reportTruncated calls OnTruncated if the evaluation stopped because MaxPathsExplored was reached.
+func (q *ListObjectsQuery) reportTruncated(ctx context.Context, truncated *uint32) {
+	if atomic.LoadUint32(truncated) == 0 {
+		return
+	}
+
+	q.Logger.WarnWithContext(
+		ctx, "list objects results truncated after exploring the maximum number of paths",
+		zap.Uint32("max paths explored", q.MaxPathsExplored),
+	)
+
+	if q.OnTruncated != nil {
+		q.OnTruncated()
+	}
+}
@@ -10,18 +10,48 @@ import (
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 )
 
+// MaxReadAuthorizationModelsPageSize is the largest page size that the API accepts in a ReadAuthorizationModels
+// request.
+const MaxReadAuthorizationModelsPageSize = 100
+
 type ReadAuthorizationModelsQuery struct {
-	backend storage.AuthorizationModelReadBackend
-	logger  logger.Logger
-	encoder encoder.Encoder
+	backend         storage.AuthorizationModelReadBackend
+	logger          logger.Logger
+	encoder         encoder.Encoder
+	defaultPageSize int32
+	maxPageSize     int32
 }
 
-func NewReadAuthorizationModelsQuery(backend storage.AuthorizationModelReadBackend, logger logger.Logger, encoder encoder.Encoder) *ReadAuthorizationModelsQuery {
-	return &ReadAuthorizationModelsQuery{
+type ReadAuthorizationModelsQueryOption func(*ReadAuthorizationModelsQuery)
+
+// WithReadAuthorizationModelsDefaultPageSize sets the page size of ReadAuthorizationModels requests that don't
+// set one. A value of 0 means storage.DefaultPageSize.
+func WithReadAuthorizationModelsDefaultPageSize(defaultPageSize int32) ReadAuthorizationModelsQueryOption {
+	return func(q *ReadAuthorizationModelsQuery) {
+		q.defaultPageSize = defaultPageSize
+	}
+}
+
+// WithReadAuthorizationModelsMaxPageSize caps the page size of ReadAuthorizationModels requests. Requests asking
+// for a larger page are served with maxPageSize models and a continuation token. A value of 0 means no cap.
+func WithReadAuthorizationModelsMaxPageSize(maxPageSize int32) ReadAuthorizationModelsQueryOption {
+	return func(q *ReadAuthorizationModelsQuery) {
+		q.maxPageSize = maxPageSize
+	}
+}
+
+func NewReadAuthorizationModelsQuery(backend storage.AuthorizationModelReadBackend, logger logger.Logger, encoder encoder.Encoder, opts ...ReadAuthorizationModelsQueryOption) *ReadAuthorizationModelsQuery {
+	q := &ReadAuthorizationModelsQuery{
 		backend: backend,
 		logger:  logger,
 		encoder: encoder,
 	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
 }
 
 func (q *ReadAuthorizationModelsQuery) Execute(ctx context.Context, req *openfgapb.ReadAuthorizationModelsRequest) (*openfgapb.ReadAuthorizationModelsResponse, error) {
@@ -30,7 +60,14 @@ func (q *ReadAuthorizationModelsQuery) Execute(ctx context.Context, req *openfga
 		return nil, serverErrors.InvalidContinuationToken
 	}
 
-	paginationOptions := storage.NewPaginationOptions(req.GetPageSize().GetValue(), string(decodedContToken))
+	pageSize := req.GetPageSize().GetValue()
+	if pageSize == 0 {
+		pageSize = q.defaultPageSize
+	}
+	if q.maxPageSize > 0 && pageSize > q.maxPageSize {
+		pageSize = q.maxPageSize
+	}
+	paginationOptions := storage.NewPaginationOptions(pageSize, string(decodedContToken))
 
 	models, contToken, err := q.backend.ReadAuthorizationModels(ctx, req.GetStoreId(), paginationOptions)
 	if err != nil {
@@ -17,16 +17,39 @@ type ReadChangesQuery struct {
 	logger        logger.Logger
 	encoder       encoder.Encoder
 	horizonOffset time.Duration
+	maxPageSize   int32
 }
 
-// NewReadChangesQuery creates a ReadChangesQuery with specified `ChangelogBackend` and `typeDefinitionReadBackend` to use for storage
-func NewReadChangesQuery(backend storage.ChangelogBackend, logger logger.Logger, encoder encoder.Encoder, horizonOffset int) *ReadChangesQuery {
-	return &ReadChangesQuery{
+type ReadChangesQueryOption func(*ReadChangesQuery)
+
+// WithReadChangesMaxPageSize caps the page size of ReadChanges requests. Requests asking for a larger
+// page are served with maxPageSize changes and a continuation token. A value of 0 means no cap.
+func WithReadChangesMaxPageSize(maxPageSize int32) ReadChangesQueryOption {
+	return func(q *ReadChangesQuery) {
+		q.maxPageSize = maxPageSize
+	}
+}
+
+// NewReadChangesQuery creates a ReadChangesQuery with specified `ChangelogBackend` and `typeDefinitionReadBackend` to use for storage.
+// Changes newer than horizonOffset minutes are never returned, regardless of the continuation token; a negative
+// horizonOffset is treated as 0 so that changes can't be read before they are committed.
+func NewReadChangesQuery(backend storage.ChangelogBackend, logger logger.Logger, encoder encoder.Encoder, horizonOffset int, opts ...ReadChangesQueryOption) *ReadChangesQuery {
+	if horizonOffset < 0 {
+		horizonOffset = 0
+	}
+
+	q := &ReadChangesQuery{
 		backend:       backend,
 		logger:        logger,
 		encoder:       encoder,
 		horizonOffset: time.Duration(horizonOffset) * time.Minute,
 	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
 }
 
 // Execute the ReadChangesQuery, returning paginated `openfga.TupleChange`(s) and a possibly non-empty continuation token.
@@ -35,7 +58,12 @@ func (q *ReadChangesQuery) Execute(ctx context.Context, req *openfgapb.ReadChang
 	if err != nil {
 		return nil, serverErrors.InvalidContinuationToken
 	}
-	paginationOptions := storage.NewPaginationOptions(req.GetPageSize().GetValue(), string(decodedContToken))
+
+	pageSize := req.GetPageSize().GetValue()
+	if q.maxPageSize > 0 && pageSize > q.maxPageSize {
+		pageSize = q.maxPageSize
+	}
+	paginationOptions := storage.NewPaginationOptions(pageSize, string(decodedContToken))
 
 	changes, contToken, err := q.backend.ReadChanges(ctx, req.StoreId, req.Type, paginationOptions, q.horizonOffset)
 	if err != nil {
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	parser "github.com/craigpastro/openfga-dsl-parser/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/logger"
+	serverErrors "github.com/openfga/openfga/pkg/server/errors"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/typesystem"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const (
+	defaultValidateModelTuplesPageSize   = 100
+	defaultValidateModelTuplesMaxScanned = 10000
+)
+
+// InvalidTuple is an existing tuple that a proposed authorization model does not allow.
+type InvalidTuple struct {
+	TupleKey *openfgapb.TupleKey
+
+	// Reason describes why the tuple is invalid under the proposed model.
+	Reason string
+}
+
+// ValidateModelTuplesResult summarizes a scan of the tuples of a store against a proposed authorization model.
+type ValidateModelTuplesResult struct {
+	TuplesScanned int
+	InvalidTuples int
+
+	// ContinuationToken is set if the scan stopped before reaching the end of the store because the maximum number
+	// of tuples to scan was reached. Passing it to a subsequent call resumes the scan where it stopped.
+	ContinuationToken string
+}
+
+// ValidateModelTuplesQuery scans the tuples of a store and reports the ones that a proposed authorization model
+// does not allow, for example because the model removes a type or a relation, or restricts the types of users
+// that can be directly related to a relation.
+type ValidateModelTuplesQuery struct {
+	datastore  storage.OpenFGADatastore
+	logger     logger.Logger
+	encoder    encoder.Encoder
+	pageSize   int
+	maxScanned int
+}
+
+type ValidateModelTuplesQueryOption func(*ValidateModelTuplesQuery)
+
+// WithValidateModelTuplesPageSize sets the number of tuples read from the datastore at a time.
+func WithValidateModelTuplesPageSize(pageSize int) ValidateModelTuplesQueryOption {
+	return func(q *ValidateModelTuplesQuery) {
+		q.pageSize = pageSize
+	}
+}
+
+// WithValidateModelTuplesMaxScanned bounds the number of tuples scanned by a single call to Execute. If it is not
+// greater than 0, the whole store is scanned in a single call.
+func WithValidateModelTuplesMaxScanned(maxScanned int) ValidateModelTuplesQueryOption {
+	return func(q *ValidateModelTuplesQuery) {
+		q.maxScanned = maxScanned
+	}
+}
+
+func NewValidateModelTuplesQuery(datastore storage.OpenFGADatastore, logger logger.Logger, encoder encoder.Encoder, opts ...ValidateModelTuplesQueryOption) *ValidateModelTuplesQuery {
+	q := &ValidateModelTuplesQuery{
+		datastore:  datastore,
+		logger:     logger,
+		encoder:    encoder,
+		pageSize:   defaultValidateModelTuplesPageSize,
+		maxScanned: defaultValidateModelTuplesMaxScanned,
+	}
+
+	for _, opt := range opts {
+		opt(q)
+	}
+
+	return q
+}
+
+// Execute validates the proposed model and scans the tuples of the store, starting from the continuation token if
+// it is set, page by page. onInvalid is called for every tuple the model does not allow as soon as it is found, so
+// results can be streamed to the caller; if it returns an error, the scan stops and the error is returned.
+func (q *ValidateModelTuplesQuery) Execute(
+	ctx context.Context,
+	storeID string,
+	model *openfgapb.AuthorizationModel,
+	continuationToken string,
+	onInvalid func(*InvalidTuple) error,
+) (*ValidateModelTuplesResult, error) {
+	typesys, err := typesystem.NewAndValidate(ctx, model)
+	if err != nil {
+		return nil, serverErrors.InvalidAuthorizationModelInput(err)
+	}
+
+	decodedContToken, err := q.encoder.Decode(continuationToken)
+	if err != nil {
+		return nil, serverErrors.InvalidContinuationToken
+	}
+	from := string(decodedContToken)
+
+	result := &ValidateModelTuplesResult{}
+	for {
+		pageSize := q.pageSize
+		if remaining := q.maxScanned - result.TuplesScanned; q.maxScanned > 0 && remaining < pageSize {
+			pageSize = remaining
+		}
+
+		tuples, contToken, err := q.datastore.ReadPage(ctx, storeID, nil, storage.NewPaginationOptions(int32(pageSize), from))
+		if err != nil {
+			return nil, serverErrors.HandleError("", err)
+		}
+
+		for _, t := range tuples {
+			result.TuplesScanned++
+
+			if err := validation.ValidateTuple(typesys, t.GetKey()); err != nil {
+				result.InvalidTuples++
+
+				if err := onInvalid(&InvalidTuple{TupleKey: t.GetKey(), Reason: err.Error()}); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if len(contToken) == 0 {
+			return result, nil
+		}
+
+		if q.maxScanned > 0 && result.TuplesScanned >= q.maxScanned {
+			result.ContinuationToken, err = q.encoder.Encode(contToken)
+			if err != nil {
+				return nil, serverErrors.HandleError("", err)
+			}
+
+			return result, nil
+		}
+
+		from = string(contToken)
+	}
+}
+
+// ParseAuthorizationModel parses a proposed authorization model written either in JSON, in the format of the body of
+// a WriteAuthorizationModel request, or in the DSL. The returned model has a new ID.
+func ParseAuthorizationModel(data []byte) (*openfgapb.AuthorizationModel, error) {
+	req := &openfgapb.WriteAuthorizationModelRequest{}
+
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := protojson.Unmarshal(data, req); err != nil {
+			return nil, fmt.Errorf("failed to parse the authorization model as JSON: %w", err)
+		}
+	} else {
+		typeDefinitions, err := parser.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the authorization model as DSL: %w", err)
+		}
+
+		req.TypeDefinitions = typeDefinitions
+	}
+
+	schemaVersion := req.GetSchemaVersion()
+	if schemaVersion == "" {
+		schemaVersion = typesystem.SchemaVersion1_1
+	}
+
+	return &openfgapb.AuthorizationModel{
+		Id:              ulid.Make().String(),
+		SchemaVersion:   schemaVersion,
+		TypeDefinitions: req.GetTypeDefinitions(),
+	}, nil
+}
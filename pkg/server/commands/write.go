@@ -12,33 +12,106 @@ import (
 	tupleUtils "github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	IndirectWriteErrorReason = "Attempting to write directly to an indirect only relationship"
+
+	// idempotentWriteAttempts is the number of times an idempotent write is attempted when a concurrent
+	// write changes the tuples being written or deleted between the moment they are read and written.
+	idempotentWriteAttempts = 3
 )
 
 // WriteCommand is used to Write and Delete tuples. Instances may be safely shared by multiple goroutines.
 type WriteCommand struct {
-	logger    logger.Logger
-	datastore storage.OpenFGADatastore
+	logger                logger.Logger
+	datastore             storage.OpenFGADatastore
+	strictTupleValidation bool
+	idempotentWrites      bool
+	idempotentDeletes     bool
+	tupleKeyLimits        validation.TupleKeyLimits
+}
+
+type WriteCommandOption func(*WriteCommand)
+
+// WithStrictTupleValidation rejects tuples whose 'user' field does not follow the 'type:id' format
+// with a type defined in the model. See validation.ValidateTupleStrict.
+func WithStrictTupleValidation(strict bool) WriteCommandOption {
+	return func(c *WriteCommand) {
+		c.strictTupleValidation = strict
+	}
+}
+
+// WithIdempotentWrites makes writing a tuple that already exists a no-op instead of an error, so that clients
+// with at-least-once delivery can safely retry writes.
+func WithIdempotentWrites(idempotent bool) WriteCommandOption {
+	return func(c *WriteCommand) {
+		c.idempotentWrites = idempotent
+	}
+}
+
+// WithIdempotentDeletes makes deleting a tuple that does not exist a no-op instead of an error, so that clients
+// with at-least-once delivery can safely retry deletes.
+func WithIdempotentDeletes(idempotent bool) WriteCommandOption {
+	return func(c *WriteCommand) {
+		c.idempotentDeletes = idempotent
+	}
+}
+
+// WithTupleKeyLimits rejects the writes of tuples with fields longer than the limits. Deletes are not limited, so
+// that tuples written before the limits were lowered can still be deleted.
+func WithTupleKeyLimits(limits validation.TupleKeyLimits) WriteCommandOption {
+	return func(c *WriteCommand) {
+		c.tupleKeyLimits = limits
+	}
 }
 
 // NewWriteCommand creates a WriteCommand with specified storage.TupleBackend to use for storage.
-func NewWriteCommand(datastore storage.OpenFGADatastore, logger logger.Logger) *WriteCommand {
-	return &WriteCommand{
+func NewWriteCommand(datastore storage.OpenFGADatastore, logger logger.Logger, opts ...WriteCommandOption) *WriteCommand {
+	c := &WriteCommand{
 		logger:    logger,
 		datastore: datastore,
 	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
 }
 
 // Execute deletes and writes the specified tuples. Deletes are applied first, then writes.
+//
+// By default, writing a tuple that already exists or deleting a tuple that does not exist fails the whole
+// request. With WithIdempotentWrites and WithIdempotentDeletes, those tuples are skipped instead, and the
+// request succeeds as long as the rest of the tuples can be written and deleted.
 func (c *WriteCommand) Execute(ctx context.Context, req *openfgapb.WriteRequest) (*openfgapb.WriteResponse, error) {
 	if err := c.validateWriteRequest(ctx, req); err != nil {
 		return nil, err
 	}
 
-	err := c.datastore.Write(ctx, req.GetStoreId(), req.GetDeletes().GetTupleKeys(), req.GetWrites().GetTupleKeys())
+	deletes := req.GetDeletes().GetTupleKeys()
+	writes := req.GetWrites().GetTupleKeys()
+
+	if !c.idempotentWrites && !c.idempotentDeletes {
+		err := c.datastore.Write(ctx, req.GetStoreId(), deletes, writes)
+		if err != nil {
+			return nil, handleError(err)
+		}
+
+		return &openfgapb.WriteResponse{}, nil
+	}
+
+	var err error
+	for attempt := 0; attempt < idempotentWriteAttempts; attempt++ {
+		err = c.writeIdempotently(ctx, req.GetStoreId(), deletes, writes)
+		if !errors.Is(err, storage.ErrInvalidWriteInput) {
+			break
+		}
+
+		// a concurrent write changed the tuples after they were read, so they are read again
+	}
 	if err != nil {
 		return nil, handleError(err)
 	}
@@ -46,8 +119,61 @@ func (c *WriteCommand) Execute(ctx context.Context, req *openfgapb.WriteRequest)
 	return &openfgapb.WriteResponse{}, nil
 }
 
+// writeIdempotently leaves out the writes of tuples that already exist and the deletes of tuples that do not
+// exist, as configured, before writing the rest.
+func (c *WriteCommand) writeIdempotently(ctx context.Context, store string, deletes, writes []*openfgapb.TupleKey) error {
+	ctx, span := tracer.Start(ctx, "writeIdempotently", trace.WithAttributes(componentAttribute(writerComponent)))
+	defer span.End()
+
+	if c.idempotentDeletes {
+		existing := make([]*openfgapb.TupleKey, 0, len(deletes))
+		for _, tk := range deletes {
+			exists, err := c.tupleExists(ctx, store, tk)
+			if err != nil {
+				return err
+			}
+			if exists {
+				existing = append(existing, tk)
+			}
+		}
+		deletes = existing
+	}
+
+	if c.idempotentWrites {
+		missing := make([]*openfgapb.TupleKey, 0, len(writes))
+		for _, tk := range writes {
+			exists, err := c.tupleExists(ctx, store, tk)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				missing = append(missing, tk)
+			}
+		}
+		writes = missing
+	}
+
+	if len(deletes) == 0 && len(writes) == 0 {
+		return nil
+	}
+
+	return c.datastore.Write(ctx, store, deletes, writes)
+}
+
+func (c *WriteCommand) tupleExists(ctx context.Context, store string, tk *openfgapb.TupleKey) (bool, error) {
+	_, err := c.datastore.ReadUserTuple(ctx, store, tk)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
 func (c *WriteCommand) validateWriteRequest(ctx context.Context, req *openfgapb.WriteRequest) error {
-	ctx, span := tracer.Start(ctx, "validateWriteRequest")
+	ctx, span := tracer.Start(ctx, "validateWriteRequest", trace.WithAttributes(componentAttribute(writerComponent)))
 	defer span.End()
 
 	store := req.GetStoreId()
@@ -75,8 +201,17 @@ func (c *WriteCommand) validateWriteRequest(ctx context.Context, req *openfgapb.
 
 		typesys := typesystem.New(authModel)
 
+		validateTuple := validation.ValidateTuple
+		if c.strictTupleValidation {
+			validateTuple = validation.ValidateTupleStrict
+		}
+
 		for _, tk := range writes {
-			err := validation.ValidateTuple(typesys, tk)
+			if err := validation.ValidateTupleKeyLimits(tk, c.tupleKeyLimits); err != nil {
+				return serverErrors.ValidationError(err)
+			}
+
+			err := validateTuple(typesys, tk)
 			if err != nil {
 				return serverErrors.ValidationError(err)
 			}
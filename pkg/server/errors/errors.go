@@ -2,6 +2,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -25,6 +26,23 @@ var (
 	StoreIDNotFound                        = status.Error(codes.Code(openfgapb.NotFoundErrorCode_store_id_not_found), "Store ID not found")
 	MismatchObjectType                     = status.Error(codes.Code(openfgapb.ErrorCode_query_string_type_continuation_token_mismatch), "The type in the querystring and the continuation token don't match")
 	RequestCancelled                       = status.Error(codes.Code(openfgapb.InternalErrorCode_cancelled), "Request Cancelled")
+	// ServerShuttingDown uses the standard Unavailable code so that clients treat it as retryable
+	ServerShuttingDown = status.Error(codes.Unavailable, "Server is shutting down. Please retry the request")
+	// TooManyConcurrentStreams is returned when a client exceeds its limit of concurrent streaming requests
+	TooManyConcurrentStreams = status.Error(codes.ResourceExhausted, "Too many concurrent streaming requests for this client. Please retry after an existing stream has finished")
+	// TooManyConcurrentWrites is returned when a store exceeds its limit of concurrent Write requests
+	TooManyConcurrentWrites = status.Error(codes.ResourceExhausted, "Too many concurrent writes for this store. Please retry after an existing write has finished")
+	// RequestDeadlineExceeded is returned when the deadline of the request set by the client, or the upstream timeout
+	// of the HTTP server for requests made over HTTP, is exceeded before the request completes
+	RequestDeadlineExceeded = status.Error(codes.DeadlineExceeded, "The request deadline was exceeded. Retry with a longer client deadline, or a longer HTTP upstream timeout for HTTP requests")
+	// DatastoreStatementTimeoutExceeded is returned when the datastore aborts a query that runs for longer than its
+	// configured statement timeout, while the deadline of the request is not exceeded
+	DatastoreStatementTimeoutExceeded = status.Error(codes.DeadlineExceeded, "A datastore query exceeded the datastore statement timeout configured on the server")
+	// DatastoreQueryTimeoutExceeded is returned when a datastore call runs for longer than the datastore query timeout
+	// of the server, while the deadline of the request is not exceeded
+	DatastoreQueryTimeoutExceeded = status.Error(codes.DeadlineExceeded, "A datastore query exceeded the datastore query timeout configured on the server")
+	// ModelCacheBypassDisabled is returned when a request asks to bypass the model caches and the server doesn't allow it
+	ModelCacheBypassDisabled = status.Error(codes.PermissionDenied, "Bypassing the authorization model cache is not enabled on this server")
 )
 
 type InternalError struct {
@@ -71,6 +89,18 @@ func AuthorizationModelNotFound(modelID string) error {
 	return status.Error(codes.Code(openfgapb.ErrorCode_authorization_model_not_found), fmt.Sprintf("Authorization Model '%s' not found", modelID))
 }
 
+// AuthorizationModelNotLatest is returned when the server only accepts the latest authorization model of a store
+// and a request pins an older one.
+func AuthorizationModelNotLatest(modelID, latestModelID string) error {
+	return status.Error(codes.Code(openfgapb.ErrorCode_validation_error), fmt.Sprintf("Authorization Model '%s' is not the latest model of the store. Omit the authorization model ID, or use the latest model '%s'", modelID, latestModelID))
+}
+
+// AuthorizationModelNotPinned is returned when a store is pinned to an authorization model and a request sets
+// another one.
+func AuthorizationModelNotPinned(modelID, pinnedModelID string) error {
+	return status.Error(codes.Code(openfgapb.ErrorCode_validation_error), fmt.Sprintf("Authorization Model '%s' is not the model the store is pinned to. Omit the authorization model ID, or use the pinned model '%s'", modelID, pinnedModelID))
+}
+
 func LatestAuthorizationModelNotFound(store string) error {
 	return status.Error(codes.Code(openfgapb.ErrorCode_latest_authorization_model_not_found), fmt.Sprintf("No authorization models found for store '%s'", store))
 }
@@ -118,13 +148,26 @@ func WriteFailedDueToInvalidInput(err error) error {
 	return status.Error(codes.Code(openfgapb.ErrorCode_write_failed_due_to_invalid_input), "Write failed due to invalid input")
 }
 
+// InvalidTupleTTL is returned when the TTL requested for the tuples of a Write is not a positive duration.
+func InvalidTupleTTL(ttl string) error {
+	return status.Error(codes.Code(openfgapb.ErrorCode_validation_error), fmt.Sprintf("Invalid tuple TTL '%s'. It must be a positive duration such as '90s' or '24h'", ttl))
+}
+
 func InvalidAuthorizationModelInput(err error) error {
 	return status.Error(codes.Code(openfgapb.ErrorCode_invalid_authorization_model), err.Error())
 }
 
 // HandleError is used to hide internal errors from users. Use `public` to return an error message to the user.
+// Timeouts are returned with the DeadlineExceeded code, and a message that tells which timeout was exceeded: see
+// RequestDeadlineExceeded, DatastoreStatementTimeoutExceeded and DatastoreQueryTimeoutExceeded.
 func HandleError(public string, err error) error {
-	if errors.Is(err, storage.ErrInvalidContinuationToken) {
+	if errors.Is(err, storage.ErrStatementTimeout) {
+		return DatastoreStatementTimeoutExceeded
+	} else if errors.Is(err, storage.ErrQueryTimeout) {
+		return DatastoreQueryTimeoutExceeded
+	} else if errors.Is(err, context.DeadlineExceeded) {
+		return RequestDeadlineExceeded
+	} else if errors.Is(err, storage.ErrInvalidContinuationToken) {
 		return InvalidContinuationToken
 	} else if errors.Is(err, storage.ErrMismatchObjectType) {
 		return MismatchObjectType
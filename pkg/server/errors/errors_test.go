@@ -1,9 +1,12 @@
 package errors
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
 
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/stretchr/testify/require"
 )
 
@@ -19,3 +22,20 @@ func TestInternalErrorsWithNoMessageReturnsInternalServiceError(t *testing.T) {
 	expected := InternalServerErrorMsg
 	require.Contains(t, err.Error(), expected)
 }
+
+func TestHandleErrorTellsTimeoutsApart(t *testing.T) {
+	t.Run("request_deadline", func(t *testing.T) {
+		err := HandleError("", fmt.Errorf("sql error: %w", context.DeadlineExceeded))
+		require.ErrorIs(t, err, RequestDeadlineExceeded)
+	})
+
+	t.Run("datastore_statement_timeout", func(t *testing.T) {
+		err := HandleError("", fmt.Errorf("%w: canceling statement due to statement timeout", storage.ErrStatementTimeout))
+		require.ErrorIs(t, err, DatastoreStatementTimeoutExceeded)
+	})
+
+	t.Run("datastore_query_timeout", func(t *testing.T) {
+		err := HandleError("", fmt.Errorf("%w: context deadline exceeded", storage.ErrQueryTimeout))
+		require.ErrorIs(t, err, DatastoreQueryTimeoutExceeded)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"context"
 
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/openfga/openfga/pkg/logger"
+	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	healthv1pb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/status"
@@ -19,6 +21,9 @@ type Checker struct {
 	healthv1pb.UnimplementedHealthServer
 	TargetService
 	TargetServiceName string
+
+	// Logger logs why the TargetService is not ready, if set.
+	Logger logger.Logger
 }
 
 var _ grpc_auth.ServiceAuthFuncOverride = (*Checker)(nil)
@@ -31,9 +36,15 @@ func (o *Checker) AuthFuncOverride(ctx context.Context, fullMethodName string) (
 func (o *Checker) Check(ctx context.Context, req *healthv1pb.HealthCheckRequest) (*healthv1pb.HealthCheckResponse, error) {
 	requestedService := req.GetService()
 	if requestedService == "" || requestedService == o.TargetServiceName {
+		// the error is not returned, so that health checks over gRPC and over HTTP see the NOT_SERVING status
+		// instead of a failed call
 		ready, err := o.TargetService.IsReady(ctx)
 		if err != nil {
-			return &healthv1pb.HealthCheckResponse{Status: healthv1pb.HealthCheckResponse_NOT_SERVING}, err
+			if o.Logger != nil {
+				o.Logger.Warn("health check failed", zap.String("service", o.TargetServiceName), zap.Error(err))
+			}
+
+			return &healthv1pb.HealthCheckResponse{Status: healthv1pb.HealthCheckResponse_NOT_SERVING}, nil
 		}
 
 		if !ready {
@@ -0,0 +1,41 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	healthv1pb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+type targetService func(ctx context.Context) (bool, error)
+
+func (t targetService) IsReady(ctx context.Context) (bool, error) {
+	return t(ctx)
+}
+
+func TestChecker(t *testing.T) {
+	check := func(target targetService) (*healthv1pb.HealthCheckResponse, error) {
+		checker := &Checker{TargetService: target, TargetServiceName: "openfga.v1.OpenFGAService"}
+		return checker.Check(context.Background(), &healthv1pb.HealthCheckRequest{})
+	}
+
+	t.Run("ready", func(t *testing.T) {
+		res, err := check(func(context.Context) (bool, error) { return true, nil })
+		require.NoError(t, err)
+		require.Equal(t, healthv1pb.HealthCheckResponse_SERVING, res.GetStatus())
+	})
+
+	t.Run("not_ready", func(t *testing.T) {
+		res, err := check(func(context.Context) (bool, error) { return false, nil })
+		require.NoError(t, err)
+		require.Equal(t, healthv1pb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+	})
+
+	t.Run("failed_readiness_check", func(t *testing.T) {
+		res, err := check(func(context.Context) (bool, error) { return false, errors.New("connection refused") })
+		require.NoError(t, err)
+		require.Equal(t, healthv1pb.HealthCheckResponse_NOT_SERVING, res.GetStatus())
+	})
+}
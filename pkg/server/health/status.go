@@ -0,0 +1,95 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SubsystemStatus is the health of a subsystem of the server, such as the datastore, along with key details about
+// it.
+type SubsystemStatus struct {
+	Healthy bool `json:"healthy"`
+
+	// Error describes why the subsystem is unhealthy.
+	Error string `json:"error,omitempty"`
+
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// SubsystemCheck returns the current SubsystemStatus of a subsystem. It must return once ctx is done.
+type SubsystemCheck func(ctx context.Context) SubsystemStatus
+
+// Status is the detailed health of the server.
+type Status struct {
+	// Healthy is true if all the subsystems are healthy.
+	Healthy    bool                       `json:"healthy"`
+	Subsystems map[string]SubsystemStatus `json:"subsystems"`
+}
+
+// CheckStatus runs the checks concurrently, and returns the Status of the server. A check that does not return
+// before the timeout is reported as unhealthy.
+func CheckStatus(ctx context.Context, checks map[string]SubsystemCheck, timeout time.Duration) Status {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+
+	status := Status{
+		Healthy:    true,
+		Subsystems: make(map[string]SubsystemStatus, len(checks)),
+	}
+
+	for name, check := range checks {
+		wg.Add(1)
+
+		go func(name string, check SubsystemCheck) {
+			defer wg.Done()
+
+			result := make(chan SubsystemStatus, 1)
+			go func() {
+				result <- check(ctx)
+			}()
+
+			var subsystemStatus SubsystemStatus
+			select {
+			case subsystemStatus = <-result:
+			case <-ctx.Done():
+				subsystemStatus = SubsystemStatus{Error: "timed out checking the subsystem"}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			status.Subsystems[name] = subsystemStatus
+			if !subsystemStatus.Healthy {
+				status.Healthy = false
+			}
+		}(name, check)
+	}
+
+	wg.Wait()
+
+	return status
+}
+
+// NewStatusHandler returns an http.Handler that serves the Status of the server as JSON, with a 503 Service
+// Unavailable status code if a subsystem is unhealthy. Unlike the gRPC health check, which only reports whether
+// the server is serving and is meant for probes, it gives operators an overview of the health of the instance.
+func NewStatusHandler(checks map[string]SubsystemCheck, timeout time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := CheckStatus(r.Context(), checks, timeout)
+
+		w.Header().Set("Content-Type", "application/json")
+		if !status.Healthy {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		_ = json.NewEncoder(w).Encode(status)
+	})
+}
@@ -0,0 +1,62 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusHandler(t *testing.T) {
+	healthy := func(ctx context.Context) SubsystemStatus {
+		return SubsystemStatus{Healthy: true, Details: map[string]any{"items": 3}}
+	}
+	unhealthy := func(ctx context.Context) SubsystemStatus {
+		return SubsystemStatus{Error: "connection refused"}
+	}
+	hanging := func(ctx context.Context) SubsystemStatus {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return SubsystemStatus{Healthy: true}
+	}
+
+	t.Run("all_healthy", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewStatusHandler(map[string]SubsystemCheck{"datastore": healthy, "cache": healthy}, time.Second).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.JSONEq(t, `{
+			"healthy": true,
+			"subsystems": {
+				"datastore": {"healthy": true, "details": {"items": 3}},
+				"cache": {"healthy": true, "details": {"items": 3}}
+			}
+		}`, rec.Body.String())
+	})
+
+	t.Run("unhealthy_subsystem", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		NewStatusHandler(map[string]SubsystemCheck{"datastore": unhealthy, "cache": healthy}, time.Second).
+			ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var status Status
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &status))
+		require.False(t, status.Healthy)
+		require.Equal(t, SubsystemStatus{Error: "connection refused"}, status.Subsystems["datastore"])
+		require.True(t, status.Subsystems["cache"].Healthy)
+	})
+
+	t.Run("check_times_out", func(t *testing.T) {
+		status := CheckStatus(context.Background(), map[string]SubsystemCheck{"oidc": hanging}, 10*time.Millisecond)
+		require.False(t, status.Healthy)
+		require.Equal(t, "timed out checking the subsystem", status.Subsystems["oidc"].Error)
+	})
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"sync"
+
+	"google.golang.org/grpc"
+)
+
+var (
+	registeredInterceptorsMu     sync.Mutex
+	registeredUnaryInterceptors  []grpc.UnaryServerInterceptor
+	registeredStreamInterceptors []grpc.StreamServerInterceptor
+)
+
+// RegisterUnaryInterceptors registers unary interceptors, e.g. for custom authorization, logging or quotas, that
+// the gRPC server built by the run command chains after its built-in interceptors. They must be registered before
+// the server is built, typically from the init function of a package compiled into the binary behind a build tag:
+//
+//	//go:build myplugins
+//
+//	package plugins
+//
+//	func init() {
+//		server.RegisterUnaryInterceptors(quotaInterceptor)
+//	}
+//
+// The interceptors run in the following order:
+//  1. request ID, validation and context tags
+//  2. metrics, if enabled
+//  3. concurrency limit, if enabled
+//  4. slow start, if enabled
+//  5. tracing, if enabled
+//  6. store ID and logging
+//  7. authentication
+//  8. the registered interceptors, in the order they were registered
+//
+// So the registered interceptors see the request ID, the span and the authenticated claims of the request, and
+// the requests they reject are logged and measured.
+func RegisterUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) {
+	registeredInterceptorsMu.Lock()
+	defer registeredInterceptorsMu.Unlock()
+
+	registeredUnaryInterceptors = append(registeredUnaryInterceptors, interceptors...)
+}
+
+// RegisterStreamInterceptors registers stream interceptors that the gRPC server built by the run command chains
+// after its built-in authentication interceptor. They run in the same order as the interceptors registered with
+// RegisterUnaryInterceptors, except that the store ID and logging interceptors, which wrap the server stream,
+// come after them.
+func RegisterStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) {
+	registeredInterceptorsMu.Lock()
+	defer registeredInterceptorsMu.Unlock()
+
+	registeredStreamInterceptors = append(registeredStreamInterceptors, interceptors...)
+}
+
+// RegisteredUnaryInterceptors returns the interceptors registered with RegisterUnaryInterceptors.
+func RegisteredUnaryInterceptors() []grpc.UnaryServerInterceptor {
+	registeredInterceptorsMu.Lock()
+	defer registeredInterceptorsMu.Unlock()
+
+	return append([]grpc.UnaryServerInterceptor(nil), registeredUnaryInterceptors...)
+}
+
+// RegisteredStreamInterceptors returns the interceptors registered with RegisterStreamInterceptors.
+func RegisteredStreamInterceptors() []grpc.StreamServerInterceptor {
+	registeredInterceptorsMu.Lock()
+	defer registeredInterceptorsMu.Unlock()
+
+	return append([]grpc.StreamServerInterceptor(nil), registeredStreamInterceptors...)
+}
@@ -3,53 +3,161 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
 
 	grpc_ctxtags "github.com/grpc-ecosystem/go-grpc-middleware/tags"
+	"github.com/openfga/openfga/internal/authn"
 	"github.com/openfga/openfga/internal/gateway"
 	"github.com/openfga/openfga/internal/graph"
 	"github.com/openfga/openfga/internal/validation"
+	"github.com/openfga/openfga/pkg/audit"
 	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/featureflags"
 	"github.com/openfga/openfga/pkg/logger"
 	httpmiddleware "github.com/openfga/openfga/pkg/middleware/http"
+	"github.com/openfga/openfga/pkg/middleware/requestid"
 	"github.com/openfga/openfga/pkg/server/commands"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/storagewrappers"
+	"github.com/openfga/openfga/pkg/telemetry"
 	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
-type ExperimentalFeatureFlag string
+// ExperimentalFeatureFlag is the name of an experimental feature. The features that can be enabled
+// are defined in the featureflags package.
+type ExperimentalFeatureFlag = featureflags.Flag
 
 const (
 	AuthorizationModelIDHeader = "openfga-authorization-model-id"
 	authorizationModelIDKey    = "authorization_model_id"
 
+	// TupleTTLHeader is the request header that makes the tuples written by a Write request expire after the
+	// provided duration, such as '90s' or '24h'. Expired tuples are excluded from reads, and are removed by the
+	// datastores that support tuple expiration in the background.
+	TupleTTLHeader = "openfga-tuple-ttl"
+
+	// ListObjectsTruncatedHeader is set to 'true' when the results of a ListObjects request are incomplete
+	// because ListObjectsMaxPathsExplored was reached. StreamedListObjects sets it as a trailer.
+	ListObjectsTruncatedHeader = "openfga-list-objects-truncated"
+
+	// ModelCacheBypassHeader set to 'true' makes a Check request read the authorization model from the datastore
+	// instead of the model caches, to tell a stale cache apart from a genuine data issue. It is rejected unless
+	// Config.ModelCacheBypassEnabled is set.
+	ModelCacheBypassHeader = "openfga-bypass-model-cache"
+
+	// CheckTraceHeader set to 'true' makes a Check request record the tuples and the relation rewrites that led to
+	// its result. If the result is allowed, the path is returned as JSON in the CheckTracePathHeader response header.
+	// Recording the path adds work to every step of the resolution, so it should only be requested for debugging.
+	// It is ignored unless the featureflags.CheckTrace experimental feature is enabled.
+	CheckTraceHeader = "openfga-check-trace"
+
+	// CheckTracePathHeader is the response header of a Check request with the CheckTraceHeader that resolved to
+	// allowed. It holds the steps of the resolution that granted access, as a JSON array of
+	// {"rule", "tuple_key", "tuple", "children"} objects.
+	CheckTracePathHeader = "openfga-check-trace-path"
+
+	// CheckTraceTruncatedHeader is set to 'true' when the path in the CheckTracePathHeader may be incomplete, because
+	// the trace reached its limit of nodes, or when the path was left out because it exceeded maxCheckTracePathSize.
+	CheckTraceTruncatedHeader = "openfga-check-trace-truncated"
+
+	// gatewayTokenHeader carries the token that proves that a request was proxied by the HTTP gateway of the server.
+	gatewayTokenHeader = "x-openfga-gateway-token"
+
+	// gatewayClientAddressHeader carries the address of the HTTP client of a request proxied by the HTTP gateway.
+	gatewayClientAddressHeader = "x-openfga-gateway-client-address"
+
 	checkConcurrencyLimit = 100
+
+	// maxCheckTracePathSize is the size above which the path of a check trace isn't returned, leaving room under the
+	// 16KB limit that gRPC clients enforce on headers by default.
+	maxCheckTracePathSize = 8 * 1024
+
+	// DrainCancelledStreamsTimeout is how long Drain waits for the streams it cancelled to return, once its context is
+	// done. A server that proxies the streams, like the HTTP gateway, should give them that much longer to finish
+	// before closing its connections.
+	DrainCancelledStreamsTimeout = time.Second
 )
 
-var tracer = otel.Tracer("openfga/pkg/server")
+var (
+	tracer             = otel.Tracer("openfga/pkg/server")
+	componentAttribute = telemetry.ComponentKey.String("server")
+
+	checkResultCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "check_result_count",
+		Help: "Number of Check calls partitioned by whether they were allowed or denied. The store_id label is only set if enabled in the server config",
+	}, []string{"allowed", "store_id"})
+
+	throttledWritesCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "throttled_write_count",
+		Help: "Number of Write calls rejected because their store had reached its limit of concurrent writes",
+	})
+
+	auditLogFailuresCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "audit_log_failed_entries_count",
+		Help: "Number of audit log entries of operations that succeeded but whose entry couldn't be written",
+	}, []string{"operation"})
+)
 
 // A Server implements the OpenFGA service backend as both
 // a GRPC and HTTP server.
 type Server struct {
 	openfgapb.UnimplementedOpenFGAServiceServer
 
-	logger    logger.Logger
-	datastore storage.OpenFGADatastore
-	encoder   encoder.Encoder
-	transport gateway.Transport
-	config    *Config
+	logger      logger.Logger
+	datastore   storage.OpenFGADatastore
+	encoder     encoder.Encoder
+	transport   gateway.Transport
+	auditLogger audit.Logger
+	config      *Config
 
 	typesystemResolver typesystem.TypesystemResolverFunc
+
+	// streamsMu guards draining, activeStreams and streamsPerClient so that no stream can be
+	// registered once Drain has begun.
+	streamsMu        sync.Mutex
+	draining         bool
+	activeStreams    sync.WaitGroup
+	streamsPerClient map[string]uint32
+
+	// drainCtx is cancelled when Drain begins, which ends the shadow checks, while streamsCtx is cancelled when
+	// the deadline of Drain is reached, which ends the streaming ListObjects requests that are still in progress.
+	drainCtx      context.Context
+	drainCancel   context.CancelFunc
+	streamsCtx    context.Context
+	streamsCancel context.CancelFunc
+
+	writesMu       sync.Mutex
+	writesPerStore map[string]uint32
+
+	// shadowChecks holds a token for every shadow Check in progress, see shadowCheck.
+	shadowChecks chan struct{}
+
+	// gatewayToken is a random token, only known to this server, that the HTTP gateway sends along with the address
+	// of the HTTP client, so that a client can't claim the address of another one. It is empty if it couldn't be
+	// generated, in which case no forwarded address is trusted.
+	gatewayToken string
 }
 
 type Dependencies struct {
@@ -57,6 +165,10 @@ type Dependencies struct {
 	Logger       logger.Logger
 	Transport    gateway.Transport
 	TokenEncoder encoder.Encoder
+
+	// AuditLogger records the administrative operations that mutate the server state. If nil, they are not
+	// recorded.
+	AuditLogger audit.Logger
 }
 
 type Config struct {
@@ -65,6 +177,80 @@ type Config struct {
 	ListObjectsDeadline    time.Duration
 	ListObjectsMaxResults  uint32
 	Experimentals          []ExperimentalFeatureFlag
+
+	// DisableAuthorizationModelIDHeader stops the server from setting the AuthorizationModelIDHeader response
+	// header, which carries the ID of the model that answered requests that take an optional model ID.
+	DisableAuthorizationModelIDHeader bool
+
+	// RequireLatestAuthorizationModel rejects Check and ListObjects requests that pin an authorization model
+	// other than the latest model of the store. Requests that omit the model ID are not affected.
+	RequireLatestAuthorizationModel bool
+
+	// ReadChangesMaxPageSize caps the page size of ReadChanges requests. A value of 0 means no cap.
+	ReadChangesMaxPageSize int32
+
+	// ReadAuthorizationModelsDefaultPageSize is the page size of ReadAuthorizationModels requests that don't set
+	// one. A value of 0 means storage.DefaultPageSize.
+	ReadAuthorizationModelsDefaultPageSize int32
+
+	// ReadAuthorizationModelsMaxPageSize caps the page size of ReadAuthorizationModels requests. A value of 0
+	// means no cap.
+	ReadAuthorizationModelsMaxPageSize int32
+
+	// DisableListObjectsDeduplication stops ListObjects and StreamedListObjects from making sure that each object
+	// is returned only once, which saves tracking the objects returned so far.
+	DisableListObjectsDeduplication bool
+
+	// ListObjectsMaxPathsExplored bounds the number of tuples read by a single ListObjects request while searching
+	// for the objects related to the user. Once it is reached, the objects found so far are returned and the
+	// ListObjectsTruncatedHeader is set. If 0, there is no limit.
+	ListObjectsMaxPathsExplored uint32
+
+	// ListObjectsMaxConcurrentStreamsPerClient limits the number of concurrent StreamedListObjects
+	// calls per client. A value of 0 means there is no limit.
+	ListObjectsMaxConcurrentStreamsPerClient uint32
+
+	// MaxConcurrentWritesPerStore limits the number of concurrent Write calls per store, so that bulk writes to a
+	// store don't degrade the others through datastore lock contention. A value of 0 means there is no limit.
+	MaxConcurrentWritesPerStore uint32
+
+	// CheckResultMetricsByStore partitions the check result metric by store. This is opt-in because
+	// the cardinality of the metric grows with the number of stores.
+	CheckResultMetricsByStore bool
+
+	// StrictTupleValidation rejects written and contextual tuples whose 'user' field does not follow
+	// the 'type:id' format with a type defined in the model.
+	StrictTupleValidation bool
+
+	// MaxContextualTuplesPerCheck limits the number of contextual tuples in a Check request. A value of 0 means
+	// there is no limit.
+	MaxContextualTuplesPerCheck uint32
+
+	// PinnedAuthorizationModelIDs maps store IDs to the authorization model that requests on the store are
+	// evaluated with, whatever the latest model of the store. Requests that omit the authorization model ID use the
+	// pinned model, and requests that set another one are rejected.
+	PinnedAuthorizationModelIDs map[string]string
+
+	// IdempotentWrites makes writing a tuple that already exists a no-op instead of an error.
+	IdempotentWrites bool
+
+	// IdempotentDeletes makes deleting a tuple that does not exist a no-op instead of an error.
+	IdempotentDeletes bool
+
+	// ModelCacheBypassEnabled allows Check requests to set the ModelCacheBypassHeader. Every such request reads
+	// the model from the datastore, which adds load to it, so it is meant to be enabled while debugging only.
+	ModelCacheBypassEnabled bool
+
+	// MaxTupleObjectLength, MaxTupleRelationLength and MaxTupleUserLength bound the length, in bytes, of the
+	// fields of the tuples written and of the contextual tuples. A value of 0 means no limit.
+	MaxTupleObjectLength   int
+	MaxTupleRelationLength int
+	MaxTupleUserLength     int
+
+	// CandidateAuthorizationModelIDs maps store IDs to a candidate authorization model that the Check requests on
+	// the store are also evaluated with, in the background, to find where it would change the results before it is
+	// promoted. The served results always come from the model the request resolves to.
+	CandidateAuthorizationModelIDs map[string]string
 }
 
 // New creates a new Server which uses the supplied backends
@@ -73,14 +259,202 @@ func New(dependencies *Dependencies, config *Config) *Server {
 
 	typesysResolverFunc := typesystem.MemoizedTypesystemResolverFunc(dependencies.Datastore)
 
+	drainCtx, drainCancel := context.WithCancel(context.Background())
+	streamsCtx, streamsCancel := context.WithCancel(context.Background())
+
+	auditLogger := dependencies.AuditLogger
+	if auditLogger == nil {
+		auditLogger = audit.NoopLogger{}
+	}
+
+	var gatewayToken string
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err == nil {
+		gatewayToken = hex.EncodeToString(token)
+	}
+
 	return &Server{
 		logger:             dependencies.Logger,
 		datastore:          dependencies.Datastore,
 		encoder:            dependencies.TokenEncoder,
 		transport:          dependencies.Transport,
+		auditLogger:        auditLogger,
 		config:             config,
 		typesystemResolver: typesysResolverFunc,
+		streamsPerClient:   map[string]uint32{},
+		writesPerStore:     map[string]uint32{},
+		drainCtx:           drainCtx,
+		drainCancel:        drainCancel,
+		streamsCtx:         streamsCtx,
+		streamsCancel:      streamsCancel,
+		shadowChecks:       make(chan struct{}, maxConcurrentShadowChecks),
+		gatewayToken:       gatewayToken,
+	}
+}
+
+// Drain stops the server from accepting new streaming requests and waits for the streaming ListObjects requests
+// in progress to finish until the provided context is done. The streams that are still in progress at that point
+// are cancelled, and return a retryable error. The shadow checks in progress are ended right away. Drain
+// should be called before stopping the grpc server, and alongside the shutdown of the HTTP gateway, since the
+// streams it proxies keep its connections open, so that clients receive a retryable error instead of a connection
+// reset.
+func (s *Server) Drain(ctx context.Context) error {
+	s.streamsMu.Lock()
+	s.draining = true
+	s.streamsMu.Unlock()
+
+	s.drainCancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.activeStreams.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.streamsCancel()
+
+	// give the cancelled streams a chance to report the retryable error before their connections are closed
+	select {
+	case <-done:
+	case <-time.After(DrainCancelledStreamsTimeout):
+	}
+
+	return ctx.Err()
+}
+
+// startStream registers a new streaming request for the client that issued it. It returns an error
+// if the server is draining or if the client has reached its limit of concurrent streams, in which
+// case the stream must be rejected. Otherwise the caller must call the returned function once the
+// stream has finished.
+//
+// Requests that can't be attributed to a client, which are only those made in-process without going through the
+// gRPC server, aren't limited.
+func (s *Server) startStream(ctx context.Context) (func(), error) {
+	client := s.clientKey(ctx)
+
+	s.streamsMu.Lock()
+	defer s.streamsMu.Unlock()
+
+	if s.draining {
+		return nil, serverErrors.ServerShuttingDown
+	}
+
+	if client == "" {
+		s.activeStreams.Add(1)
+		return s.activeStreams.Done, nil
+	}
+
+	limit := s.config.ListObjectsMaxConcurrentStreamsPerClient
+	if limit > 0 && s.streamsPerClient[client] >= limit {
+		return nil, serverErrors.TooManyConcurrentStreams
+	}
+
+	s.streamsPerClient[client]++
+	s.activeStreams.Add(1)
+
+	return func() {
+		s.streamsMu.Lock()
+		defer s.streamsMu.Unlock()
+
+		s.streamsPerClient[client]--
+		if s.streamsPerClient[client] == 0 {
+			delete(s.streamsPerClient, client)
+		}
+
+		s.activeStreams.Done()
+	}, nil
+}
+
+// startWrite registers a new Write request for the store. It returns an error if the store has reached its
+// limit of concurrent writes, in which case the write must be rejected. Otherwise the caller must call the
+// returned function once the write has finished.
+func (s *Server) startWrite(storeID string) (func(), error) {
+	limit := s.config.MaxConcurrentWritesPerStore
+	if limit == 0 {
+		return func() {}, nil
+	}
+
+	s.writesMu.Lock()
+	defer s.writesMu.Unlock()
+
+	if s.writesPerStore[storeID] >= limit {
+		throttledWritesCounter.Inc()
+		return nil, serverErrors.TooManyConcurrentWrites
+	}
+
+	s.writesPerStore[storeID]++
+
+	return func() {
+		s.writesMu.Lock()
+		defer s.writesMu.Unlock()
+
+		s.writesPerStore[storeID]--
+		if s.writesPerStore[storeID] == 0 {
+			delete(s.writesPerStore, storeID)
+		}
+	}, nil
+}
+
+// clientKey identifies the client that issued the request. It is the authenticated subject if there
+// is one, otherwise it is the address of the HTTP client for the requests proxied by the HTTP gateway, and the
+// address of the peer for the others. It is empty if the request has no peer.
+func (s *Server) clientKey(ctx context.Context) string {
+	if claims, ok := authn.AuthClaimsFromContext(ctx); ok && claims.Subject != "" {
+		return "subject:" + claims.Subject
+	}
+
+	// the peer of the requests proxied by the gateway is the gateway itself
+	if addr, ok := s.forwardedClientAddress(ctx); ok {
+		return "peer:" + addr
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "peer:" + host
+	}
+
+	return ""
+}
+
+// ForwardClientAddress returns the gRPC metadata that forwards the address of the HTTP client to the gRPC server,
+// for the HTTP gateway, so that the requests it proxies are attributed to their client rather than to the gateway.
+func (s *Server) ForwardClientAddress(_ context.Context, r *http.Request) metadata.MD {
+	if s.gatewayToken == "" || r.RemoteAddr == "" {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
 	}
+
+	return metadata.Pairs(gatewayTokenHeader, s.gatewayToken, gatewayClientAddressHeader, host)
+}
+
+// forwardedClientAddress returns the address of the HTTP client forwarded by the HTTP gateway, if the request was
+// proxied by it.
+func (s *Server) forwardedClientAddress(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok || s.gatewayToken == "" {
+		return "", false
+	}
+
+	tokens := md.Get(gatewayTokenHeader)
+	addrs := md.Get(gatewayClientAddressHeader)
+	if len(tokens) != 1 || len(addrs) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(s.gatewayToken)) != 1 {
+		return "", false
+	}
+
+	return addrs[0], true
 }
 
 func (s *Server) ListObjects(ctx context.Context, req *openfgapb.ListObjectsRequest) (*openfgapb.ListObjectsResponse, error) {
@@ -88,6 +462,7 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgapb.ListObjectsRequ
 	targetObjectType := req.GetType()
 
 	ctx, span := tracer.Start(ctx, "ListObjects", trace.WithAttributes(
+		componentAttribute,
 		attribute.String("object_type", targetObjectType),
 		attribute.String("relation", req.GetRelation()),
 		attribute.String("user", req.GetUser()),
@@ -96,6 +471,10 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgapb.ListObjectsRequ
 
 	storeID := req.GetStoreId()
 
+	if err := s.ensureLatestAuthorizationModel(ctx, storeID, req.GetAuthorizationModelId()); err != nil {
+		return nil, err
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
@@ -108,6 +487,13 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgapb.ListObjectsRequ
 		ListObjectsMaxResults: s.config.ListObjectsMaxResults,
 		ResolveNodeLimit:      s.config.ResolveNodeLimit,
 		CheckConcurrencyLimit: checkConcurrencyLimit,
+		StrictTupleValidation: s.config.StrictTupleValidation,
+		TupleKeyLimits:        s.tupleKeyLimits(),
+		DeduplicateResults:    !s.config.DisableListObjectsDeduplication,
+		MaxPathsExplored:      s.config.ListObjectsMaxPathsExplored,
+		OnTruncated: func() {
+			s.transport.SetHeader(ctx, ListObjectsTruncatedHeader, "true")
+		},
 	}
 
 	return q.Execute(
@@ -126,17 +512,39 @@ func (s *Server) ListObjects(ctx context.Context, req *openfgapb.ListObjectsRequ
 func (s *Server) StreamedListObjects(req *openfgapb.StreamedListObjectsRequest, srv openfgapb.OpenFGAService_StreamedListObjectsServer) error {
 	ctx := srv.Context()
 	ctx, span := tracer.Start(ctx, "StreamedListObjects", trace.WithAttributes(
+		componentAttribute,
 		attribute.String("object_type", req.GetType()),
 		attribute.String("relation", req.GetRelation()),
 		attribute.String("user", req.GetUser()),
 	))
 	defer span.End()
 
+	endStream, err := s.startStream(ctx)
+	if err != nil {
+		return err
+	}
+	defer endStream()
+
+	// cancel the stream if the server is still draining it when the deadline of the drain is reached
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-s.streamsCtx.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
 	storeID := req.GetStoreId()
 
+	if err := s.ensureLatestAuthorizationModel(ctx, storeID, req.GetAuthorizationModelId()); err != nil {
+		return s.streamError(srv.Context(), err)
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
-		return err
+		return s.streamError(srv.Context(), err)
 	}
 
 	q := &commands.ListObjectsQuery{
@@ -146,19 +554,39 @@ func (s *Server) StreamedListObjects(req *openfgapb.StreamedListObjectsRequest,
 		ListObjectsMaxResults: s.config.ListObjectsMaxResults,
 		ResolveNodeLimit:      s.config.ResolveNodeLimit,
 		CheckConcurrencyLimit: checkConcurrencyLimit,
+		StrictTupleValidation: s.config.StrictTupleValidation,
+		TupleKeyLimits:        s.tupleKeyLimits(),
+		DeduplicateResults:    !s.config.DisableListObjectsDeduplication,
+		MaxPathsExplored:      s.config.ListObjectsMaxPathsExplored,
+		OnTruncated: func() {
+			srv.SetTrailer(metadata.Pairs(ListObjectsTruncatedHeader, "true"))
+		},
 	}
 
 	req.AuthorizationModelId = typesys.GetAuthorizationModelID() // the resolved model id
-	return q.ExecuteStreamed(
+	err = q.ExecuteStreamed(
 		typesystem.ContextWithTypesystem(ctx, typesys),
 		req,
 		srv,
 	)
+
+	return s.streamError(srv.Context(), err)
+}
+
+// streamError maps the result of a streaming ListObjects request. If the drain cancelled the stream, the results
+// sent so far may be incomplete, so whatever error the cancellation caused, or none, the client is told to retry.
+func (s *Server) streamError(ctx context.Context, err error) error {
+	if s.streamsCtx.Err() != nil && ctx.Err() == nil {
+		return serverErrors.ServerShuttingDown
+	}
+
+	return err
 }
 
 func (s *Server) Read(ctx context.Context, req *openfgapb.ReadRequest) (*openfgapb.ReadResponse, error) {
 	tk := req.GetTupleKey()
 	ctx, span := tracer.Start(ctx, "Read", trace.WithAttributes(
+		componentAttribute,
 		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
 		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
 		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
@@ -175,17 +603,37 @@ func (s *Server) Read(ctx context.Context, req *openfgapb.ReadRequest) (*openfga
 }
 
 func (s *Server) Write(ctx context.Context, req *openfgapb.WriteRequest) (*openfgapb.WriteResponse, error) {
-	ctx, span := tracer.Start(ctx, "Write")
+	ctx, span := tracer.Start(ctx, "Write", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	storeID := req.GetStoreId()
 
+	endWrite, err := s.startWrite(storeID)
+	if err != nil {
+		return nil, err
+	}
+	defer endWrite()
+
+	// the model and the existing tuples are read from the primary, so that the write is validated against the
+	// latest writes
+	ctx = storage.ContextWithReadFromPrimary(ctx)
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.AuthorizationModelId)
 	if err != nil {
 		return nil, err
 	}
 
-	cmd := commands.NewWriteCommand(s.datastore, s.logger)
+	ctx, err = withTupleTTL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := commands.NewWriteCommand(s.datastore, s.logger,
+		commands.WithStrictTupleValidation(s.config.StrictTupleValidation),
+		commands.WithIdempotentWrites(s.config.IdempotentWrites),
+		commands.WithIdempotentDeletes(s.config.IdempotentDeletes),
+		commands.WithTupleKeyLimits(s.tupleKeyLimits()),
+	)
 	return cmd.Execute(ctx, &openfgapb.WriteRequest{
 		StoreId:              storeID,
 		AuthorizationModelId: typesys.GetAuthorizationModelID(), // the resolved model id
@@ -194,9 +642,60 @@ func (s *Server) Write(ctx context.Context, req *openfgapb.WriteRequest) (*openf
 	})
 }
 
+// withTupleTTL returns a context that makes the written tuples expire if the request sets the TupleTTLHeader.
+func withTupleTTL(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	values := md.Get(TupleTTLHeader)
+	if len(values) == 0 {
+		return ctx, nil
+	}
+
+	ttl, err := time.ParseDuration(values[0])
+	if err != nil || ttl <= 0 {
+		return nil, serverErrors.InvalidTupleTTL(values[0])
+	}
+
+	return storage.ContextWithTupleExpiration(ctx, time.Now().Add(ttl)), nil
+}
+
+// withModelCacheBypass returns a context that bypasses the authorization model caches if the request sets the
+// ModelCacheBypassHeader to 'true'.
+func (s *Server) withModelCacheBypass(ctx context.Context) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	values := md.Get(ModelCacheBypassHeader)
+	if len(values) == 0 || values[0] != "true" {
+		return ctx, nil
+	}
+
+	if !s.config.ModelCacheBypassEnabled {
+		return nil, serverErrors.ModelCacheBypassDisabled
+	}
+
+	s.logger.InfoWithContext(ctx, "bypassing the authorization model caches", zap.String("header", ModelCacheBypassHeader))
+
+	return storage.ContextWithModelCacheBypass(ctx), nil
+}
+
+// Check reports whether the user of the request has the relation with the object. It never fails open: if the
+// datastore returns an error while the Check is evaluated, and the error could change the result, Check returns an
+// internal error instead of a result. A response with allowed=false is always a genuine deny, so clients that want
+// to fail closed can treat an error like a deny, and clients that want to retry can tell the two apart. There is no
+// configuration to turn datastore errors into a result.
+//
+// For the same reason, a Check for a relation that isn't defined on the type of the object, or for an object of a
+// type that isn't in the model, fails with a validation error rather than returning allowed=false.
 func (s *Server) Check(ctx context.Context, req *openfgapb.CheckRequest) (*openfgapb.CheckResponse, error) {
 	tk := req.GetTupleKey()
 	ctx, span := tracer.Start(ctx, "Check", trace.WithAttributes(
+		componentAttribute,
 		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
 		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
 		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
@@ -209,25 +708,129 @@ func (s *Server) Check(ctx context.Context, req *openfgapb.CheckRequest) (*openf
 
 	storeID := req.GetStoreId()
 
+	ctx, err := s.withModelCacheBypass(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureLatestAuthorizationModel(ctx, storeID, req.GetAuthorizationModelId()); err != nil {
+		return nil, err
+	}
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
 	}
 
-	if err := validation.ValidateUserObjectRelation(typesys, tk); err != nil {
-		return nil, serverErrors.ValidationError(err)
+	checkTrace := s.checkTraceFromRequest(ctx)
+	if checkTrace != nil {
+		ctx = graph.ContextWithCheckTrace(ctx, checkTrace)
+	}
+
+	allowed, err := s.checkWithTypesystem(ctx, typesys, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkTrace != nil && allowed {
+		s.setCheckTracePathHeader(ctx, checkTrace)
+	}
+
+	res := &openfgapb.CheckResponse{
+		Allowed: allowed,
 	}
 
-	for _, ctxTuple := range req.GetContextualTuples().GetTupleKeys() {
-		if err := validation.ValidateTuple(typesys, ctxTuple); err != nil {
-			return nil, serverErrors.HandleTupleValidateError(err)
+	s.shadowCheck(req, typesys.GetAuthorizationModelID(), allowed)
+
+	span.SetAttributes(attribute.KeyValue{Key: "allowed", Value: attribute.BoolValue(res.GetAllowed())})
+
+	storeLabel := ""
+	if s.config.CheckResultMetricsByStore {
+		storeLabel = storeID
+	}
+	checkResultCounter.WithLabelValues(strconv.FormatBool(res.GetAllowed()), storeLabel).Inc()
+
+	return res, nil
+}
+
+// checkTraceFromRequest returns a CheckTrace if the request set the CheckTraceHeader to 'true' and the
+// featureflags.CheckTrace experimental feature is enabled, and nil otherwise.
+// The trace records up to as many nodes as the resolution can visit at each of its ResolveNodeLimit levels.
+func (s *Server) checkTraceFromRequest(ctx context.Context) *graph.CheckTrace {
+	if !featureflags.Enabled(s.config.Experimentals, featureflags.CheckTrace) {
+		return nil
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	values := md.Get(CheckTraceHeader)
+	if len(values) == 0 || values[0] != "true" {
+		return nil
+	}
+
+	return graph.NewCheckTrace(s.config.ResolveNodeLimit * checkConcurrencyLimit)
+}
+
+func (s *Server) setCheckTracePathHeader(ctx context.Context, checkTrace *graph.CheckTrace) {
+	path, err := json.Marshal(checkTrace.Path())
+	if err != nil {
+		s.logger.ErrorWithContext(ctx, "failed to marshal the check trace", zap.Error(err))
+		return
+	}
+
+	if len(path) > maxCheckTracePathSize {
+		s.transport.SetHeader(ctx, CheckTraceTruncatedHeader, "true")
+		return
+	}
+
+	s.transport.SetHeader(ctx, CheckTracePathHeader, string(path))
+
+	if checkTrace.Truncated() {
+		s.transport.SetHeader(ctx, CheckTraceTruncatedHeader, "true")
+	}
+}
+
+func (s *Server) tupleKeyLimits() validation.TupleKeyLimits {
+	return validation.TupleKeyLimits{
+		MaxObjectLength:   s.config.MaxTupleObjectLength,
+		MaxRelationLength: s.config.MaxTupleRelationLength,
+		MaxUserLength:     s.config.MaxTupleUserLength,
+	}
+}
+
+// checkWithTypesystem validates the Check request against the provided TypeSystem and then evaluates it.
+func (s *Server) checkWithTypesystem(ctx context.Context, typesys *typesystem.TypeSystem, req *openfgapb.CheckRequest) (bool, error) {
+	if err := validation.ValidateUserObjectRelation(typesys, req.GetTupleKey()); err != nil {
+		return false, serverErrors.ValidationError(err)
+	}
+
+	contextualTuples := req.GetContextualTuples().GetTupleKeys()
+	if maxContextualTuples := s.config.MaxContextualTuplesPerCheck; maxContextualTuples > 0 && len(contextualTuples) > int(maxContextualTuples) {
+		return false, serverErrors.ExceededEntityLimit("contextual tuples", int(maxContextualTuples))
+	}
+
+	validateTuple := validation.ValidateContextualTuple
+	if s.config.StrictTupleValidation {
+		validateTuple = validation.ValidateTupleStrict
+	}
+
+	for _, ctxTuple := range contextualTuples {
+		if err := validation.ValidateTupleKeyLimits(ctxTuple, s.tupleKeyLimits()); err != nil {
+			return false, serverErrors.HandleTupleValidateError(err)
+		}
+
+		if err := validateTuple(typesys, ctxTuple); err != nil {
+			return false, serverErrors.HandleTupleValidateError(err)
 		}
 	}
 
 	ctx = typesystem.ContextWithTypesystem(ctx, typesys)
 
 	checkResolver := graph.NewLocalChecker(
-		storage.NewCombinedTupleReader(s.datastore, req.ContextualTuples.GetTupleKeys()),
+		storage.NewCombinedTupleReader(storagewrappers.NewCoalescingTupleReader(s.datastore), contextualTuples),
 		checkConcurrencyLimit,
 	)
 
@@ -235,30 +838,26 @@ func (s *Server) Check(ctx context.Context, req *openfgapb.CheckRequest) (*openf
 		StoreID:              req.GetStoreId(),
 		AuthorizationModelID: typesys.GetAuthorizationModelID(), // the resolved model id
 		TupleKey:             req.GetTupleKey(),
-		ContextualTuples:     req.ContextualTuples.GetTupleKeys(),
+		ContextualTuples:     contextualTuples,
 		ResolutionMetadata: &graph.ResolutionMetadata{
 			Depth: s.config.ResolveNodeLimit,
 		},
 	})
 	if err != nil {
 		if errors.Is(err, graph.ErrResolutionDepthExceeded) {
-			return nil, serverErrors.AuthorizationModelResolutionTooComplex
+			return false, serverErrors.AuthorizationModelResolutionTooComplex
 		}
 
-		return nil, serverErrors.HandleError("", err)
-	}
-
-	res := &openfgapb.CheckResponse{
-		Allowed: resp.Allowed,
+		return false, serverErrors.HandleError("", err)
 	}
 
-	span.SetAttributes(attribute.KeyValue{Key: "allowed", Value: attribute.BoolValue(res.GetAllowed())})
-	return res, nil
+	return resp.Allowed, nil
 }
 
 func (s *Server) Expand(ctx context.Context, req *openfgapb.ExpandRequest) (*openfgapb.ExpandResponse, error) {
 	tk := req.GetTupleKey()
 	ctx, span := tracer.Start(ctx, "Expand", trace.WithAttributes(
+		componentAttribute,
 		attribute.KeyValue{Key: "object", Value: attribute.StringValue(tk.GetObject())},
 		attribute.KeyValue{Key: "relation", Value: attribute.StringValue(tk.GetRelation())},
 		attribute.KeyValue{Key: "user", Value: attribute.StringValue(tk.GetUser())},
@@ -282,6 +881,7 @@ func (s *Server) Expand(ctx context.Context, req *openfgapb.ExpandRequest) (*ope
 
 func (s *Server) ReadAuthorizationModel(ctx context.Context, req *openfgapb.ReadAuthorizationModelRequest) (*openfgapb.ReadAuthorizationModelResponse, error) {
 	ctx, span := tracer.Start(ctx, "ReadAuthorizationModel", trace.WithAttributes(
+		componentAttribute,
 		attribute.KeyValue{Key: authorizationModelIDKey, Value: attribute.StringValue(req.GetId())},
 	))
 	defer span.End()
@@ -291,34 +891,136 @@ func (s *Server) ReadAuthorizationModel(ctx context.Context, req *openfgapb.Read
 }
 
 func (s *Server) WriteAuthorizationModel(ctx context.Context, req *openfgapb.WriteAuthorizationModelRequest) (*openfgapb.WriteAuthorizationModelResponse, error) {
-	ctx, span := tracer.Start(ctx, "WriteAuthorizationModel")
+	ctx, span := tracer.Start(ctx, "WriteAuthorizationModel", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
+	ctx = storage.ContextWithReadFromPrimary(ctx)
+
 	c := commands.NewWriteAuthorizationModelCommand(s.datastore, s.logger)
 	res, err := c.Execute(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	s.recordAudit(ctx, audit.OperationWriteAuthorizationModel, req.GetStoreId(), req, res)
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusCreated))
 
 	return res, nil
 }
 
 func (s *Server) ReadAuthorizationModels(ctx context.Context, req *openfgapb.ReadAuthorizationModelsRequest) (*openfgapb.ReadAuthorizationModelsResponse, error) {
-	ctx, span := tracer.Start(ctx, "ReadAuthorizationModels")
+	ctx, span := tracer.Start(ctx, "ReadAuthorizationModels", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
-	c := commands.NewReadAuthorizationModelsQuery(s.datastore, s.logger, s.encoder)
+	c := commands.NewReadAuthorizationModelsQuery(s.datastore, s.logger, s.encoder,
+		commands.WithReadAuthorizationModelsDefaultPageSize(s.config.ReadAuthorizationModelsDefaultPageSize),
+		commands.WithReadAuthorizationModelsMaxPageSize(s.config.ReadAuthorizationModelsMaxPageSize),
+	)
 	return c.Execute(ctx, req)
 }
 
+// AuthorizationModelWithStatus describes an authorization model of a store along with its validation status.
+type AuthorizationModelWithStatus struct {
+	AuthorizationModel *openfgapb.AuthorizationModel
+
+	// IsLatest is true if this is the most recent authorization model of the store.
+	IsLatest bool
+
+	// Validated is true if the validation status was requested and computed.
+	Validated bool
+
+	// ValidationError is empty if the model is valid, otherwise it describes why it isn't.
+	// It is only set if Validated is true.
+	ValidationError string
+}
+
+// ListAuthorizationModelsWithStatus lists a page of the authorization models of a store. If includeValidation
+// is true, each model is also validated. Validation is expensive, so the results for valid models are cached.
+func (s *Server) ListAuthorizationModelsWithStatus(
+	ctx context.Context,
+	req *openfgapb.ReadAuthorizationModelsRequest,
+	includeValidation bool,
+) ([]*AuthorizationModelWithStatus, string, error) {
+	ctx, span := tracer.Start(ctx, "ListAuthorizationModelsWithStatus", trace.WithAttributes(
+		componentAttribute,
+		attribute.Bool("include_validation", includeValidation),
+	))
+	defer span.End()
+
+	c := commands.NewReadAuthorizationModelsQuery(s.datastore, s.logger, s.encoder,
+		commands.WithReadAuthorizationModelsDefaultPageSize(s.config.ReadAuthorizationModelsDefaultPageSize),
+		commands.WithReadAuthorizationModelsMaxPageSize(s.config.ReadAuthorizationModelsMaxPageSize),
+	)
+	res, err := c.Execute(ctx, req)
+	if err != nil {
+		return nil, "", err
+	}
+
+	latestModelID, err := s.datastore.FindLatestAuthorizationModelID(ctx, req.GetStoreId())
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return nil, "", serverErrors.HandleError("", err)
+	}
+
+	models := make([]*AuthorizationModelWithStatus, 0, len(res.GetAuthorizationModels()))
+	for _, model := range res.GetAuthorizationModels() {
+		modelStatus := &AuthorizationModelWithStatus{
+			AuthorizationModel: model,
+			IsLatest:           model.GetId() == latestModelID,
+		}
+
+		if includeValidation {
+			// the typesystem resolver validates the model and memoizes the valid ones
+			_, err := s.typesystemResolver(ctx, req.GetStoreId(), model.GetId())
+			if err != nil {
+				if !errors.Is(err, typesystem.ErrInvalidModel) {
+					return nil, "", serverErrors.HandleError("", err)
+				}
+
+				modelStatus.ValidationError = err.Error()
+			}
+
+			modelStatus.Validated = true
+		}
+
+		models = append(models, modelStatus)
+	}
+
+	return models, res.GetContinuationToken(), nil
+}
+
+// ValidateModelAgainstTuples reports the existing tuples of a store that a proposed authorization model does not
+// allow, so that a model that would orphan live data isn't promoted. The model isn't written. Models written in the
+// DSL or in JSON can be parsed with commands.ParseAuthorizationModel.
+//
+// onInvalid is called with every invalid tuple as soon as it is found. A single call scans a bounded number of
+// tuples; if the result has a continuation token, the scan can be resumed by passing it to a subsequent call.
+func (s *Server) ValidateModelAgainstTuples(
+	ctx context.Context,
+	storeID string,
+	model *openfgapb.AuthorizationModel,
+	continuationToken string,
+	onInvalid func(*commands.InvalidTuple) error,
+) (*commands.ValidateModelTuplesResult, error) {
+	ctx, span := tracer.Start(ctx, "ValidateModelAgainstTuples", trace.WithAttributes(
+		componentAttribute,
+		attribute.String("store_id", storeID),
+	))
+	defer span.End()
+
+	q := commands.NewValidateModelTuplesQuery(s.datastore, s.logger, s.encoder)
+	return q.Execute(ctx, storeID, model, continuationToken, onInvalid)
+}
+
 func (s *Server) WriteAssertions(ctx context.Context, req *openfgapb.WriteAssertionsRequest) (*openfgapb.WriteAssertionsResponse, error) {
-	ctx, span := tracer.Start(ctx, "WriteAssertions")
+	ctx, span := tracer.Start(ctx, "WriteAssertions", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	storeID := req.GetStoreId()
 
+	// the model was usually written right before its assertions, so it is read from the primary
+	ctx = storage.ContextWithReadFromPrimary(ctx)
+
 	typesys, err := s.resolveTypesystem(ctx, storeID, req.GetAuthorizationModelId())
 	if err != nil {
 		return nil, err
@@ -340,7 +1042,7 @@ func (s *Server) WriteAssertions(ctx context.Context, req *openfgapb.WriteAssert
 }
 
 func (s *Server) ReadAssertions(ctx context.Context, req *openfgapb.ReadAssertionsRequest) (*openfgapb.ReadAssertionsResponse, error) {
-	ctx, span := tracer.Start(ctx, "ReadAssertions")
+	ctx, span := tracer.Start(ctx, "ReadAssertions", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	typesys, err := s.resolveTypesystem(ctx, req.GetStoreId(), req.GetAuthorizationModelId())
@@ -354,16 +1056,19 @@ func (s *Server) ReadAssertions(ctx context.Context, req *openfgapb.ReadAssertio
 
 func (s *Server) ReadChanges(ctx context.Context, req *openfgapb.ReadChangesRequest) (*openfgapb.ReadChangesResponse, error) {
 	ctx, span := tracer.Start(ctx, "ReadChangesQuery", trace.WithAttributes(
+		componentAttribute,
 		attribute.KeyValue{Key: "type", Value: attribute.StringValue(req.GetType())},
 	))
 	defer span.End()
 
-	q := commands.NewReadChangesQuery(s.datastore, s.logger, s.encoder, s.config.ChangelogHorizonOffset)
+	q := commands.NewReadChangesQuery(s.datastore, s.logger, s.encoder, s.config.ChangelogHorizonOffset,
+		commands.WithReadChangesMaxPageSize(s.config.ReadChangesMaxPageSize),
+	)
 	return q.Execute(ctx, req)
 }
 
 func (s *Server) CreateStore(ctx context.Context, req *openfgapb.CreateStoreRequest) (*openfgapb.CreateStoreResponse, error) {
-	ctx, span := tracer.Start(ctx, "CreateStore")
+	ctx, span := tracer.Start(ctx, "CreateStore", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	c := commands.NewCreateStoreCommand(s.datastore, s.logger)
@@ -372,13 +1077,15 @@ func (s *Server) CreateStore(ctx context.Context, req *openfgapb.CreateStoreRequ
 		return nil, err
 	}
 
+	s.recordAudit(ctx, audit.OperationCreateStore, res.GetId(), req, res)
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusCreated))
 
 	return res, nil
 }
 
 func (s *Server) DeleteStore(ctx context.Context, req *openfgapb.DeleteStoreRequest) (*openfgapb.DeleteStoreResponse, error) {
-	ctx, span := tracer.Start(ctx, "DeleteStore")
+	ctx, span := tracer.Start(ctx, "DeleteStore", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	cmd := commands.NewDeleteStoreCommand(s.datastore, s.logger)
@@ -387,13 +1094,15 @@ func (s *Server) DeleteStore(ctx context.Context, req *openfgapb.DeleteStoreRequ
 		return nil, err
 	}
 
+	s.recordAudit(ctx, audit.OperationDeleteStore, req.GetStoreId(), req, nil)
+
 	s.transport.SetHeader(ctx, httpmiddleware.XHttpCode, strconv.Itoa(http.StatusNoContent))
 
 	return res, nil
 }
 
 func (s *Server) GetStore(ctx context.Context, req *openfgapb.GetStoreRequest) (*openfgapb.GetStoreResponse, error) {
-	ctx, span := tracer.Start(ctx, "GetStore")
+	ctx, span := tracer.Start(ctx, "GetStore", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	q := commands.NewGetStoreQuery(s.datastore, s.logger)
@@ -401,13 +1110,52 @@ func (s *Server) GetStore(ctx context.Context, req *openfgapb.GetStoreRequest) (
 }
 
 func (s *Server) ListStores(ctx context.Context, req *openfgapb.ListStoresRequest) (*openfgapb.ListStoresResponse, error) {
-	ctx, span := tracer.Start(ctx, "ListStores")
+	ctx, span := tracer.Start(ctx, "ListStores", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
 	q := commands.NewListStoresQuery(s.datastore, s.logger, s.encoder)
 	return q.Execute(ctx, req)
 }
 
+// recordAudit writes the audit log entry of an administrative operation that succeeded. The operation is already
+// committed, so an entry that cannot be written doesn't fail it: the failure is logged at the error level and
+// counted by the 'audit_log_failed_entries_count' metric instead.
+func (s *Server) recordAudit(ctx context.Context, operation, storeID string, req, res proto.Message) {
+	if err := s.writeAuditEntry(ctx, operation, storeID, req, res); err != nil {
+		auditLogFailuresCounter.WithLabelValues(operation).Inc()
+		s.logger.ErrorWithContext(ctx, "failed to write audit log entry", zap.String("operation", operation), zap.String("store_id", storeID), zap.Error(err))
+	}
+}
+
+func (s *Server) writeAuditEntry(ctx context.Context, operation, storeID string, req, res proto.Message) error {
+	entry := audit.Entry{
+		Time:      time.Now().UTC(),
+		Operation: operation,
+		StoreID:   storeID,
+	}
+
+	if claims, ok := authn.AuthClaimsFromContext(ctx); ok {
+		entry.Principal = claims.Subject
+	}
+
+	if requestID, ok := requestid.FromContext(ctx); ok {
+		entry.RequestID = requestID
+	}
+
+	var err error
+	if entry.Request, err = protojson.Marshal(req); err != nil {
+		return err
+	}
+
+	if res != nil {
+		if entry.Response, err = protojson.Marshal(res); err != nil {
+			return err
+		}
+	}
+
+	return s.auditLogger.Log(ctx, entry)
+}
+
 // IsReady reports whether this OpenFGA server instance is ready to accept
 // traffic.
 func (s *Server) IsReady(ctx context.Context) (bool, error) {
@@ -418,12 +1166,48 @@ func (s *Server) IsReady(ctx context.Context) (bool, error) {
 	return s.datastore.IsReady(ctx)
 }
 
+// ensureLatestAuthorizationModel returns an error if the server requires the latest authorization model
+// and modelID is set to a model other than the latest model of the store.
+func (s *Server) ensureLatestAuthorizationModel(ctx context.Context, storeID, modelID string) error {
+	if !s.config.RequireLatestAuthorizationModel || modelID == "" {
+		return nil
+	}
+
+	// requests on a pinned store are evaluated with the pinned model, which resolveTypesystem enforces
+	if _, ok := s.config.PinnedAuthorizationModelIDs[storeID]; ok {
+		return nil
+	}
+
+	latestModelID, err := s.datastore.FindLatestAuthorizationModelID(ctx, storeID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return serverErrors.LatestAuthorizationModelNotFound(storeID)
+		}
+
+		return serverErrors.HandleError("", err)
+	}
+
+	if modelID != latestModelID {
+		return serverErrors.AuthorizationModelNotLatest(modelID, latestModelID)
+	}
+
+	return nil
+}
+
 // resolveTypesystem resolves the underlying TypeSystem given the storeID and modelID and
 // it sets some response metadata based on the model resolution.
 func (s *Server) resolveTypesystem(ctx context.Context, storeID, modelID string) (*typesystem.TypeSystem, error) {
-	ctx, span := tracer.Start(ctx, "resolveTypesystem")
+	ctx, span := tracer.Start(ctx, "resolveTypesystem", trace.WithAttributes(componentAttribute))
 	defer span.End()
 
+	if pinnedModelID, ok := s.config.PinnedAuthorizationModelIDs[storeID]; ok {
+		if modelID != "" && modelID != pinnedModelID {
+			return nil, serverErrors.AuthorizationModelNotPinned(modelID, pinnedModelID)
+		}
+
+		modelID = pinnedModelID
+	}
+
 	typesys, err := s.typesystemResolver(ctx, storeID, modelID)
 	if err != nil {
 		if errors.Is(err, typesystem.ErrModelNotFound) {
@@ -445,7 +1229,11 @@ func (s *Server) resolveTypesystem(ctx context.Context, storeID, modelID string)
 
 	span.SetAttributes(attribute.KeyValue{Key: authorizationModelIDKey, Value: attribute.StringValue(resolvedModelID)})
 	grpc_ctxtags.Extract(ctx).Set(authorizationModelIDKey, resolvedModelID)
-	_ = grpc.SetHeader(ctx, metadata.Pairs(AuthorizationModelIDHeader, resolvedModelID))
+	// the typesystem is also resolved outside of gRPC calls, e.g. when the server is used as a library, where there is
+	// no header to set
+	if !s.config.DisableAuthorizationModelIDHeader && grpc.ServerTransportStreamFromContext(ctx) != nil {
+		s.transport.SetHeader(ctx, AuthorizationModelIDHeader, resolvedModelID)
+	}
 
 	return typesys, nil
 }
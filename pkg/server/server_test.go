@@ -4,17 +4,24 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 	"testing"
 	"time"
 
 	parser "github.com/craigpastro/openfga-dsl-parser/v2"
 	"github.com/golang/mock/gomock"
 	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/internal/authn"
 	"github.com/openfga/openfga/internal/gateway"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/audit"
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/featureflags"
 	"github.com/openfga/openfga/pkg/logger"
 	serverErrors "github.com/openfga/openfga/pkg/server/errors"
 	"github.com/openfga/openfga/pkg/server/test"
@@ -26,10 +33,13 @@ import (
 	storagefixtures "github.com/openfga/openfga/pkg/testfixtures/storage"
 	"github.com/openfga/openfga/pkg/tuple"
 	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -187,6 +197,302 @@ func TestCheckDoesNotThrowBecauseDirectTupleWasFound(t *testing.T) {
 	require.Equal(t, true, checkResponse.Allowed)
 }
 
+func TestCheckFailsClosedOnDatastoreErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+	}{
+		{
+			name: "direct_relation",
+			model: `
+			type user
+
+			type repo
+			  relations
+			    define reader: [user] as self
+			`,
+		},
+		{
+			name: "union",
+			model: `
+			type user
+
+			type repo
+			  relations
+			    define writer: [user] as self
+			    define reader: [user] as self or writer
+			`,
+		},
+		{
+			name: "exclusion",
+			model: `
+			type user
+
+			type repo
+			  relations
+			    define blocked: [user] as self
+			    define reader: [user] as self but not blocked
+			`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			storeID := ulid.Make().String()
+			modelID := ulid.Make().String()
+
+			mockController := gomock.NewController(t)
+			defer mockController.Finish()
+
+			mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+			mockDatastore.EXPECT().
+				ReadAuthorizationModel(gomock.Any(), storeID, modelID).
+				AnyTimes().
+				Return(&openfgapb.AuthorizationModel{
+					SchemaVersion:   typesystem.SchemaVersion1_1,
+					TypeDefinitions: parser.MustParse(tc.model),
+				}, nil)
+
+			mockDatastore.EXPECT().
+				ReadUserTuple(gomock.Any(), storeID, gomock.Any()).
+				AnyTimes().
+				Return(nil, errors.New("connection reset by peer"))
+
+			mockDatastore.EXPECT().
+				ReadUsersetTuples(gomock.Any(), storeID, gomock.Any()).
+				AnyTimes().
+				Return(nil, errors.New("connection reset by peer"))
+
+			s := New(&Dependencies{
+				Datastore: mockDatastore,
+				Logger:    logger.NewNoopLogger(),
+				Transport: gateway.NewNoopTransport(),
+			}, &Config{
+				ResolveNodeLimit: test.DefaultResolveNodeLimit,
+			})
+
+			checkResponse, err := s.Check(ctx, &openfgapb.CheckRequest{
+				StoreId:              storeID,
+				TupleKey:             tuple.NewTupleKey("repo:openfga", "reader", "user:anne"),
+				AuthorizationModelId: modelID,
+			})
+			require.Nil(t, checkResponse, "a datastore error must never produce a result")
+
+			var internalError serverErrors.InternalError
+			require.ErrorAs(t, err, &internalError)
+			require.EqualError(t, err, status.Error(codes.Code(openfgapb.InternalErrorCode_internal_error), serverErrors.InternalServerErrorMsg).Error())
+		})
+	}
+}
+
+func TestCheckWithMalformedContextualTuples(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define viewer: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: gateway.NewNoopTransport(),
+	}, &Config{
+		ResolveNodeLimit:            test.DefaultResolveNodeLimit,
+		MaxContextualTuplesPerCheck: 2,
+	})
+
+	tests := []struct {
+		name             string
+		contextualTuples []*openfgapb.TupleKey
+		expectedError    error
+	}{
+		{
+			name: "valid",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:2", "viewer", "user:bob"),
+			},
+		},
+		{
+			name: "object_without_id",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:", "viewer", "user:anne"),
+			},
+			expectedError: serverErrors.InvalidTuple("invalid 'object' field format", tuple.NewTupleKey("document:", "viewer", "user:anne")),
+		},
+		{
+			name: "object_with_undefined_type",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+			},
+			expectedError: serverErrors.InvalidTuple("type 'folder' not found", tuple.NewTupleKey("folder:1", "viewer", "user:anne")),
+		},
+		{
+			name: "user_without_type",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "anne"),
+			},
+			expectedError: serverErrors.InvalidTuple("the 'user' field must be an object (e.g. document:1) or an 'object#relation' or a typed wildcard (e.g. group:*)", tuple.NewTupleKey("document:1", "viewer", "anne")),
+		},
+		{
+			name: "user_with_undefined_type",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "employee:anne"),
+			},
+			expectedError: serverErrors.InvalidTuple("type 'employee' not found", tuple.NewTupleKey("document:1", "viewer", "employee:anne")),
+		},
+		{
+			name: "too_many_contextual_tuples",
+			contextualTuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+				tuple.NewTupleKey("document:3", "viewer", "user:anne"),
+			},
+			expectedError: serverErrors.ExceededEntityLimit("contextual tuples", 2),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := s.Check(ctx, &openfgapb.CheckRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: modelID,
+				TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+				ContextualTuples: &openfgapb.ContextualTupleKeys{
+					TupleKeys: tc.contextualTuples,
+				},
+			})
+			if tc.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, tc.expectedError)
+		})
+	}
+}
+
+// TestCheckWithUndefinedRelation makes sure that a Check for a relation that isn't defined on the type of the object
+// fails with a validation error instead of returning allowed=false, so that typos in relation names don't look like
+// a deny.
+func TestCheckWithUndefinedRelation(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define viewer: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: gateway.NewNoopTransport(),
+	}, &Config{
+		ResolveNodeLimit: test.DefaultResolveNodeLimit,
+	})
+
+	_, err = s.Check(ctx, &openfgapb.CheckRequest{
+		StoreId:              storeID,
+		AuthorizationModelId: modelID,
+		TupleKey:             tuple.NewTupleKey("document:1", "veiwer", "user:anne"),
+	})
+	require.ErrorIs(t, err, serverErrors.ValidationError(errors.New("relation 'document#veiwer' not found")))
+}
+
+func TestTupleKeyLengthLimits(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define viewer: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: gateway.NewNoopTransport(),
+	}, &Config{
+		ResolveNodeLimit:   test.DefaultResolveNodeLimit,
+		MaxTupleUserLength: 9,
+	})
+
+	t.Run("write_within_limits", func(t *testing.T) {
+		_, err := s.Write(ctx, &openfgapb.WriteRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			Writes: &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			}},
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("write_exceeding_limits", func(t *testing.T) {
+		_, err := s.Write(ctx, &openfgapb.WriteRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			Writes: &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:annie"),
+			}},
+		})
+		require.ErrorContains(t, err, "the 'user' field is longer than the maximum of 9 bytes")
+	})
+
+	t.Run("contextual_tuple_exceeding_limits", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewTupleKey("document:2", "viewer", "user:anne"),
+			ContextualTuples: &openfgapb.ContextualTupleKeys{TupleKeys: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:2", "viewer", "user:annie"),
+			}},
+		})
+		require.ErrorContains(t, err, "the 'user' field is longer than the maximum of 9 bytes")
+	})
+}
+
 func TestOperationsWithInvalidModel(t *testing.T) {
 	ctx := context.Background()
 	storeID := ulid.Make().String()
@@ -275,6 +581,84 @@ func TestOperationsWithInvalidModel(t *testing.T) {
 
 }
 
+func TestListAuthorizationModelsWithStatus(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	validModel := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define viewer: [user] as self
+		`),
+	}
+	err := ds.WriteAuthorizationModel(ctx, storeID, validModel)
+	require.NoError(t, err)
+
+	invalidModel := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define r1: [user] as self and r2
+		    define r2: [user] as self and r1
+		`),
+	}
+	err = ds.WriteAuthorizationModel(ctx, storeID, invalidModel)
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore:    ds,
+		Logger:       logger.NewNoopLogger(),
+		Transport:    gateway.NewNoopTransport(),
+		TokenEncoder: encoder.NewBase64Encoder(),
+	}, &Config{
+		ResolveNodeLimit: test.DefaultResolveNodeLimit,
+	})
+
+	t.Run("without_validation", func(t *testing.T) {
+		models, _, err := s.ListAuthorizationModelsWithStatus(ctx, &openfgapb.ReadAuthorizationModelsRequest{StoreId: storeID}, false)
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+
+		for _, model := range models {
+			require.False(t, model.Validated)
+			require.Empty(t, model.ValidationError)
+		}
+	})
+
+	t.Run("with_validation", func(t *testing.T) {
+		models, _, err := s.ListAuthorizationModelsWithStatus(ctx, &openfgapb.ReadAuthorizationModelsRequest{StoreId: storeID}, true)
+		require.NoError(t, err)
+		require.Len(t, models, 2)
+
+		for _, model := range models {
+			require.True(t, model.Validated)
+
+			switch model.AuthorizationModel.GetId() {
+			case validModel.Id:
+				require.False(t, model.IsLatest)
+				require.Empty(t, model.ValidationError)
+			case invalidModel.Id:
+				require.True(t, model.IsLatest)
+				require.NotEmpty(t, model.ValidationError)
+			default:
+				require.FailNow(t, "unexpected model", model.AuthorizationModel.GetId())
+			}
+		}
+	})
+}
+
 func TestShortestPathToSolutionWins(t *testing.T) {
 	ctx := context.Background()
 
@@ -426,6 +810,172 @@ func TestResolveAuthorizationModel(t *testing.T) {
 	})
 }
 
+// recordingTransport records the response headers set by the server.
+type recordingTransport struct {
+	mu      sync.Mutex
+	headers map[string]string
+}
+
+func (r *recordingTransport) SetHeader(_ context.Context, key, value string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.headers[key] = value
+}
+
+func (r *recordingTransport) reset() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	headers := r.headers
+	r.headers = map[string]string{}
+	return headers
+}
+
+// fakeServerTransportStream makes a context look like the context of a gRPC call.
+type fakeServerTransportStream struct{}
+
+func (fakeServerTransportStream) Method() string               { return "" }
+func (fakeServerTransportStream) SetHeader(metadata.MD) error  { return nil }
+func (fakeServerTransportStream) SendHeader(metadata.MD) error { return nil }
+func (fakeServerTransportStream) SetTrailer(metadata.MD) error { return nil }
+
+func TestResolvedAuthorizationModelIDHeader(t *testing.T) {
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), fakeServerTransportStream{})
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define viewer: [user] as self
+		`),
+	}
+	err := ds.WriteAuthorizationModel(ctx, storeID, model)
+	require.NoError(t, err)
+
+	tk := tuple.NewTupleKey("repo:openfga", "viewer", "user:anne")
+
+	for _, disabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("disabled=%t", disabled), func(t *testing.T) {
+			transport := &recordingTransport{headers: map[string]string{}}
+
+			s := New(&Dependencies{
+				Datastore:    ds,
+				Logger:       logger.NewNoopLogger(),
+				Transport:    transport,
+				TokenEncoder: encoder.NewBase64Encoder(),
+			}, &Config{
+				ResolveNodeLimit:                  test.DefaultResolveNodeLimit,
+				ListObjectsDeadline:               5 * time.Second,
+				DisableAuthorizationModelIDHeader: disabled,
+			})
+
+			assertHeader := func(t *testing.T) {
+				headers := transport.reset()
+				if disabled {
+					require.NotContains(t, headers, AuthorizationModelIDHeader)
+				} else {
+					require.Equal(t, model.Id, headers[AuthorizationModelIDHeader])
+				}
+			}
+
+			_, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, TupleKey: tk})
+			require.NoError(t, err)
+			assertHeader(t)
+
+			_, err = s.ListObjects(ctx, &openfgapb.ListObjectsRequest{StoreId: storeID, Type: "repo", Relation: "viewer", User: "user:anne"})
+			require.NoError(t, err)
+			assertHeader(t)
+
+			_, err = s.Expand(ctx, &openfgapb.ExpandRequest{StoreId: storeID, TupleKey: tuple.NewTupleKey("repo:openfga", "viewer", "")})
+			require.NoError(t, err)
+			assertHeader(t)
+
+			// outside of a gRPC call there is no header to set
+			_, err = s.Check(context.Background(), &openfgapb.CheckRequest{StoreId: storeID, TupleKey: tk})
+			require.NoError(t, err)
+			require.NotContains(t, transport.reset(), AuthorizationModelIDHeader)
+		})
+	}
+}
+
+func TestRequireLatestAuthorizationModel(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	typedefs := parser.MustParse(`
+	type user
+
+	type repo
+	  relations
+	    define viewer: [user] as self
+	`)
+
+	oldModelID := ulid.Make().String()
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:              oldModelID,
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: typedefs,
+	})
+	require.NoError(t, err)
+
+	latestModelID := ulid.Make().String()
+	err = ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:              latestModelID,
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: typedefs,
+	})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore:    ds,
+		Logger:       logger.NewNoopLogger(),
+		Transport:    gateway.NewNoopTransport(),
+		TokenEncoder: encoder.NewBase64Encoder(),
+	}, &Config{
+		ResolveNodeLimit:                test.DefaultResolveNodeLimit,
+		ListObjectsDeadline:             5 * time.Second,
+		RequireLatestAuthorizationModel: true,
+	})
+
+	tk := tuple.NewTupleKey("repo:openfga", "viewer", "user:anne")
+
+	t.Run("check_with_an_old_model_is_rejected", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, AuthorizationModelId: oldModelID, TupleKey: tk})
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotLatest(oldModelID, latestModelID))
+	})
+
+	t.Run("list_objects_with_an_old_model_is_rejected", func(t *testing.T) {
+		_, err := s.ListObjects(ctx, &openfgapb.ListObjectsRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: oldModelID,
+			Type:                 "repo",
+			Relation:             "viewer",
+			User:                 "user:anne",
+		})
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotLatest(oldModelID, latestModelID))
+	})
+
+	t.Run("check_with_the_latest_model_is_allowed", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, AuthorizationModelId: latestModelID, TupleKey: tk})
+		require.NoError(t, err)
+	})
+
+	t.Run("check_without_a_model_is_allowed", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, TupleKey: tk})
+		require.NoError(t, err)
+	})
+}
+
 type mockStreamServer struct {
 	grpc.ServerStream
 }
@@ -505,37 +1055,105 @@ func BenchmarkListObjectsNoRaceCondition(b *testing.B) {
 }
 
 // This test ensures that when the data storage fails, ListObjects v0 throws an error
-func TestListObjects_Unoptimized_UnhappyPaths(t *testing.T) {
+func TestPinnedAuthorizationModel(t *testing.T) {
 	ctx := context.Background()
-	logger := logger.NewNoopLogger()
-	transport := gateway.NewNoopTransport()
-	store := ulid.Make().String()
-	modelID := ulid.Make().String()
-
-	mockController := gomock.NewController(t)
-	defer mockController.Finish()
+	storeID := ulid.Make().String()
 
-	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	ds := memory.New()
+	t.Cleanup(ds.Close)
 
-	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, modelID).AnyTimes().Return(&openfgapb.AuthorizationModel{
+	pinnedModelID := ulid.Make().String()
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            pinnedModelID,
 		SchemaVersion: typesystem.SchemaVersion1_1,
 		TypeDefinitions: parser.MustParse(`
 		type user
 
 		type repo
 		  relations
-		    define allowed: [user] as self
-		    define viewer: [user] as self and allowed
+		    define viewer: [user] as self
 		`),
-	}, nil)
-	mockDatastore.EXPECT().ReadStartingWithUser(gomock.Any(), store, gomock.Any()).AnyTimes().Return(nil, errors.New("error reading from storage"))
+	})
+	require.NoError(t, err)
 
-	s := New(&Dependencies{
-		Datastore: mockDatastore,
-		Transport: transport,
-		Logger:    logger,
-	}, &Config{
-		ResolveNodeLimit:      test.DefaultResolveNodeLimit,
+	// the latest model no longer defines the 'viewer' relation
+	latestModelID := ulid.Make().String()
+	err = ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            latestModelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define reader: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	err = ds.Write(ctx, storeID, nil, []*openfgapb.TupleKey{tuple.NewTupleKey("repo:openfga", "viewer", "user:anne")})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: gateway.NewNoopTransport(),
+	}, &Config{
+		ResolveNodeLimit:            test.DefaultResolveNodeLimit,
+		PinnedAuthorizationModelIDs: map[string]string{storeID: pinnedModelID},
+	})
+
+	tk := tuple.NewTupleKey("repo:openfga", "viewer", "user:anne")
+
+	t.Run("model_id_omitted", func(t *testing.T) {
+		res, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, TupleKey: tk})
+		require.NoError(t, err)
+		require.True(t, res.GetAllowed())
+	})
+
+	t.Run("pinned_model_id", func(t *testing.T) {
+		res, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, AuthorizationModelId: pinnedModelID, TupleKey: tk})
+		require.NoError(t, err)
+		require.True(t, res.GetAllowed())
+	})
+
+	t.Run("other_model_id", func(t *testing.T) {
+		_, err := s.Check(ctx, &openfgapb.CheckRequest{StoreId: storeID, AuthorizationModelId: latestModelID, TupleKey: tk})
+		require.ErrorIs(t, err, serverErrors.AuthorizationModelNotPinned(latestModelID, pinnedModelID))
+	})
+}
+
+func TestListObjects_Unoptimized_UnhappyPaths(t *testing.T) {
+	ctx := context.Background()
+	logger := logger.NewNoopLogger()
+	transport := gateway.NewNoopTransport()
+	store := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, modelID).AnyTimes().Return(&openfgapb.AuthorizationModel{
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define allowed: [user] as self
+		    define viewer: [user] as self and allowed
+		`),
+	}, nil)
+	mockDatastore.EXPECT().ReadStartingWithUser(gomock.Any(), store, gomock.Any()).AnyTimes().Return(nil, errors.New("error reading from storage"))
+
+	s := New(&Dependencies{
+		Datastore: mockDatastore,
+		Transport: transport,
+		Logger:    logger,
+	}, &Config{
+		ResolveNodeLimit:      test.DefaultResolveNodeLimit,
 		ListObjectsDeadline:   5 * time.Second,
 		ListObjectsMaxResults: 1000,
 	})
@@ -566,6 +1184,581 @@ func TestListObjects_Unoptimized_UnhappyPaths(t *testing.T) {
 	})
 }
 
+func TestStreamedListObjectsRejectedWhileDraining(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	s := New(&Dependencies{
+		Datastore: mockDatastore,
+		Transport: gateway.NewNoopTransport(),
+		Logger:    logger.NewNoopLogger(),
+	}, &Config{
+		ResolveNodeLimit:      test.DefaultResolveNodeLimit,
+		ListObjectsDeadline:   5 * time.Second,
+		ListObjectsMaxResults: 1000,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, s.Drain(ctx))
+
+	err := s.StreamedListObjects(&openfgapb.StreamedListObjectsRequest{
+		StoreId:  ulid.Make().String(),
+		Type:     "repo",
+		Relation: "viewer",
+		User:     "user:bob",
+	}, NewMockStreamServer())
+
+	require.ErrorIs(t, err, serverErrors.ServerShuttingDown)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestDrainWaitsForStreamsUntilDeadline(t *testing.T) {
+	newServer := func() *Server {
+		return New(&Dependencies{
+			Transport: gateway.NewNoopTransport(),
+			Logger:    logger.NewNoopLogger(),
+		}, &Config{})
+	}
+
+	t.Run("stream_finishes_before_deadline", func(t *testing.T) {
+		s := newServer()
+
+		endStream, err := s.startStream(context.Background())
+		require.NoError(t, err)
+
+		drained := make(chan error, 1)
+		go func() {
+			drained <- s.Drain(context.Background())
+		}()
+
+		select {
+		case <-drained:
+			require.FailNow(t, "drain returned while a stream was in progress")
+		case <-time.After(50 * time.Millisecond):
+		}
+		require.NoError(t, s.streamsCtx.Err())
+
+		endStream()
+		require.NoError(t, <-drained)
+	})
+
+	t.Run("stream_cancelled_at_deadline", func(t *testing.T) {
+		s := newServer()
+
+		endStream, err := s.startStream(context.Background())
+		require.NoError(t, err)
+		defer endStream()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err = s.Drain(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Error(t, s.streamsCtx.Err())
+
+		// the error caused by the cancellation is reported as retryable
+		err = s.streamError(context.Background(), serverErrors.NewInternalError("", context.Canceled))
+		require.ErrorIs(t, err, serverErrors.ServerShuttingDown)
+	})
+}
+
+func TestStreamedListObjectsConcurrencyLimitPerClient(t *testing.T) {
+	s := New(&Dependencies{
+		Transport: gateway.NewNoopTransport(),
+		Logger:    logger.NewNoopLogger(),
+	}, &Config{
+		ListObjectsMaxConcurrentStreamsPerClient: 1,
+	})
+
+	aliceCtx := authn.ContextWithAuthClaims(context.Background(), &authn.AuthClaims{Subject: "alice"})
+	bobCtx := authn.ContextWithAuthClaims(context.Background(), &authn.AuthClaims{Subject: "bob"})
+
+	endAliceStream, err := s.startStream(aliceCtx)
+	require.NoError(t, err)
+
+	_, err = s.startStream(aliceCtx)
+	require.ErrorIs(t, err, serverErrors.TooManyConcurrentStreams)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	endBobStream, err := s.startStream(bobCtx)
+	require.NoError(t, err)
+	endBobStream()
+
+	endAliceStream()
+
+	endAliceStream, err = s.startStream(aliceCtx)
+	require.NoError(t, err)
+	endAliceStream()
+}
+
+func TestStreamedListObjectsConcurrencyLimitPerGatewayClient(t *testing.T) {
+	s := New(&Dependencies{
+		Transport: gateway.NewNoopTransport(),
+		Logger:    logger.NewNoopLogger(),
+	}, &Config{
+		ListObjectsMaxConcurrentStreamsPerClient: 1,
+	})
+
+	// the peer of every request proxied by the gateway is the gateway
+	gatewayCtx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 8081}})
+	clientCtx := func(remoteAddr string) context.Context {
+		md := s.ForwardClientAddress(context.Background(), &http.Request{RemoteAddr: remoteAddr})
+		return metadata.NewIncomingContext(gatewayCtx, md)
+	}
+
+	endStream, err := s.startStream(clientCtx("10.0.0.1:51000"))
+	require.NoError(t, err)
+
+	_, err = s.startStream(clientCtx("10.0.0.1:51001"))
+	require.ErrorIs(t, err, serverErrors.TooManyConcurrentStreams)
+
+	endOtherStream, err := s.startStream(clientCtx("10.0.0.2:51000"))
+	require.NoError(t, err)
+	endOtherStream()
+
+	t.Run("forged_gateway_token_is_ignored", func(t *testing.T) {
+		md := metadata.Pairs(gatewayTokenHeader, "forged", gatewayClientAddressHeader, "10.0.0.3")
+		require.Equal(t, "peer:127.0.0.1", s.clientKey(metadata.NewIncomingContext(gatewayCtx, md)))
+	})
+
+	endStream()
+}
+
+func TestWriteConcurrencyLimitPerStore(t *testing.T) {
+	s := New(&Dependencies{
+		Transport: gateway.NewNoopTransport(),
+		Logger:    logger.NewNoopLogger(),
+	}, &Config{
+		MaxConcurrentWritesPerStore: 1,
+	})
+
+	storeA := ulid.Make().String()
+	storeB := ulid.Make().String()
+
+	endWriteA, err := s.startWrite(storeA)
+	require.NoError(t, err)
+
+	_, err = s.Write(context.Background(), &openfgapb.WriteRequest{StoreId: storeA})
+	require.ErrorIs(t, err, serverErrors.TooManyConcurrentWrites)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+	endWriteB, err := s.startWrite(storeB)
+	require.NoError(t, err)
+	endWriteB()
+
+	endWriteA()
+
+	endWriteA, err = s.startWrite(storeA)
+	require.NoError(t, err)
+	endWriteA()
+}
+
+func TestWithTupleTTL(t *testing.T) {
+	t.Run("no_header", func(t *testing.T) {
+		ctx, err := withTupleTTL(context.Background())
+		require.NoError(t, err)
+
+		_, ok := storage.TupleExpirationFromContext(ctx)
+		require.False(t, ok)
+	})
+
+	t.Run("valid_ttl", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TupleTTLHeader, "1h"))
+
+		ctx, err := withTupleTTL(ctx)
+		require.NoError(t, err)
+
+		expiresAt, ok := storage.TupleExpirationFromContext(ctx)
+		require.True(t, ok)
+		require.WithinDuration(t, time.Now().Add(time.Hour), expiresAt, time.Minute)
+	})
+
+	for _, ttl := range []string{"forever", "0s", "-1h"} {
+		t.Run("invalid_ttl_"+ttl, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(TupleTTLHeader, ttl))
+
+			_, err := withTupleTTL(ctx)
+			require.ErrorIs(t, err, serverErrors.InvalidTupleTTL(ttl))
+		})
+	}
+}
+
+func TestShadowCheck(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	modelID := ulid.Make().String()
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define editor: [user] as self
+		    define viewer: [user] as self
+		`),
+	})
+	require.NoError(t, err)
+
+	// editors are also viewers in the candidate model
+	candidateModelID := ulid.Make().String()
+	err = ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            candidateModelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type document
+		  relations
+		    define editor: [user] as self
+		    define viewer: [user] as self or editor
+		`),
+	})
+	require.NoError(t, err)
+
+	err = ds.Write(ctx, storeID, nil, []*openfgapb.TupleKey{tuple.NewTupleKey("document:1", "editor", "user:anne")})
+	require.NoError(t, err)
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: gateway.NewNoopTransport(),
+	}, &Config{
+		ResolveNodeLimit:               test.DefaultResolveNodeLimit,
+		CandidateAuthorizationModelIDs: map[string]string{storeID: candidateModelID},
+	})
+
+	tests := []struct {
+		name            string
+		tupleKey        *openfgapb.TupleKey
+		expectedOutcome string
+	}{
+		{
+			name:            "mismatch",
+			tupleKey:        tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+			expectedOutcome: "mismatch",
+		},
+		{
+			name:            "match",
+			tupleKey:        tuple.NewTupleKey("document:1", "viewer", "user:bob"),
+			expectedOutcome: "match",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			counter := shadowCheckCounter.WithLabelValues(tc.expectedOutcome)
+			before := testutil.ToFloat64(counter)
+
+			res, err := s.Check(ctx, &openfgapb.CheckRequest{
+				StoreId:              storeID,
+				AuthorizationModelId: modelID,
+				TupleKey:             tc.tupleKey,
+			})
+			require.NoError(t, err)
+
+			// the served result comes from the requested model
+			require.False(t, res.GetAllowed())
+
+			require.Eventually(t, func() bool {
+				return testutil.ToFloat64(counter) == before+1
+			}, time.Second, 10*time.Millisecond)
+		})
+	}
+
+	t.Run("not_shadowed_with_the_candidate_model", func(t *testing.T) {
+		before := testutil.ToFloat64(shadowCheckCounter.WithLabelValues("match"))
+
+		res, err := s.Check(ctx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: candidateModelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, res.GetAllowed())
+
+		require.Len(t, s.shadowChecks, 0)
+		require.Equal(t, before, testutil.ToFloat64(shadowCheckCounter.WithLabelValues("match")))
+	})
+}
+
+func TestModelCacheBypass(t *testing.T) {
+	bypassCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ModelCacheBypassHeader, "true"))
+
+	t.Run("disabled", func(t *testing.T) {
+		s := New(&Dependencies{
+			Datastore: memory.New(),
+			Transport: gateway.NewNoopTransport(),
+			Logger:    logger.NewNoopLogger(),
+		}, &Config{})
+
+		_, err := s.withModelCacheBypass(bypassCtx)
+		require.ErrorIs(t, err, serverErrors.ModelCacheBypassDisabled)
+	})
+
+	t.Run("reads_the_model_from_the_datastore", func(t *testing.T) {
+		store := ulid.Make().String()
+		modelID := ulid.Make().String()
+
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), store, modelID).Return(
+			&openfgapb.AuthorizationModel{
+				Id:            modelID,
+				SchemaVersion: typesystem.SchemaVersion1_1,
+			},
+			nil,
+		).Times(2)
+
+		s := New(&Dependencies{
+			Datastore: mockDatastore,
+			Transport: gateway.NewNoopTransport(),
+			Logger:    logger.NewNoopLogger(),
+		}, &Config{ModelCacheBypassEnabled: true})
+
+		// the second resolution is served from the cache
+		for i := 0; i < 2; i++ {
+			_, err := s.resolveTypesystem(context.Background(), store, modelID)
+			require.NoError(t, err)
+		}
+
+		ctx, err := s.withModelCacheBypass(bypassCtx)
+		require.NoError(t, err)
+		require.True(t, storage.ModelCacheBypassFromContext(ctx))
+
+		_, err = s.resolveTypesystem(ctx, store, modelID)
+		require.NoError(t, err)
+	})
+}
+
+func TestCheckTrace(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	err := ds.WriteAuthorizationModel(ctx, storeID, &openfgapb.AuthorizationModel{
+		Id:            modelID,
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type group
+		  relations
+		    define member: [user] as self
+
+		type document
+		  relations
+		    define editor: [group#member] as self
+		    define viewer: [user] as self or editor
+		`),
+	})
+	require.NoError(t, err)
+
+	err = ds.Write(ctx, storeID, nil, []*openfgapb.TupleKey{
+		tuple.NewTupleKey("document:1", "editor", "group:eng#member"),
+		tuple.NewTupleKey("group:eng", "member", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	transport := &recordingTransport{headers: map[string]string{}}
+
+	s := New(&Dependencies{
+		Datastore: ds,
+		Logger:    logger.NewNoopLogger(),
+		Transport: transport,
+	}, &Config{
+		ResolveNodeLimit:                  test.DefaultResolveNodeLimit,
+		DisableAuthorizationModelIDHeader: true,
+		Experimentals:                     []ExperimentalFeatureFlag{featureflags.CheckTrace},
+	})
+
+	traceCtx := metadata.NewIncomingContext(ctx, metadata.Pairs(CheckTraceHeader, "true"))
+
+	t.Run("allowed", func(t *testing.T) {
+		resp, err := s.Check(traceCtx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+
+		require.JSONEq(t, `[{
+			"rule": "check",
+			"tuple_key": "document:1#viewer@user:anne",
+			"children": [{
+				"rule": "computed_userset",
+				"tuple_key": "document:1#editor@user:anne",
+				"children": [{
+					"rule": "userset",
+					"tuple_key": "group:eng#member@user:anne",
+					"tuple": "document:1#editor@group:eng#member",
+					"children": [{
+						"rule": "direct",
+						"tuple_key": "group:eng#member@user:anne",
+						"tuple": "group:eng#member@user:anne"
+					}]
+				}]
+			}]
+		}]`, transport.headers[CheckTracePathHeader])
+		require.NotContains(t, transport.reset(), CheckTraceTruncatedHeader)
+	})
+
+	t.Run("not_allowed", func(t *testing.T) {
+		resp, err := s.Check(traceCtx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:bob"),
+		})
+		require.NoError(t, err)
+		require.False(t, resp.GetAllowed())
+		require.NotContains(t, transport.reset(), CheckTracePathHeader)
+	})
+
+	t.Run("not_requested", func(t *testing.T) {
+		resp, err := s.Check(ctx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.NotContains(t, transport.reset(), CheckTracePathHeader)
+	})
+
+	t.Run("feature_not_enabled", func(t *testing.T) {
+		s := New(&Dependencies{
+			Datastore: ds,
+			Logger:    logger.NewNoopLogger(),
+			Transport: transport,
+		}, &Config{
+			ResolveNodeLimit:                  test.DefaultResolveNodeLimit,
+			DisableAuthorizationModelIDHeader: true,
+		})
+
+		resp, err := s.Check(traceCtx, &openfgapb.CheckRequest{
+			StoreId:              storeID,
+			AuthorizationModelId: modelID,
+			TupleKey:             tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		})
+		require.NoError(t, err)
+		require.True(t, resp.GetAllowed())
+		require.NotContains(t, transport.reset(), CheckTracePathHeader)
+	})
+}
+
+func TestRegisterInterceptors(t *testing.T) {
+	unary := func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(ctx, req)
+	}
+	stream := func(srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, ss)
+	}
+
+	unaryBefore := len(RegisteredUnaryInterceptors())
+	streamBefore := len(RegisteredStreamInterceptors())
+
+	RegisterUnaryInterceptors(unary, unary)
+	RegisterStreamInterceptors(stream)
+
+	require.Len(t, RegisteredUnaryInterceptors(), unaryBefore+2)
+	require.Len(t, RegisteredStreamInterceptors(), streamBefore+1)
+
+	// the returned slices are copies, so callers can't change the registered interceptors
+	registered := RegisteredUnaryInterceptors()
+	registered[0] = nil
+	require.NotNil(t, RegisteredUnaryInterceptors()[0])
+}
+
+type recordingAuditLogger struct {
+	entries []audit.Entry
+	err     error
+}
+
+func (l *recordingAuditLogger) Log(_ context.Context, entry audit.Entry) error {
+	if l.err != nil {
+		return l.err
+	}
+
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *recordingAuditLogger) Close() error {
+	return nil
+}
+
+func TestAuditLog(t *testing.T) {
+	ds := memory.New()
+	t.Cleanup(ds.Close)
+
+	auditLogger := &recordingAuditLogger{}
+	s := New(&Dependencies{
+		Datastore:   ds,
+		Transport:   gateway.NewNoopTransport(),
+		Logger:      logger.NewNoopLogger(),
+		AuditLogger: auditLogger,
+	}, &Config{})
+
+	ctx := authn.ContextWithAuthClaims(context.Background(), &authn.AuthClaims{Subject: "alice"})
+
+	createRes, err := s.CreateStore(ctx, &openfgapb.CreateStoreRequest{Name: "audited"})
+	require.NoError(t, err)
+	storeID := createRes.GetId()
+
+	writeRes, err := s.WriteAuthorizationModel(ctx, &openfgapb.WriteAuthorizationModelRequest{
+		StoreId:         storeID,
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`type user`),
+	})
+	require.NoError(t, err)
+
+	// read operations are not recorded
+	_, err = s.GetStore(ctx, &openfgapb.GetStoreRequest{StoreId: storeID})
+	require.NoError(t, err)
+
+	_, err = s.DeleteStore(ctx, &openfgapb.DeleteStoreRequest{StoreId: storeID})
+	require.NoError(t, err)
+
+	require.Len(t, auditLogger.entries, 3)
+
+	operations := []string{audit.OperationCreateStore, audit.OperationWriteAuthorizationModel, audit.OperationDeleteStore}
+	for i, entry := range auditLogger.entries {
+		require.Equal(t, operations[i], entry.Operation)
+		require.Equal(t, "alice", entry.Principal)
+		require.Equal(t, storeID, entry.StoreID)
+		require.WithinDuration(t, time.Now(), entry.Time, time.Minute)
+		require.NotEmpty(t, entry.Request)
+	}
+
+	require.Contains(t, string(auditLogger.entries[0].Request), "audited")
+	require.Contains(t, string(auditLogger.entries[1].Response), writeRes.GetAuthorizationModelId())
+	require.Empty(t, auditLogger.entries[2].Response)
+
+	t.Run("operation_succeeds_if_the_entry_cannot_be_written", func(t *testing.T) {
+		auditLogger.err = errors.New("disk full")
+		failures := testutil.ToFloat64(auditLogFailuresCounter.WithLabelValues(audit.OperationCreateStore))
+
+		_, err := s.CreateStore(ctx, &openfgapb.CreateStoreRequest{Name: "unaudited"})
+		require.NoError(t, err)
+		require.Equal(t, failures+1, testutil.ToFloat64(auditLogFailuresCounter.WithLabelValues(audit.OperationCreateStore)))
+	})
+}
+
 // This test ensures that when the data storage fails for known eror, ListObjects v0 throws the correct error
 func TestListObjects_Unoptimized_UnhappyPaths_Known_Error(t *testing.T) {
 	ctx := context.Background()
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"time"
+
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// shadowCheckTimeout bounds the evaluation of a Check with the candidate model of its store.
+	shadowCheckTimeout = 5 * time.Second
+
+	// maxConcurrentShadowChecks bounds the number of shadow Checks in progress, so that shadow evaluation can't
+	// pile up when the candidate model is slow. The Checks served while the bound is reached are not shadowed.
+	maxConcurrentShadowChecks = 100
+)
+
+var shadowCheckCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "shadow_check_count",
+	Help: "Number of Check calls also evaluated with the candidate authorization model of their store, partitioned by whether the candidate model agreed with the served result ('match' or 'mismatch'), failed to evaluate ('error'), or was not evaluated because too many shadow Checks were in progress ('skipped')",
+}, []string{"outcome"})
+
+// shadowCheck evaluates the Check request with the candidate authorization model of its store, if it has one, in
+// the background, and records whether the candidate model agrees with the served result. It never affects the
+// response of the Check, and shadow Checks are cancelled when the server is drained.
+func (s *Server) shadowCheck(req *openfgapb.CheckRequest, servedModelID string, allowed bool) {
+	candidateModelID, ok := s.config.CandidateAuthorizationModelIDs[req.GetStoreId()]
+	if !ok || candidateModelID == servedModelID {
+		return
+	}
+
+	select {
+	case s.shadowChecks <- struct{}{}:
+	default:
+		shadowCheckCounter.WithLabelValues("skipped").Inc()
+		return
+	}
+
+	req = proto.Clone(req).(*openfgapb.CheckRequest)
+
+	go func() {
+		defer func() { <-s.shadowChecks }()
+
+		ctx, cancel := context.WithTimeout(s.drainCtx, shadowCheckTimeout)
+		defer cancel()
+
+		fields := []zap.Field{
+			zap.String("store_id", req.GetStoreId()),
+			zap.String("candidate_authorization_model_id", candidateModelID),
+			zap.String("tuple_key", tuple.TupleKeyToString(req.GetTupleKey())),
+		}
+
+		candidateAllowed, err := s.evaluateShadowCheck(ctx, req, candidateModelID)
+		if err != nil {
+			shadowCheckCounter.WithLabelValues("error").Inc()
+			s.logger.Info("failed to evaluate the Check with the candidate authorization model", append(fields, zap.Error(err))...)
+			return
+		}
+
+		if candidateAllowed == allowed {
+			shadowCheckCounter.WithLabelValues("match").Inc()
+			return
+		}
+
+		shadowCheckCounter.WithLabelValues("mismatch").Inc()
+		s.logger.Warn("the candidate authorization model disagrees with the served Check result",
+			append(fields, zap.Bool("allowed", allowed), zap.Bool("candidate_allowed", candidateAllowed))...)
+	}()
+}
+
+func (s *Server) evaluateShadowCheck(ctx context.Context, req *openfgapb.CheckRequest, candidateModelID string) (bool, error) {
+	ctx, span := tracer.Start(ctx, "shadowCheck")
+	defer span.End()
+
+	typesys, err := s.typesystemResolver(ctx, req.GetStoreId(), candidateModelID)
+	if err != nil {
+		return false, err
+	}
+
+	return s.checkWithTypesystem(ctx, typesys, req)
+}
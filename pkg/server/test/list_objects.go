@@ -97,6 +97,31 @@ func TestListObjectsRespectsMaxResults(t *testing.T, ds storage.OpenFGADatastore
 			minimumResultsExpected: 2,
 			allResults:             []string{"document:1", "document:2", "document:3"},
 		},
+		{
+			name:   "counts_distinct_objects_when_related_through_multiple_paths",
+			schema: typesystem.SchemaVersion1_1,
+			model: `
+			type user
+			type document
+			  relations
+			    define editor: [user] as self
+			    define viewer: [user] as self or editor
+			`,
+			tuples: []*openfgapb.TupleKey{
+				tuple.NewTupleKey("document:1", "viewer", "user:alice"),
+				tuple.NewTupleKey("document:1", "editor", "user:alice"),
+				tuple.NewTupleKey("document:2", "editor", "user:alice"),
+			},
+			user:       "user:alice",
+			objectType: "document",
+			relation:   "viewer",
+			contextualTuples: &openfgapb.ContextualTupleKeys{
+				TupleKeys: []*openfgapb.TupleKey{tuple.NewTupleKey("document:2", "viewer", "user:alice")},
+			},
+			maxResults:             2,
+			minimumResultsExpected: 2,
+			allResults:             []string{"document:1", "document:2"},
+		},
 		{
 			name:   "respects_when_schema_1_1_and_concurrent_checks_implementation",
 			schema: typesystem.SchemaVersion1_1,
@@ -207,6 +232,7 @@ func TestListObjectsRespectsMaxResults(t *testing.T, ds storage.OpenFGADatastore
 				ListObjectsMaxResults: test.maxResults,
 				ResolveNodeLimit:      DefaultResolveNodeLimit,
 				CheckConcurrencyLimit: 100,
+				DeduplicateResults:    true,
 			}
 
 			// assertions
@@ -254,6 +280,7 @@ func TestListObjectsRespectsMaxResults(t *testing.T, ds storage.OpenFGADatastore
 				require.LessOrEqual(t, len(res.Objects), int(test.maxResults))
 				require.GreaterOrEqual(t, len(res.Objects), int(test.minimumResultsExpected))
 				require.Subset(t, test.allResults, res.Objects)
+				require.Len(t, res.Objects, len(uniqueStrings(res.Objects)))
 			})
 		})
 	}
@@ -262,6 +289,84 @@ func TestListObjectsRespectsMaxResults(t *testing.T, ds storage.OpenFGADatastore
 // Used to avoid compiler optimizations (see https://dave.cheney.net/2013/06/30/how-to-write-benchmarks-in-go)
 var listObjectsResponse *openfgapb.ListObjectsResponse //nolint
 
+func TestListObjectsRespectsMaxPathsExplored(t *testing.T, ds storage.OpenFGADatastore) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+		type document
+		  relations
+		    define viewer: [user] as self
+		`),
+	}
+	err := ds.WriteAuthorizationModel(ctx, store, model)
+	require.NoError(t, err)
+
+	var tuples []*openfgapb.TupleKey
+	for i := 0; i < 10; i++ {
+		tuples = append(tuples, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:jon"))
+	}
+	err = ds.Write(ctx, store, nil, tuples)
+	require.NoError(t, err)
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesystem.New(model))
+
+	req := &openfgapb.ListObjectsRequest{
+		StoreId:              store,
+		AuthorizationModelId: model.Id,
+		Type:                 "document",
+		Relation:             "viewer",
+		User:                 "user:jon",
+	}
+
+	t.Run("truncates_once_the_maximum_is_reached", func(t *testing.T) {
+		var truncated bool
+		listObjectsQuery := commands.ListObjectsQuery{
+			Datastore:             ds,
+			Logger:                logger.NewNoopLogger(),
+			ListObjectsDeadline:   10 * time.Second,
+			ListObjectsMaxResults: 1000,
+			ResolveNodeLimit:      DefaultResolveNodeLimit,
+			CheckConcurrencyLimit: 100,
+			MaxPathsExplored:      3,
+			OnTruncated: func() {
+				truncated = true
+			},
+		}
+
+		res, err := listObjectsQuery.Execute(ctx, req)
+		require.NoError(t, err)
+		require.True(t, truncated)
+		require.NotEmpty(t, res.GetObjects())
+		require.LessOrEqual(t, len(res.GetObjects()), 3)
+	})
+
+	t.Run("does_not_truncate_below_the_maximum", func(t *testing.T) {
+		var truncated bool
+		listObjectsQuery := commands.ListObjectsQuery{
+			Datastore:             ds,
+			Logger:                logger.NewNoopLogger(),
+			ListObjectsDeadline:   10 * time.Second,
+			ListObjectsMaxResults: 1000,
+			ResolveNodeLimit:      DefaultResolveNodeLimit,
+			CheckConcurrencyLimit: 100,
+			MaxPathsExplored:      100,
+			OnTruncated: func() {
+				truncated = true
+			},
+		}
+
+		res, err := listObjectsQuery.Execute(ctx, req)
+		require.NoError(t, err)
+		require.False(t, truncated)
+		require.Len(t, res.GetObjects(), 10)
+	})
+}
+
 func BenchmarkListObjectsWithReverseExpand(b *testing.B, ds storage.OpenFGADatastore) {
 
 	ctx := context.Background()
@@ -406,3 +511,12 @@ func BenchmarkListObjectsWithConcurrentChecks(b *testing.B, ds storage.OpenFGADa
 
 	listObjectsResponse = r
 }
+
+func uniqueStrings(values []string) map[string]struct{} {
+	unique := make(map[string]struct{}, len(values))
+	for _, v := range values {
+		unique[v] = struct{}{}
+	}
+
+	return unique
+}
@@ -140,6 +140,97 @@ func TestReadAuthorizationModelsWithPaging(t *testing.T, datastore storage.OpenF
 	require.ErrorContains(err, "Invalid continuation token")
 }
 
+func TestReadAuthorizationModelsPageSizeLimits(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+	logger := logger.NewNoopLogger()
+	store := ulid.Make().String()
+
+	for i := 0; i < 3; i++ {
+		err := datastore.WriteAuthorizationModel(ctx, store, &openfgapb.AuthorizationModel{
+			Id:              ulid.Make().String(),
+			SchemaVersion:   typesystem.SchemaVersion1_0,
+			TypeDefinitions: []*openfgapb.TypeDefinition{{Type: "repo"}},
+		})
+		require.NoError(t, err)
+	}
+
+	query := commands.NewReadAuthorizationModelsQuery(datastore, logger, encoder.NewBase64Encoder(),
+		commands.WithReadAuthorizationModelsDefaultPageSize(1),
+		commands.WithReadAuthorizationModelsMaxPageSize(2),
+	)
+
+	tests := []struct {
+		name              string
+		pageSize          *wrapperspb.Int32Value
+		expectedNumModels int
+	}{
+		{name: "default_page_size", pageSize: nil, expectedNumModels: 1},
+		{name: "below_max_page_size", pageSize: wrapperspb.Int32(1), expectedNumModels: 1},
+		{name: "at_max_page_size", pageSize: wrapperspb.Int32(2), expectedNumModels: 2},
+		{name: "above_max_page_size", pageSize: wrapperspb.Int32(3), expectedNumModels: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := query.Execute(ctx, &openfgapb.ReadAuthorizationModelsRequest{
+				StoreId:  store,
+				PageSize: test.pageSize,
+			})
+			require.NoError(t, err)
+			require.Len(t, resp.GetAuthorizationModels(), test.expectedNumModels)
+			require.NotEmpty(t, resp.GetContinuationToken())
+		})
+	}
+
+	t.Run("clamped_page_continues_with_the_token", func(t *testing.T) {
+		first, err := query.Execute(ctx, &openfgapb.ReadAuthorizationModelsRequest{
+			StoreId:  store,
+			PageSize: wrapperspb.Int32(3),
+		})
+		require.NoError(t, err)
+		require.Len(t, first.GetAuthorizationModels(), 2)
+
+		second, err := query.Execute(ctx, &openfgapb.ReadAuthorizationModelsRequest{
+			StoreId:           store,
+			PageSize:          wrapperspb.Int32(3),
+			ContinuationToken: first.GetContinuationToken(),
+		})
+		require.NoError(t, err)
+		require.Len(t, second.GetAuthorizationModels(), 1)
+		require.Empty(t, second.GetContinuationToken())
+	})
+
+	t.Run("default_limits_serve_the_largest_page_of_the_api", func(t *testing.T) {
+		store := ulid.Make().String()
+
+		for i := 0; i < commands.MaxReadAuthorizationModelsPageSize+1; i++ {
+			err := datastore.WriteAuthorizationModel(ctx, store, &openfgapb.AuthorizationModel{
+				Id:              ulid.Make().String(),
+				SchemaVersion:   typesystem.SchemaVersion1_0,
+				TypeDefinitions: []*openfgapb.TypeDefinition{{Type: "repo"}},
+			})
+			require.NoError(t, err)
+		}
+
+		// the limits of the default config
+		query := commands.NewReadAuthorizationModelsQuery(datastore, logger, encoder.NewBase64Encoder(),
+			commands.WithReadAuthorizationModelsDefaultPageSize(storage.DefaultPageSize),
+			commands.WithReadAuthorizationModelsMaxPageSize(commands.MaxReadAuthorizationModelsPageSize),
+		)
+
+		resp, err := query.Execute(ctx, &openfgapb.ReadAuthorizationModelsRequest{StoreId: store})
+		require.NoError(t, err)
+		require.Len(t, resp.GetAuthorizationModels(), storage.DefaultPageSize)
+
+		resp, err = query.Execute(ctx, &openfgapb.ReadAuthorizationModelsRequest{
+			StoreId:  store,
+			PageSize: wrapperspb.Int32(commands.MaxReadAuthorizationModelsPageSize),
+		})
+		require.NoError(t, err)
+		require.Len(t, resp.GetAuthorizationModels(), commands.MaxReadAuthorizationModelsPageSize)
+	})
+}
+
 func TestReadAuthorizationModelsInvalidContinuationToken(t *testing.T, datastore storage.OpenFGADatastore) {
 	require := require.New(t)
 	ctx := context.Background()
@@ -201,6 +201,35 @@ func TestReadChanges(t *testing.T, datastore storage.OpenFGADatastore) {
 		readChangesQuery := commands.NewReadChangesQuery(backend, logger.NewNoopLogger(), encoder, 2)
 		runTests(t, ctx, testCases, readChangesQuery)
 	})
+
+	t.Run("read_changes_with_max_page_size", func(t *testing.T) {
+		testCases := []testCase{
+			{
+				_name:   "page_size_larger_than_the_max_is_clamped_to_the_max",
+				request: newReadChangesRequest(store, "repo", "", storage.DefaultPageSize),
+				expectedChanges: []*openfgapb.TupleChange{{
+					TupleKey:  tkMaria,
+					Operation: openfgapb.TupleOperation_TUPLE_OPERATION_WRITE,
+				}},
+				expectEmptyContinuationToken:     false,
+				expectedError:                    nil,
+				saveContinuationTokenForNextTest: true,
+			},
+			{
+				_name:   "using_the_token_from_the_previous_test_yields_the_next_change",
+				request: newReadChangesRequest(store, "repo", "", storage.DefaultPageSize),
+				expectedChanges: []*openfgapb.TupleChange{{
+					TupleKey:  tkCraig,
+					Operation: openfgapb.TupleOperation_TUPLE_OPERATION_WRITE,
+				}},
+				expectEmptyContinuationToken: false,
+				expectedError:                nil,
+			},
+		}
+
+		readChangesQuery := commands.NewReadChangesQuery(backend, logger.NewNoopLogger(), encoder, 0, commands.WithReadChangesMaxPageSize(1))
+		runTests(t, ctx, testCases, readChangesQuery)
+	})
 }
 
 func runTests(t *testing.T, ctx context.Context, testCasesInOrder []testCase, readChangesQuery *commands.ReadChangesQuery) {
@@ -41,6 +41,10 @@ func RunQueryTests(t *testing.T, ds storage.OpenFGADatastore) {
 		func(t *testing.T) { TestReadAuthorizationModelsWithPaging(t, ds) },
 	)
 
+	t.Run("TestReadAuthorizationModelsPageSizeLimits",
+		func(t *testing.T) { TestReadAuthorizationModelsPageSizeLimits(t, ds) },
+	)
+
 	t.Run("TestReadAuthorizationModelsInvalidContinuationToken",
 		func(t *testing.T) { TestReadAuthorizationModelsInvalidContinuationToken(t, ds) },
 	)
@@ -51,10 +55,13 @@ func RunQueryTests(t *testing.T, ds storage.OpenFGADatastore) {
 	)
 
 	t.Run("TestListObjectsRespectsMaxResults", func(t *testing.T) { TestListObjectsRespectsMaxResults(t, ds) })
+	t.Run("TestListObjectsRespectsMaxPathsExplored", func(t *testing.T) { TestListObjectsRespectsMaxPathsExplored(t, ds) })
+	t.Run("TestValidateModelTuplesQuery", func(t *testing.T) { TestValidateModelTuplesQuery(t, ds) })
 }
 
 func RunCommandTests(t *testing.T, ds storage.OpenFGADatastore) {
 	t.Run("TestWriteCommand", func(t *testing.T) { TestWriteCommand(t, ds) })
+	t.Run("TestIdempotentWriteCommand", func(t *testing.T) { TestIdempotentWriteCommand(t, ds) })
 	t.Run("TestWriteAuthorizationModel", func(t *testing.T) { WriteAuthorizationModelTest(t, ds) })
 	t.Run("TestWriteAssertions", func(t *testing.T) { TestWriteAssertions(t, ds) })
 	t.Run("TestCreateStore", func(t *testing.T) { TestCreateStore(t, ds) })
@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/pkg/encoder"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/server/commands"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+func TestValidateModelTuplesQuery(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	err := datastore.Write(ctx, storeID, nil, []*openfgapb.TupleKey{
+		tuple.NewTupleKey("document:1", "viewer", "user:anne"),
+		tuple.NewTupleKey("document:1", "viewer", "group:eng#member"),
+		tuple.NewTupleKey("document:1", "editor", "user:bob"),
+		tuple.NewTupleKey("folder:1", "viewer", "user:anne"),
+	})
+	require.NoError(t, err)
+
+	// the proposed model no longer allows groups as viewers, removes the editor relation and the folder type
+	model, err := commands.ParseAuthorizationModel([]byte(`
+	type user
+
+	type group
+	  relations
+	    define member: [user] as self
+
+	type document
+	  relations
+	    define viewer: [user] as self
+	`))
+	require.NoError(t, err)
+
+	t.Run("reports_the_tuples_the_model_does_not_allow", func(t *testing.T) {
+		var invalid []string
+		q := commands.NewValidateModelTuplesQuery(datastore, logger.NewNoopLogger(), encoder.NewBase64Encoder())
+		result, err := q.Execute(ctx, storeID, model, "", func(invalidTuple *commands.InvalidTuple) error {
+			require.NotEmpty(t, invalidTuple.Reason)
+			invalid = append(invalid, tuple.TupleKeyToString(invalidTuple.TupleKey))
+			return nil
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, 4, result.TuplesScanned)
+		require.Equal(t, 3, result.InvalidTuples)
+		require.Empty(t, result.ContinuationToken)
+		require.ElementsMatch(t, []string{
+			"document:1#viewer@group:eng#member",
+			"document:1#editor@user:bob",
+			"folder:1#viewer@user:anne",
+		}, invalid)
+	})
+
+	t.Run("bounded_scans_can_be_resumed", func(t *testing.T) {
+		q := commands.NewValidateModelTuplesQuery(datastore, logger.NewNoopLogger(), encoder.NewBase64Encoder(),
+			commands.WithValidateModelTuplesPageSize(1),
+			commands.WithValidateModelTuplesMaxScanned(3),
+		)
+
+		invalidCount := 0
+		onInvalid := func(*commands.InvalidTuple) error {
+			invalidCount++
+			return nil
+		}
+
+		result, err := q.Execute(ctx, storeID, model, "", onInvalid)
+		require.NoError(t, err)
+		require.Equal(t, 3, result.TuplesScanned)
+		require.NotEmpty(t, result.ContinuationToken)
+
+		result, err = q.Execute(ctx, storeID, model, result.ContinuationToken, onInvalid)
+		require.NoError(t, err)
+		require.Equal(t, 1, result.TuplesScanned)
+		require.Equal(t, 3, invalidCount)
+	})
+
+	t.Run("stops_when_the_callback_fails", func(t *testing.T) {
+		q := commands.NewValidateModelTuplesQuery(datastore, logger.NewNoopLogger(), encoder.NewBase64Encoder())
+		_, err := q.Execute(ctx, storeID, model, "", func(*commands.InvalidTuple) error {
+			return fmt.Errorf("stream closed")
+		})
+		require.EqualError(t, err, "stream closed")
+	})
+
+	t.Run("invalid_model", func(t *testing.T) {
+		q := commands.NewValidateModelTuplesQuery(datastore, logger.NewNoopLogger(), encoder.NewBase64Encoder())
+		_, err := q.Execute(ctx, storeID, &openfgapb.AuthorizationModel{
+			Id:            ulid.Make().String(),
+			SchemaVersion: "1.1",
+			TypeDefinitions: []*openfgapb.TypeDefinition{
+				{
+					Type: "document",
+					Relations: map[string]*openfgapb.Userset{
+						"viewer": {Userset: &openfgapb.Userset_ComputedUserset{
+							ComputedUserset: &openfgapb.ObjectRelation{Relation: "undefined"},
+						}},
+					},
+				},
+			},
+		}, "", func(*commands.InvalidTuple) error { return nil })
+		require.Error(t, err)
+	})
+}
@@ -1257,3 +1257,95 @@ func TestWriteCommand(t *testing.T, datastore storage.OpenFGADatastore) {
 		})
 	}
 }
+
+func TestIdempotentWriteCommand(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+	logger := logger.NewNoopLogger()
+
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type repo
+		  relations
+		    define admin: [user] as self
+		`),
+	}
+
+	existing := tuple.NewTupleKey("repo:openfga/openfga", "admin", "user:alice")
+	missing := tuple.NewTupleKey("repo:openfga/openfga", "admin", "user:bob")
+
+	setup := func(t *testing.T) string {
+		store := ulid.Make().String()
+
+		err := datastore.WriteAuthorizationModel(ctx, store, model)
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, store, nil, []*openfgapb.TupleKey{existing})
+		require.NoError(t, err)
+
+		return store
+	}
+
+	t.Run("writing_an_existing_tuple_is_a_no_op", func(t *testing.T) {
+		store := setup(t)
+
+		cmd := commands.NewWriteCommand(datastore, logger, commands.WithIdempotentWrites(true))
+		_, err := cmd.Execute(ctx, &openfgapb.WriteRequest{
+			StoreId:              store,
+			AuthorizationModelId: model.Id,
+			Writes:               &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{existing, missing}},
+		})
+		require.NoError(t, err)
+
+		_, err = datastore.ReadUserTuple(ctx, store, missing)
+		require.NoError(t, err)
+
+		// deletes are still strict
+		_, err = cmd.Execute(ctx, &openfgapb.WriteRequest{
+			StoreId: store,
+			Deletes: &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{tuple.NewTupleKey("repo:openfga/openfga", "admin", "user:charlie")}},
+		})
+		require.ErrorIs(t, err, serverErrors.WriteFailedDueToInvalidInput(storage.InvalidWriteInputError(tuple.NewTupleKey("repo:openfga/openfga", "admin", "user:charlie"), openfgapb.TupleOperation_TUPLE_OPERATION_DELETE)))
+	})
+
+	t.Run("deleting_a_missing_tuple_is_a_no_op", func(t *testing.T) {
+		store := setup(t)
+
+		cmd := commands.NewWriteCommand(datastore, logger, commands.WithIdempotentDeletes(true))
+		_, err := cmd.Execute(ctx, &openfgapb.WriteRequest{
+			StoreId: store,
+			Deletes: &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{existing, missing}},
+		})
+		require.NoError(t, err)
+
+		_, err = datastore.ReadUserTuple(ctx, store, existing)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		// writes are still strict
+		err = datastore.Write(ctx, store, nil, []*openfgapb.TupleKey{existing})
+		require.NoError(t, err)
+
+		_, err = cmd.Execute(ctx, &openfgapb.WriteRequest{
+			StoreId:              store,
+			AuthorizationModelId: model.Id,
+			Writes:               &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{existing}},
+		})
+		require.ErrorIs(t, err, serverErrors.WriteFailedDueToInvalidInput(storage.InvalidWriteInputError(existing, openfgapb.TupleOperation_TUPLE_OPERATION_WRITE)))
+	})
+
+	t.Run("a_request_with_nothing_left_to_write_succeeds", func(t *testing.T) {
+		store := setup(t)
+
+		cmd := commands.NewWriteCommand(datastore, logger, commands.WithIdempotentWrites(true), commands.WithIdempotentDeletes(true))
+		_, err := cmd.Execute(ctx, &openfgapb.WriteRequest{
+			StoreId:              store,
+			AuthorizationModelId: model.Id,
+			Writes:               &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{existing}},
+			Deletes:              &openfgapb.TupleKeys{TupleKeys: []*openfgapb.TupleKey{missing}},
+		})
+		require.NoError(t, err)
+	})
+}
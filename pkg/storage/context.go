@@ -24,10 +24,15 @@ func NewContextWrapper(inner OpenFGADatastore) *ContextTracerWrapper {
 }
 
 // queryContext returns a new context (not a child context) with a timeout and
-// the same span data as the supplied context.
+// the same span data as the supplied context. It keeps whether the reads must be sent to the primary.
 func queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
 	span := trace.SpanFromContext(ctx)
-	return trace.ContextWithSpan(context.Background(), span), func() {}
+	queryCtx := trace.ContextWithSpan(context.Background(), span)
+	if ReadFromPrimaryFromContext(ctx) {
+		queryCtx = ContextWithReadFromPrimary(queryCtx)
+	}
+
+	return queryCtx, func() {}
 }
 
 func (c *ContextTracerWrapper) Close() {
@@ -18,6 +18,13 @@ var (
 	ErrMismatchObjectType       = errors.New("mismatched types in request and continuation token")
 	ErrExceededWriteBatchLimit  = errors.New("number of operations exceeded write batch limit")
 	ErrCancelled                = errors.New("request has been cancelled")
+
+	// ErrStatementTimeout is returned when the database aborts a query that ran for longer than the statement
+	// timeout of the datastore.
+	ErrStatementTimeout = errors.New("the datastore statement timeout was exceeded")
+
+	// ErrQueryTimeout is returned when a datastore call runs for longer than the query timeout of the server.
+	ErrQueryTimeout = errors.New("the datastore query timeout was exceeded")
 )
 
 func ExceededMaxTypeDefinitionsLimitError(limit int) error {
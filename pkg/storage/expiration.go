@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type tupleExpirationCtxKey struct{}
+
+// ContextWithTupleExpiration returns a context that makes the tuples written by a Write call made with it expire at
+// expiresAt. Expired tuples are excluded from every read right away, and are removed from the datastore by
+// TupleExpirationPurger.PurgeExpiredTuples later on. Writing a tuple that exists but has expired replaces it.
+func ContextWithTupleExpiration(parent context.Context, expiresAt time.Time) context.Context {
+	return context.WithValue(parent, tupleExpirationCtxKey{}, expiresAt.UTC())
+}
+
+// TupleExpirationFromContext returns the time the tuples written with the context expire at, if any.
+func TupleExpirationFromContext(ctx context.Context) (time.Time, bool) {
+	expiresAt, ok := ctx.Value(tupleExpirationCtxKey{}).(time.Time)
+	return expiresAt, ok
+}
+
+// TupleExpirationPurger is implemented by the datastores that support tuple expiration.
+type TupleExpirationPurger interface {
+	// PurgeExpiredTuples deletes up to limit tuples, across all stores, that expired at or before the provided
+	// time, and records their deletion in the changelog. It returns the number of tuples deleted, which is less
+	// than limit once there are no more expired tuples.
+	PurgeExpiredTuples(ctx context.Context, before time.Time, limit int) (int, error)
+}
@@ -82,6 +82,9 @@ type MemoryBackend struct {
 	// map: store => set of tuples
 	tuples map[string][]*openfgapb.Tuple /* GUARDED_BY(mu) */
 
+	// map: store => tuple key => time the tuple expires at, for the tuples that expire
+	expirations map[string]map[string]time.Time /* GUARDED_BY(mu) */
+
 	// ChangelogBackend
 	// map: store => set of changes
 	changes map[string][]*openfgapb.TupleChange
@@ -98,6 +101,7 @@ type MemoryBackend struct {
 }
 
 var _ storage.OpenFGADatastore = (*MemoryBackend)(nil)
+var _ storage.TupleExpirationPurger = (*MemoryBackend)(nil)
 
 type AuthorizationModelEntry struct {
 	model  *openfgapb.AuthorizationModel
@@ -110,6 +114,7 @@ func New(opts ...StorageOption) storage.OpenFGADatastore {
 		maxTuplesPerWrite:             defaultMaxTuplesPerWrite,
 		maxTypesPerAuthorizationModel: defaultMaxTypesPerAuthorizationModel,
 		tuples:                        make(map[string][]*openfgapb.Tuple, 0),
+		expirations:                   make(map[string]map[string]time.Time, 0),
 		changes:                       make(map[string][]*openfgapb.TupleChange, 0),
 		authorizationModels:           make(map[string]map[string]*AuthorizationModelEntry),
 		stores:                        make(map[string]*openfgapb.Store, 0),
@@ -228,10 +233,9 @@ func (s *MemoryBackend) read(ctx context.Context, store string, tk *openfgapb.Tu
 
 	var matches []*openfgapb.Tuple
 	if tk.GetObject() == "" && tk.GetRelation() == "" && tk.GetUser() == "" {
-		matches = make([]*openfgapb.Tuple, len(s.tuples[store]))
-		copy(matches, s.tuples[store])
+		matches = s.liveTuples(store)
 	} else {
-		for _, t := range s.tuples[store] {
+		for _, t := range s.liveTuples(store) {
 			if match(tk, t.Key) {
 				matches = append(matches, t)
 			}
@@ -270,7 +274,7 @@ func (s *MemoryBackend) Write(ctx context.Context, store string, deletes storage
 
 	now := timestamppb.Now()
 
-	if err := validateTuples(s.tuples[store], deletes, writes); err != nil {
+	if err := validateTuples(s.liveTuples(store), deletes, writes); err != nil {
 		return err
 	}
 
@@ -280,12 +284,27 @@ Delete:
 		for _, k := range deletes {
 			if match(k, t.Key) {
 				s.changes[store] = append(s.changes[store], &openfgapb.TupleChange{TupleKey: t.Key, Operation: openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, Timestamp: now})
+				delete(s.expirations[store], tupleUtils.TupleKeyToString(t.Key))
 				continue Delete
 			}
 		}
+
+		// an expired tuple is replaced by a write of the same tuple
+		if s.isExpired(store, t.Key, now.AsTime()) {
+			for _, k := range writes {
+				if match(k, t.Key) {
+					s.changes[store] = append(s.changes[store], &openfgapb.TupleChange{TupleKey: t.Key, Operation: openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, Timestamp: now})
+					delete(s.expirations[store], tupleUtils.TupleKeyToString(t.Key))
+					continue Delete
+				}
+			}
+		}
+
 		tuples = append(tuples, t)
 	}
 
+	expiresAt, expires := storage.TupleExpirationFromContext(ctx)
+
 Write:
 	for _, t := range writes {
 		for _, et := range tuples {
@@ -295,11 +314,77 @@ Write:
 		}
 		tuples = append(tuples, &openfgapb.Tuple{Key: t, Timestamp: now})
 		s.changes[store] = append(s.changes[store], &openfgapb.TupleChange{TupleKey: t, Operation: openfgapb.TupleOperation_TUPLE_OPERATION_WRITE, Timestamp: now})
+
+		if expires {
+			if s.expirations[store] == nil {
+				s.expirations[store] = map[string]time.Time{}
+			}
+			s.expirations[store][tupleUtils.TupleKeyToString(t)] = expiresAt
+		}
 	}
 	s.tuples[store] = tuples
 	return nil
 }
 
+// liveTuples returns the tuples of the store that haven't expired. It must be called with mu held.
+func (s *MemoryBackend) liveTuples(store string) []*openfgapb.Tuple {
+	if len(s.expirations[store]) == 0 {
+		tuples := make([]*openfgapb.Tuple, len(s.tuples[store]))
+		copy(tuples, s.tuples[store])
+		return tuples
+	}
+
+	now := time.Now()
+
+	var tuples []*openfgapb.Tuple
+	for _, t := range s.tuples[store] {
+		if !s.isExpired(store, t.Key, now) {
+			tuples = append(tuples, t)
+		}
+	}
+
+	return tuples
+}
+
+// isExpired reports whether the tuple of the store has expired at the provided time. It must be called with mu held.
+func (s *MemoryBackend) isExpired(store string, tk *openfgapb.TupleKey, now time.Time) bool {
+	expiresAt, ok := s.expirations[store][tupleUtils.TupleKeyToString(tk)]
+	return ok && !expiresAt.After(now)
+}
+
+// PurgeExpiredTuples See storage.TupleExpirationPurger.PurgeExpiredTuples
+func (s *MemoryBackend) PurgeExpiredTuples(ctx context.Context, before time.Time, limit int) (int, error) {
+	_, span := tracer.Start(ctx, "memory.PurgeExpiredTuples")
+	defer span.End()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := timestamppb.Now()
+
+	purged := 0
+	for store, expirations := range s.expirations {
+		if len(expirations) == 0 {
+			continue
+		}
+
+		var tuples []*openfgapb.Tuple
+		for _, t := range s.tuples[store] {
+			if purged < limit && s.isExpired(store, t.Key, before) {
+				s.changes[store] = append(s.changes[store], &openfgapb.TupleChange{TupleKey: t.Key, Operation: openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, Timestamp: now})
+				delete(expirations, tupleUtils.TupleKeyToString(t.Key))
+				purged++
+				continue
+			}
+
+			tuples = append(tuples, t)
+		}
+		s.tuples[store] = tuples
+	}
+
+	return purged, nil
+}
+
 func validateTuples(tuples []*openfgapb.Tuple, deletes, writes []*openfgapb.TupleKey) error {
 	for _, tk := range deletes {
 		if !find(tuples, tk) {
@@ -331,7 +416,7 @@ func (s *MemoryBackend) ReadUserTuple(ctx context.Context, store string, key *op
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	for _, t := range s.tuples[store] {
+	for _, t := range s.liveTuples(store) {
 		if match(key, t.Key) {
 			return t, nil
 		}
@@ -350,7 +435,7 @@ func (s *MemoryBackend) ReadUsersetTuples(ctx context.Context, store string, fil
 	defer s.mu.Unlock()
 
 	var matches []*openfgapb.Tuple
-	for _, t := range s.tuples[store] {
+	for _, t := range s.liveTuples(store) {
 		if match(&openfgapb.TupleKey{
 			Object:   filter.Object,
 			Relation: filter.Relation,
@@ -387,7 +472,7 @@ func (s *MemoryBackend) ReadStartingWithUser(
 	defer s.mu.Unlock()
 
 	var matches []*openfgapb.Tuple
-	for _, t := range s.tuples[store] {
+	for _, t := range s.liveTuples(store) {
 		if tupleUtils.GetType(t.Key.GetObject()) != filter.ObjectType {
 			continue
 		}
@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrMigrationNeeded is returned when the schema of a datastore is older than the one the server requires.
+var ErrMigrationNeeded = errors.New("datastore migration needed")
+
+// SchemaMigrator is implemented by the datastores whose schema is migrated with the 'migrate' command.
+type SchemaMigrator interface {
+	// MigrateSchema runs the pending migrations of the datastore schema if autoMigrate is true. Otherwise, it
+	// returns an error wrapping ErrMigrationNeeded if there are pending migrations.
+	MigrateSchema(ctx context.Context, autoMigrate bool) error
+}
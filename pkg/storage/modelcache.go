@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+type modelCacheBypassCtxKey struct{}
+
+// ContextWithModelCacheBypass returns a context that makes the authorization model caches read the models requested
+// with it from the datastore, without reading or updating the cached ones.
+func ContextWithModelCacheBypass(parent context.Context) context.Context {
+	return context.WithValue(parent, modelCacheBypassCtxKey{}, true)
+}
+
+// ModelCacheBypassFromContext reports whether the authorization model caches must be bypassed for the context.
+func ModelCacheBypassFromContext(ctx context.Context) bool {
+	bypass, _ := ctx.Value(modelCacheBypassCtxKey{}).(bool)
+	return bypass
+}
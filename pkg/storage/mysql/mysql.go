@@ -7,13 +7,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/go-sql-driver/mysql"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/cenkalti/backoff/v4"
+	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
@@ -37,10 +38,12 @@ type MySQL struct {
 }
 
 var _ storage.OpenFGADatastore = (*MySQL)(nil)
+var _ storage.TupleExpirationPurger = (*MySQL)(nil)
+var _ storage.SchemaMigrator = (*MySQL)(nil)
 
 func New(uri string, cfg *sqlcommon.Config) (*MySQL, error) {
 
-	if cfg.Username != "" || cfg.Password != "" {
+	if cfg.Username != "" || cfg.Password != "" || cfg.StatementTimeout != 0 {
 		dsnCfg, err := mysql.ParseDSN(uri)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse mysql connection dsn: %w", err)
@@ -53,10 +56,19 @@ func New(uri string, cfg *sqlcommon.Config) (*MySQL, error) {
 			dsnCfg.Passwd = cfg.Password
 		}
 
+		if cfg.StatementTimeout != 0 {
+			// the driver sets unknown dsn params as session system variables on every new connection.
+			// MySQL only enforces max_execution_time on read-only SELECT statements.
+			if dsnCfg.Params == nil {
+				dsnCfg.Params = map[string]string{}
+			}
+			dsnCfg.Params["max_execution_time"] = strconv.FormatInt(cfg.StatementTimeout.Milliseconds(), 10)
+		}
+
 		uri = dsnCfg.FormatDSN()
 	}
 
-	db, err := sql.Open("mysql", uri)
+	db, err := sqlcommon.OpenDB("mysql", uri, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize mysql connection: %w", err)
 	}
@@ -77,18 +89,7 @@ func New(uri string, cfg *sqlcommon.Config) (*MySQL, error) {
 		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	}
 
-	policy := backoff.NewExponentialBackOff()
-	policy.MaxElapsedTime = 1 * time.Minute
-	attempt := 1
-	err = backoff.Retry(func() error {
-		err = db.PingContext(context.Background())
-		if err != nil {
-			cfg.Logger.Info("waiting for mysql", zap.Int("attempt", attempt))
-			attempt++
-			return err
-		}
-		return nil
-	}, policy)
+	err = sqlcommon.PingDB(db, "mysql", cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize mysql connection: %w", err)
 	}
@@ -134,7 +135,8 @@ func (m *MySQL) read(ctx context.Context, store string, tupleKey *openfgapb.Tupl
 	sb := m.stbl.
 		Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
 		From("tuple").
-		Where(sq.Eq{"store": store})
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 	if opts != nil {
 		sb = sb.OrderBy("ulid")
 	}
@@ -183,6 +185,22 @@ func (m *MySQL) Write(ctx context.Context, store string, deletes storage.Deletes
 	return sqlcommon.Write(ctx, sqlcommon.NewDBInfo(m.db, m.stbl, sq.Expr("NOW()")), store, deletes, writes, now)
 }
 
+// PurgeExpiredTuples See storage.TupleExpirationPurger.PurgeExpiredTuples
+func (m *MySQL) PurgeExpiredTuples(ctx context.Context, before time.Time, limit int) (int, error) {
+	ctx, span := tracer.Start(ctx, "mysql.PurgeExpiredTuples")
+	defer span.End()
+
+	return sqlcommon.PurgeExpiredTuples(ctx, sqlcommon.NewDBInfo(m.db, m.stbl, sq.Expr("NOW()")), before, limit, time.Now().UTC())
+}
+
+// MigrateSchema See storage.SchemaMigrator.MigrateSchema
+func (m *MySQL) MigrateSchema(ctx context.Context, autoMigrate bool) error {
+	ctx, span := tracer.Start(ctx, "mysql.MigrateSchema")
+	defer span.End()
+
+	return sqlcommon.MigrateSchema(ctx, m.db, "mysql", assets.MySQLMigrationDir, autoMigrate, m.logger)
+}
+
 func (m *MySQL) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
 	ctx, span := tracer.Start(ctx, "mysql.ReadUserTuple")
 	defer span.End()
@@ -202,6 +220,7 @@ func (m *MySQL) ReadUserTuple(ctx context.Context, store string, tupleKey *openf
 			"_user":       tupleKey.GetUser(),
 			"user_type":   userType,
 		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
 		QueryRowContext(ctx).
 		Scan(&record.ObjectType, &record.ObjectID, &record.Relation, &record.User)
 	if err != nil {
@@ -218,7 +237,8 @@ func (m *MySQL) ReadUsersetTuples(ctx context.Context, store string, filter stor
 	sb := m.stbl.Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
 		From("tuple").
 		Where(sq.Eq{"store": store}).
-		Where(sq.Eq{"user_type": tupleUtils.UserSet})
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	objectType, objectID := tupleUtils.SplitObject(filter.Object)
 	if objectType != "" {
@@ -271,7 +291,9 @@ func (m *MySQL) ReadStartingWithUser(ctx context.Context, store string, opts sto
 			"object_type": opts.ObjectType,
 			"relation":    opts.Relation,
 			"_user":       targetUsersArg,
-		}).QueryContext(ctx)
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
+		QueryContext(ctx)
 	if err != nil {
 		return nil, sqlcommon.HandleSQLError(err)
 	}
@@ -117,3 +117,17 @@ func TestReadPageEnsureOrder(t *testing.T) {
 	require.Equal(t, firstTuple, tuples[1].Key)
 
 }
+
+func TestStatementTimeout(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "mysql")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig(sqlcommon.WithStatementTimeout(100*time.Millisecond)))
+	require.NoError(t, err)
+	defer ds.Close()
+
+	var maxExecutionTime int
+	err = ds.db.QueryRowContext(context.Background(), "SELECT @@SESSION.max_execution_time").Scan(&maxExecutionTime)
+	require.NoError(t, err)
+	require.Equal(t, 100, maxExecutionTime)
+}
@@ -8,12 +8,13 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
-	"github.com/cenkalti/backoff/v4"
 	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/openfga/openfga/assets"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/sqlcommon"
@@ -34,9 +35,15 @@ type Postgres struct {
 	logger                 logger.Logger
 	maxTuplesPerWriteField int
 	maxTypesPerModelField  int
+
+	// migrationURI is the connection uri without the statement timeout, which migrations must not be subject to. It
+	// is empty if there is no statement timeout.
+	migrationURI string
 }
 
 var _ storage.OpenFGADatastore = (*Postgres)(nil)
+var _ storage.TupleExpirationPurger = (*Postgres)(nil)
+var _ storage.SchemaMigrator = (*Postgres)(nil)
 
 func New(uri string, cfg *sqlcommon.Config) (*Postgres, error) {
 
@@ -68,7 +75,18 @@ func New(uri string, cfg *sqlcommon.Config) (*Postgres, error) {
 		uri = parsed.String()
 	}
 
-	db, err := sql.Open("pgx", uri)
+	var migrationURI string
+	if cfg.StatementTimeout != 0 {
+		migrationURI = uri
+
+		var err error
+		uri, err = withStatementTimeout(uri, cfg.StatementTimeout)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sqlcommon.OpenDB("pgx", uri, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize postgres connection: %w", err)
 	}
@@ -89,18 +107,7 @@ func New(uri string, cfg *sqlcommon.Config) (*Postgres, error) {
 		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
 	}
 
-	policy := backoff.NewExponentialBackOff()
-	policy.MaxElapsedTime = 1 * time.Minute
-	attempt := 1
-	err = backoff.Retry(func() error {
-		err = db.PingContext(context.Background())
-		if err != nil {
-			cfg.Logger.Info("waiting for postgres", zap.Int("attempt", attempt))
-			attempt++
-			return err
-		}
-		return nil
-	}, policy)
+	err = sqlcommon.PingDB(db, "postgres", cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize postgres connection: %w", err)
 	}
@@ -111,9 +118,32 @@ func New(uri string, cfg *sqlcommon.Config) (*Postgres, error) {
 		logger:                 cfg.Logger,
 		maxTuplesPerWriteField: cfg.MaxTuplesPerWriteField,
 		maxTypesPerModelField:  cfg.MaxTypesPerModelField,
+		migrationURI:           migrationURI,
 	}, nil
 }
 
+// withStatementTimeout sets the statement_timeout run-time parameter in the connection uri, which may be
+// either a URL or a keyword/value connection string. The parameter is set on every connection when it is
+// established, so Postgres cancels any statement that runs for longer than the timeout.
+func withStatementTimeout(uri string, timeout time.Duration) (string, error) {
+	statementTimeout := strconv.FormatInt(timeout.Milliseconds(), 10)
+
+	if !strings.HasPrefix(uri, "postgres://") && !strings.HasPrefix(uri, "postgresql://") {
+		return strings.TrimSpace(uri) + " statement_timeout=" + statementTimeout, nil
+	}
+
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse postgres connection uri: %w", err)
+	}
+
+	query := parsed.Query()
+	query.Set("statement_timeout", statementTimeout)
+	parsed.RawQuery = query.Encode()
+
+	return parsed.String(), nil
+}
+
 // Close closes any open connections and cleans up residual resources
 // used by this storage adapter instance.
 func (p *Postgres) Close() {
@@ -147,7 +177,8 @@ func (p *Postgres) read(ctx context.Context, store string, tupleKey *openfgapb.T
 	sb := p.stbl.
 		Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
 		From("tuple").
-		Where(sq.Eq{"store": store})
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 	if opts != nil {
 		sb = sb.OrderBy("ulid")
 	}
@@ -196,6 +227,34 @@ func (p *Postgres) Write(ctx context.Context, store string, deletes storage.Dele
 	return sqlcommon.Write(ctx, sqlcommon.NewDBInfo(p.db, p.stbl, "NOW()"), store, deletes, writes, now)
 }
 
+// PurgeExpiredTuples See storage.TupleExpirationPurger.PurgeExpiredTuples
+func (p *Postgres) PurgeExpiredTuples(ctx context.Context, before time.Time, limit int) (int, error) {
+	ctx, span := tracer.Start(ctx, "postgres.PurgeExpiredTuples")
+	defer span.End()
+
+	return sqlcommon.PurgeExpiredTuples(ctx, sqlcommon.NewDBInfo(p.db, p.stbl, "NOW()"), before, limit, time.Now().UTC())
+}
+
+// MigrateSchema See storage.SchemaMigrator.MigrateSchema
+func (p *Postgres) MigrateSchema(ctx context.Context, autoMigrate bool) error {
+	ctx, span := tracer.Start(ctx, "postgres.MigrateSchema")
+	defer span.End()
+
+	if p.migrationURI == "" {
+		return sqlcommon.MigrateSchema(ctx, p.db, "postgres", assets.PostgresMigrationDir, autoMigrate, p.logger)
+	}
+
+	// a migration may rewrite whole tables, so it runs on a dedicated connection without the statement timeout
+	db, err := sql.Open("pgx", p.migrationURI)
+	if err != nil {
+		return fmt.Errorf("failed to open the postgres migration connection: %w", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	return sqlcommon.MigrateSchema(ctx, db, "postgres", assets.PostgresMigrationDir, autoMigrate, p.logger)
+}
+
 func (p *Postgres) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
 	ctx, span := tracer.Start(ctx, "postgres.ReadUserTuple")
 	defer span.End()
@@ -215,6 +274,7 @@ func (p *Postgres) ReadUserTuple(ctx context.Context, store string, tupleKey *op
 			"_user":       tupleKey.GetUser(),
 			"user_type":   userType,
 		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
 		QueryRowContext(ctx).
 		Scan(&record.ObjectType, &record.ObjectID, &record.Relation, &record.User)
 	if err != nil {
@@ -231,7 +291,8 @@ func (p *Postgres) ReadUsersetTuples(ctx context.Context, store string, filter s
 	sb := p.stbl.Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
 		From("tuple").
 		Where(sq.Eq{"store": store}).
-		Where(sq.Eq{"user_type": tupleUtils.UserSet})
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
 
 	objectType, objectID := tupleUtils.SplitObject(filter.Object)
 	if objectType != "" {
@@ -284,7 +345,9 @@ func (p *Postgres) ReadStartingWithUser(ctx context.Context, store string, opts
 			"object_type": opts.ObjectType,
 			"relation":    opts.Relation,
 			"_user":       targetUsersArg,
-		}).QueryContext(ctx)
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
+		QueryContext(ctx)
 	if err != nil {
 		return nil, sqlcommon.HandleSQLError(err)
 	}
@@ -0,0 +1,17 @@
+package storage
+
+import "context"
+
+type readFromPrimaryCtxKey struct{}
+
+// ContextWithReadFromPrimary returns a context that makes the datastores with read replicas send the reads made
+// with it to the primary, so that they see the writes that were just committed.
+func ContextWithReadFromPrimary(parent context.Context) context.Context {
+	return context.WithValue(parent, readFromPrimaryCtxKey{}, true)
+}
+
+// ReadFromPrimaryFromContext reports whether the reads made with the context must be sent to the primary.
+func ReadFromPrimaryFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(readFromPrimaryCtxKey{}).(bool)
+	return primary
+}
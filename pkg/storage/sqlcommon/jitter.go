@@ -0,0 +1,162 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"math/rand"
+	"time"
+)
+
+// OpenDB opens a database handle like sql.Open. If cfg.ConnMaxLifetime and cfg.ConnMaxLifetimeJitter are
+// set, the lifetime of each connection is shortened by a random fraction of up to ConnMaxLifetimeJitter,
+// so that connections opened at the same time (e.g. at startup) don't all expire at once.
+func OpenDB(driverName, dsn string, cfg *Config) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.ConnMaxLifetime == 0 || cfg.ConnMaxLifetimeJitter == 0 {
+		return db, nil
+	}
+
+	var connector driver.Connector = &dsnConnector{dsn: dsn, driver: db.Driver()}
+	if driverCtx, ok := db.Driver().(driver.DriverContext); ok {
+		connector, err = driverCtx.OpenConnector(dsn)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+
+	// the handle returned by sql.Open does not hold any connections, it is only needed to look up the driver
+	_ = db.Close()
+
+	return sql.OpenDB(&jitteredLifetimeConnector{
+		Connector:   connector,
+		maxLifetime: cfg.ConnMaxLifetime,
+		jitter:      cfg.ConnMaxLifetimeJitter,
+	}), nil
+}
+
+// dsnConnector is a driver.Connector for drivers that don't implement driver.DriverContext.
+type dsnConnector struct {
+	dsn    string
+	driver driver.Driver
+}
+
+func (c *dsnConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c *dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+type jitteredLifetimeConnector struct {
+	driver.Connector
+	maxLifetime time.Duration
+	jitter      float64
+}
+
+func (c *jitteredLifetimeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := c.maxLifetime - time.Duration(rand.Float64()*c.jitter*float64(c.maxLifetime))
+
+	return &jitteredLifetimeConn{Conn: conn, expiresAt: time.Now().Add(lifetime)}, nil
+}
+
+// jitteredLifetimeConn reports itself as invalid once it expires, so that database/sql closes it instead
+// of returning it to the pool. It forwards the optional driver interfaces to the wrapped connection.
+type jitteredLifetimeConn struct {
+	driver.Conn
+	expiresAt time.Time
+}
+
+var (
+	_ driver.Validator          = (*jitteredLifetimeConn)(nil)
+	_ driver.SessionResetter    = (*jitteredLifetimeConn)(nil)
+	_ driver.ConnBeginTx        = (*jitteredLifetimeConn)(nil)
+	_ driver.ConnPrepareContext = (*jitteredLifetimeConn)(nil)
+	_ driver.ExecerContext      = (*jitteredLifetimeConn)(nil)
+	_ driver.QueryerContext     = (*jitteredLifetimeConn)(nil)
+	_ driver.Pinger             = (*jitteredLifetimeConn)(nil)
+	_ driver.NamedValueChecker  = (*jitteredLifetimeConn)(nil)
+)
+
+func (c *jitteredLifetimeConn) IsValid() bool {
+	if time.Now().After(c.expiresAt) {
+		return false
+	}
+
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+
+	return true
+}
+
+func (c *jitteredLifetimeConn) ResetSession(ctx context.Context) error {
+	if time.Now().After(c.expiresAt) {
+		return driver.ErrBadConn
+	}
+
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+
+	return nil
+}
+
+func (c *jitteredLifetimeConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+
+	return c.Conn.Begin() //nolint:staticcheck // fallback for drivers that don't implement driver.ConnBeginTx
+}
+
+func (c *jitteredLifetimeConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+
+	return c.Conn.Prepare(query)
+}
+
+func (c *jitteredLifetimeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if execer, ok := c.Conn.(driver.ExecerContext); ok {
+		return execer.ExecContext(ctx, query, args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c *jitteredLifetimeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if queryer, ok := c.Conn.(driver.QueryerContext); ok {
+		return queryer.QueryContext(ctx, query, args)
+	}
+
+	return nil, driver.ErrSkip
+}
+
+func (c *jitteredLifetimeConn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c *jitteredLifetimeConn) CheckNamedValue(value *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(value)
+	}
+
+	return driver.ErrSkip
+}
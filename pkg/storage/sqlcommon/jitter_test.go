@@ -0,0 +1,53 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeConn struct {
+	driver.Conn
+}
+
+type fakeConnector struct {
+	driver.Connector
+}
+
+func (f *fakeConnector) Connect(_ context.Context) (driver.Conn, error) {
+	return &fakeConn{}, nil
+}
+
+func TestJitteredLifetimeConnector(t *testing.T) {
+	const maxLifetime = time.Hour
+
+	connector := &jitteredLifetimeConnector{
+		Connector:   &fakeConnector{},
+		maxLifetime: maxLifetime,
+		jitter:      0.5,
+	}
+
+	for i := 0; i < 100; i++ {
+		start := time.Now()
+
+		conn, err := connector.Connect(context.Background())
+		require.NoError(t, err)
+
+		expiresAt := conn.(*jitteredLifetimeConn).expiresAt
+		require.False(t, expiresAt.Before(start.Add(maxLifetime/2)))
+		require.False(t, expiresAt.After(time.Now().Add(maxLifetime)))
+	}
+}
+
+func TestJitteredLifetimeConnIsInvalidOnceExpired(t *testing.T) {
+	conn := &jitteredLifetimeConn{Conn: &fakeConn{}, expiresAt: time.Now().Add(time.Hour)}
+	require.True(t, conn.IsValid())
+	require.NoError(t, conn.ResetSession(context.Background()))
+
+	conn.expiresAt = time.Now().Add(-time.Second)
+	require.False(t, conn.IsValid())
+	require.ErrorIs(t, conn.ResetSession(context.Background()), driver.ErrBadConn)
+}
@@ -0,0 +1,54 @@
+package sqlcommon
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/openfga/openfga/assets"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/pressly/goose/v3"
+)
+
+// MigrateSchema implements storage.SchemaMigrator.MigrateSchema with the embedded migrations in migrationsDir.
+func MigrateSchema(ctx context.Context, db *sql.DB, dialect, migrationsDir string, autoMigrate bool, logger logger.Logger) error {
+	goose.SetLogger(goose.NopLogger())
+	goose.SetBaseFS(assets.EmbedMigrations)
+
+	if err := goose.SetDialect(dialect); err != nil {
+		return err
+	}
+
+	currentVersion, err := goose.GetDBVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to get the version of the datastore schema: %w", err)
+	}
+
+	migrations, err := goose.CollectMigrations(migrationsDir, 0, goose.MaxVersion)
+	if err != nil {
+		return err
+	}
+
+	latest, err := migrations.Last()
+	if err != nil {
+		return err
+	}
+
+	if currentVersion >= latest.Version {
+		return nil
+	}
+
+	if !autoMigrate {
+		return fmt.Errorf("%w: the datastore schema is at version %d but this server requires version %d. Run 'openfga migrate', or set 'datastore.autoMigrate' to migrate it at startup",
+			storage.ErrMigrationNeeded, currentVersion, latest.Version)
+	}
+
+	logger.InfoWithContext(ctx, fmt.Sprintf("migrating the datastore schema from version %d to version %d", currentVersion, latest.Version))
+
+	if err := goose.Up(db, migrationsDir); err != nil {
+		return fmt.Errorf("failed to migrate the datastore schema: %w", err)
+	}
+
+	return nil
+}
@@ -11,15 +11,21 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/cenkalti/backoff/v4"
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/oklog/ulid/v2"
 	"github.com/openfga/openfga/pkg/logger"
 	"github.com/openfga/openfga/pkg/storage"
 	tupleUtils "github.com/openfga/openfga/pkg/tuple"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// DefaultConnectTimeout is the default maximum amount of time to wait for the database to accept connections.
+const DefaultConnectTimeout = time.Minute
+
 type Config struct {
 	Username               string
 	Password               string
@@ -31,6 +37,26 @@ type Config struct {
 	MaxIdleConns    int
 	ConnMaxIdleTime time.Duration
 	ConnMaxLifetime time.Duration
+
+	// ConnMaxLifetimeJitter is the maximum fraction (between 0 and 1) of ConnMaxLifetime by which the
+	// lifetime of each connection is randomly shortened.
+	ConnMaxLifetimeJitter float64
+
+	// StatementTimeout is the maximum amount of time the database lets a single query run before aborting it.
+	// It is enforced by the database itself, see the datastore implementations for how. Zero means no timeout.
+	StatementTimeout time.Duration
+
+	// ConnectTimeout is the maximum amount of time to wait for the database to accept connections when the
+	// datastore is created. Defaults to DefaultConnectTimeout.
+	ConnectTimeout time.Duration
+
+	// ConnectMaxAttempts is the maximum number of times the database is pinged when the datastore is created.
+	// Zero means the attempts are only bounded by ConnectTimeout.
+	ConnectMaxAttempts int
+
+	// ConnectInitialInterval is how long to wait after the first failed ping. The wait grows exponentially with
+	// each further attempt. Defaults to backoff.DefaultInitialInterval.
+	ConnectInitialInterval time.Duration
 }
 
 type DatastoreOption func(*Config)
@@ -89,6 +115,33 @@ func WithConnMaxLifetime(d time.Duration) DatastoreOption {
 	}
 }
 
+func WithConnMaxLifetimeJitter(jitter float64) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ConnMaxLifetimeJitter = jitter
+	}
+}
+
+func WithStatementTimeout(d time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.StatementTimeout = d
+	}
+}
+
+func WithConnectTimeout(d time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ConnectTimeout = d
+	}
+}
+
+// WithConnectRetry bounds the number of times the database is pinged when the datastore is created and sets the
+// wait after the first failed ping. A maxAttempts of zero keeps the attempts bounded by the connect timeout only.
+func WithConnectRetry(maxAttempts int, initialInterval time.Duration) DatastoreOption {
+	return func(cfg *Config) {
+		cfg.ConnectMaxAttempts = maxAttempts
+		cfg.ConnectInitialInterval = initialInterval
+	}
+}
+
 func NewConfig(opts ...DatastoreOption) *Config {
 	cfg := &Config{}
 
@@ -104,6 +157,14 @@ func NewConfig(opts ...DatastoreOption) *Config {
 		cfg.MaxTuplesPerWriteField = storage.DefaultMaxTuplesPerWrite
 	}
 
+	if cfg.ConnectTimeout == 0 {
+		cfg.ConnectTimeout = DefaultConnectTimeout
+	}
+
+	if cfg.ConnectInitialInterval == 0 {
+		cfg.ConnectInitialInterval = backoff.DefaultInitialInterval
+	}
+
 	if cfg.MaxTypesPerModelField == 0 {
 		cfg.MaxTypesPerModelField = storage.DefaultMaxTypesPerAuthorizationModel
 	}
@@ -111,6 +172,48 @@ func NewConfig(opts ...DatastoreOption) *Config {
 	return cfg
 }
 
+// PingDB waits for the database to accept connections, retrying with an exponential backoff. It gives up once
+// cfg.ConnectTimeout has elapsed or cfg.ConnectMaxAttempts pings have failed, so that a database that is down or
+// unreachable fails the startup instead of blocking it.
+func PingDB(db *sql.DB, engine string, cfg *Config) error {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ConnectTimeout)
+	defer cancel()
+
+	policy := backoff.NewExponentialBackOff()
+	policy.InitialInterval = cfg.ConnectInitialInterval
+	policy.MaxElapsedTime = 0 // bounded by ctx
+
+	var b backoff.BackOff = policy
+	if cfg.ConnectMaxAttempts > 0 {
+		b = backoff.WithMaxRetries(policy, uint64(cfg.ConnectMaxAttempts-1))
+	}
+
+	var pingErr error
+	attempts := 0
+	err := backoff.Retry(func() error {
+		attempts++
+		pingErr = db.PingContext(ctx)
+		if pingErr != nil {
+			cfg.Logger.Info(fmt.Sprintf("waiting for %s", engine), zap.Int("attempt", attempts), zap.Error(pingErr))
+			return pingErr
+		}
+		return nil
+	}, backoff.WithContext(b, ctx))
+	if err != nil {
+		if pingErr != nil {
+			err = pingErr
+		}
+
+		if ctx.Err() == nil {
+			return fmt.Errorf("%s did not accept connections after %d attempts: %w", engine, attempts, err)
+		}
+
+		return fmt.Errorf("%s did not accept connections within %s (%d attempts): %w", engine, cfg.ConnectTimeout, attempts, err)
+	}
+
+	return nil
+}
+
 type TupleRecord struct {
 	Store      string
 	ObjectType string
@@ -172,7 +275,7 @@ func NewSQLTupleIterator(rows *sql.Rows) *SQLTupleIterator {
 func (t *SQLTupleIterator) next() (*TupleRecord, error) {
 	if !t.rows.Next() {
 		if err := t.rows.Err(); err != nil {
-			return nil, err
+			return nil, HandleSQLError(err)
 		}
 		return nil, storage.ErrIteratorDone
 	}
@@ -237,7 +340,10 @@ func HandleSQLError(err error, args ...interface{}) error {
 		return storage.ErrNotFound
 	} else if errors.Is(err, storage.ErrIteratorDone) {
 		return err
-	} else if strings.Contains(err.Error(), "duplicate key value") { // Postgres
+	} else if isStatementTimeout(err) {
+		return fmt.Errorf("%w: %v", storage.ErrStatementTimeout, err)
+	} else if strings.Contains(err.Error(), "duplicate key value") || // Postgres
+		strings.Contains(err.Error(), "UNIQUE constraint failed") { // SQLite
 		if len(args) > 0 {
 			if tk, ok := args[0].(*openfgapb.TupleKey); ok {
 				return storage.InvalidWriteInputError(tk, openfgapb.TupleOperation_TUPLE_OPERATION_WRITE)
@@ -256,6 +362,24 @@ func HandleSQLError(err error, args ...interface{}) error {
 	return fmt.Errorf("sql error: %w", err)
 }
 
+// isStatementTimeout reports whether the database aborted the query because it exceeded the statement timeout, as
+// opposed to the query being cancelled because its context is done.
+func isStatementTimeout(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// query_canceled (57014) is also used when the client cancels the query
+		return pgErr.Code == "57014" && strings.Contains(pgErr.Message, "statement timeout")
+	}
+
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		// ER_QUERY_TIMEOUT, raised when max_execution_time is exceeded
+		return mysqlErr.Number == 3024
+	}
+
+	return false
+}
+
 // DBInfo encapsulates DB information for use in common method
 type DBInfo struct {
 	db      *sql.DB
@@ -289,6 +413,8 @@ func Write(ctx context.Context, dbInfo *DBInfo, store string, deletes storage.De
 
 	deleteBuilder := dbInfo.stbl.Delete("tuple")
 
+	expiresAt, expires := storage.TupleExpirationFromContext(ctx)
+
 	for _, tk := range deletes {
 		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
@@ -302,6 +428,7 @@ func Write(ctx context.Context, dbInfo *DBInfo, store string, deletes storage.De
 				"_user":       tk.GetUser(),
 				"user_type":   tupleUtils.GetUserTypeFromUser(tk.GetUser()),
 			}).
+			Where(NotExpired(now)).
 			RunWith(txn). // Part of a txn
 			ExecContext(ctx)
 		if err != nil {
@@ -322,14 +449,45 @@ func Write(ctx context.Context, dbInfo *DBInfo, store string, deletes storage.De
 
 	insertBuilder := dbInfo.stbl.
 		Insert("tuple").
-		Columns("store", "object_type", "object_id", "relation", "_user", "user_type", "ulid", "inserted_at")
+		Columns("store", "object_type", "object_id", "relation", "_user", "user_type", "ulid", "inserted_at", "expires_at")
 
 	for _, tk := range writes {
 		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
 		objectType, objectID := tupleUtils.SplitObject(tk.GetObject())
 
+		// an expired tuple is replaced by a write of the same tuple
+		res, err := deleteBuilder.
+			Where(sq.Eq{
+				"store":       store,
+				"object_type": objectType,
+				"object_id":   objectID,
+				"relation":    tk.GetRelation(),
+				"_user":       tk.GetUser(),
+			}).
+			Where(sq.LtOrEq{"expires_at": now}).
+			RunWith(txn). // Part of a txn
+			ExecContext(ctx)
+		if err != nil {
+			return HandleSQLError(err, tk)
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return HandleSQLError(err)
+		}
+
+		if rowsAffected == 1 {
+			deleteID := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+			changelogBuilder = changelogBuilder.Values(store, objectType, objectID, tk.GetRelation(), tk.GetUser(), openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, deleteID, dbInfo.sqlTime)
+		}
+
+		var tupleExpiresAt interface{}
+		if expires {
+			tupleExpiresAt = expiresAt
+		}
+
 		_, err = insertBuilder.
-			Values(store, objectType, objectID, tk.GetRelation(), tk.GetUser(), tupleUtils.GetUserTypeFromUser(tk.GetUser()), id, dbInfo.sqlTime).
+			Values(store, objectType, objectID, tk.GetRelation(), tk.GetUser(), tupleUtils.GetUserTypeFromUser(tk.GetUser()), id, dbInfo.sqlTime, tupleExpiresAt).
 			RunWith(txn). // Part of a txn
 			ExecContext(ctx)
 		if err != nil {
@@ -352,3 +510,100 @@ func Write(ctx context.Context, dbInfo *DBInfo, store string, deletes storage.De
 
 	return nil
 }
+
+// NotExpired returns the condition that excludes the tuples that have expired at the provided time.
+func NotExpired(now time.Time) sq.Sqlizer {
+	return sq.Or{sq.Eq{"expires_at": nil}, sq.Gt{"expires_at": now}}
+}
+
+// PurgeExpiredTuples provides the common method for purging expired tuples across sql storage. See
+// storage.TupleExpirationPurger.
+func PurgeExpiredTuples(ctx context.Context, dbInfo *DBInfo, before time.Time, limit int, now time.Time) (int, error) {
+	txn, err := dbInfo.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+	defer func() {
+		_ = txn.Rollback()
+	}()
+
+	rows, err := dbInfo.stbl.
+		Select("store", "object_type", "object_id", "relation", "_user").
+		From("tuple").
+		Where(sq.LtOrEq{"expires_at": before}).
+		Limit(uint64(limit)).
+		RunWith(txn). // Part of a txn
+		QueryContext(ctx)
+	if err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	var expired []TupleRecord
+	for rows.Next() {
+		var record TupleRecord
+		if err := rows.Scan(&record.Store, &record.ObjectType, &record.ObjectID, &record.Relation, &record.User); err != nil {
+			rows.Close()
+			return 0, HandleSQLError(err)
+		}
+		expired = append(expired, record)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	if len(expired) == 0 {
+		return 0, nil
+	}
+
+	changelogBuilder := dbInfo.stbl.
+		Insert("changelog").
+		Columns("store", "object_type", "object_id", "relation", "_user", "operation", "ulid", "inserted_at")
+
+	purged := 0
+	for _, record := range expired {
+		// the tuple may have been re-created by a Write, or purged by another replica, since it was selected
+		res, err := dbInfo.stbl.
+			Delete("tuple").
+			Where(sq.Eq{
+				"store":       record.Store,
+				"object_type": record.ObjectType,
+				"object_id":   record.ObjectID,
+				"relation":    record.Relation,
+				"_user":       record.User,
+			}).
+			Where(sq.LtOrEq{"expires_at": before}).
+			RunWith(txn). // Part of a txn
+			ExecContext(ctx)
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+
+		rowsAffected, err := res.RowsAffected()
+		if err != nil {
+			return 0, HandleSQLError(err)
+		}
+
+		if rowsAffected != 1 {
+			continue
+		}
+
+		id := ulid.MustNew(ulid.Timestamp(now), ulid.DefaultEntropy()).String()
+		changelogBuilder = changelogBuilder.Values(record.Store, record.ObjectType, record.ObjectID, record.Relation, record.User, openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, id, dbInfo.sqlTime)
+		purged++
+	}
+
+	if purged == 0 {
+		return 0, nil
+	}
+
+	if _, err := changelogBuilder.RunWith(txn).ExecContext(ctx); err != nil { // Part of a txn
+		return 0, HandleSQLError(err)
+	}
+
+	if err := txn.Commit(); err != nil {
+		return 0, HandleSQLError(err)
+	}
+
+	return purged, nil
+}
@@ -4,8 +4,10 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/openfga/openfga/pkg/storage"
 	"github.com/stretchr/testify/require"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
@@ -54,4 +56,50 @@ func TestHandleSQLError(t *testing.T) {
 		err := HandleSQLError(sql.ErrNoRows)
 		require.ErrorIs(t, err, storage.ErrNotFound)
 	})
+
+	t.Run("postgres_statement_timeout_wraps_ErrStatementTimeout", func(t *testing.T) {
+		err := HandleSQLError(&pgconn.PgError{Code: "57014", Message: "canceling statement due to statement timeout"})
+		require.ErrorIs(t, err, storage.ErrStatementTimeout)
+	})
+
+	t.Run("postgres_cancelled_query_does_not_wrap_ErrStatementTimeout", func(t *testing.T) {
+		err := HandleSQLError(&pgconn.PgError{Code: "57014", Message: "canceling statement due to user request"})
+		require.NotErrorIs(t, err, storage.ErrStatementTimeout)
+	})
+
+	t.Run("mysql_max_execution_time_wraps_ErrStatementTimeout", func(t *testing.T) {
+		err := HandleSQLError(&mysql.MySQLError{
+			Number:  3024,
+			Message: "Query execution was interrupted, maximum statement execution time exceeded",
+		})
+		require.ErrorIs(t, err, storage.ErrStatementTimeout)
+	})
+}
+
+func TestPingDBFailsAfterConnectTimeout(t *testing.T) {
+	// nothing listens on port 1, so the database never accepts connections
+	db, err := sql.Open("mysql", "root:secret@tcp(127.0.0.1:1)/openfga")
+	require.NoError(t, err)
+	defer db.Close()
+
+	cfg := NewConfig(WithConnectTimeout(200 * time.Millisecond))
+
+	start := time.Now()
+	err = PingDB(db, "mysql", cfg)
+	require.ErrorContains(t, err, "mysql did not accept connections within 200ms")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestPingDBGivesUpAfterMaxAttempts(t *testing.T) {
+	// nothing listens on port 1, so the database never accepts connections
+	db, err := sql.Open("mysql", "root:secret@tcp(127.0.0.1:1)/openfga")
+	require.NoError(t, err)
+	defer db.Close()
+
+	cfg := NewConfig(WithConnectRetry(3, time.Millisecond))
+
+	start := time.Now()
+	err = PingDB(db, "mysql", cfg)
+	require.ErrorContains(t, err, "mysql did not accept connections after 3 attempts")
+	require.Less(t, time.Since(start), 5*time.Second)
 }
@@ -0,0 +1,783 @@
+// Package sqlite contains an implementation of the storage interface that works with SQLite.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/openfga/openfga/assets"
+	"github.com/openfga/openfga/pkg/logger"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	tupleUtils "github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.opentelemetry.io/otel"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	_ "modernc.org/sqlite"
+)
+
+var tracer = otel.Tracer("openfga/pkg/storage/sqlite")
+
+// busyTimeout is how long a statement waits for the lock held by a concurrent write before failing, unless the
+// 'busy_timeout' pragma is set in the connection uri.
+const busyTimeout = 5 * time.Second
+
+type SQLite struct {
+	stbl                   sq.StatementBuilderType
+	db                     *sql.DB
+	logger                 logger.Logger
+	maxTuplesPerWriteField int
+	maxTypesPerModelField  int
+}
+
+var _ storage.OpenFGADatastore = (*SQLite)(nil)
+var _ storage.TupleExpirationPurger = (*SQLite)(nil)
+var _ storage.SchemaMigrator = (*SQLite)(nil)
+
+// New returns a datastore backed by the SQLite database file at uri, e.g. '/var/lib/openfga/openfga.db' or
+// 'file:openfga.db?_pragma=synchronous(NORMAL)'. The file is created if it doesn't exist. Unless set in the uri, the
+// database is opened in WAL mode, so that reads aren't blocked by writes, and with a busy timeout of 5 seconds. The
+// username, password and statement timeout of the config don't apply to SQLite, and are ignored.
+func New(uri string, cfg *sqlcommon.Config) (*SQLite, error) {
+	dsn, err := prepareDSN(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sqlite connection uri: %w", err)
+	}
+
+	db, err := sqlcommon.OpenDB("sqlite", dsn, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize sqlite connection: %w", err)
+	}
+
+	if cfg.MaxOpenConns != 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+
+	if cfg.MaxIdleConns != 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+
+	if cfg.ConnMaxIdleTime != 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	}
+
+	if cfg.ConnMaxLifetime != 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+
+	err = sqlcommon.PingDB(db, "sqlite", cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize sqlite connection: %w", err)
+	}
+
+	return &SQLite{
+		stbl:                   sq.StatementBuilder.RunWith(db),
+		db:                     db,
+		logger:                 cfg.Logger,
+		maxTuplesPerWriteField: cfg.MaxTuplesPerWriteField,
+		maxTypesPerModelField:  cfg.MaxTypesPerModelField,
+	}, nil
+}
+
+// prepareDSN adds the connection parameters the datastore relies on to uri. Times are written in a single format
+// that sorts like the times it represents, since SQLite compares them as text, and write transactions take the
+// write lock upfront, so that concurrent writes wait for each other instead of failing to upgrade their lock.
+func prepareDSN(uri string) (string, error) {
+	path, rawQuery, _ := strings.Cut(uri, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "", err
+	}
+
+	query.Set("_time_format", "sqlite")
+	query.Set("_txlock", "immediate")
+
+	var hasBusyTimeout, hasJournalMode bool
+	for _, pragma := range query["_pragma"] {
+		pragma = strings.ToLower(pragma)
+		hasBusyTimeout = hasBusyTimeout || strings.HasPrefix(pragma, "busy_timeout")
+		hasJournalMode = hasJournalMode || strings.HasPrefix(pragma, "journal_mode")
+	}
+
+	if !hasBusyTimeout {
+		query.Add("_pragma", fmt.Sprintf("busy_timeout(%d)", busyTimeout.Milliseconds()))
+	}
+
+	if !hasJournalMode {
+		query.Add("_pragma", "journal_mode(WAL)")
+	}
+
+	return path + "?" + query.Encode(), nil
+}
+
+// Close closes the datastore and cleans up any residual resources.
+func (s *SQLite) Close() {
+	s.db.Close()
+}
+
+func (s *SQLite) Read(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (storage.TupleIterator, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.Read")
+	defer span.End()
+
+	return s.read(ctx, store, tupleKey, nil)
+}
+
+func (s *SQLite) ReadPage(ctx context.Context, store string, tupleKey *openfgapb.TupleKey, opts storage.PaginationOptions) ([]*openfgapb.Tuple, []byte, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadPage")
+	defer span.End()
+
+	iter, err := s.read(ctx, store, tupleKey, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer iter.Stop()
+
+	return iter.ToArray(opts)
+}
+
+func (s *SQLite) read(ctx context.Context, store string, tupleKey *openfgapb.TupleKey, opts *storage.PaginationOptions) (*sqlcommon.SQLTupleIterator, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.read")
+	defer span.End()
+
+	sb := s.stbl.
+		Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
+	if opts != nil {
+		sb = sb.OrderBy("ulid")
+	}
+	objectType, objectID := tupleUtils.SplitObject(tupleKey.GetObject())
+	if objectType != "" {
+		sb = sb.Where(sq.Eq{"object_type": objectType})
+	}
+	if objectID != "" {
+		sb = sb.Where(sq.Eq{"object_id": objectID})
+	}
+	if tupleKey.GetRelation() != "" {
+		sb = sb.Where(sq.Eq{"relation": tupleKey.GetRelation()})
+	}
+	if tupleKey.GetUser() != "" {
+		sb = sb.Where(sq.Eq{"_user": tupleKey.GetUser()})
+	}
+	if opts != nil && opts.From != "" {
+		token, err := sqlcommon.UnmarshallContToken(opts.From)
+		if err != nil {
+			return nil, err
+		}
+		sb = sb.Where(sq.GtOrEq{"ulid": token.Ulid})
+	}
+	if opts != nil && opts.PageSize != 0 {
+		sb = sb.Limit(uint64(opts.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return sqlcommon.NewSQLTupleIterator(rows), nil
+}
+
+func (s *SQLite) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes) error {
+	ctx, span := tracer.Start(ctx, "sqlite.Write")
+	defer span.End()
+
+	if len(deletes)+len(writes) > s.MaxTuplesPerWrite() {
+		return storage.ErrExceededWriteBatchLimit
+	}
+
+	now := time.Now().UTC()
+
+	// SQLite has no clock of its own, so the insertion time is bound like any other value
+	return sqlcommon.Write(ctx, sqlcommon.NewDBInfo(s.db, s.stbl, now), store, deletes, writes, now)
+}
+
+// PurgeExpiredTuples See storage.TupleExpirationPurger.PurgeExpiredTuples
+func (s *SQLite) PurgeExpiredTuples(ctx context.Context, before time.Time, limit int) (int, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.PurgeExpiredTuples")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	return sqlcommon.PurgeExpiredTuples(ctx, sqlcommon.NewDBInfo(s.db, s.stbl, now), before.UTC(), limit, now)
+}
+
+// MigrateSchema See storage.SchemaMigrator.MigrateSchema
+func (s *SQLite) MigrateSchema(ctx context.Context, autoMigrate bool) error {
+	ctx, span := tracer.Start(ctx, "sqlite.MigrateSchema")
+	defer span.End()
+
+	return sqlcommon.MigrateSchema(ctx, s.db, "sqlite3", assets.SQLiteMigrationDir, autoMigrate, s.logger)
+}
+
+func (s *SQLite) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadUserTuple")
+	defer span.End()
+
+	objectType, objectID := tupleUtils.SplitObject(tupleKey.GetObject())
+	userType := tupleUtils.GetUserTypeFromUser(tupleKey.GetUser())
+
+	var record sqlcommon.TupleRecord
+	err := s.stbl.
+		Select("object_type", "object_id", "relation", "_user").
+		From("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": objectType,
+			"object_id":   objectID,
+			"relation":    tupleKey.GetRelation(),
+			"_user":       tupleKey.GetUser(),
+			"user_type":   userType,
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
+		QueryRowContext(ctx).
+		Scan(&record.ObjectType, &record.ObjectID, &record.Relation, &record.User)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return record.AsTuple(), nil
+}
+
+func (s *SQLite) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter) (storage.TupleIterator, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadUsersetTuples")
+	defer span.End()
+
+	sb := s.stbl.Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
+		From("tuple").
+		Where(sq.Eq{"store": store}).
+		Where(sq.Eq{"user_type": tupleUtils.UserSet}).
+		Where(sqlcommon.NotExpired(time.Now().UTC()))
+
+	objectType, objectID := tupleUtils.SplitObject(filter.Object)
+	if objectType != "" {
+		sb = sb.Where(sq.Eq{"object_type": objectType})
+	}
+	if objectID != "" {
+		sb = sb.Where(sq.Eq{"object_id": objectID})
+	}
+	if filter.Relation != "" {
+		sb = sb.Where(sq.Eq{"relation": filter.Relation})
+	}
+	if len(filter.AllowedUserTypeRestrictions) > 0 {
+		orConditions := sq.Or{}
+		for _, userset := range filter.AllowedUserTypeRestrictions {
+			if _, ok := userset.RelationOrWildcard.(*openfgapb.RelationReference_Relation); ok {
+				orConditions = append(orConditions, sq.Like{"_user": userset.Type + ":%#" + userset.GetRelation()})
+			}
+			if _, ok := userset.RelationOrWildcard.(*openfgapb.RelationReference_Wildcard); ok {
+				orConditions = append(orConditions, sq.Eq{"_user": userset.Type + ":*"})
+			}
+		}
+		sb = sb.Where(orConditions)
+	}
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return sqlcommon.NewSQLTupleIterator(rows), nil
+}
+
+func (s *SQLite) ReadStartingWithUser(ctx context.Context, store string, opts storage.ReadStartingWithUserFilter) (storage.TupleIterator, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadStartingWithUser")
+	defer span.End()
+
+	var targetUsersArg []string
+	for _, u := range opts.UserFilter {
+		targetUser := u.GetObject()
+		if u.GetRelation() != "" {
+			targetUser = strings.Join([]string{u.GetObject(), u.GetRelation()}, "#")
+		}
+		targetUsersArg = append(targetUsersArg, targetUser)
+	}
+
+	rows, err := s.stbl.
+		Select("store", "object_type", "object_id", "relation", "_user", "ulid", "inserted_at").
+		From("tuple").
+		Where(sq.Eq{
+			"store":       store,
+			"object_type": opts.ObjectType,
+			"relation":    opts.Relation,
+			"_user":       targetUsersArg,
+		}).
+		Where(sqlcommon.NotExpired(time.Now().UTC())).
+		QueryContext(ctx)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return sqlcommon.NewSQLTupleIterator(rows), nil
+}
+
+func (s *SQLite) MaxTuplesPerWrite() int {
+	return s.maxTuplesPerWriteField
+}
+
+func (s *SQLite) ReadAuthorizationModel(ctx context.Context, store string, modelID string) (*openfgapb.AuthorizationModel, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadAuthorizationModel")
+	defer span.End()
+
+	rows, err := s.stbl.
+		Select("schema_version", "type", "type_definition").
+		From("authorization_model").
+		Where(sq.Eq{
+			"store":                  store,
+			"authorization_model_id": modelID,
+		}).QueryContext(ctx)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var schemaVersion string
+	var typeDefs []*openfgapb.TypeDefinition
+	for rows.Next() {
+		var typeName string
+		var marshalledTypeDef []byte
+		err = rows.Scan(&schemaVersion, &typeName, &marshalledTypeDef)
+		if err != nil {
+			return nil, sqlcommon.HandleSQLError(err)
+		}
+
+		var typeDef openfgapb.TypeDefinition
+		if err := proto.Unmarshal(marshalledTypeDef, &typeDef); err != nil {
+			return nil, err
+		}
+
+		typeDefs = append(typeDefs, &typeDef)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	if len(typeDefs) == 0 {
+		return nil, storage.ErrNotFound
+	}
+
+	// Update the schema version lazily if it is not a valid typesystem.SchemaVersion.
+	if schemaVersion != typesystem.SchemaVersion1_0 && schemaVersion != typesystem.SchemaVersion1_1 {
+		schemaVersion = typesystem.SchemaVersion1_0
+		_, err = s.stbl.
+			Update("authorization_model").
+			Set("schema_version", schemaVersion).
+			Where(sq.Eq{"store": store, "authorization_model_id": modelID}).
+			ExecContext(ctx)
+		if err != nil {
+			// Don't worry if we error, we'll update it lazily next time, but let's log:
+			s.logger.Warn("failed to lazily update schema version", zap.String("store", store), zap.String("authorization_model_id", modelID))
+		}
+	}
+
+	return &openfgapb.AuthorizationModel{
+		SchemaVersion:   schemaVersion,
+		Id:              modelID,
+		TypeDefinitions: typeDefs,
+	}, nil
+}
+
+func (s *SQLite) ReadAuthorizationModels(ctx context.Context, store string, opts storage.PaginationOptions) ([]*openfgapb.AuthorizationModel, []byte, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadAuthorizationModels")
+	defer span.End()
+
+	sb := s.stbl.Select("authorization_model_id").
+		Distinct().
+		From("authorization_model").
+		Where(sq.Eq{"store": store}).
+		OrderBy("authorization_model_id desc")
+
+	if opts.From != "" {
+		token, err := sqlcommon.UnmarshallContToken(opts.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		sb = sb.Where(sq.LtOrEq{"authorization_model_id": token.Ulid})
+	}
+	if opts.PageSize > 0 {
+		sb = sb.Limit(uint64(opts.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, nil, sqlcommon.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var modelIDs []string
+	var modelID string
+
+	for rows.Next() {
+		err = rows.Scan(&modelID)
+		if err != nil {
+			return nil, nil, sqlcommon.HandleSQLError(err)
+		}
+
+		modelIDs = append(modelIDs, modelID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, sqlcommon.HandleSQLError(err)
+	}
+
+	var token []byte
+	numModelIDs := len(modelIDs)
+	if len(modelIDs) > opts.PageSize {
+		numModelIDs = opts.PageSize
+		token, err = json.Marshal(sqlcommon.NewContToken(modelID, ""))
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	models := make([]*openfgapb.AuthorizationModel, 0, numModelIDs)
+	// We use numModelIDs here to avoid retrieving possibly one extra model.
+	for i := 0; i < numModelIDs; i++ {
+		model, err := s.ReadAuthorizationModel(ctx, store, modelIDs[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		models = append(models, model)
+	}
+
+	return models, token, nil
+}
+
+func (s *SQLite) FindLatestAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.FindLatestAuthorizationModelID")
+	defer span.End()
+
+	var modelID string
+	err := s.stbl.
+		Select("authorization_model_id").
+		From("authorization_model").
+		Where(sq.Eq{"store": store}).
+		OrderBy("authorization_model_id desc").
+		Limit(1).
+		QueryRowContext(ctx).
+		Scan(&modelID)
+	if err != nil {
+		return "", sqlcommon.HandleSQLError(err)
+	}
+
+	return modelID, nil
+}
+
+func (s *SQLite) MaxTypesPerAuthorizationModel() int {
+	return s.maxTypesPerModelField
+}
+
+func (s *SQLite) WriteAuthorizationModel(ctx context.Context, store string, model *openfgapb.AuthorizationModel) error {
+	ctx, span := tracer.Start(ctx, "sqlite.WriteAuthorizationModel")
+	defer span.End()
+
+	schemaVersion := model.GetSchemaVersion()
+	typeDefinitions := model.GetTypeDefinitions()
+
+	if len(typeDefinitions) > s.MaxTypesPerAuthorizationModel() {
+		return storage.ExceededMaxTypeDefinitionsLimitError(s.maxTypesPerModelField)
+	}
+
+	if len(typeDefinitions) < 1 {
+		return nil
+	}
+
+	sb := s.stbl.
+		Insert("authorization_model").
+		Columns("store", "authorization_model_id", "schema_version", "type", "type_definition")
+
+	for _, td := range typeDefinitions {
+		marshalledTypeDef, err := proto.Marshal(td)
+		if err != nil {
+			return err
+		}
+
+		sb = sb.Values(store, model.Id, schemaVersion, td.GetType(), marshalledTypeDef)
+	}
+
+	_, err := sb.ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) CreateStore(ctx context.Context, store *openfgapb.Store) (*openfgapb.Store, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.CreateStore")
+	defer span.End()
+
+	now := time.Now().UTC()
+
+	_, err := s.stbl.
+		Insert("store").
+		Columns("id", "name", "created_at", "updated_at").
+		Values(store.Id, store.Name, now, now).
+		ExecContext(ctx)
+	if err != nil {
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return &openfgapb.Store{
+		Id:        store.Id,
+		Name:      store.Name,
+		CreatedAt: timestamppb.New(now),
+		UpdatedAt: timestamppb.New(now),
+	}, nil
+}
+
+func (s *SQLite) GetStore(ctx context.Context, id string) (*openfgapb.Store, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.GetStore")
+	defer span.End()
+
+	row := s.stbl.
+		Select("id", "name", "created_at", "updated_at").
+		From("store").
+		Where(sq.Eq{
+			"id":         id,
+			"deleted_at": nil,
+		}).
+		QueryRowContext(ctx)
+
+	var storeID, name string
+	var createdAt, updatedAt time.Time
+	err := row.Scan(&storeID, &name, &createdAt, &updatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, storage.ErrNotFound
+		}
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	return &openfgapb.Store{
+		Id:        storeID,
+		Name:      name,
+		CreatedAt: timestamppb.New(createdAt),
+		UpdatedAt: timestamppb.New(updatedAt),
+	}, nil
+}
+
+func (s *SQLite) ListStores(ctx context.Context, opts storage.PaginationOptions) ([]*openfgapb.Store, []byte, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ListStores")
+	defer span.End()
+
+	sb := s.stbl.Select("id", "name", "created_at", "updated_at").
+		From("store").
+		Where(sq.Eq{"deleted_at": nil}).
+		OrderBy("id")
+
+	if opts.From != "" {
+		token, err := sqlcommon.UnmarshallContToken(opts.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		sb = sb.Where(sq.GtOrEq{"id": token.Ulid})
+	}
+	if opts.PageSize > 0 {
+		sb = sb.Limit(uint64(opts.PageSize + 1)) // + 1 is used to determine whether to return a continuation token.
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, nil, sqlcommon.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var stores []*openfgapb.Store
+	var id string
+	for rows.Next() {
+		var name string
+		var createdAt, updatedAt time.Time
+		err := rows.Scan(&id, &name, &createdAt, &updatedAt)
+		if err != nil {
+			return nil, nil, sqlcommon.HandleSQLError(err)
+		}
+
+		stores = append(stores, &openfgapb.Store{
+			Id:        id,
+			Name:      name,
+			CreatedAt: timestamppb.New(createdAt),
+			UpdatedAt: timestamppb.New(updatedAt),
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, sqlcommon.HandleSQLError(err)
+	}
+
+	if len(stores) > opts.PageSize {
+		contToken, err := json.Marshal(sqlcommon.NewContToken(id, ""))
+		if err != nil {
+			return nil, nil, err
+		}
+		return stores[:opts.PageSize], contToken, nil
+	}
+
+	return stores, nil, nil
+}
+
+func (s *SQLite) DeleteStore(ctx context.Context, id string) error {
+	ctx, span := tracer.Start(ctx, "sqlite.DeleteStore")
+	defer span.End()
+
+	_, err := s.stbl.
+		Update("store").
+		Set("deleted_at", time.Now().UTC()).
+		Where(sq.Eq{"id": id}).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgapb.Assertion) error {
+	ctx, span := tracer.Start(ctx, "sqlite.WriteAssertions")
+	defer span.End()
+
+	marshalledAssertions, err := proto.Marshal(&openfgapb.Assertions{Assertions: assertions})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.stbl.
+		Insert("assertion").
+		Columns("store", "authorization_model_id", "assertions").
+		Values(store, modelID, marshalledAssertions).
+		Suffix("ON CONFLICT(store, authorization_model_id) DO UPDATE SET assertions = ?", marshalledAssertions).
+		ExecContext(ctx)
+	if err != nil {
+		return sqlcommon.HandleSQLError(err)
+	}
+
+	return nil
+}
+
+func (s *SQLite) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgapb.Assertion, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadAssertions")
+	defer span.End()
+
+	var marshalledAssertions []byte
+	err := s.stbl.
+		Select("assertions").
+		From("assertion").
+		Where(sq.Eq{
+			"store":                  store,
+			"authorization_model_id": modelID,
+		}).
+		QueryRowContext(ctx).
+		Scan(&marshalledAssertions)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []*openfgapb.Assertion{}, nil
+		}
+		return nil, sqlcommon.HandleSQLError(err)
+	}
+
+	var assertions openfgapb.Assertions
+	err = proto.Unmarshal(marshalledAssertions, &assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	return assertions.Assertions, nil
+}
+
+func (s *SQLite) ReadChanges(
+	ctx context.Context,
+	store, objectTypeFilter string,
+	opts storage.PaginationOptions,
+	horizonOffset time.Duration,
+) ([]*openfgapb.TupleChange, []byte, error) {
+	ctx, span := tracer.Start(ctx, "sqlite.ReadChanges")
+	defer span.End()
+
+	sb := s.stbl.Select("ulid", "object_type", "object_id", "relation", "_user", "operation", "inserted_at").
+		From("changelog").
+		Where(sq.Eq{"store": store}).
+		Where(sq.LtOrEq{"inserted_at": time.Now().UTC().Add(-horizonOffset)}).
+		OrderBy("inserted_at asc")
+
+	if objectTypeFilter != "" {
+		sb = sb.Where(sq.Eq{"object_type": objectTypeFilter})
+	}
+	if opts.From != "" {
+		token, err := sqlcommon.UnmarshallContToken(opts.From)
+		if err != nil {
+			return nil, nil, err
+		}
+		if token.ObjectType != objectTypeFilter {
+			return nil, nil, storage.ErrMismatchObjectType
+		}
+
+		sb = sb.Where(sq.Gt{"ulid": token.Ulid}) // > as we always return a continuation token
+	}
+	if opts.PageSize > 0 {
+		sb = sb.Limit(uint64(opts.PageSize)) // + 1 is NOT used here as we always return a continuation token
+	}
+
+	rows, err := sb.QueryContext(ctx)
+	if err != nil {
+		return nil, nil, sqlcommon.HandleSQLError(err)
+	}
+	defer rows.Close()
+
+	var changes []*openfgapb.TupleChange
+	var ulid string
+	for rows.Next() {
+		var objectType, objectID, relation, user string
+		var operation int
+		var insertedAt time.Time
+
+		err = rows.Scan(&ulid, &objectType, &objectID, &relation, &user, &operation, &insertedAt)
+		if err != nil {
+			return nil, nil, sqlcommon.HandleSQLError(err)
+		}
+
+		changes = append(changes, &openfgapb.TupleChange{
+			TupleKey: &openfgapb.TupleKey{
+				Object:   tupleUtils.BuildObject(objectType, objectID),
+				Relation: relation,
+				User:     user,
+			},
+			Operation: openfgapb.TupleOperation(operation),
+			Timestamp: timestamppb.New(insertedAt.UTC()),
+		})
+	}
+
+	if len(changes) == 0 {
+		return nil, nil, storage.ErrNotFound
+	}
+
+	contToken, err := json.Marshal(sqlcommon.NewContToken(ulid, objectTypeFilter))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return changes, contToken, nil
+}
+
+// IsReady reports whether this SQLite datastore instance is ready
+// to accept connections.
+func (s *SQLite) IsReady(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/sqlcommon"
+	"github.com/openfga/openfga/pkg/storage/test"
+	storagefixtures "github.com/openfga/openfga/pkg/testfixtures/storage"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/require"
+	openfgav1 "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+func TestSQLiteDatastore(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+	test.RunAllTests(t, ds)
+}
+
+// TestReadPageEnsureOrder asserts that the read page is ordered by ulid
+func TestReadPageEnsureOrder(t *testing.T) {
+	testDatastore := storagefixtures.RunDatastoreTestContainer(t, "sqlite")
+
+	uri := testDatastore.GetConnectionURI(true)
+	ds, err := New(uri, sqlcommon.NewConfig())
+	require.NoError(t, err)
+	defer ds.Close()
+
+	ctx := context.Background()
+
+	store := "store"
+	firstTuple := tuple.NewTupleKey("doc:object_id_1", "relation", "user:user_1")
+	secondTuple := tuple.NewTupleKey("doc:object_id_2", "relation", "user:user_2")
+
+	now := time.Now().UTC()
+	err = sqlcommon.Write(ctx,
+		sqlcommon.NewDBInfo(ds.db, ds.stbl, now),
+		store,
+		[]*openfgav1.TupleKey{},
+		[]*openfgav1.TupleKey{firstTuple},
+		now)
+	require.NoError(t, err)
+
+	// tweak time so that ULID is smaller
+	err = sqlcommon.Write(ctx,
+		sqlcommon.NewDBInfo(ds.db, ds.stbl, now),
+		store,
+		[]*openfgav1.TupleKey{},
+		[]*openfgav1.TupleKey{secondTuple},
+		now.Add(time.Minute*-1))
+	require.NoError(t, err)
+
+	tuples, _, err := ds.ReadPage(ctx,
+		store,
+		tuple.NewTupleKey("doc:", "relation", ""),
+		storage.NewPaginationOptions(0, ""))
+	require.NoError(t, err)
+
+	require.Equal(t, 2, len(tuples))
+	// we expect that objectID2 will return first because it has a smaller ulid
+	require.Equal(t, secondTuple, tuples[0].Key)
+	require.Equal(t, firstTuple, tuples[1].Key)
+}
+
+func TestPrepareDSN(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		dsn, err := prepareDSN("/var/lib/openfga/openfga.db")
+		require.NoError(t, err)
+
+		path, query := parseDSN(t, dsn)
+		require.Equal(t, "/var/lib/openfga/openfga.db", path)
+		require.Equal(t, "sqlite", query.Get("_time_format"))
+		require.Equal(t, "immediate", query.Get("_txlock"))
+		require.ElementsMatch(t, []string{"busy_timeout(5000)", "journal_mode(WAL)"}, query["_pragma"])
+	})
+
+	t.Run("pragmas_of_the_uri_are_kept", func(t *testing.T) {
+		dsn, err := prepareDSN("file:openfga.db?_pragma=journal_mode(DELETE)&_pragma=synchronous(NORMAL)")
+		require.NoError(t, err)
+
+		path, query := parseDSN(t, dsn)
+		require.Equal(t, "file:openfga.db", path)
+		require.ElementsMatch(t, []string{"journal_mode(DELETE)", "synchronous(NORMAL)", "busy_timeout(5000)"}, query["_pragma"])
+	})
+}
+
+func parseDSN(t *testing.T, dsn string) (string, url.Values) {
+	path, rawQuery, _ := strings.Cut(dsn, "?")
+	query, err := url.ParseQuery(rawQuery)
+	require.NoError(t, err)
+
+	return path, query
+}
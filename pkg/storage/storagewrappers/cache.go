@@ -0,0 +1,69 @@
+package storagewrappers
+
+import (
+	"time"
+
+	"github.com/karlseguin/ccache/v3"
+)
+
+// Cache is a key-value cache used by the caching datastore wrapper. Implementations must be safe
+// for concurrent use. Custom implementations (e.g. backed by Redis or memcached) can be provided
+// to NewCachedOpenFGADatastore with WithModelCache.
+type Cache[T any] interface {
+	// Get returns the value stored under key, and whether it was found and has not expired.
+	Get(key string) (T, bool)
+
+	// Set stores the value under key for the duration of ttl.
+	Set(key string, value T, ttl time.Duration)
+
+	// Delete removes the value stored under key, if any.
+	Delete(key string)
+
+	// Stop releases the resources held by the cache.
+	Stop()
+}
+
+type inMemoryLRUCache[T any] struct {
+	ccache *ccache.Cache[T]
+}
+
+var _ Cache[any] = (*inMemoryLRUCache[any])(nil)
+
+// NewInMemoryLRUCache returns a Cache backed by an in-memory LRU cache that holds up to maxSize entries.
+func NewInMemoryLRUCache[T any](maxSize int64) *inMemoryLRUCache[T] {
+	return &inMemoryLRUCache[T]{
+		ccache: ccache.New(ccache.Configure[T]().MaxSize(maxSize)),
+	}
+}
+
+func (i *inMemoryLRUCache[T]) Get(key string) (T, bool) {
+	item := i.ccache.Get(key)
+	if item == nil || item.Expired() {
+		var zero T
+		return zero, false
+	}
+
+	return item.Value(), true
+}
+
+func (i *inMemoryLRUCache[T]) Set(key string, value T, ttl time.Duration) {
+	i.ccache.Set(key, value, ttl)
+}
+
+func (i *inMemoryLRUCache[T]) Delete(key string) {
+	i.ccache.Delete(key)
+}
+
+// ItemCount returns the number of entries in the cache.
+func (i *inMemoryLRUCache[T]) ItemCount() int {
+	return i.ccache.ItemCount()
+}
+
+// Evictions returns the number of entries evicted to make room for newer ones since the previous call.
+func (i *inMemoryLRUCache[T]) Evictions() int {
+	return i.ccache.GetDropped()
+}
+
+func (i *inMemoryLRUCache[T]) Stop() {
+	i.ccache.Stop()
+}
@@ -3,54 +3,388 @@ package storagewrappers
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"io"
+	"net"
+	"sync"
+	"syscall"
 	"time"
 
-	"github.com/karlseguin/ccache/v3"
 	"github.com/openfga/openfga/pkg/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/singleflight"
 )
 
-const ttl = time.Hour * 168
+const (
+	defaultModelCacheTTL = time.Hour * 168
+
+	// noExpiryTTL is the ttl of the models cached with a model cache ttl of 0, long enough to never expire
+	noExpiryTTL = 100 * 365 * 24 * time.Hour
+
+	defaultModelReadRetries      = 2
+	defaultModelReadRetryBackoff = 20 * time.Millisecond
+
+	// modelReadTimeout bounds a read of the datastore shared by concurrent callers, or refreshing the cache in the
+	// background, which isn't bound by the context of any caller
+	modelReadTimeout = 10 * time.Second
+)
 
 var _ storage.OpenFGADatastore = (*cachedOpenFGADatastore)(nil)
 
+var (
+	modelCacheHitCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "model_cache_hit_count",
+		Help: "Number of ReadAuthorizationModel calls served from the authorization model cache. The store_id label is only set if enabled in the server config",
+	}, []string{"store_id"})
+
+	modelCacheMissCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "model_cache_miss_count",
+		Help: "Number of ReadAuthorizationModel calls that didn't find the model in the authorization model cache. The store_id label is only set if enabled in the server config",
+	}, []string{"store_id"})
+
+	modelCacheItemsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "model_cache_items",
+		Help: "Number of authorization models in the authorization model cache, as of the last sample",
+	})
+
+	modelCacheMaxSizeGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "model_cache_max_size",
+		Help: "Maximum number of authorization models in the authorization model cache before the least recently used ones are evicted",
+	})
+
+	modelCacheEvictionCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "model_cache_eviction_count",
+		Help: "Number of authorization models evicted from the authorization model cache to make room for newer ones, counted every sample",
+	})
+)
+
 type cachedOpenFGADatastore struct {
 	storage.OpenFGADatastore
 	lookupGroup singleflight.Group
-	cache       *ccache.Cache[*openfgapb.AuthorizationModel]
+	cache       Cache[*openfgapb.AuthorizationModel]
+	cacheTTL    time.Duration
+
+	// maxSize is 0 if the cache was provided with WithModelCache
+	maxSize int
+
+	latestModelIDTTL   time.Duration
+	latestModelIDCache Cache[*latestModelIDEntry]
+
+	// latestModelIDGenerations counts the models written to each store through the wrapper, so that a lookup of
+	// the latest model ID that was in flight during a write doesn't cache the ID it read before the write
+	latestModelIDMu          sync.Mutex
+	latestModelIDGenerations map[string]uint64
+
+	modelReadRetries      int
+	modelReadRetryBackoff time.Duration
+
+	metricsByStore bool
+}
+
+type latestModelIDEntry struct {
+	modelID   string
+	fetchedAt time.Time
+}
+
+type CachedOpenFGADatastoreOption func(*cachedOpenFGADatastore)
+
+// WithModelCache sets the Cache used to store authorization models. It overrides the default
+// in-memory LRU cache, so maxSize is ignored when this option is provided.
+func WithModelCache(cache Cache[*openfgapb.AuthorizationModel]) CachedOpenFGADatastoreOption {
+	return func(c *cachedOpenFGADatastore) {
+		c.cache = cache
+	}
+}
+
+// WithModelCacheTTL sets how long an authorization model stays cached after it was read. Models are immutable, so
+// this only matters when stores are recreated with the same IDs, e.g. in tests. It defaults to 168 hours. A ttl of
+// 0 means the models never expire, and are only evicted when the cache is full.
+func WithModelCacheTTL(ttl time.Duration) CachedOpenFGADatastoreOption {
+	return func(c *cachedOpenFGADatastore) {
+		c.cacheTTL = ttl
+	}
+}
+
+// WithLatestModelIDTTL enables caching the result of FindLatestAuthorizationModelID for each store.
+// An entry is fresh for ttl after it was fetched. During the following ttl the stale entry is still
+// served, while a single background lookup refreshes it, so callers never block on the datastore at
+// the moment the entry expires. This means the latest model ID returned can be up to 2*ttl old. Once
+// an entry is older than that, callers block until it is refreshed. The entry of a store is invalidated when a
+// model is written to the store through the wrapper, so that the new model is visible right away, but models
+// written through other instances of the server are only seen once the entry is refreshed. A ttl of 0 disables
+// the cache, which is the default.
+func WithLatestModelIDTTL(ttl time.Duration) CachedOpenFGADatastoreOption {
+	return func(c *cachedOpenFGADatastore) {
+		c.latestModelIDTTL = ttl
+	}
+}
+
+// WithModelReadRetry sets how many times a failed read of an authorization model that isn't cached yet is retried
+// before the error is returned, and the backoff before the first retry, which doubles on every further retry.
+// Concurrent reads of the same model share a single read and its retries, so a flaky datastore doesn't cause a
+// storm of independent reads. Only the errors of a broken or refused connection to the datastore are retried, others,
+// such as storage.ErrNotFound, are returned right away. A value of 0 retries disables retrying.
+func WithModelReadRetry(retries int, backoff time.Duration) CachedOpenFGADatastoreOption {
+	return func(c *cachedOpenFGADatastore) {
+		c.modelReadRetries = retries
+		c.modelReadRetryBackoff = backoff
+	}
+}
+
+// WithModelCacheMetricsByStore partitions the 'model_cache_hit_count' and 'model_cache_miss_count' metrics by
+// store. It is disabled by default because the cardinality of the metrics grows with the number of stores.
+func WithModelCacheMetricsByStore(enabled bool) CachedOpenFGADatastoreOption {
+	return func(c *cachedOpenFGADatastore) {
+		c.metricsByStore = enabled
+	}
 }
 
 // NewCachedOpenFGADatastore returns a wrapper over a datastore that caches up to maxSize *openfgapb.AuthorizationModel
-// on every call to storage.ReadAuthorizationModel.
-func NewCachedOpenFGADatastore(inner storage.OpenFGADatastore, maxSize int) *cachedOpenFGADatastore {
-	return &cachedOpenFGADatastore{
-		OpenFGADatastore: inner,
-		cache:            ccache.New(ccache.Configure[*openfgapb.AuthorizationModel]().MaxSize(int64(maxSize))),
+// on every call to storage.ReadAuthorizationModel. The hits and misses of the cache are counted by the
+// 'model_cache_hit_count' and 'model_cache_miss_count' metrics. Reads made with a context returned by
+// storage.ContextWithModelCacheBypass skip the caches, don't update them, and aren't counted as hits or misses.
+func NewCachedOpenFGADatastore(inner storage.OpenFGADatastore, maxSize int, opts ...CachedOpenFGADatastoreOption) *cachedOpenFGADatastore {
+	c := &cachedOpenFGADatastore{
+		OpenFGADatastore:      inner,
+		cacheTTL:              defaultModelCacheTTL,
+		modelReadRetries:      defaultModelReadRetries,
+		modelReadRetryBackoff: defaultModelReadRetryBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.cache == nil {
+		c.cache = NewInMemoryLRUCache[*openfgapb.AuthorizationModel](int64(maxSize))
+		c.maxSize = maxSize
+	}
+
+	if c.cacheTTL == 0 {
+		c.cacheTTL = noExpiryTTL
+	}
+
+	if c.latestModelIDTTL > 0 {
+		c.latestModelIDCache = NewInMemoryLRUCache[*latestModelIDEntry](int64(maxSize))
+		c.latestModelIDGenerations = map[string]uint64{}
+	}
+
+	return c
+}
+
+// ModelCacheItemCount returns the number of authorization models in the cache. It returns false if the cache can't
+// report it, which is the case for the caches provided with WithModelCache that don't have an ItemCount() int method.
+func (c *cachedOpenFGADatastore) ModelCacheItemCount() (int, bool) {
+	counter, ok := c.cache.(interface{ ItemCount() int })
+	if !ok {
+		return 0, false
+	}
+
+	return counter.ItemCount(), true
+}
+
+// ReportModelCacheMetrics samples the number of authorization models in the cache, and the number of models evicted
+// from it, every interval until ctx is done. They are reported by the 'model_cache_items' gauge and the
+// 'model_cache_eviction_count' counter, alongside the 'model_cache_max_size' gauge. Caches provided with
+// WithModelCache are only sampled if they have ItemCount() int and Evictions() int methods, and the max size of
+// the cache isn't reported for them.
+func (c *cachedOpenFGADatastore) ReportModelCacheMetrics(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.sampleModelCacheMetrics()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *cachedOpenFGADatastore) sampleModelCacheMetrics() {
+	if c.maxSize > 0 {
+		modelCacheMaxSizeGauge.Set(float64(c.maxSize))
+	}
+
+	if items, ok := c.ModelCacheItemCount(); ok {
+		modelCacheItemsGauge.Set(float64(items))
+	}
+
+	if evictions, ok := c.cache.(interface{ Evictions() int }); ok {
+		modelCacheEvictionCounter.Add(float64(evictions.Evictions()))
 	}
 }
 
 func (c *cachedOpenFGADatastore) ReadAuthorizationModel(ctx context.Context, storeID, modelID string) (*openfgapb.AuthorizationModel, error) {
+	if storage.ModelCacheBypassFromContext(ctx) {
+		return c.readAuthorizationModelWithRetry(ctx, storeID, modelID)
+	}
+
 	cacheKey := fmt.Sprintf("%s:%s", storeID, modelID)
-	cachedEntry := c.cache.Get(cacheKey)
+	if cachedModel, ok := c.cache.Get(cacheKey); ok {
+		modelCacheHitCounter.WithLabelValues(c.storeLabel(storeID)).Inc()
+		return cachedModel, nil
+	}
+
+	modelCacheMissCounter.WithLabelValues(c.storeLabel(storeID)).Inc()
 
-	if cachedEntry != nil {
-		return cachedEntry.Value(), nil
+	lookupKey := modelLookupKey(cacheKey)
+	if storage.ReadFromPrimaryFromContext(ctx) {
+		// don't share a read that may be served by a replica that hasn't seen the model yet
+		lookupKey += ":primary"
 	}
 
-	model, err := c.OpenFGADatastore.ReadAuthorizationModel(ctx, storeID, modelID)
-	if err != nil {
-		return nil, err
+	// the read is shared by the concurrent callers, so it must not be cancelled when the caller that started it goes
+	// away. Each caller still stops waiting for it when its own context is done.
+	resultCh := c.lookupGroup.DoChan(lookupKey, func() (interface{}, error) {
+		readCtx, cancel := context.WithTimeout(detachedContext(ctx), modelReadTimeout)
+		defer cancel()
+
+		model, err := c.readAuthorizationModelWithRetry(readCtx, storeID, modelID)
+		if err != nil {
+			return nil, err
+		}
+
+		c.cache.Set(cacheKey, model, c.cacheTTL)
+
+		return model, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-resultCh:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return result.Val.(*openfgapb.AuthorizationModel), nil
+	}
+}
+
+// detachedContext returns a context that isn't cancelled with ctx, but keeps its span and whether the reads must be
+// sent to the primary.
+func detachedContext(ctx context.Context) context.Context {
+	detached := trace.ContextWithSpan(context.Background(), trace.SpanFromContext(ctx))
+	if storage.ReadFromPrimaryFromContext(ctx) {
+		detached = storage.ContextWithReadFromPrimary(detached)
+	}
+
+	return detached
+}
+
+// storeLabel returns the value of the store_id label of the model cache metrics, which is empty unless the metrics
+// are partitioned by store.
+func (c *cachedOpenFGADatastore) storeLabel(storeID string) string {
+	if !c.metricsByStore {
+		return ""
+	}
+
+	return storeID
+}
+
+func (c *cachedOpenFGADatastore) readAuthorizationModelWithRetry(ctx context.Context, storeID, modelID string) (*openfgapb.AuthorizationModel, error) {
+	backoff := c.modelReadRetryBackoff
+	for retry := 0; ; retry++ {
+		model, err := c.OpenFGADatastore.ReadAuthorizationModel(ctx, storeID, modelID)
+		if err == nil {
+			return model, nil
+		}
+
+		if retry >= c.modelReadRetries || !isTransientError(err) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// isTransientError reports whether a failed datastore read may succeed if it is retried, which is only the case for
+// the errors of a broken or refused connection to the datastore.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
 	}
 
-	c.cache.Set(cacheKey, model, ttl) // these are immutable, once created, there cannot be edits, therefore they can be cached without ttl
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.As(err, &netErr)
+}
 
-	return model, nil
+func modelLookupKey(cacheKey string) string {
+	return fmt.Sprintf("ReadAuthorizationModel:%s", cacheKey)
 }
 
 func (c *cachedOpenFGADatastore) FindLatestAuthorizationModelID(ctx context.Context, storeID string) (string, error) {
-	v, err, _ := c.lookupGroup.Do(fmt.Sprintf("FindLatestAuthorizationModelID:%s", storeID), func() (interface{}, error) {
+	if storage.ModelCacheBypassFromContext(ctx) {
+		return c.OpenFGADatastore.FindLatestAuthorizationModelID(ctx, storeID)
+	}
+
+	if c.latestModelIDCache == nil {
+		return c.findLatestAuthorizationModelID(ctx, storeID)
+	}
+
+	if entry, ok := c.latestModelIDCache.Get(storeID); ok {
+		if time.Since(entry.fetchedAt) >= c.latestModelIDTTL {
+			// serve the stale entry and refresh it in the background. singleflight ensures that
+			// only one refresh per store is in flight at any time.
+			c.lookupGroup.DoChan(latestModelIDLookupKey(storeID), func() (interface{}, error) {
+				refreshCtx, cancel := context.WithTimeout(context.Background(), modelReadTimeout)
+				defer cancel()
+
+				return c.refreshLatestAuthorizationModelID(refreshCtx, storeID)
+			})
+		}
+
+		return entry.modelID, nil
+	}
+
+	v, err, _ := c.lookupGroup.Do(latestModelIDLookupKey(storeID), func() (interface{}, error) {
+		return c.refreshLatestAuthorizationModelID(ctx, storeID)
+	})
+	if err != nil {
+		return "", err
+	}
+	return v.(string), nil
+}
+
+// WriteAuthorizationModel writes the model and invalidates the cached latest model ID of the store.
+func (c *cachedOpenFGADatastore) WriteAuthorizationModel(ctx context.Context, storeID string, model *openfgapb.AuthorizationModel) error {
+	if err := c.OpenFGADatastore.WriteAuthorizationModel(ctx, storeID, model); err != nil {
+		return err
+	}
+
+	if c.latestModelIDCache != nil {
+		// forget any lookup in flight too, as it may have read the latest model ID before the write. Forgetting it
+		// doesn't stop it, so the generation is bumped to keep it from caching what it read.
+		c.latestModelIDMu.Lock()
+		c.latestModelIDGenerations[storeID]++
+		c.lookupGroup.Forget(latestModelIDLookupKey(storeID))
+		c.latestModelIDCache.Delete(storeID)
+		c.latestModelIDMu.Unlock()
+	}
+
+	return nil
+}
+
+func (c *cachedOpenFGADatastore) findLatestAuthorizationModelID(ctx context.Context, storeID string) (string, error) {
+	v, err, _ := c.lookupGroup.Do(latestModelIDLookupKey(storeID), func() (interface{}, error) {
 		return c.OpenFGADatastore.FindLatestAuthorizationModelID(ctx, storeID)
 	})
 	if err != nil {
@@ -59,6 +393,41 @@ func (c *cachedOpenFGADatastore) FindLatestAuthorizationModelID(ctx context.Cont
 	return v.(string), nil
 }
 
+func (c *cachedOpenFGADatastore) refreshLatestAuthorizationModelID(ctx context.Context, storeID string) (string, error) {
+	c.latestModelIDMu.Lock()
+	generation := c.latestModelIDGenerations[storeID]
+	c.latestModelIDMu.Unlock()
+
+	modelID, err := c.OpenFGADatastore.FindLatestAuthorizationModelID(ctx, storeID)
+	if err != nil {
+		return "", err
+	}
+
+	c.latestModelIDMu.Lock()
+	defer c.latestModelIDMu.Unlock()
+
+	// a model was written while the lookup was in flight, so the ID may be outdated
+	if c.latestModelIDGenerations[storeID] != generation {
+		return modelID, nil
+	}
+
+	// keep the entry around for twice the ttl so that it can be served while it is being refreshed
+	c.latestModelIDCache.Set(storeID, &latestModelIDEntry{modelID: modelID, fetchedAt: time.Now()}, 2*c.latestModelIDTTL)
+
+	return modelID, nil
+}
+
+func latestModelIDLookupKey(storeID string) string {
+	return fmt.Sprintf("FindLatestAuthorizationModelID:%s", storeID)
+}
+
+// Close stops the caches and closes the wrapped datastore.
 func (c *cachedOpenFGADatastore) Close() {
 	c.cache.Stop()
+
+	if c.latestModelIDCache != nil {
+		c.latestModelIDCache.Stop()
+	}
+
+	c.OpenFGADatastore.Close()
 }
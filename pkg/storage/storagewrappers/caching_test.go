@@ -2,6 +2,8 @@ package storagewrappers
 
 import (
 	"context"
+	"database/sql/driver"
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
@@ -10,12 +12,34 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/oklog/ulid/v2"
 	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
 	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/storage/test"
 	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/require"
 	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
 )
 
+// TestCachedMemoryDatastore runs the datastore test suite against the memory datastore wrapped the same
+// way the server wraps every datastore engine.
+func TestCachedMemoryDatastore(t *testing.T) {
+	ds := NewCachedOpenFGADatastore(storage.NewContextWrapper(memory.New()), 100)
+	defer ds.Close()
+
+	test.RunAllTests(t, ds)
+}
+
+func TestCloseClosesTheWrappedDatastore(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().Close().Times(1)
+
+	NewCachedOpenFGADatastore(mockDatastore, 5).Close()
+}
+
 func TestReadAuthorizationModel(t *testing.T) {
 	ctx := context.Background()
 	memoryBackend := memory.New()
@@ -46,13 +70,138 @@ func TestReadAuthorizationModel(t *testing.T) {
 
 	// check what's stored inside the cache
 	modelKey := fmt.Sprintf("%s:%s", storeID, model.Id)
-	cachedModel := cachingBackend.cache.Get(modelKey).Value()
+	cachedModel, ok := cachingBackend.cache.Get(modelKey)
+	require.True(t, ok)
 	require.Equal(t, model, cachedModel)
 
 	// check that second hit to cache -> hit
 	gotModel, err = cachingBackend.ReadAuthorizationModel(ctx, storeID, model.Id)
 	require.NoError(t, err)
 	require.Equal(t, model, gotModel)
+
+	itemCount, ok := cachingBackend.ModelCacheItemCount()
+	require.True(t, ok)
+	require.Equal(t, 1, itemCount)
+}
+
+func TestReadAuthorizationModelRetriesTransientErrors(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+
+	gomock.InOrder(
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(nil, fmt.Errorf("sql error: %w", driver.ErrBadConn)).Times(1),
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(model, nil).Times(1),
+	)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelReadRetry(1, time.Millisecond))
+
+	gotModel, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+	require.NoError(t, err)
+	require.Equal(t, model, gotModel)
+
+	// the model was cached, so it isn't read again
+	gotModel, err = cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+	require.NoError(t, err)
+	require.Equal(t, model, gotModel)
+}
+
+func TestReadAuthorizationModelDoesNotRetryNotFound(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, modelID).Return(nil, storage.ErrNotFound).Times(1)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelReadRetry(3, time.Millisecond))
+
+	_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, modelID)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestReadAuthorizationModelGivesUpAfterRetries(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, modelID).Return(nil, fmt.Errorf("sql error: %w", driver.ErrBadConn)).Times(3)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelReadRetry(2, time.Millisecond))
+
+	_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, modelID)
+	require.ErrorIs(t, err, driver.ErrBadConn)
+}
+
+func TestReadAuthorizationModelDoesNotRetryPermanentErrors(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	modelID := ulid.Make().String()
+
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, modelID).Return(nil, errors.New("invalid model")).Times(1)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelReadRetry(3, time.Millisecond))
+
+	_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, modelID)
+	require.EqualError(t, err, "invalid model")
+}
+
+func TestReadAuthorizationModelSharedReadOutlivesTheCaller(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+
+	reading := make(chan struct{})
+	release := make(chan struct{})
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).DoAndReturn(func(ctx context.Context, storeID, modelID string) (*openfgapb.AuthorizationModel, error) {
+		close(reading)
+		<-release
+		return model, ctx.Err()
+	}).Times(1)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := cachingBackend.ReadAuthorizationModel(ctx, storeID, model.Id)
+		leaderErr <- err
+	}()
+	<-reading
+
+	// the caller that started the read goes away, without cancelling the read for the other callers
+	cancel()
+	require.ErrorIs(t, <-leaderErr, context.Canceled)
+
+	waiterResult := make(chan *openfgapb.AuthorizationModel, 1)
+	go func() {
+		gotModel, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+		waiterResult <- gotModel
+	}()
+
+	close(release)
+	require.Equal(t, model, <-waiterResult)
 }
 
 func TestSingleFlightFindLatestAuthorizationModelID(t *testing.T) {
@@ -81,3 +230,292 @@ func TestSingleFlightFindLatestAuthorizationModelID(t *testing.T) {
 	}
 	wg.Wait()
 }
+
+type fakeCache[T any] struct {
+	mu      sync.Mutex
+	entries map[string]T
+}
+
+func (f *fakeCache[T]) Get(key string) (T, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	v, ok := f.entries[key]
+	return v, ok
+}
+
+func (f *fakeCache[T]) Set(key string, value T, ttl time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[key] = value
+}
+
+func (f *fakeCache[T]) Delete(key string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, key)
+}
+
+func (f *fakeCache[T]) Stop() {}
+
+func TestReadAuthorizationModelWithCustomCache(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(model, nil).Times(1)
+	mockDatastore.EXPECT().Close()
+
+	cache := &fakeCache[*openfgapb.AuthorizationModel]{entries: map[string]*openfgapb.AuthorizationModel{}}
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelCache(cache))
+	defer cachingBackend.Close()
+
+	for i := 0; i < 2; i++ {
+		gotModel, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+		require.Equal(t, model, gotModel)
+	}
+
+	cachedModel, ok := cache.Get(fmt.Sprintf("%s:%s", storeID, model.Id))
+	require.True(t, ok)
+	require.Equal(t, model, cachedModel)
+
+	// the fake cache can't report its size
+	_, ok = cachingBackend.ModelCacheItemCount()
+	require.False(t, ok)
+}
+
+func TestReadAuthorizationModelCacheTTL(t *testing.T) {
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+
+	t.Run("expired_models_are_read_again", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(model, nil).Times(2)
+		mockDatastore.EXPECT().Close()
+
+		const ttl = 50 * time.Millisecond
+		cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelCacheTTL(ttl))
+		defer cachingBackend.Close()
+
+		_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+
+		_, err = cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+
+		time.Sleep(2 * ttl)
+
+		_, err = cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+	})
+
+	t.Run("zero_means_no_expiry", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(model, nil).Times(1)
+		mockDatastore.EXPECT().Close()
+
+		cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelCacheTTL(0))
+		defer cachingBackend.Close()
+
+		for i := 0; i < 2; i++ {
+			_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+			require.NoError(t, err)
+		}
+	})
+}
+
+func TestReadAuthorizationModelCacheMetrics(t *testing.T) {
+	storeID := ulid.Make().String()
+	model := &openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+	}
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+	mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), storeID, model.Id).Return(model, nil).Times(2)
+	mockDatastore.EXPECT().Close()
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithModelCacheMetricsByStore(true))
+	defer cachingBackend.Close()
+
+	hits := testutil.ToFloat64(modelCacheHitCounter.WithLabelValues(storeID))
+	misses := testutil.ToFloat64(modelCacheMissCounter.WithLabelValues(storeID))
+
+	for i := 0; i < 3; i++ {
+		_, err := cachingBackend.ReadAuthorizationModel(context.Background(), storeID, model.Id)
+		require.NoError(t, err)
+	}
+
+	// reads that bypass the cache are neither hits nor misses
+	_, err := cachingBackend.ReadAuthorizationModel(storage.ContextWithModelCacheBypass(context.Background()), storeID, model.Id)
+	require.NoError(t, err)
+
+	require.Equal(t, hits+2, testutil.ToFloat64(modelCacheHitCounter.WithLabelValues(storeID)))
+	require.Equal(t, misses+1, testutil.ToFloat64(modelCacheMissCounter.WithLabelValues(storeID)))
+}
+
+func TestSampleModelCacheMetrics(t *testing.T) {
+	ctx := context.Background()
+	memoryBackend := memory.New()
+	cachingBackend := NewCachedOpenFGADatastore(memoryBackend, 2)
+	defer cachingBackend.Close()
+
+	evictions := testutil.ToFloat64(modelCacheEvictionCounter)
+
+	storeID := ulid.Make().String()
+	for i := 0; i < 5; i++ {
+		model := &openfgapb.AuthorizationModel{
+			Id:            ulid.Make().String(),
+			SchemaVersion: typesystem.SchemaVersion1_1,
+		}
+
+		err := memoryBackend.WriteAuthorizationModel(ctx, storeID, model)
+		require.NoError(t, err)
+
+		_, err = cachingBackend.ReadAuthorizationModel(ctx, storeID, model.Id)
+		require.NoError(t, err)
+	}
+
+	// the cache is updated asynchronously, so sample it until it settles. The third model overflows the cache,
+	// which evicts all of its models
+	require.Eventually(t, func() bool {
+		cachingBackend.sampleModelCacheMetrics()
+		return testutil.ToFloat64(modelCacheItemsGauge) == 2 && testutil.ToFloat64(modelCacheEvictionCounter) == evictions+3
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(modelCacheMaxSizeGauge))
+}
+
+func TestFindLatestAuthorizationModelIDServesStaleWhileRefreshing(t *testing.T) {
+	const ttl = 200 * time.Millisecond
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	refreshed := make(chan struct{})
+	gomock.InOrder(
+		mockDatastore.EXPECT().FindLatestAuthorizationModelID(gomock.Any(), storeID).Return("first", nil),
+		mockDatastore.EXPECT().FindLatestAuthorizationModelID(gomock.Any(), storeID).DoAndReturn(func(ctx context.Context, storeID string) (string, error) {
+			defer close(refreshed)
+			return "second", nil
+		}),
+	)
+
+	mockDatastore.EXPECT().Close()
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithLatestModelIDTTL(ttl))
+	defer cachingBackend.Close()
+
+	id, err := cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, "first", id)
+
+	// a fresh entry is served from the cache
+	id, err = cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, "first", id)
+
+	time.Sleep(ttl)
+
+	// a stale entry is served while it is refreshed in the background
+	id, err = cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, "first", id)
+
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		require.FailNow(t, "the stale entry was not refreshed")
+	}
+
+	require.Eventually(t, func() bool {
+		id, err := cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+		return err == nil && id == "second"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestWriteAuthorizationModelInvalidatesLatestModelID(t *testing.T) {
+	ctx := context.Background()
+	memoryBackend := memory.New()
+	cachingBackend := NewCachedOpenFGADatastore(memoryBackend, 5, WithLatestModelIDTTL(time.Hour))
+	defer cachingBackend.Close()
+
+	storeID := ulid.Make().String()
+	for i := 0; i < 2; i++ {
+		model := &openfgapb.AuthorizationModel{
+			Id:            ulid.Make().String(),
+			SchemaVersion: typesystem.SchemaVersion1_1,
+		}
+
+		err := cachingBackend.WriteAuthorizationModel(ctx, storeID, model)
+		require.NoError(t, err)
+
+		id, err := cachingBackend.FindLatestAuthorizationModelID(ctx, storeID)
+		require.NoError(t, err)
+		require.Equal(t, model.Id, id)
+	}
+}
+
+func TestWriteAuthorizationModelDuringRefreshKeepsLatestModelID(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+	mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+
+	storeID := ulid.Make().String()
+	refreshing := make(chan struct{})
+	written := make(chan struct{})
+	refreshed := make(chan struct{})
+	gomock.InOrder(
+		mockDatastore.EXPECT().FindLatestAuthorizationModelID(gomock.Any(), storeID).Return("first", nil),
+		mockDatastore.EXPECT().FindLatestAuthorizationModelID(gomock.Any(), storeID).DoAndReturn(func(ctx context.Context, storeID string) (string, error) {
+			defer close(refreshed)
+			close(refreshing)
+			<-written
+			// the lookup read the latest model ID before the write
+			return "first", nil
+		}),
+		mockDatastore.EXPECT().FindLatestAuthorizationModelID(gomock.Any(), storeID).Return("second", nil),
+	)
+	mockDatastore.EXPECT().WriteAuthorizationModel(gomock.Any(), storeID, gomock.Any()).Return(nil)
+
+	cachingBackend := NewCachedOpenFGADatastore(mockDatastore, 5, WithLatestModelIDTTL(ttl))
+
+	id, err := cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, "first", id)
+
+	time.Sleep(ttl)
+
+	// start a background refresh and write a model while it is in flight
+	_, err = cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	<-refreshing
+
+	err = cachingBackend.WriteAuthorizationModel(context.Background(), storeID, &openfgapb.AuthorizationModel{Id: "second"})
+	require.NoError(t, err)
+	close(written)
+	<-refreshed
+
+	// the refresh that was in flight didn't cache the ID it read before the write
+	id, err = cachingBackend.FindLatestAuthorizationModelID(context.Background(), storeID)
+	require.NoError(t, err)
+	require.Equal(t, "second", id)
+}
@@ -0,0 +1,183 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+	"golang.org/x/sync/singleflight"
+)
+
+var _ storage.RelationshipTupleReader = (*coalescingTupleReader)(nil)
+
+var (
+	coalescedReadsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "datastore_coalesced_read_count",
+		Help: "Number of Read, ReadUserTuple and ReadUsersetTuples calls that were served without a datastore round-trip, because an identical read was already in flight or done within the same request",
+	}, []string{"method"})
+)
+
+type coalescingTupleReader struct {
+	storage.RelationshipTupleReader
+
+	group singleflight.Group
+
+	mu      sync.Mutex
+	results map[string]any
+}
+
+// NewCoalescingTupleReader returns a wrapper over a tuple reader that coalesces identical calls to Read,
+// ReadUserTuple and ReadUsersetTuples. Concurrent identical reads share a single datastore round-trip, and
+// the results are kept for the lifetime of the wrapper so that later identical reads don't need one at all.
+// This suits the resolution of a single Check or ListObjects request, where the same tuples are read many times
+// over by the branches of the evaluation, so a new wrapper must be created for every request.
+//
+// Reads are deadline-aware: a read is not issued if the context is already done, and a caller whose context
+// is done stops waiting on a shared read without cancelling it for the other callers.
+func NewCoalescingTupleReader(wrapped storage.RelationshipTupleReader) *coalescingTupleReader {
+	return &coalescingTupleReader{
+		RelationshipTupleReader: wrapped,
+		results:                 map[string]any{},
+	}
+}
+
+func (c *coalescingTupleReader) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
+	key := fmt.Sprintf("ReadUserTuple/%s/%s#%s@%s", store, tupleKey.GetObject(), tupleKey.GetRelation(), tupleKey.GetUser())
+
+	val, err := c.do(ctx, "ReadUserTuple", key, func(ctx context.Context) (any, error) {
+		t, err := c.RelationshipTupleReader.ReadUserTuple(ctx, store, tupleKey)
+		if errors.Is(err, storage.ErrNotFound) {
+			// a missing tuple is a result worth sharing too
+			return (*openfgapb.Tuple)(nil), nil
+		}
+		return t, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	t := val.(*openfgapb.Tuple)
+	if t == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	return t, nil
+}
+
+func (c *coalescingTupleReader) Read(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (storage.TupleIterator, error) {
+	key := fmt.Sprintf("Read/%s/%s#%s@%s", store, tupleKey.GetObject(), tupleKey.GetRelation(), tupleKey.GetUser())
+
+	val, err := c.do(ctx, "Read", key, func(ctx context.Context) (any, error) {
+		iter, err := c.RelationshipTupleReader.Read(ctx, store, tupleKey)
+		if err != nil {
+			return nil, err
+		}
+		return readAll(iter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewStaticTupleIterator(val.([]*openfgapb.Tuple)), nil
+}
+
+func (c *coalescingTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter) (storage.TupleIterator, error) {
+	allowedTypes := make([]string, 0, len(filter.AllowedUserTypeRestrictions))
+	for _, ref := range filter.AllowedUserTypeRestrictions {
+		allowedType := ref.GetType()
+		if ref.GetWildcard() != nil {
+			allowedType += ":*"
+		}
+		if ref.GetRelation() != "" {
+			allowedType += "#" + ref.GetRelation()
+		}
+		allowedTypes = append(allowedTypes, allowedType)
+	}
+	key := fmt.Sprintf("ReadUsersetTuples/%s/%s#%s@%s", store, filter.Object, filter.Relation, strings.Join(allowedTypes, ","))
+
+	val, err := c.do(ctx, "ReadUsersetTuples", key, func(ctx context.Context) (any, error) {
+		iter, err := c.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter)
+		if err != nil {
+			return nil, err
+		}
+		return readAll(iter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.NewStaticTupleIterator(val.([]*openfgapb.Tuple)), nil
+}
+
+// do returns the result of a previous identical read if there is one, or else joins or starts the read
+// identified by key. Only successful results are kept.
+func (c *coalescingTupleReader) do(ctx context.Context, method, key string, read func(context.Context) (any, error)) (any, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	val, ok := c.results[key]
+	c.mu.Unlock()
+	if ok {
+		coalescedReadsCounter.WithLabelValues(method).Inc()
+		return val, nil
+	}
+
+	resultCh := c.group.DoChan(key, func() (any, error) {
+		val, err := read(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		c.mu.Lock()
+		c.results[key] = val
+		c.mu.Unlock()
+
+		return val, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-resultCh:
+		if res.Shared {
+			coalescedReadsCounter.WithLabelValues(method).Inc()
+		}
+
+		// the read may have been issued with the context of a caller whose deadline has expired,
+		// while ours has not, in which case the read is retried with our own context
+		if res.Err != nil && res.Shared && ctx.Err() == nil && isContextError(res.Err) {
+			return read(ctx)
+		}
+
+		return res.Val, res.Err
+	}
+}
+
+func readAll(iter storage.TupleIterator) ([]*openfgapb.Tuple, error) {
+	defer iter.Stop()
+
+	var tuples []*openfgapb.Tuple
+	for {
+		t, err := iter.Next()
+		if err != nil {
+			if errors.Is(err, storage.ErrIteratorDone) {
+				return tuples, nil
+			}
+			return nil, err
+		}
+
+		tuples = append(tuples, t)
+	}
+}
+
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, storage.ErrCancelled)
+}
@@ -0,0 +1,217 @@
+package storagewrappers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	parser "github.com/craigpastro/openfga-dsl-parser/v2"
+	"github.com/oklog/ulid/v2"
+	"github.com/openfga/openfga/internal/graph"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+// countingTupleReader counts the tuple reads that reach the wrapped reader, and delays each of them
+// to simulate a datastore round-trip.
+type countingTupleReader struct {
+	storage.RelationshipTupleReader
+	delay time.Duration
+	calls atomic.Int64
+}
+
+func (c *countingTupleReader) ReadUserTuple(ctx context.Context, store string, tk *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+	return c.RelationshipTupleReader.ReadUserTuple(ctx, store, tk)
+}
+
+func (c *countingTupleReader) Read(ctx context.Context, store string, tk *openfgapb.TupleKey) (storage.TupleIterator, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+	return c.RelationshipTupleReader.Read(ctx, store, tk)
+}
+
+func (c *countingTupleReader) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter) (storage.TupleIterator, error) {
+	c.calls.Add(1)
+	time.Sleep(c.delay)
+	return c.RelationshipTupleReader.ReadUsersetTuples(ctx, store, filter)
+}
+
+func TestCoalescingTupleReader(t *testing.T) {
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	defer ds.Close()
+
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+	err := ds.Write(ctx, storeID, nil, []*openfgapb.TupleKey{tk})
+	require.NoError(t, err)
+
+	t.Run("concurrent_identical_reads_share_a_single_datastore_call", func(t *testing.T) {
+		counting := &countingTupleReader{RelationshipTupleReader: ds, delay: 50 * time.Millisecond}
+		reader := NewCoalescingTupleReader(counting)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := reader.ReadUserTuple(ctx, storeID, tk)
+				require.NoError(t, err)
+				require.Equal(t, tk, got.GetKey())
+			}()
+		}
+		wg.Wait()
+
+		require.EqualValues(t, 1, counting.calls.Load())
+	})
+
+	t.Run("results_are_reused_by_later_reads", func(t *testing.T) {
+		counting := &countingTupleReader{RelationshipTupleReader: ds}
+		reader := NewCoalescingTupleReader(counting)
+
+		for i := 0; i < 2; i++ {
+			iter, err := reader.Read(ctx, storeID, tuple.NewTupleKey("document:1", "viewer", ""))
+			require.NoError(t, err)
+
+			got, err := iter.Next()
+			require.NoError(t, err)
+			require.Equal(t, tk, got.GetKey())
+
+			_, err = iter.Next()
+			require.ErrorIs(t, err, storage.ErrIteratorDone)
+		}
+
+		require.EqualValues(t, 1, counting.calls.Load())
+	})
+
+	t.Run("not_found_is_reused_by_later_reads", func(t *testing.T) {
+		counting := &countingTupleReader{RelationshipTupleReader: ds}
+		reader := NewCoalescingTupleReader(counting)
+
+		for i := 0; i < 2; i++ {
+			_, err := reader.ReadUserTuple(ctx, storeID, tuple.NewTupleKey("document:2", "viewer", "user:anne"))
+			require.ErrorIs(t, err, storage.ErrNotFound)
+		}
+
+		require.EqualValues(t, 1, counting.calls.Load())
+	})
+
+	t.Run("no_read_is_issued_once_the_deadline_has_passed", func(t *testing.T) {
+		counting := &countingTupleReader{RelationshipTupleReader: ds}
+		reader := NewCoalescingTupleReader(counting)
+
+		cancelledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, err := reader.ReadUserTuple(cancelledCtx, storeID, tk)
+		require.ErrorIs(t, err, context.Canceled)
+		require.EqualValues(t, 0, counting.calls.Load())
+	})
+
+	t.Run("a_caller_past_its_deadline_stops_waiting_without_failing_the_others", func(t *testing.T) {
+		counting := &countingTupleReader{RelationshipTupleReader: ds, delay: 200 * time.Millisecond}
+		reader := NewCoalescingTupleReader(counting)
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			got, err := reader.ReadUserTuple(ctx, storeID, tk)
+			require.NoError(t, err)
+			require.Equal(t, tk, got.GetKey())
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+
+		shortCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+		_, err := reader.ReadUserTuple(shortCtx, storeID, tk)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+
+		<-done
+		require.EqualValues(t, 1, counting.calls.Load())
+	})
+}
+
+// BenchmarkCheckWithCoalescingTupleReader checks many documents that inherit their viewers from the same folder,
+// as ListObjects does, and reports the number of tuple reads that reached the datastore per operation.
+func BenchmarkCheckWithCoalescingTupleReader(b *testing.B) {
+	const numDocuments = 100
+
+	ctx := context.Background()
+	storeID := ulid.Make().String()
+
+	ds := memory.New()
+	defer ds.Close()
+
+	writes := []*openfgapb.TupleKey{tuple.NewTupleKey("folder:1", "viewer", "user:anne")}
+	for i := 0; i < numDocuments; i++ {
+		writes = append(writes, tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "parent", "folder:1"))
+	}
+	err := ds.Write(ctx, storeID, nil, writes)
+	require.NoError(b, err)
+
+	ctx = typesystem.ContextWithTypesystem(ctx, typesystem.New(&openfgapb.AuthorizationModel{
+		Id:            ulid.Make().String(),
+		SchemaVersion: typesystem.SchemaVersion1_1,
+		TypeDefinitions: parser.MustParse(`
+		type user
+
+		type folder
+		  relations
+		    define viewer: [user] as self
+
+		type document
+		  relations
+		    define parent: [folder] as self
+		    define viewer: [user] as self or viewer from parent
+		`),
+	}))
+
+	benchmarks := map[string]func(storage.RelationshipTupleReader) storage.RelationshipTupleReader{
+		"direct": func(r storage.RelationshipTupleReader) storage.RelationshipTupleReader {
+			return r
+		},
+		"coalescing": func(r storage.RelationshipTupleReader) storage.RelationshipTupleReader {
+			return NewCoalescingTupleReader(r)
+		},
+	}
+
+	for name, wrap := range benchmarks {
+		b.Run(name, func(b *testing.B) {
+			counting := &countingTupleReader{RelationshipTupleReader: ds, delay: time.Millisecond}
+
+			for n := 0; n < b.N; n++ {
+				// a new reader per operation, like the server creates one per request
+				checker := graph.NewLocalChecker(wrap(counting), 100)
+
+				var wg sync.WaitGroup
+				for i := 0; i < numDocuments; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						resp, err := checker.ResolveCheck(ctx, &graph.ResolveCheckRequest{
+							StoreID:            storeID,
+							TupleKey:           tuple.NewTupleKey(fmt.Sprintf("document:%d", i), "viewer", "user:anne"),
+							ResolutionMetadata: &graph.ResolutionMetadata{Depth: 25},
+						})
+						require.NoError(b, err)
+						require.True(b, resp.GetAllowed())
+					}(i)
+				}
+				wg.Wait()
+			}
+
+			b.ReportMetric(float64(counting.calls.Load())/float64(b.N), "datastore_calls/op")
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package storagewrappers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/openfga/openfga/pkg/storage"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+var _ storage.OpenFGADatastore = (*queryTimeoutOpenFGADatastore)(nil)
+
+type queryTimeoutOpenFGADatastore struct {
+	storage.OpenFGADatastore
+	timeout time.Duration
+}
+
+// NewQueryTimeoutOpenFGADatastore returns a wrapper over a datastore that bounds each call with the given timeout.
+// A call that runs out of time returns an error wrapping storage.ErrQueryTimeout, unless the context it was given
+// was done first. For the calls that return an iterator, the timeout also covers reading the iterator, until it is
+// stopped or done.
+func NewQueryTimeoutOpenFGADatastore(inner storage.OpenFGADatastore, timeout time.Duration) *queryTimeoutOpenFGADatastore {
+	return &queryTimeoutOpenFGADatastore{
+		OpenFGADatastore: inner,
+		timeout:          timeout,
+	}
+}
+
+// queryError tells an error caused by the query timeout apart from the other errors of the datastore.
+func queryError(ctx, queryCtx context.Context, err error) error {
+	if err == nil || errors.Is(err, storage.ErrIteratorDone) {
+		return err
+	}
+
+	if ctx.Err() == nil && errors.Is(queryCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", storage.ErrQueryTimeout, err)
+	}
+
+	return err
+}
+
+func (q *queryTimeoutOpenFGADatastore) Read(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (storage.TupleIterator, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+
+	iter, err := q.OpenFGADatastore.Read(queryCtx, store, tupleKey)
+	return q.iterator(ctx, queryCtx, cancel, iter, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadPage(ctx context.Context, store string, tupleKey *openfgapb.TupleKey, opts storage.PaginationOptions) ([]*openfgapb.Tuple, []byte, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	tuples, token, err := q.OpenFGADatastore.ReadPage(queryCtx, store, tupleKey, opts)
+	return tuples, token, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	t, err := q.OpenFGADatastore.ReadUserTuple(queryCtx, store, tupleKey)
+	return t, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter) (storage.TupleIterator, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+
+	iter, err := q.OpenFGADatastore.ReadUsersetTuples(queryCtx, store, filter)
+	return q.iterator(ctx, queryCtx, cancel, iter, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter) (storage.TupleIterator, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+
+	iter, err := q.OpenFGADatastore.ReadStartingWithUser(queryCtx, store, filter)
+	return q.iterator(ctx, queryCtx, cancel, iter, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) Write(ctx context.Context, store string, deletes storage.Deletes, writes storage.Writes) error {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	return queryError(ctx, queryCtx, q.OpenFGADatastore.Write(queryCtx, store, deletes, writes))
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadAuthorizationModel(ctx context.Context, store string, id string) (*openfgapb.AuthorizationModel, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	model, err := q.OpenFGADatastore.ReadAuthorizationModel(queryCtx, store, id)
+	return model, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadAuthorizationModels(ctx context.Context, store string, opts storage.PaginationOptions) ([]*openfgapb.AuthorizationModel, []byte, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	models, token, err := q.OpenFGADatastore.ReadAuthorizationModels(queryCtx, store, opts)
+	return models, token, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) FindLatestAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	id, err := q.OpenFGADatastore.FindLatestAuthorizationModelID(queryCtx, store)
+	return id, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) WriteAuthorizationModel(ctx context.Context, store string, model *openfgapb.AuthorizationModel) error {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	return queryError(ctx, queryCtx, q.OpenFGADatastore.WriteAuthorizationModel(queryCtx, store, model))
+}
+
+func (q *queryTimeoutOpenFGADatastore) CreateStore(ctx context.Context, store *openfgapb.Store) (*openfgapb.Store, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	created, err := q.OpenFGADatastore.CreateStore(queryCtx, store)
+	return created, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) DeleteStore(ctx context.Context, id string) error {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	return queryError(ctx, queryCtx, q.OpenFGADatastore.DeleteStore(queryCtx, id))
+}
+
+func (q *queryTimeoutOpenFGADatastore) GetStore(ctx context.Context, id string) (*openfgapb.Store, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	store, err := q.OpenFGADatastore.GetStore(queryCtx, id)
+	return store, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ListStores(ctx context.Context, opts storage.PaginationOptions) ([]*openfgapb.Store, []byte, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	stores, token, err := q.OpenFGADatastore.ListStores(queryCtx, opts)
+	return stores, token, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) WriteAssertions(ctx context.Context, store, modelID string, assertions []*openfgapb.Assertion) error {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	return queryError(ctx, queryCtx, q.OpenFGADatastore.WriteAssertions(queryCtx, store, modelID, assertions))
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadAssertions(ctx context.Context, store, modelID string) ([]*openfgapb.Assertion, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	assertions, err := q.OpenFGADatastore.ReadAssertions(queryCtx, store, modelID)
+	return assertions, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) ReadChanges(ctx context.Context, store, objectType string, opts storage.PaginationOptions, horizonOffset time.Duration) ([]*openfgapb.TupleChange, []byte, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	changes, token, err := q.OpenFGADatastore.ReadChanges(queryCtx, store, objectType, opts, horizonOffset)
+	return changes, token, queryError(ctx, queryCtx, err)
+}
+
+func (q *queryTimeoutOpenFGADatastore) IsReady(ctx context.Context) (bool, error) {
+	queryCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	ready, err := q.OpenFGADatastore.IsReady(queryCtx)
+	return ready, queryError(ctx, queryCtx, err)
+}
+
+// iterator keeps the query context of a call that returned an iterator alive until the iterator is stopped or done.
+func (q *queryTimeoutOpenFGADatastore) iterator(ctx, queryCtx context.Context, cancel context.CancelFunc, iter storage.TupleIterator, err error) (storage.TupleIterator, error) {
+	if err != nil {
+		cancel()
+		return nil, queryError(ctx, queryCtx, err)
+	}
+
+	return &queryTimeoutTupleIterator{
+		TupleIterator: iter,
+		ctx:           ctx,
+		queryCtx:      queryCtx,
+		cancel:        cancel,
+	}, nil
+}
+
+type queryTimeoutTupleIterator struct {
+	storage.TupleIterator
+	ctx      context.Context
+	queryCtx context.Context
+	cancel   context.CancelFunc
+}
+
+func (i *queryTimeoutTupleIterator) Next() (*openfgapb.Tuple, error) {
+	t, err := i.TupleIterator.Next()
+	if errors.Is(err, storage.ErrIteratorDone) {
+		i.cancel()
+	}
+
+	return t, queryError(i.ctx, i.queryCtx, err)
+}
+
+func (i *queryTimeoutTupleIterator) Stop() {
+	i.TupleIterator.Stop()
+	i.cancel()
+}
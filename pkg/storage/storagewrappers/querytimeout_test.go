@@ -0,0 +1,77 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oklog/ulid/v2"
+	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+func TestQueryTimeoutDatastore(t *testing.T) {
+	blockUntilDone := func(ctx context.Context, store, id string) (*openfgapb.AuthorizationModel, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	t.Run("timeout_returns_a_query_timeout_error", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(blockUntilDone)
+
+		ds := NewQueryTimeoutOpenFGADatastore(mockDatastore, 10*time.Millisecond)
+
+		_, err := ds.ReadAuthorizationModel(context.Background(), "store", "model")
+		require.ErrorIs(t, err, storage.ErrQueryTimeout)
+		require.NotErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("done_context_returns_the_datastore_error", func(t *testing.T) {
+		mockController := gomock.NewController(t)
+		defer mockController.Finish()
+
+		mockDatastore := mockstorage.NewMockOpenFGADatastore(mockController)
+		mockDatastore.EXPECT().ReadAuthorizationModel(gomock.Any(), gomock.Any(), gomock.Any()).DoAndReturn(blockUntilDone)
+
+		ds := NewQueryTimeoutOpenFGADatastore(mockDatastore, time.Minute)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		_, err := ds.ReadAuthorizationModel(ctx, "store", "model")
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		require.NotErrorIs(t, err, storage.ErrQueryTimeout)
+	})
+
+	t.Run("iterators_can_be_read_after_the_call_returns", func(t *testing.T) {
+		ctx := context.Background()
+		store := ulid.Make().String()
+		tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+		ds := NewQueryTimeoutOpenFGADatastore(memory.New(), time.Minute)
+		defer ds.Close()
+
+		err := ds.Write(ctx, store, nil, []*openfgapb.TupleKey{tk})
+		require.NoError(t, err)
+
+		iter, err := ds.Read(ctx, store, tuple.NewTupleKey("document:1", "", ""))
+		require.NoError(t, err)
+		defer iter.Stop()
+
+		got, err := iter.Next()
+		require.NoError(t, err)
+		require.Equal(t, tk, got.GetKey())
+
+		_, err = iter.Next()
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+	})
+}
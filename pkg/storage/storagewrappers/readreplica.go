@@ -0,0 +1,88 @@
+package storagewrappers
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/openfga/openfga/pkg/storage"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+var _ storage.OpenFGADatastore = (*readReplicaOpenFGADatastore)(nil)
+
+type readReplicaOpenFGADatastore struct {
+	storage.OpenFGADatastore
+	replicas []storage.OpenFGADatastore
+	next     atomic.Uint64
+}
+
+// NewReadReplicaOpenFGADatastore returns a wrapper over a primary datastore that sends the tuple reads and the
+// authorization model reads to the replicas, picked in turn, and everything else to the primary. The replicas
+// are expected to replicate the primary asynchronously, so these reads may not see the latest writes yet. Reads
+// made with a context returned by storage.ContextWithReadFromPrimary, and FindLatestAuthorizationModelID, which
+// must see the models that were just written, always go to the primary. With no replicas, every call goes to the
+// primary.
+func NewReadReplicaOpenFGADatastore(primary storage.OpenFGADatastore, replicas []storage.OpenFGADatastore) *readReplicaOpenFGADatastore {
+	return &readReplicaOpenFGADatastore{
+		OpenFGADatastore: primary,
+		replicas:         replicas,
+	}
+}
+
+// replica returns the next replica in round-robin order, or the primary if there are no replicas or the context
+// requires reading from the primary.
+func (r *readReplicaOpenFGADatastore) replica(ctx context.Context) storage.OpenFGADatastore {
+	if len(r.replicas) == 0 || storage.ReadFromPrimaryFromContext(ctx) {
+		return r.OpenFGADatastore
+	}
+
+	n := r.next.Add(1) - 1
+	return r.replicas[n%uint64(len(r.replicas))]
+}
+
+func (r *readReplicaOpenFGADatastore) Read(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (storage.TupleIterator, error) {
+	return r.replica(ctx).Read(ctx, store, tupleKey)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadPage(ctx context.Context, store string, tupleKey *openfgapb.TupleKey, opts storage.PaginationOptions) ([]*openfgapb.Tuple, []byte, error) {
+	return r.replica(ctx).ReadPage(ctx, store, tupleKey, opts)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadUserTuple(ctx context.Context, store string, tupleKey *openfgapb.TupleKey) (*openfgapb.Tuple, error) {
+	return r.replica(ctx).ReadUserTuple(ctx, store, tupleKey)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadUsersetTuples(ctx context.Context, store string, filter storage.ReadUsersetTuplesFilter) (storage.TupleIterator, error) {
+	return r.replica(ctx).ReadUsersetTuples(ctx, store, filter)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadStartingWithUser(ctx context.Context, store string, filter storage.ReadStartingWithUserFilter) (storage.TupleIterator, error) {
+	return r.replica(ctx).ReadStartingWithUser(ctx, store, filter)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadAuthorizationModel(ctx context.Context, store string, id string) (*openfgapb.AuthorizationModel, error) {
+	return r.replica(ctx).ReadAuthorizationModel(ctx, store, id)
+}
+
+func (r *readReplicaOpenFGADatastore) ReadAuthorizationModels(ctx context.Context, store string, opts storage.PaginationOptions) ([]*openfgapb.AuthorizationModel, []byte, error) {
+	return r.replica(ctx).ReadAuthorizationModels(ctx, store, opts)
+}
+
+func (r *readReplicaOpenFGADatastore) FindLatestAuthorizationModelID(ctx context.Context, store string) (string, error) {
+	return r.OpenFGADatastore.FindLatestAuthorizationModelID(ctx, store)
+}
+
+// IsReady reports whether the primary is ready to accept traffic. The replicas aren't checked, so that a single
+// replica that is down doesn't take the whole server out of service.
+func (r *readReplicaOpenFGADatastore) IsReady(ctx context.Context) (bool, error) {
+	return r.OpenFGADatastore.IsReady(ctx)
+}
+
+// Close closes the replicas and the primary.
+func (r *readReplicaOpenFGADatastore) Close() {
+	for _, replica := range r.replicas {
+		replica.Close()
+	}
+
+	r.OpenFGADatastore.Close()
+}
@@ -0,0 +1,153 @@
+package storagewrappers
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/oklog/ulid/v2"
+	mockstorage "github.com/openfga/openfga/internal/mocks"
+	"github.com/openfga/openfga/pkg/storage"
+	"github.com/openfga/openfga/pkg/storage/memory"
+	"github.com/openfga/openfga/pkg/tuple"
+	"github.com/openfga/openfga/pkg/typesystem"
+	"github.com/stretchr/testify/require"
+	openfgapb "go.buf.build/openfga/go/openfga/api/openfga/v1"
+)
+
+func TestReadReplicaDatastoreRoutesReadsToReplicasInTurn(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	primary := memory.New()
+	replica1 := memory.New()
+	replica2 := memory.New()
+
+	// only the first replica has the tuple, so a read finds it only when it is routed there
+	err := replica1.Write(ctx, store, nil, []*openfgapb.TupleKey{tk})
+	require.NoError(t, err)
+
+	ds := NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica1, replica2})
+	defer ds.Close()
+
+	_, err = ds.ReadUserTuple(ctx, store, tk)
+	require.NoError(t, err)
+
+	_, err = ds.ReadUserTuple(ctx, store, tk)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = ds.ReadUserTuple(ctx, store, tk)
+	require.NoError(t, err)
+}
+
+func TestReadReplicaDatastoreWritesToPrimary(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	primary := memory.New()
+	replica := memory.New()
+
+	ds := NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica})
+	defer ds.Close()
+
+	err := ds.Write(ctx, store, nil, []*openfgapb.TupleKey{tk})
+	require.NoError(t, err)
+
+	_, err = primary.ReadUserTuple(ctx, store, tk)
+	require.NoError(t, err)
+
+	_, err = replica.ReadUserTuple(ctx, store, tk)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestReadReplicaDatastoreWithoutReplicasReadsFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	ds := NewReadReplicaOpenFGADatastore(memory.New(), nil)
+	defer ds.Close()
+
+	err := ds.Write(ctx, store, nil, []*openfgapb.TupleKey{tk})
+	require.NoError(t, err)
+
+	_, err = ds.ReadUserTuple(ctx, store, tk)
+	require.NoError(t, err)
+}
+
+func TestReadReplicaDatastoreCloseClosesPrimaryAndReplicas(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	primary := mockstorage.NewMockOpenFGADatastore(mockController)
+	primary.EXPECT().Close().Times(1)
+
+	replica := mockstorage.NewMockOpenFGADatastore(mockController)
+	replica.EXPECT().Close().Times(1)
+
+	NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica}).Close()
+}
+
+func TestReadReplicaDatastoreReadFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+	tk := tuple.NewTupleKey("document:1", "viewer", "user:anne")
+
+	primary := memory.New()
+	replica := memory.New()
+
+	ds := NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica})
+	defer ds.Close()
+
+	// the write hasn't reached the replica yet
+	err := ds.Write(ctx, store, nil, []*openfgapb.TupleKey{tk})
+	require.NoError(t, err)
+
+	_, err = ds.ReadUserTuple(ctx, store, tk)
+	require.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = ds.ReadUserTuple(storage.ContextWithReadFromPrimary(ctx), store, tk)
+	require.NoError(t, err)
+}
+
+func TestReadReplicaDatastoreFindsLatestModelOnPrimary(t *testing.T) {
+	ctx := context.Background()
+	store := ulid.Make().String()
+
+	primary := memory.New()
+	replica := memory.New()
+
+	ds := NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica})
+	defer ds.Close()
+
+	model := &openfgapb.AuthorizationModel{
+		Id:              ulid.Make().String(),
+		SchemaVersion:   typesystem.SchemaVersion1_1,
+		TypeDefinitions: []*openfgapb.TypeDefinition{{Type: "user"}},
+	}
+	err := ds.WriteAuthorizationModel(ctx, store, model)
+	require.NoError(t, err)
+
+	modelID, err := ds.FindLatestAuthorizationModelID(ctx, store)
+	require.NoError(t, err)
+	require.Equal(t, model.Id, modelID)
+}
+
+func TestReadReplicaDatastoreIsReadyIgnoresReplicas(t *testing.T) {
+	mockController := gomock.NewController(t)
+	defer mockController.Finish()
+
+	ctx := context.Background()
+
+	primary := mockstorage.NewMockOpenFGADatastore(mockController)
+	primary.EXPECT().IsReady(gomock.Any()).Return(true, nil)
+
+	replica := mockstorage.NewMockOpenFGADatastore(mockController)
+	replica.EXPECT().IsReady(gomock.Any()).Times(0)
+
+	ready, err := NewReadReplicaOpenFGADatastore(primary, []storage.OpenFGADatastore{replica}).IsReady(ctx)
+	require.NoError(t, err)
+	require.True(t, ready)
+}
@@ -674,6 +674,81 @@ func ReadStartingWithUserTest(t *testing.T, datastore storage.OpenFGADatastore)
 	})
 }
 
+func TupleExpirationTest(t *testing.T, datastore storage.OpenFGADatastore) {
+	ctx := context.Background()
+
+	t.Run("expired_tuples_are_excluded_from_reads_and_purged", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tk := tuple.NewTupleKey("doc:readme", "viewer", "user:jon")
+
+		expiresAt := time.Now().Add(time.Second)
+		err := datastore.Write(storage.ContextWithTupleExpiration(ctx, expiresAt), storeID, nil, []*openfgapb.TupleKey{tk})
+		require.NoError(t, err)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, tk)
+		require.NoError(t, err)
+
+		time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, tk)
+		require.ErrorIs(t, err, storage.ErrNotFound)
+
+		iter, err := datastore.Read(ctx, storeID, &openfgapb.TupleKey{Object: "doc:readme"})
+		require.NoError(t, err)
+		_, err = iter.Next()
+		require.ErrorIs(t, err, storage.ErrIteratorDone)
+		iter.Stop()
+
+		purger, ok := datastore.(storage.TupleExpirationPurger)
+		if !ok {
+			return
+		}
+
+		purged, err := purger.PurgeExpiredTuples(ctx, time.Now(), 100)
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, purged, 1)
+
+		changes, _, err := datastore.ReadChanges(ctx, storeID, "", storage.PaginationOptions{PageSize: 10}, 0)
+		require.NoError(t, err)
+		require.Len(t, changes, 2)
+		require.Equal(t, openfgapb.TupleOperation_TUPLE_OPERATION_DELETE, changes[1].GetOperation())
+	})
+
+	t.Run("writing_an_expired_tuple_again_replaces_it", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tk := tuple.NewTupleKey("doc:readme", "viewer", "user:jon")
+
+		expiresAt := time.Now().Add(time.Second)
+		err := datastore.Write(storage.ContextWithTupleExpiration(ctx, expiresAt), storeID, nil, []*openfgapb.TupleKey{tk})
+		require.NoError(t, err)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgapb.TupleKey{tk})
+		require.EqualError(t, err, storage.InvalidWriteInputError(tk, openfgapb.TupleOperation_TUPLE_OPERATION_WRITE).Error())
+
+		time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+
+		err = datastore.Write(ctx, storeID, nil, []*openfgapb.TupleKey{tk})
+		require.NoError(t, err)
+
+		_, err = datastore.ReadUserTuple(ctx, storeID, tk)
+		require.NoError(t, err)
+	})
+
+	t.Run("expired_tuples_cannot_be_deleted", func(t *testing.T) {
+		storeID := ulid.Make().String()
+		tk := tuple.NewTupleKey("doc:readme", "viewer", "user:jon")
+
+		expiresAt := time.Now().Add(time.Second)
+		err := datastore.Write(storage.ContextWithTupleExpiration(ctx, expiresAt), storeID, nil, []*openfgapb.TupleKey{tk})
+		require.NoError(t, err)
+
+		time.Sleep(time.Until(expiresAt) + 10*time.Millisecond)
+
+		err = datastore.Write(ctx, storeID, []*openfgapb.TupleKey{tk}, nil)
+		require.EqualError(t, err, storage.InvalidWriteInputError(tk, openfgapb.TupleOperation_TUPLE_OPERATION_DELETE).Error())
+	})
+}
+
 func getObjects(tupleIterator storage.TupleIterator, require *require.Assertions) []string {
 	var objects []string
 	for {
@@ -0,0 +1,54 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// ExportStatus records the outcome of the last export of spans to the OTLP collector, so that whether the
+// exporter is connected can be reported. It is safe for concurrent use.
+type ExportStatus struct {
+	mu         sync.Mutex
+	lastExport time.Time
+	lastErr    error
+}
+
+// Last returns the time of the last export and its error, if it failed. The time is zero if no spans have been
+// exported yet.
+func (s *ExportStatus) Last() (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastExport, s.lastErr
+}
+
+func (s *ExportStatus) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastExport = time.Now()
+	s.lastErr = err
+}
+
+// WithExportStatus records the outcome of every export of spans in status.
+func WithExportStatus(status *ExportStatus) TracerOption {
+	return func(d *customTracer) {
+		d.exportStatus = status
+	}
+}
+
+// statusRecordingExporter is a sdktrace.SpanExporter that records the outcome of every export in an ExportStatus.
+type statusRecordingExporter struct {
+	sdktrace.SpanExporter
+	status *ExportStatus
+}
+
+func (e *statusRecordingExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+	e.status.record(err)
+
+	return err
+}
@@ -0,0 +1,42 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+type stubExporter struct {
+	err error
+}
+
+func (e *stubExporter) ExportSpans(context.Context, []sdktrace.ReadOnlySpan) error { return e.err }
+
+func (e *stubExporter) Shutdown(context.Context) error { return nil }
+
+func TestStatusRecordingExporter(t *testing.T) {
+	status := &ExportStatus{}
+	inner := &stubExporter{}
+	exp := &statusRecordingExporter{SpanExporter: inner, status: status}
+
+	lastExport, err := status.Last()
+	require.True(t, lastExport.IsZero())
+	require.NoError(t, err)
+
+	inner.err = errors.New("connection refused")
+	require.ErrorIs(t, exp.ExportSpans(context.Background(), nil), inner.err)
+
+	lastExport, err = status.Last()
+	require.WithinDuration(t, time.Now(), lastExport, time.Minute)
+	require.ErrorIs(t, err, inner.err)
+
+	inner.err = nil
+	require.NoError(t, exp.ExportSpans(context.Background(), nil))
+
+	_, err = status.Last()
+	require.NoError(t, err)
+}
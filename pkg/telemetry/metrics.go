@@ -0,0 +1,111 @@
+package telemetry
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var metricsNamespaceRegex = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+// ValidateMetricsNamespace returns an error if namespace cannot be used as a Prometheus metric name prefix.
+// An empty namespace is valid and means that metric names are not prefixed.
+func ValidateMetricsNamespace(namespace string) error {
+	if namespace == "" {
+		return nil
+	}
+
+	if !metricsNamespaceRegex.MatchString(namespace) {
+		return fmt.Errorf("'%s' is not a valid Prometheus metric name prefix", namespace)
+	}
+
+	return nil
+}
+
+// prefixedGatherer prefixes the names of all the metric families gathered by the wrapped Gatherer.
+type prefixedGatherer struct {
+	prometheus.Gatherer
+	prefix string
+}
+
+// NewPrefixedGatherer returns a Gatherer that exports every metric of the provided Gatherer under the
+// given namespace, joined to the metric name with an underscore (e.g. namespace 'openfga' exports
+// 'check_result_count' as 'openfga_check_result_count'). Prefixing at gather time means metrics registered
+// at package initialization, such as the RPC metrics, are covered too. If namespace is empty, the
+// provided Gatherer is returned as is.
+func NewPrefixedGatherer(namespace string, gatherer prometheus.Gatherer) prometheus.Gatherer {
+	if namespace == "" {
+		return gatherer
+	}
+
+	return &prefixedGatherer{
+		Gatherer: gatherer,
+		prefix:   strings.TrimSuffix(namespace, "_") + "_",
+	}
+}
+
+// Gather implements prometheus.Gatherer.
+func (p *prefixedGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := p.Gatherer.Gather()
+
+	// Gather may return partial results alongside an error, so prefix whatever was gathered.
+	for _, family := range families {
+		name := p.prefix + family.GetName()
+		family.Name = &name
+	}
+
+	return families, err
+}
+
+// constLabelsGatherer adds constant labels to all the metrics gathered by the wrapped Gatherer.
+type constLabelsGatherer struct {
+	prometheus.Gatherer
+	labels []*dto.LabelPair
+}
+
+// NewConstLabelsGatherer returns a Gatherer that adds the provided labels to every metric of the provided
+// Gatherer. A metric that already has a label with the same name keeps its own value. If labels is empty,
+// the provided Gatherer is returned as is.
+func NewConstLabelsGatherer(labels prometheus.Labels, gatherer prometheus.Gatherer) prometheus.Gatherer {
+	if len(labels) == 0 {
+		return gatherer
+	}
+
+	g := &constLabelsGatherer{Gatherer: gatherer}
+	for name, value := range labels {
+		name, value := name, value
+		g.labels = append(g.labels, &dto.LabelPair{Name: &name, Value: &value})
+	}
+
+	return g
+}
+
+// Gather implements prometheus.Gatherer.
+func (c *constLabelsGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := c.Gatherer.Gather()
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			existing := make(map[string]struct{}, len(metric.GetLabel()))
+			for _, label := range metric.GetLabel() {
+				existing[label.GetName()] = struct{}{}
+			}
+
+			for _, label := range c.labels {
+				if _, ok := existing[label.GetName()]; !ok {
+					metric.Label = append(metric.Label, label)
+				}
+			}
+
+			sort.Slice(metric.Label, func(i, j int) bool {
+				return metric.Label[i].GetName() < metric.Label[j].GetName()
+			})
+		}
+	}
+
+	return families, err
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPrefixedGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "check_result_count",
+		Help: "test counter",
+	}))
+
+	tests := []struct {
+		namespace    string
+		expectedName string
+	}{
+		{namespace: "", expectedName: "check_result_count"},
+		{namespace: "openfga", expectedName: "openfga_check_result_count"},
+		{namespace: "openfga_", expectedName: "openfga_check_result_count"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.namespace, func(t *testing.T) {
+			families, err := NewPrefixedGatherer(test.namespace, registry).Gather()
+			require.NoError(t, err)
+			require.Len(t, families, 1)
+			require.Equal(t, test.expectedName, families[0].GetName())
+		})
+	}
+}
+
+func TestValidateMetricsNamespace(t *testing.T) {
+	require.NoError(t, ValidateMetricsNamespace(""))
+	require.NoError(t, ValidateMetricsNamespace("openfga"))
+	require.NoError(t, ValidateMetricsNamespace("my_service_"))
+	require.Error(t, ValidateMetricsNamespace("my-service"))
+	require.Error(t, ValidateMetricsNamespace("1service"))
+}
+
+func TestNewConstLabelsGatherer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "check_result_count",
+		Help: "test counter",
+	}, []string{"allowed"})
+	counter.WithLabelValues("true").Inc()
+	registry.MustRegister(counter)
+
+	gatherer := NewConstLabelsGatherer(prometheus.Labels{"cluster": "us-east-1", "allowed": "overridden"}, registry)
+
+	families, err := gatherer.Gather()
+	require.NoError(t, err)
+	require.Len(t, families, 1)
+	require.Len(t, families[0].GetMetric(), 1)
+
+	labels := map[string]string{}
+	for _, label := range families[0].GetMetric()[0].GetLabel() {
+		labels[label.GetName()] = label.GetValue()
+	}
+	require.Equal(t, map[string]string{"allowed": "true", "cluster": "us-east-1"}, labels)
+}
@@ -0,0 +1,114 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type forcedSamplingCtxKey struct{}
+
+// ContextWithForcedSampling returns a context that causes every span started from it (and from any
+// of its children) to be sampled, regardless of the configured sampling ratio.
+func ContextWithForcedSampling(parent context.Context) context.Context {
+	return context.WithValue(parent, forcedSamplingCtxKey{}, true)
+}
+
+// ForcedSamplingFromContext reports whether sampling has been forced for the provided context.
+func ForcedSamplingFromContext(ctx context.Context) bool {
+	forced, ok := ctx.Value(forcedSamplingCtxKey{}).(bool)
+	return ok && forced
+}
+
+// forceableSampler always samples spans started from a context returned by ContextWithForcedSampling,
+// and otherwise defers to the wrapped sampler.
+type forceableSampler struct {
+	sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*forceableSampler)(nil)
+
+func (f forceableSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if ForcedSamplingFromContext(p.ParentContext) {
+		return sdktrace.SamplingResult{
+			Decision:   sdktrace.RecordAndSample,
+			Tracestate: trace.SpanContextFromContext(p.ParentContext).TraceState(),
+		}
+	}
+
+	return f.Sampler.ShouldSample(p)
+}
+
+func (f forceableSampler) Description() string {
+	return "ForceableSampler{" + f.Sampler.Description() + "}"
+}
+
+// newRemoteParentBasedSampler samples the spans with a remote parent, such as the span of a request whose trace
+// context was propagated by the client, like their parent was, so that the sampling decisions made upstream are
+// respected. The other spans, including the ones with a local parent, are sampled with the provided sampler, so
+// that, e.g., the ratio of the store of a span still applies.
+func newRemoteParentBasedSampler(sampler sdktrace.Sampler) sdktrace.Sampler {
+	return sdktrace.ParentBased(sampler,
+		sdktrace.WithLocalParentSampled(sampler),
+		sdktrace.WithLocalParentNotSampled(sampler),
+	)
+}
+
+// StoreIDAttributeKey is the span attribute holding the store ID of a request.
+const StoreIDAttributeKey = attribute.Key("store_id")
+
+type samplingStoreIDCtxKey struct{}
+
+// ContextWithSamplingStoreID returns a context that causes the spans started from it to be sampled with the ratio of
+// the store, if one is set with WithStoreSamplingRatios.
+func ContextWithSamplingStoreID(parent context.Context, storeID string) context.Context {
+	return context.WithValue(parent, samplingStoreIDCtxKey{}, storeID)
+}
+
+// storeSampler samples the spans of the stores that have a ratio of their own with it, and the spans of the other
+// stores, or of no store, with the default sampler. The store of a span is read from the context returned by
+// ContextWithSamplingStoreID, or else from the StoreIDAttributeKey attribute of the span.
+type storeSampler struct {
+	defaultSampler sdktrace.Sampler
+	stores         map[string]sdktrace.Sampler
+}
+
+var _ sdktrace.Sampler = (*storeSampler)(nil)
+
+func newStoreSampler(defaultSampler sdktrace.Sampler, ratios map[string]float64) storeSampler {
+	stores := make(map[string]sdktrace.Sampler, len(ratios))
+	for storeID, ratio := range ratios {
+		stores[storeID] = sdktrace.TraceIDRatioBased(ratio)
+	}
+
+	return storeSampler{defaultSampler: defaultSampler, stores: stores}
+}
+
+func (s storeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	if sampler, ok := s.stores[samplingStoreID(p)]; ok {
+		return sampler.ShouldSample(p)
+	}
+
+	return s.defaultSampler.ShouldSample(p)
+}
+
+func samplingStoreID(p sdktrace.SamplingParameters) string {
+	if storeID, ok := p.ParentContext.Value(samplingStoreIDCtxKey{}).(string); ok {
+		return storeID
+	}
+
+	for _, attr := range p.Attributes {
+		if attr.Key == StoreIDAttributeKey {
+			return attr.Value.AsString()
+		}
+	}
+
+	return ""
+}
+
+func (s storeSampler) Description() string {
+	return fmt.Sprintf("StoreSampler{default:%s,stores:%d}", s.defaultSampler.Description(), len(s.stores))
+}
@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStoreSampler(t *testing.T) {
+	sampler := newStoreSampler(sdktrace.NeverSample(), map[string]float64{
+		"important": 1,
+		"bulk":      0,
+	})
+
+	traceID := trace.TraceID{0x01}
+
+	t.Run("store_from_context", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: ContextWithSamplingStoreID(context.Background(), "important"),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.RecordAndSample, res.Decision)
+	})
+
+	t.Run("store_from_attributes", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       traceID,
+			Attributes:    []attribute.KeyValue{StoreIDAttributeKey.String("important")},
+		})
+		require.Equal(t, sdktrace.RecordAndSample, res.Decision)
+	})
+
+	t.Run("store_with_a_lower_ratio", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: ContextWithSamplingStoreID(context.Background(), "bulk"),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.Drop, res.Decision)
+	})
+
+	t.Run("other_stores_use_the_default_sampler", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: ContextWithSamplingStoreID(context.Background(), "other"),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.Drop, res.Decision)
+	})
+}
+
+func TestRemoteParentBasedSampler(t *testing.T) {
+	sampler := newRemoteParentBasedSampler(sdktrace.NeverSample())
+
+	traceID := trace.TraceID{0x01}
+
+	parentContext := func(flags trace.TraceFlags, remote bool) context.Context {
+		sc := trace.NewSpanContext(trace.SpanContextConfig{
+			TraceID:    traceID,
+			SpanID:     trace.SpanID{0x01},
+			TraceFlags: flags,
+			Remote:     remote,
+		})
+
+		if remote {
+			return trace.ContextWithRemoteSpanContext(context.Background(), sc)
+		}
+
+		return trace.ContextWithSpanContext(context.Background(), sc)
+	}
+
+	t.Run("sampled_remote_parent", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: parentContext(trace.FlagsSampled, true),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.RecordAndSample, res.Decision)
+	})
+
+	t.Run("not_sampled_remote_parent", func(t *testing.T) {
+		sampler := newRemoteParentBasedSampler(sdktrace.AlwaysSample())
+
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: parentContext(0, true),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.Drop, res.Decision)
+	})
+
+	t.Run("sampled_local_parent_uses_the_sampler", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: parentContext(trace.FlagsSampled, false),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.Drop, res.Decision)
+	})
+
+	t.Run("no_parent_uses_the_sampler", func(t *testing.T) {
+		res := sampler.ShouldSample(sdktrace.SamplingParameters{
+			ParentContext: context.Background(),
+			TraceID:       traceID,
+		})
+		require.Equal(t, sdktrace.Drop, res.Decision)
+	})
+}
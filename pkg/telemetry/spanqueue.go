@@ -0,0 +1,156 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// QueueFullPolicy decides what happens to an ended span when the queue of spans waiting to be exported is full.
+type QueueFullPolicy string
+
+const (
+	// QueueFullPolicyDrop drops the span right away.
+	QueueFullPolicyDrop QueueFullPolicy = "drop"
+
+	// QueueFullPolicyBlock blocks the goroutine ending the span until there is room in the queue, for up to
+	// a timeout, and drops the span if there still isn't. The timeout bounds the backpressure on request handling.
+	QueueFullPolicyBlock QueueFullPolicy = "block"
+)
+
+var droppedSpansCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Name: "trace_dropped_spans_count",
+	Help: "Number of sampled spans dropped without being exported because the export queue was full",
+})
+
+// queueingSpanProcessor queues ended spans in front of a blocking batch span processor. The batch span processor
+// of the SDK drops spans silently when its queue is full and can only block without a bound otherwise, so the
+// queue is handled here instead, to apply the QueueFullPolicy and to count the dropped spans.
+type queueingSpanProcessor struct {
+	next sdktrace.SpanProcessor
+
+	policy       QueueFullPolicy
+	blockTimeout time.Duration
+
+	queue    chan sdktrace.ReadOnlySpan
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+var _ sdktrace.SpanProcessor = (*queueingSpanProcessor)(nil)
+
+// newQueueingSpanProcessor returns a span processor that queues up to queueSize spans before handing them over to
+// next, which is expected to block rather than drop spans when it can't keep up.
+func newQueueingSpanProcessor(next sdktrace.SpanProcessor, queueSize int, policy QueueFullPolicy, blockTimeout time.Duration) *queueingSpanProcessor {
+	p := &queueingSpanProcessor{
+		next:         next,
+		policy:       policy,
+		blockTimeout: blockTimeout,
+		queue:        make(chan sdktrace.ReadOnlySpan, queueSize),
+		stopCh:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go p.processQueue()
+
+	return p
+}
+
+func (p *queueingSpanProcessor) processQueue() {
+	defer close(p.done)
+
+	for {
+		select {
+		case s := <-p.queue:
+			p.next.OnEnd(s)
+		case <-p.stopCh:
+			// hand over what is left in the queue before stopping
+			for {
+				select {
+				case s := <-p.queue:
+					p.next.OnEnd(s)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// OnStart implements sdktrace.SpanProcessor.
+func (p *queueingSpanProcessor) OnStart(parent context.Context, s sdktrace.ReadWriteSpan) {
+	p.next.OnStart(parent, s)
+}
+
+// OnEnd implements sdktrace.SpanProcessor.
+func (p *queueingSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	if !s.SpanContext().IsSampled() {
+		return
+	}
+
+	select {
+	case <-p.stopCh:
+		return
+	default:
+	}
+
+	select {
+	case p.queue <- s:
+		return
+	default:
+	}
+
+	if p.policy == QueueFullPolicyBlock && p.blockTimeout > 0 {
+		timer := time.NewTimer(p.blockTimeout)
+		defer timer.Stop()
+
+		select {
+		case p.queue <- s:
+			return
+		case <-timer.C:
+		case <-p.stopCh:
+		}
+	}
+
+	droppedSpansCounter.Inc()
+}
+
+// Shutdown implements sdktrace.SpanProcessor. The queued spans are handed over to the next processor before it is
+// shut down.
+func (p *queueingSpanProcessor) Shutdown(ctx context.Context) error {
+	p.stopOnce.Do(func() {
+		close(p.stopCh)
+	})
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	return p.next.Shutdown(ctx)
+}
+
+// ForceFlush implements sdktrace.SpanProcessor. It waits for the queue to be handed over to the next processor
+// before flushing it.
+func (p *queueingSpanProcessor) ForceFlush(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for len(p.queue) > 0 {
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return p.next.ForceFlush(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return p.next.ForceFlush(ctx)
+}
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// blockedSpanProcessor blocks on OnEnd until it is released, like a batch span processor that
+// can't keep up with its exporter.
+type blockedSpanProcessor struct {
+	release chan struct{}
+	ended   chan sdktrace.ReadOnlySpan
+}
+
+func (b *blockedSpanProcessor) OnStart(context.Context, sdktrace.ReadWriteSpan) {}
+
+func (b *blockedSpanProcessor) OnEnd(s sdktrace.ReadOnlySpan) {
+	<-b.release
+	b.ended <- s
+}
+
+func (b *blockedSpanProcessor) Shutdown(context.Context) error { return nil }
+
+func (b *blockedSpanProcessor) ForceFlush(context.Context) error { return nil }
+
+func TestQueueingSpanProcessor(t *testing.T) {
+	for _, policy := range []QueueFullPolicy{QueueFullPolicyDrop, QueueFullPolicyBlock} {
+		t.Run(string(policy), func(t *testing.T) {
+			next := &blockedSpanProcessor{release: make(chan struct{}), ended: make(chan sdktrace.ReadOnlySpan, 10)}
+			processor := newQueueingSpanProcessor(next, 1, policy, 20*time.Millisecond)
+
+			tp := sdktrace.NewTracerProvider(
+				sdktrace.WithSampler(sdktrace.AlwaysSample()),
+				sdktrace.WithSpanProcessor(processor),
+			)
+			tracer := tp.Tracer("test")
+
+			droppedBefore := testutil.ToFloat64(droppedSpansCounter)
+
+			// the first span is picked up by the queue goroutine, which blocks on the next processor,
+			// the second one fills the queue and the third one is dropped
+			for i := 0; i < 3; i++ {
+				_, span := tracer.Start(context.Background(), "span")
+				start := time.Now()
+				span.End()
+
+				if i == 2 && policy == QueueFullPolicyBlock {
+					require.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			require.Equal(t, droppedBefore+1, testutil.ToFloat64(droppedSpansCounter))
+
+			close(next.release)
+			require.NoError(t, tp.Shutdown(context.Background()))
+			require.Len(t, next.ended, 2)
+		})
+	}
+}
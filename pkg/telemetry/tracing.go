@@ -3,18 +3,45 @@ package telemetry
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ComponentKey is the span attribute identifying the OpenFGA component (e.g. "server" or "resolver")
+// that produced a span. It allows latency to be broken down per component within a single service.
+const ComponentKey = attribute.Key("openfga.component")
+
+// ClusterKey is the resource attribute identifying the cluster or deployment an OpenFGA instance runs in.
+// The same value is added as the ClusterLabel label to metrics.
+const ClusterKey = attribute.Key("openfga.cluster")
+
+// ClusterLabel is the metric label identifying the cluster or deployment an OpenFGA instance runs in.
+const ClusterLabel = "cluster"
+
+// OTLPProtocol is the protocol the spans are exported to the collector with.
+type OTLPProtocol string
+
+const (
+	// OTLPProtocolGRPC exports the spans with OTLP over gRPC, usually on port 4317.
+	OTLPProtocolGRPC OTLPProtocol = "grpc"
+
+	// OTLPProtocolHTTPProtobuf exports the spans with OTLP over HTTP with protobuf payloads, usually on port 4318.
+	OTLPProtocolHTTPProtobuf OTLPProtocol = "http/protobuf"
 )
 
 type TracerOption func(d *customTracer)
@@ -25,30 +52,118 @@ func WithOTLPEndpoint(endpoint string) TracerOption {
 	}
 }
 
+// WithOTLPProtocol sets the protocol the spans are exported with. It defaults to OTLPProtocolGRPC. With
+// OTLPProtocolHTTPProtobuf, the endpoint can also be a full URL, such as 'https://collector:4318/v1/traces'.
+func WithOTLPProtocol(protocol OTLPProtocol) TracerOption {
+	return func(d *customTracer) {
+		d.protocol = protocol
+	}
+}
+
+// WithOTLPTLS exports the spans to the collector over TLS with the provided config, e.g. with a client certificate
+// for a collector that requires mutual TLS. With a nil config, the spans are exported in plaintext.
+func WithOTLPTLS(cfg *tls.Config) TracerOption {
+	return func(d *customTracer) {
+		d.tlsConfig = cfg
+	}
+}
+
 func WithSamplingRatio(samplingRatio float64) TracerOption {
 	return func(d *customTracer) {
 		d.samplingRatio = samplingRatio
 	}
 }
 
+// WithParentBasedSampling samples the spans of the requests that carry the trace context of a remote parent like
+// the parent was, instead of with the sampling ratio: sampled if the parent was sampled, dropped otherwise. Spans
+// started from a context returned by ContextWithForcedSampling are sampled either way.
+func WithParentBasedSampling(enabled bool) TracerOption {
+	return func(d *customTracer) {
+		d.parentBasedSampling = enabled
+	}
+}
+
+// WithStoreSamplingRatios sets the sampling ratio of the spans of some stores, keyed by store ID, instead of the
+// ratio set with WithSamplingRatio. The store of a span is read from the context returned by
+// ContextWithSamplingStoreID, or else from the StoreIDAttributeKey attribute of the span.
+func WithStoreSamplingRatios(ratios map[string]float64) TracerOption {
+	return func(d *customTracer) {
+		d.storeSamplingRatios = ratios
+	}
+}
+
 func WithAttributes(attrs ...attribute.KeyValue) TracerOption {
 	return func(d *customTracer) {
 		d.attributes = attrs
 	}
 }
 
+// WithQueueFullPolicy sets what happens to ended spans when the export queue is full. With QueueFullPolicyBlock,
+// the goroutine ending a span waits for up to blockTimeout for room in the queue. Dropped spans are counted by the
+// 'trace_dropped_spans_count' metric whatever the policy.
+func WithQueueFullPolicy(policy QueueFullPolicy, blockTimeout time.Duration) TracerOption {
+	return func(d *customTracer) {
+		d.queueFullPolicy = policy
+		d.queueFullBlockTimeout = blockTimeout
+	}
+}
+
+// WithBatchMaxQueueSize sets the maximum number of ended spans waiting to be exported. It defaults to
+// sdktrace.DefaultMaxQueueSize. What happens to spans ended when the queue is full is set with WithQueueFullPolicy.
+func WithBatchMaxQueueSize(size int) TracerOption {
+	return func(d *customTracer) {
+		d.batchMaxQueueSize = size
+	}
+}
+
+// WithBatchMaxExportBatchSize sets the maximum number of spans exported at once. It defaults to
+// sdktrace.DefaultMaxExportBatchSize.
+func WithBatchMaxExportBatchSize(size int) TracerOption {
+	return func(d *customTracer) {
+		d.batchMaxExportBatchSize = size
+	}
+}
+
+// WithBatchTimeout sets how long ended spans wait for a full batch before being exported anyway. It defaults to
+// sdktrace.DefaultScheduleDelay milliseconds.
+func WithBatchTimeout(timeout time.Duration) TracerOption {
+	return func(d *customTracer) {
+		d.batchTimeout = timeout
+	}
+}
+
 type customTracer struct {
 	endpoint   string
+	protocol   OTLPProtocol
+	tlsConfig  *tls.Config
 	attributes []attribute.KeyValue
 
-	samplingRatio float64
+	samplingRatio       float64
+	storeSamplingRatios map[string]float64
+	parentBasedSampling bool
+
+	queueFullPolicy       QueueFullPolicy
+	queueFullBlockTimeout time.Duration
+
+	batchMaxQueueSize       int
+	batchMaxExportBatchSize int
+	batchTimeout            time.Duration
+
+	exportStatus *ExportStatus
 }
 
 func MustNewTracerProvider(opts ...TracerOption) *sdktrace.TracerProvider {
 	tracer := &customTracer{
 		endpoint:      "",
+		protocol:      OTLPProtocolGRPC,
 		attributes:    []attribute.KeyValue{},
 		samplingRatio: 0,
+
+		queueFullPolicy: QueueFullPolicyDrop,
+
+		batchMaxQueueSize:       sdktrace.DefaultMaxQueueSize,
+		batchMaxExportBatchSize: sdktrace.DefaultMaxExportBatchSize,
+		batchTimeout:            sdktrace.DefaultScheduleDelay * time.Millisecond,
 	}
 
 	for _, opt := range opts {
@@ -66,19 +181,45 @@ func MustNewTracerProvider(opts ...TracerOption) *sdktrace.TracerProvider {
 	defer cancel()
 
 	var exp sdktrace.SpanExporter
-	exp, err = otlptracegrpc.New(ctx,
-		otlptracegrpc.WithInsecure(),
-		otlptracegrpc.WithEndpoint(tracer.endpoint),
-		otlptracegrpc.WithDialOption(grpc.WithBlock()),
-	)
+	switch tracer.protocol {
+	case OTLPProtocolGRPC:
+		exp, err = newOTLPGRPCExporter(ctx, tracer.endpoint, tracer.tlsConfig)
+	case OTLPProtocolHTTPProtobuf:
+		exp, err = newOTLPHTTPExporter(ctx, tracer.endpoint, tracer.tlsConfig)
+	default:
+		err = fmt.Errorf("unsupported otlp protocol '%s'", tracer.protocol)
+	}
 	if err != nil {
 		panic(fmt.Sprintf("failed to establish a connection with the otlp exporter: %v", err))
 	}
 
+	if tracer.exportStatus != nil {
+		exp = &statusRecordingExporter{SpanExporter: exp, status: tracer.exportStatus}
+	}
+
+	sampler := sdktrace.TraceIDRatioBased(tracer.samplingRatio)
+	if len(tracer.storeSamplingRatios) > 0 {
+		sampler = newStoreSampler(sampler, tracer.storeSamplingRatios)
+	}
+
+	if tracer.parentBasedSampling {
+		sampler = newRemoteParentBasedSampler(sampler)
+	}
+
 	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(tracer.samplingRatio)),
+		sdktrace.WithSampler(forceableSampler{sampler}),
 		sdktrace.WithResource(res),
-		sdktrace.WithSpanProcessor(sdktrace.NewBatchSpanProcessor(exp)),
+		sdktrace.WithSpanProcessor(newQueueingSpanProcessor(
+			sdktrace.NewBatchSpanProcessor(exp,
+				sdktrace.WithBlocking(),
+				sdktrace.WithMaxQueueSize(tracer.batchMaxQueueSize),
+				sdktrace.WithMaxExportBatchSize(tracer.batchMaxExportBatchSize),
+				sdktrace.WithBatchTimeout(tracer.batchTimeout),
+			),
+			tracer.batchMaxQueueSize,
+			tracer.queueFullPolicy,
+			tracer.queueFullBlockTimeout,
+		)),
 	)
 
 	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
@@ -88,6 +229,51 @@ func MustNewTracerProvider(opts ...TracerOption) *sdktrace.TracerProvider {
 	return tp
 }
 
+func newOTLPGRPCExporter(ctx context.Context, endpoint string, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
+	transportOption := otlptracegrpc.WithInsecure()
+	if tlsConfig != nil {
+		transportOption = otlptracegrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig))
+	}
+
+	return otlptracegrpc.New(ctx,
+		transportOption,
+		otlptracegrpc.WithEndpoint(endpoint),
+		otlptracegrpc.WithDialOption(grpc.WithBlock()),
+	)
+}
+
+// newOTLPHTTPExporter returns an exporter of spans with OTLP over HTTP. The endpoint is either a 'host:port', in
+// which case the spans are sent to the default '/v1/traces' path, or a full URL. Without a TLS config, the spans
+// are sent in plaintext unless the URL has the 'https' scheme.
+func newOTLPHTTPExporter(ctx context.Context, endpoint string, tlsConfig *tls.Config) (sdktrace.SpanExporter, error) {
+	var opts []otlptracehttp.Option
+
+	secure := tlsConfig != nil
+	if strings.Contains(endpoint, "://") {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid otlp endpoint '%s': %w", endpoint, err)
+		}
+
+		endpoint = u.Host
+		if u.Path != "" {
+			opts = append(opts, otlptracehttp.WithURLPath(u.Path))
+		}
+
+		secure = secure || u.Scheme == "https"
+	}
+
+	opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+
+	if !secure {
+		opts = append(opts, otlptracehttp.WithInsecure())
+	} else if tlsConfig != nil {
+		opts = append(opts, otlptracehttp.WithTLSClientConfig(tlsConfig))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
 func TraceError(span trace.Span, err error) {
 	span.RecordError(err)
 	span.SetStatus(codes.Error, err.Error())
@@ -0,0 +1,65 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPHTTPExporter(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		paths = append(paths, r.URL.Path)
+	}))
+	defer collector.Close()
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		expectedPath string
+	}{
+		{
+			name:         "host_and_port",
+			endpoint:     collector.Listener.Addr().String(),
+			expectedPath: "/v1/traces",
+		},
+		{
+			name:         "full_url",
+			endpoint:     collector.URL + "/otlp/v1/traces",
+			expectedPath: "/otlp/v1/traces",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mu.Lock()
+			paths = nil
+			mu.Unlock()
+
+			tp := MustNewTracerProvider(
+				WithOTLPEndpoint(test.endpoint),
+				WithOTLPProtocol(OTLPProtocolHTTPProtobuf),
+				WithSamplingRatio(1),
+			)
+			defer func() {
+				_ = tp.Shutdown(context.Background())
+			}()
+
+			_, span := tp.Tracer("test").Start(context.Background(), "span")
+			span.End()
+
+			require.NoError(t, tp.ForceFlush(context.Background()))
+
+			mu.Lock()
+			defer mu.Unlock()
+			require.Equal(t, []string{test.expectedPath}, paths)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/openfga/openfga/assets"
+	"github.com/pressly/goose/v3"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type sqliteTestContainer struct {
+	path    string
+	version int64
+}
+
+// NewSQLiteTestContainer returns an implementation of the DatastoreTestContainer interface
+// for SQLite.
+func NewSQLiteTestContainer() *sqliteTestContainer {
+	return &sqliteTestContainer{}
+}
+
+func (s *sqliteTestContainer) GetDatabaseSchemaVersion() int64 {
+	return s.version
+}
+
+// RunSQLiteTestContainer creates a SQLite database file in a temporary directory, runs all the
+// migrations against it, and returns a bootstrapped implementation of the DatastoreTestContainer
+// interface wired up for the SQLite datastore engine. No container is needed, since SQLite runs
+// in-process.
+func (s *sqliteTestContainer) RunSQLiteTestContainer(t testing.TB) DatastoreTestContainer {
+	sqliteTestContainer := &sqliteTestContainer{
+		path: filepath.Join(t.TempDir(), "openfga.db"),
+	}
+
+	goose.SetLogger(goose.NopLogger())
+
+	db, err := goose.OpenDBWithDriver("sqlite", sqliteTestContainer.GetConnectionURI(true))
+	require.NoError(t, err)
+
+	goose.SetBaseFS(assets.EmbedMigrations)
+
+	err = goose.Up(db, assets.SQLiteMigrationDir)
+	require.NoError(t, err)
+	version, err := goose.GetDBVersion(db)
+	require.NoError(t, err)
+	sqliteTestContainer.version = version
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	return sqliteTestContainer
+}
+
+// GetConnectionURI returns the path of the database file of the sqlite test container.
+func (s *sqliteTestContainer) GetConnectionURI(includeCredentials bool) string {
+	return s.path
+}
+
+func (s *sqliteTestContainer) GetUsername() string {
+	return ""
+}
+
+func (s *sqliteTestContainer) GetPassword() string {
+	return ""
+}
@@ -24,7 +24,8 @@ type TypesystemResolverFunc func(ctx context.Context, storeID, modelID string) (
 // MemoizedTypesystemResolverFunc returns a TypesystemResolverFunc that either fetches the provided authorization
 // model (if provided) or looks up the latest authorization model, and then it constructs a TypeSystem from
 // the resolved model. The type-system resolution is memoized so if another lookup of the same model occurs,
-// then the earlier TypeSystem that was constructed will be used.
+// then the earlier TypeSystem that was constructed will be used. Lookups made with a context returned by
+// storage.ContextWithModelCacheBypass always read the model from the datastore, and don't update the memoized ones.
 //
 // The memoized resolver function is safe for concurrent use.
 func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBackend) TypesystemResolverFunc {
@@ -39,6 +40,18 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 
 		var err error
 
+		// requests that bypass the model caches read straight from the datastore, without sharing the lookups
+		// of the other requests, which may be served from the cache of the datastore.
+		bypassCache := storage.ModelCacheBypassFromContext(ctx)
+		lookup := func(key string, fn func() (interface{}, error)) (interface{}, error) {
+			if bypassCache {
+				return fn()
+			}
+
+			v, err, _ := lookupGroup.Do(key, fn)
+			return v, err
+		}
+
 		if modelID != "" {
 			if _, err := ulid.Parse(modelID); err != nil {
 				return nil, ErrModelNotFound
@@ -46,7 +59,7 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 		}
 
 		if modelID == "" {
-			v, err, _ := lookupGroup.Do(fmt.Sprintf("FindLatestAuthorizationModelID:%s", storeID), func() (interface{}, error) {
+			v, err := lookup(fmt.Sprintf("FindLatestAuthorizationModelID:%s", storeID), func() (interface{}, error) {
 				return datastore.FindLatestAuthorizationModelID(ctx, storeID)
 			})
 			if err != nil {
@@ -62,12 +75,14 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 
 		key := fmt.Sprintf("%s/%s", storeID, modelID)
 
-		item := cache.Get(key)
-		if item != nil {
-			return item.Value(), nil
+		if !bypassCache {
+			item := cache.Get(key)
+			if item != nil {
+				return item.Value(), nil
+			}
 		}
 
-		v, err, _ := lookupGroup.Do(fmt.Sprintf("ReadAuthorizationModel:%s/%s", storeID, modelID), func() (interface{}, error) {
+		v, err := lookup(fmt.Sprintf("ReadAuthorizationModel:%s/%s", storeID, modelID), func() (interface{}, error) {
 			return datastore.ReadAuthorizationModel(ctx, storeID, modelID)
 		})
 		if err != nil {
@@ -85,6 +100,10 @@ func MemoizedTypesystemResolverFunc(datastore storage.AuthorizationModelReadBack
 			return nil, fmt.Errorf("%w: %v", ErrInvalidModel, err)
 		}
 
+		if bypassCache {
+			return typesys, nil
+		}
+
 		cache.Set(key, typesys, typesystemCacheTTL)
 
 		return typesys, nil
@@ -18,6 +18,18 @@ var tracer = otel.Tracer("openfga/pkg/typesystem")
 
 type ctxKey string
 
+// A model is always evaluated with the semantics of the schema version it was written with, which is stored
+// along with it, so that upgrading the server never silently changes the results of the models of a store.
+// The semantics differ as follows:
+//
+//   - 1.0: relations have no type restrictions, so a 'user' field can be any user ID, e.g. 'anne', and the
+//     untyped wildcard '*' relates every user to the object.
+//   - 1.1: relations define the types of users they can be related to, which must be objects ('type:id'),
+//     usersets ('type:id#relation') or typed wildcards ('type:*'). A typed wildcard only relates the users of
+//     its type, and tuples that don't satisfy the type restrictions are ignored.
+//
+// Only 1.1 models are served by the Check, ListObjects, Expand and Write APIs. 1.0 models are rejected with a
+// validation error rather than evaluated with the semantics of 1.1.
 const (
 	SchemaVersion1_0 string = "1.0"
 	SchemaVersion1_1 string = "1.1"